@@ -18,13 +18,13 @@ require (
 	github.com/swaggo/files v0.0.0-20220728132757-551d4a08d97a
 	github.com/swaggo/gin-swagger v1.5.2
 	github.com/swaggo/swag v1.8.5
+	github.com/zhashkevych/scheduler v1.0.0
 	go.mongodb.org/mongo-driver v1.10.1
 )
 
 require (
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible // indirect
 	github.com/montanaflynn/stats v0.6.6 // indirect
-	github.com/zhashkevych/scheduler v1.0.0 // indirect
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
 )
 
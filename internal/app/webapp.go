@@ -11,12 +11,14 @@ import (
 	cachememory "github.com/o-sokol-o/cache-memory"
 	cmd_line "github.com/o-sokol-o/hub/pkg/cmd_line"
 	database "github.com/o-sokol-o/hub/pkg/database/postgres"
+	"github.com/o-sokol-o/hub/pkg/jwt_processing"
 	webserver "github.com/o-sokol-o/hub/pkg/server"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"github.com/zhashkevych/scheduler"
 
 	repositories "github.com/o-sokol-o/hub/internal/repositories/pgsql"
 	services "github.com/o-sokol-o/hub/internal/services"
@@ -52,6 +54,7 @@ type AppContext struct {
 	cacheMemory cachememory.Cache
 	masterDB    *sqlx.DB
 	handlers    *handlers.Handler
+	scheduler   *scheduler.Scheduler
 
 	// Env          webcontext.Env
 	// MasterDbHost string
@@ -167,6 +170,30 @@ func NewApplication(log *logrus.Logger) (*AppContext, error) {
 		if app.cfg.HTTP.Port == "" {
 			app.cfg.HTTP.Port = os.Getenv("LOCALPORT")
 		}
+
+		// Ключи подписи токенов
+		var jwtCfg jwt_processing.Config
+		if err := viper.UnmarshalKey("jwt", &jwtCfg); err != nil {
+			log.Printf("error reading jwt config: %s", err.Error())
+			return nil, err
+		}
+		if len(jwtCfg.Keys) == 0 {
+			// Случайный ключ разлогинивает всех при перезапуске, а токены одного экземпляра
+			// не принимаются другими - без ключей приложение запускается только с явным jwt.ephemeral
+			if !jwtCfg.Ephemeral {
+				log.Printf("error loading jwt keys: no signing keys configured (jwt.keys), set jwt.ephemeral for development")
+				return nil, errors.New("jwt: no signing keys configured")
+			}
+			log.Printf("jwt: no signing keys configured, using a random key: tokens do not survive a restart")
+			jwt_processing.SetKeys(jwt_processing.NewEphemeralKeySet())
+		} else {
+			keys, err := jwt_processing.LoadKeys(jwtCfg)
+			if err != nil {
+				log.Printf("error loading jwt keys: %s", err.Error())
+				return nil, err
+			}
+			jwt_processing.SetKeys(keys)
+		}
 	}
 
 	// =============   Инициализируем кэш и подключаемся к БД   =============
@@ -213,6 +240,13 @@ func NewApplication(log *logrus.Logger) (*AppContext, error) {
 		return nil, err
 	}
 
+	// Каналы уведомлений
+	app.handlers.InitNotifications(app.cfg)
+
+	// Плановые задачи
+	app.scheduler = scheduler.NewScheduler()
+	app.handlers.InitJobs(context.Background(), app.scheduler)
+
 	return &app, nil
 }
 
@@ -242,6 +276,9 @@ func (app *AppContext) Shutdown() {
 		app.log.Printf("error occured on server shutting down: %s", err.Error())
 	}
 
+	// Дождаться завершения плановых задач
+	app.scheduler.Stop()
+
 	// TODO: Добавить запись кэша в базу
 	// AppCtx.MasterDB.SaveCacheData(AppCtx.CacheMemory)
 
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Виды правил оповещения
+const (
+	AlertAbove   = "above"   // значение выше threshold_high
+	AlertBelow   = "below"   // значение ниже threshold_low
+	AlertOutside = "outside" // значение вне диапазона threshold_low..threshold_high
+	AlertNoData  = "no_data" // нет показаний дольше no_data_sec
+)
+
+// Состояния правила
+const (
+	AlertStateOK      = "ok"
+	AlertStatePending = "pending" // порог превышен, но меньше min_duration_sec
+	AlertStateFiring  = "firing"
+)
+
+// Виды событий оповещения
+const (
+	AlertEventFiring       = "firing"
+	AlertEventResolved     = "resolved"
+	AlertEventAcknowledged = "acknowledged"
+	AlertEventSnoozed      = "snoozed"
+	AlertEventUnsnoozed    = "unsnoozed" // отсрочка истекла, оповещение снова активно
+)
+
+// Статусы экземпляра оповещения
+const (
+	AlertFiring       = "firing"
+	AlertAcknowledged = "acknowledged"
+	AlertSnoozed      = "snoozed"
+	AlertResolved     = "resolved"
+)
+
+// Ограничения длительностей: ожидание - до суток, нет данных, напоминания и отсрочка - до недели
+const (
+	MaxAlertMinDuration = 24 * 60 * 60
+	MaxAlertNoData      = 7 * 24 * 60 * 60
+	MinAlertRenotify    = 5 * 60
+	MaxAlertRenotify    = 7 * 24 * 60 * 60
+	MaxAlertSnooze      = 7 * 24 * 60 * 60
+)
+
+var ErrAlertAction = errors.New("alert action is not allowed")
+
+// Правило оповещения по показаниям сенсора.
+// Сработавшее правило возвращается в ok, когда значение вернётся за порог с запасом hysteresis.
+type AlertRule struct {
+	ID             int        `json:"id" db:"id" example:"1"`
+	AccountID      int        `json:"account_id" db:"account_id" example:"1"`
+	SensorID       int        `json:"sensor_id" db:"sensor_id" example:"12"`
+	Title          string     `json:"title" db:"title" example:"Reef tank is too hot"`
+	Kind           string     `json:"kind" db:"kind" example:"above"`
+	ThresholdLow   *float64   `json:"threshold_low,omitempty" db:"threshold_low"`
+	ThresholdHigh  *float64   `json:"threshold_high,omitempty" db:"threshold_high" example:"28"`
+	Hysteresis     float64    `json:"hysteresis" db:"hysteresis" example:"0.5"`
+	MinDurationSec int        `json:"min_duration_sec" db:"min_duration_sec" example:"300"`
+	NoDataSec      int        `json:"no_data_sec" db:"no_data_sec" example:"0"`
+	RenotifySec    int        `json:"renotify_sec" db:"renotify_sec" example:"1800"`
+	Enabled        bool       `json:"enabled" db:"enabled" example:"true"`
+	State          string     `json:"state" db:"state" example:"ok"`
+	PendingSince   *time.Time `json:"pending_since,omitempty" db:"pending_since"`
+	LastReadingAt  *time.Time `json:"last_reading_at,omitempty" db:"last_reading_at"`
+	StateChangedAt time.Time  `json:"state_changed_at" db:"state_changed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Validate проверяет согласованность порогов и длительностей правила
+func (r AlertRule) Validate() error {
+	switch r.Kind {
+	case AlertAbove:
+		if r.ThresholdHigh == nil {
+			return errors.New("threshold_high is required")
+		}
+	case AlertBelow:
+		if r.ThresholdLow == nil {
+			return errors.New("threshold_low is required")
+		}
+	case AlertOutside:
+		if r.ThresholdLow == nil || r.ThresholdHigh == nil {
+			return errors.New("threshold_low and threshold_high are required")
+		}
+		if *r.ThresholdLow >= *r.ThresholdHigh {
+			return errors.New("threshold_low must be less than threshold_high")
+		}
+		if 2*r.Hysteresis >= *r.ThresholdHigh-*r.ThresholdLow {
+			return errors.New("hysteresis must be less than half of the range")
+		}
+	case AlertNoData:
+		if r.NoDataSec <= 0 || r.NoDataSec > MaxAlertNoData {
+			return errors.New("no_data_sec must be between 1 and 604800")
+		}
+	default:
+		return errors.New("kind must be one of: above, below, outside, no_data")
+	}
+
+	if r.Hysteresis < 0 {
+		return errors.New("hysteresis must not be negative")
+	}
+	if r.MinDurationSec < 0 || r.MinDurationSec > MaxAlertMinDuration {
+		return errors.New("min_duration_sec must be between 0 and 86400")
+	}
+	if r.Kind != AlertNoData && r.NoDataSec != 0 {
+		return errors.New("no_data_sec is used only by no_data rules")
+	}
+	if r.RenotifySec != 0 && (r.RenotifySec < MinAlertRenotify || r.RenotifySec > MaxAlertRenotify) {
+		return errors.New("renotify_sec must be 0 or between 300 and 604800")
+	}
+
+	return nil
+}
+
+type CreateAlertRule struct {
+	SensorID       int      `json:"sensor_id" binding:"required" example:"12"`
+	Title          string   `json:"title" example:"Reef tank is too hot"`
+	Kind           string   `json:"kind" binding:"required" example:"above"`
+	ThresholdLow   *float64 `json:"threshold_low,omitempty"`
+	ThresholdHigh  *float64 `json:"threshold_high,omitempty" example:"28"`
+	Hysteresis     float64  `json:"hysteresis" example:"0.5"`
+	MinDurationSec int      `json:"min_duration_sec" example:"300"`
+	NoDataSec      int      `json:"no_data_sec" example:"0"`
+	RenotifySec    int      `json:"renotify_sec" example:"1800"`
+	Enabled        *bool    `json:"enabled,omitempty" example:"true"`
+}
+
+func (i CreateAlertRule) Rule() AlertRule {
+	r := AlertRule{
+		SensorID:       i.SensorID,
+		Title:          i.Title,
+		Kind:           i.Kind,
+		ThresholdLow:   i.ThresholdLow,
+		ThresholdHigh:  i.ThresholdHigh,
+		Hysteresis:     i.Hysteresis,
+		MinDurationSec: i.MinDurationSec,
+		NoDataSec:      i.NoDataSec,
+		RenotifySec:    i.RenotifySec,
+		Enabled:        true,
+		State:          AlertStateOK,
+	}
+	if i.Enabled != nil {
+		r.Enabled = *i.Enabled
+	}
+	return r
+}
+
+func (i CreateAlertRule) Validate() error {
+	if i.SensorID <= 0 {
+		return errors.New("invalid sensor_id")
+	}
+	if len(i.Title) > 255 {
+		return errors.New("title is too long")
+	}
+	return i.Rule().Validate()
+}
+
+// Вид правила и сенсор не меняются - для другого условия создаётся новое правило
+type UpdateAlertRule struct {
+	ID             int      `json:"-"`
+	Title          *string  `json:"title" example:"Reef tank is too hot"`
+	ThresholdLow   *float64 `json:"threshold_low"`
+	ThresholdHigh  *float64 `json:"threshold_high" example:"29"`
+	Hysteresis     *float64 `json:"hysteresis" example:"0.5"`
+	MinDurationSec *int     `json:"min_duration_sec" example:"600"`
+	NoDataSec      *int     `json:"no_data_sec"`
+	RenotifySec    *int     `json:"renotify_sec" example:"3600"`
+	Enabled        *bool    `json:"enabled" example:"false"`
+}
+
+func (i UpdateAlertRule) Validate() error {
+	if i.ID == 0 || (i.Title == nil && i.ThresholdLow == nil && i.ThresholdHigh == nil && i.Hysteresis == nil &&
+		i.MinDurationSec == nil && i.NoDataSec == nil && i.RenotifySec == nil && i.Enabled == nil) {
+		return errors.New("update has no values")
+	}
+	if i.Title != nil && len(*i.Title) > 255 {
+		return errors.New("title is too long")
+	}
+
+	return nil
+}
+
+// Apply возвращает правило с применёнными изменениями
+func (i UpdateAlertRule) Apply(r AlertRule) AlertRule {
+	if i.Title != nil {
+		r.Title = *i.Title
+	}
+	if i.ThresholdLow != nil {
+		r.ThresholdLow = i.ThresholdLow
+	}
+	if i.ThresholdHigh != nil {
+		r.ThresholdHigh = i.ThresholdHigh
+	}
+	if i.Hysteresis != nil {
+		r.Hysteresis = *i.Hysteresis
+	}
+	if i.MinDurationSec != nil {
+		r.MinDurationSec = *i.MinDurationSec
+	}
+	if i.NoDataSec != nil {
+		r.NoDataSec = *i.NoDataSec
+	}
+	if i.RenotifySec != nil {
+		r.RenotifySec = *i.RenotifySec
+	}
+	if i.Enabled != nil {
+		r.Enabled = *i.Enabled
+	}
+	return r
+}
+
+// Событие оповещения: срабатывание, снятие или действие пользователя (UserID)
+type AlertEvent struct {
+	ID        int       `json:"id" db:"id" example:"1"`
+	AlertID   *int      `json:"alert_id,omitempty" db:"alert_id" example:"7"`
+	RuleID    int       `json:"rule_id" db:"rule_id" example:"1"`
+	AccountID int       `json:"account_id" db:"account_id" example:"1"`
+	SensorID  int       `json:"sensor_id" db:"sensor_id" example:"12"`
+	Kind      string    `json:"kind" db:"kind" example:"firing"`
+	Value     *string   `json:"value,omitempty" db:"value" example:"28.4"`
+	Message   string    `json:"message" db:"message" example:"Reef tank is too hot: 28.4 is above 28"`
+	UserID    *int      `json:"user_id,omitempty" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Фильтр списка событий
+type AlertEventsFilter struct {
+	SensorID  int
+	RuleID    int
+	AlertID   int
+	AquahubID int
+	From      time.Time
+	To        time.Time
+	GroupFilter
+}
+
+// Экземпляр оповещения - от срабатывания правила до снятия.
+// Подтверждённое оповещение не напоминает о себе, отложенное - молчит до snoozed_until.
+type Alert struct {
+	ID             int        `json:"id" db:"id" example:"7"`
+	RuleID         int        `json:"rule_id" db:"rule_id" example:"1"`
+	RuleTitle      string     `json:"rule_title" db:"rule_title" example:"Reef tank is too hot"`
+	AccountID      int        `json:"account_id" db:"account_id" example:"1"`
+	AquahubID      int        `json:"aquahub_id" db:"aquahub_id" example:"1"`
+	SensorID       int        `json:"sensor_id" db:"sensor_id" example:"12"`
+	Status         string     `json:"status" db:"status" example:"firing"`
+	Value          *string    `json:"value,omitempty" db:"value" example:"28.4"`
+	Message        string     `json:"message" db:"message" example:"Reef tank is too hot: 28.4 is above 28"`
+	StartedAt      time.Time  `json:"started_at" db:"started_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy *int       `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty" db:"snoozed_until"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolvedBy     *int       `json:"resolved_by,omitempty" db:"resolved_by"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty" db:"last_notified_at"`
+	NotifyCount    int        `json:"notify_count" db:"notify_count" example:"1"`
+	RenotifySec    int        `json:"-" db:"renotify_sec"`
+}
+
+// Фильтр списка оповещений; From/To - оповещения, активные в этом периоде
+type AlertsFilter struct {
+	Status    string
+	AquahubID int
+	SensorID  int
+	From      time.Time
+	To        time.Time
+	GroupFilter
+}
+
+// Отсрочка до Until или на DurationSec секунд
+type SnoozeAlert struct {
+	Until       *time.Time `json:"until,omitempty" example:"2022-09-01T08:00:00Z"`
+	DurationSec int        `json:"duration_sec,omitempty" example:"3600"`
+}
+
+// Time возвращает конец отсрочки относительно now
+func (i SnoozeAlert) Time(now time.Time) (time.Time, error) {
+	if (i.Until == nil) == (i.DurationSec == 0) {
+		return time.Time{}, errors.New("either until or duration_sec is required")
+	}
+
+	until := now.Add(time.Duration(i.DurationSec) * time.Second)
+	if i.Until != nil {
+		until = *i.Until
+	}
+
+	if !until.After(now) {
+		return time.Time{}, errors.New("snooze time must be in the future")
+	}
+	if until.Sub(now) > MaxAlertSnooze*time.Second {
+		return time.Time{}, errors.New("snooze must not be longer than 7 days")
+	}
+	return until, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Настройки детекторов аномалий сенсора. Нулевое значение выключает детектор.
+type SensorDetectors struct {
+	SensorID        int       `json:"sensor_id" db:"sensor_id" example:"12"`
+	MaxRate         float64   `json:"max_rate" db:"max_rate" example:"0.5"`
+	ZScoreWindow    int       `json:"zscore_window" db:"zscore_window" example:"60"`
+	ZScoreThreshold float64   `json:"zscore_threshold" db:"zscore_threshold" example:"4"`
+	FlatlineMinutes int       `json:"flatline_minutes" db:"flatline_minutes" example:"1440"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Тело запроса на установку детекторов:
+//   - max_rate - максимальное изменение значения за минуту;
+//   - zscore_window, zscore_threshold - окно предыдущих показаний и порог z-score;
+//   - flatline_minutes - сколько минут значение может не меняться.
+type SetSensorDetectors struct {
+	MaxRate         float64 `json:"max_rate" example:"0.5"`
+	ZScoreWindow    int     `json:"zscore_window" example:"60"`
+	ZScoreThreshold float64 `json:"zscore_threshold" example:"4"`
+	FlatlineMinutes int     `json:"flatline_minutes" example:"1440"`
+}
+
+// Максимальное окно z-score: история читается из БД при каждом приёме показаний
+const MaxZScoreWindow = 1000
+
+func (i SetSensorDetectors) Validate() error {
+	if i.MaxRate < 0 || i.ZScoreWindow < 0 || i.ZScoreThreshold < 0 || i.FlatlineMinutes < 0 {
+		return errors.New("detector settings must not be negative")
+	}
+	if i.MaxRate == 0 && i.ZScoreWindow == 0 && i.FlatlineMinutes == 0 {
+		return errors.New("no detectors enabled")
+	}
+	if i.ZScoreWindow != 0 && (i.ZScoreWindow < 3 || i.ZScoreWindow > MaxZScoreWindow) {
+		return errors.New("zscore_window must be between 3 and 1000")
+	}
+	if (i.ZScoreWindow == 0) != (i.ZScoreThreshold == 0) {
+		return errors.New("zscore_window and zscore_threshold must be set together")
+	}
+
+	return nil
+}
+
+// Помеченное детекторами показание сенсора
+type SensorFlag struct {
+	ID         int       `json:"id" db:"id"`
+	SensorID   int       `json:"sensor_id" db:"sensor_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	Value      string    `json:"value" db:"value"`
+	Flag       string    `json:"flag" db:"flag" example:"rate,zscore"`
+	FlagReason string    `json:"flag_reason" db:"flag_reason"`
+}
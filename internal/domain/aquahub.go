@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -14,14 +15,16 @@ import (
 // ID         string          `json:"id" db:"id" validate:"required,uuid" example:"985f1746-1d9f-459f-a2d9-fc53ece5ae86"`
 // Name       string          `json:"name"  validate:"required" example:"Rocket Launch"`
 type AquahubList struct {
-	ID          int           `json:"id" db:"id" validate:"required"`
-	AccountID   int           `json:"account_id" db:"account_id" validate:"required" truss:"api-create"`
-	Title       string        `json:"title" db:"title" validate:"required" binding:"required"`
-	Description string        `json:"description" db:"description" validate:"required"`
-	Status      AquaHubStatus `json:"status" db:"status" validate:"omitempty,oneof=active archived" enums:"active,archived" swaggertype:"string" example:"active"`
-	CreatedAt   time.Time     `json:"created_at" db:"created_at" truss:"api-read"`
-	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at" truss:"api-read"`
-	ArchivedAt  *pq.NullTime  `json:"archived_at,omitempty" db:"archived_at" truss:"api-hide" swaggertype:"string"`
+	ID          int            `json:"id" db:"id" validate:"required"`
+	AccountID   int            `json:"account_id" db:"account_id" validate:"required" truss:"api-create"`
+	Title       string         `json:"title" db:"title" validate:"required" binding:"required"`
+	Description string         `json:"description" db:"description" validate:"required"`
+	Status      AquaHubStatus  `json:"status" db:"status" validate:"omitempty,oneof=active archived" enums:"active,archived" swaggertype:"string" example:"active"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at" truss:"api-read"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at" truss:"api-read"`
+	ArchivedAt  *time.Time     `json:"archived_at,omitempty" db:"archived_at" truss:"api-hide"`
+	GroupID     *int           `json:"group_id,omitempty" db:"group_id" example:"3"`
+	Tags        pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string" example:"reef"`
 }
 
 // Checklists a list of Checklists.
@@ -38,6 +41,165 @@ type AquahubListFindRequest struct {
 	IncludeArchived bool          `json:"include-archived" example:"false"`
 }
 
+// Значения status аквахабов и устройств (device_status_t)
+func (s AquaHubStatus) Validate() error {
+	for _, v := range AquaHubStatus_Values {
+		if s == v {
+			return nil
+		}
+	}
+	return errors.New("status must be one of: active, archived")
+}
+
+type CreateAquahub struct {
+	AccountID   int    `json:"account_id" binding:"required" example:"1"`
+	Title       string `json:"title" binding:"required" example:"AquaHub More"`
+	Description string `json:"description" example:"Reef tank"`
+}
+
+func (i CreateAquahub) Validate() error {
+	if i.AccountID == 0 || strings.TrimSpace(i.Title) == "" {
+		return errors.New("account_id and title are required")
+	}
+	if len(i.Title) > 255 || len(i.Description) > 255 {
+		return errors.New("title or description is too long")
+	}
+	return nil
+}
+
+// Созданный аквахаб с токеном для настройки хаба (api_key = h_token:u_token)
+type AquahubCreated struct {
+	ID     int    `json:"id" example:"3"`
+	HToken string `json:"h_token" example:"Yk0uT2323R7vzNX1aqen104Ur2zN"`
+}
+
+type UpdateAquahub struct {
+	Title       *string        `json:"title" example:"AquaHub More"`
+	Description *string        `json:"description" example:"Reef tank"`
+	Status      *AquaHubStatus `json:"status,omitempty" enums:"active,archived" swaggertype:"string" example:"active"`
+}
+
+func (i UpdateAquahub) Validate() error {
+	if i.Title == nil && i.Description == nil && i.Status == nil {
+		return errors.New("update has no values")
+	}
+	if i.Title != nil && (strings.TrimSpace(*i.Title) == "" || len(*i.Title) > 255) {
+		return errors.New("title must be 1..255 characters")
+	}
+	if i.Description != nil && len(*i.Description) > 255 {
+		return errors.New("description is too long")
+	}
+	if i.Status != nil {
+		return i.Status.Validate()
+	}
+	return nil
+}
+
+// Устройство аквахаба; local_id - номер устройства на хабе
+type Device struct {
+	ID           int            `json:"id" db:"id" example:"4"`
+	AquahubID    int            `json:"aquahub_id" db:"aquahub_id" example:"2"`
+	LocalID      int            `json:"local_id" db:"local_id" example:"1"`
+	Title        string         `json:"title" db:"title" example:"Thermometer"`
+	Description  string         `json:"description" db:"description" example:"DS18B20"`
+	Status       AquaHubStatus  `json:"status" db:"status" enums:"active,archived" swaggertype:"string" example:"active"`
+	DeviceTypeID *int           `json:"device_type_id,omitempty" db:"device_type_id" example:"1"`
+	ArchivedAt   *time.Time     `json:"archived_at,omitempty" db:"archived_at"`
+	Tags         pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string" example:"lights"`
+}
+
+type CreateDevice struct {
+	AquahubID   int    `json:"aquahub_id" binding:"required" example:"2"`
+	LocalID     int    `json:"local_id" example:"1"`
+	Title       string `json:"title" binding:"required" example:"Thermometer"`
+	Description string `json:"description" example:"DS18B20"`
+}
+
+func (i CreateDevice) Validate() error {
+	if i.AquahubID == 0 || strings.TrimSpace(i.Title) == "" {
+		return errors.New("aquahub_id and title are required")
+	}
+	if i.LocalID < 0 {
+		return errors.New("local_id must not be negative")
+	}
+	if len(i.Title) > 255 || len(i.Description) > 255 {
+		return errors.New("title or description is too long")
+	}
+	return nil
+}
+
+type UpdateDevice struct {
+	Title       *string        `json:"title" example:"Thermometer"`
+	Description *string        `json:"description" example:"DS18B20"`
+	Status      *AquaHubStatus `json:"status,omitempty" enums:"active,archived" swaggertype:"string" example:"active"`
+}
+
+func (i UpdateDevice) Validate() error {
+	return UpdateAquahub(i).Validate()
+}
+
+// Сенсор устройства. Virtual - вычисляемый сенсор (формула задаётся через /api/virtual-sensors)
+type Sensor struct {
+	ID                int            `json:"id" db:"id" example:"5"`
+	DeviceID          int            `json:"device_id" db:"device_id" example:"4"`
+	AquahubID         int            `json:"aquahub_id" db:"aquahub_id" example:"2"`
+	LocalID           int            `json:"local_id" db:"local_id" example:"1"`
+	Title             string         `json:"title" db:"title" example:"Water"`
+	Description       string         `json:"description" db:"description" example:"Water temperature"`
+	ForEngineer       bool           `json:"for_engineer" db:"for_engineer" example:"true"`
+	ForAnalytics      bool           `json:"for_analytics" db:"for_analytics" example:"false"`
+	Unit              string         `json:"unit" db:"unit" example:"°C"`
+	DataType          string         `json:"data_type" db:"data_type" enums:"float,int,bool,string" example:"float"`
+	ReportIntervalSec *int           `json:"report_interval_sec,omitempty" db:"report_interval_sec" example:"60"`
+	Virtual           bool           `json:"virtual" db:"virtual" example:"false"`
+	ArchivedAt        *time.Time     `json:"archived_at,omitempty" db:"archived_at"`
+	Tags              pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string" example:"critical"`
+}
+
+type CreateSensor struct {
+	DeviceID     int    `json:"device_id" binding:"required" example:"4"`
+	LocalID      int    `json:"local_id" example:"1"`
+	Title        string `json:"title" binding:"required" example:"Water"`
+	Description  string `json:"description" example:"Water temperature"`
+	ForEngineer  *bool  `json:"for_engineer,omitempty" example:"true"`
+	ForAnalytics *bool  `json:"for_analytics,omitempty" example:"false"`
+}
+
+func (i CreateSensor) Validate() error {
+	if i.DeviceID == 0 || strings.TrimSpace(i.Title) == "" {
+		return errors.New("device_id and title are required")
+	}
+	if i.LocalID < 0 {
+		return errors.New("local_id must not be negative")
+	}
+	if len(i.Title) > 255 || len(i.Description) > 255 {
+		return errors.New("title or description is too long")
+	}
+	return nil
+}
+
+type UpdateSensor struct {
+	Title        *string `json:"title" example:"Water"`
+	Description  *string `json:"description" example:"Water temperature"`
+	ForEngineer  *bool   `json:"for_engineer,omitempty" example:"true"`
+	ForAnalytics *bool   `json:"for_analytics,omitempty" example:"false"`
+}
+
+func (i UpdateSensor) Validate() error {
+	if i.Title == nil && i.Description == nil && i.ForEngineer == nil && i.ForAnalytics == nil {
+		return errors.New("update has no values")
+	}
+	if i.Title != nil && (strings.TrimSpace(*i.Title) == "" || len(*i.Title) > 255) {
+		return errors.New("title must be 1..255 characters")
+	}
+	if i.Description != nil && len(*i.Description) > 255 {
+		return errors.New("description is too long")
+	}
+	return nil
+}
+
+var ErrLocalIdInUse = errors.New("local_id is already used")
+
 //=========================================================================================
 
 type NameOfDeviceSensor struct {
@@ -64,6 +226,13 @@ type SensorDataSet struct {
 	Local_sensor_id int `json:"local_sensor_id" db:"local_sensor_id"`
 
 	Value string `json:"value" db:"value"`
+
+	// Исходное показание сенсора до калибровки (NULL у вычисляемых значений)
+	Raw_value *string `json:"raw_value,omitempty" db:"raw_value"`
+
+	// Пометка детекторов аномалий: список сработавших детекторов и причина (NULL - показание в норме)
+	Flag        *string `json:"flag,omitempty" db:"flag"`
+	Flag_reason *string `json:"flag_reason,omitempty" db:"flag_reason"`
 }
 
 //--------------------------------------------------------------------------------------
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Архив: удаление хабов, устройств, сенсоров, чек-листов и их пунктов переносит их в архив.
+// Списки не показывают архивные записи без параметра include-archived, запись по ID доступна.
+// Плановая задача окончательно удаляет записи, пролежавшие в архиве дольше ArchivePurgeAfter.
+const ArchivePurgeAfter = 30 * 24 * time.Hour
+
+// Число окончательно удалённых записей архива
+type ArchivePurged struct {
+	Aquahubs       int
+	Devices        int
+	Sensors        int
+	Checklists     int
+	ChecklistItems int
+}
+
+func (p ArchivePurged) Total() int {
+	return p.Aquahubs + p.Devices + p.Sensors + p.Checklists + p.ChecklistItems
+}
@@ -0,0 +1,375 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Действия правил автоматизации
+const (
+	AutomationActionCommand   = "device_command" // команда устройству
+	AutomationActionNotify    = "notify"         // уведомление участникам аккаунта
+	AutomationActionChecklist = "checklist_item" // пункт в чек-листе
+)
+
+// Причины запуска
+const (
+	AutomationTriggerSensor   = "sensor"
+	AutomationTriggerSchedule = "schedule"
+	AutomationTriggerTest     = "test"
+)
+
+// Результаты запуска
+const (
+	AutomationRunOK      = "ok"
+	AutomationRunDryRun  = "dry_run" // действия не выполнялись
+	AutomationRunSkipped = "skipped" // остановлен защитой от зацикливания
+	AutomationRunFailed  = "failed"
+)
+
+// Ограничения правила; пауза и лимит запусков в час защищают от зацикливания
+const (
+	MaxAutomationConditions      = 10
+	MaxAutomationActions         = 10
+	MaxAutomationForSec          = 24 * 60 * 60
+	DefaultAutomationCooldown    = 60
+	MinAutomationCooldown        = 10
+	MaxAutomationCooldown        = 24 * 60 * 60
+	DefaultAutomationRunsPerHour = 10
+	MaxAutomationRunsPerHour     = 60
+)
+
+// Условие: последнее значение сенсора сравнивается с value не меньше for_sec подряд
+type AutomationCondition struct {
+	SensorID int     `json:"sensor_id" example:"12"`
+	Op       string  `json:"op" example:">"`
+	Value    float64 `json:"value" example:"28"`
+	ForSec   int     `json:"for_sec" example:"600"`
+}
+
+type AutomationConditions []AutomationCondition
+
+// Scan supports reading the AutomationConditions value from the jsonb column.
+func (c *AutomationConditions) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, c)
+}
+
+// Value converts the AutomationConditions value to be stored in the jsonb column.
+func (c AutomationConditions) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// Расписание: время суток "HH:MM" во временной зоне аккаунта, дни недели 0 (воскресенье) .. 6; пусто - каждый день
+type AutomationSchedule struct {
+	At       string `json:"at" example:"08:00"`
+	Weekdays []int  `json:"weekdays,omitempty" example:"1,2,3,4,5"`
+}
+
+// Scan supports reading the AutomationSchedule value from the jsonb column.
+func (s *AutomationSchedule) Scan(value interface{}) error {
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, s)
+}
+
+// Value converts the AutomationSchedule value to be stored in the jsonb column.
+func (s AutomationSchedule) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Действие правила; используются поля своего типа:
+// device_command - device_id, command, value; notify - message; checklist_item - checklist_id, title, description
+type AutomationAction struct {
+	Type        string `json:"type" example:"device_command"`
+	DeviceID    int    `json:"device_id,omitempty" example:"3"`
+	Command     string `json:"command,omitempty" example:"fan"`
+	Value       string `json:"value,omitempty" example:"on"`
+	Message     string `json:"message,omitempty"`
+	ChecklistID int    `json:"checklist_id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type AutomationActions []AutomationAction
+
+// Scan supports reading the AutomationActions value from the jsonb column.
+func (a *AutomationActions) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, a)
+}
+
+// Value converts the AutomationActions value to be stored in the jsonb column.
+func (a AutomationActions) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Состояние условия: последнее значение сенсора и с какого момента условие выполняется
+type AutomationConditionState struct {
+	Known bool       `json:"known"`
+	Value float64    `json:"value"`
+	At    time.Time  `json:"at"`
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// Состояние правила: состояния условий по порядку и выполнялись ли условия при последней проверке
+type AutomationState struct {
+	Active     bool                       `json:"active"`
+	Conditions []AutomationConditionState `json:"conditions"`
+}
+
+// Scan supports reading the AutomationState value from the jsonb column.
+func (s *AutomationState) Scan(value interface{}) error {
+	if value == nil {
+		*s = AutomationState{}
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, s)
+}
+
+// Value converts the AutomationState value to be stored in the jsonb column.
+func (s AutomationState) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Правило автоматизации аккаунта: условия по сенсорам и/или расписание запускают действия.
+// Правило только с условиями срабатывает, когда все условия начали выполняться;
+// правило с расписанием - в заданное время, если условия (когда они есть) выполняются.
+// В режиме dry_run запуски записываются в журнал, но действия не выполняются.
+type Automation struct {
+	ID             int                  `json:"id" db:"id" example:"1"`
+	AccountID      int                  `json:"account_id" db:"account_id" example:"1"`
+	Title          string               `json:"title" db:"title" example:"Cool the reef tank"`
+	Enabled        bool                 `json:"enabled" db:"enabled" example:"true"`
+	DryRun         bool                 `json:"dry_run" db:"dry_run" example:"false"`
+	Conditions     AutomationConditions `json:"conditions" db:"conditions"`
+	Schedule       *AutomationSchedule  `json:"schedule,omitempty" db:"schedule"`
+	Actions        AutomationActions    `json:"actions" db:"actions"`
+	CooldownSec    int                  `json:"cooldown_sec" db:"cooldown_sec" example:"600"`
+	MaxRunsPerHour int                  `json:"max_runs_per_hour" db:"max_runs_per_hour" example:"4"`
+	State          AutomationState      `json:"-" db:"state"`
+	Timezone       string               `json:"-" db:"timezone"`
+	CheckedAt      *time.Time           `json:"-" db:"checked_at"`
+	LastRunAt      *time.Time           `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedAt      time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// Validate проверяет структуру правила; операции сравнения и время расписания проверяет сервис
+func (a Automation) Validate() error {
+	if a.Title == "" || len(a.Title) > 255 {
+		return errors.New("title must be 1..255 characters")
+	}
+	if len(a.Conditions) == 0 && a.Schedule == nil {
+		return errors.New("rule needs conditions or schedule")
+	}
+	if len(a.Conditions) > MaxAutomationConditions {
+		return errors.New("too many conditions")
+	}
+	if len(a.Actions) == 0 || len(a.Actions) > MaxAutomationActions {
+		return errors.New("rule needs 1..10 actions")
+	}
+
+	for _, c := range a.Conditions {
+		if c.SensorID <= 0 {
+			return errors.New("condition has invalid sensor_id")
+		}
+		if c.ForSec < 0 || c.ForSec > MaxAutomationForSec {
+			return errors.New("condition for_sec must be between 0 and 86400")
+		}
+	}
+
+	if a.Schedule != nil {
+		for _, d := range a.Schedule.Weekdays {
+			if d < 0 || d > 6 {
+				return errors.New("schedule weekdays must be 0 (Sunday) .. 6")
+			}
+		}
+	}
+
+	for _, x := range a.Actions {
+		switch x.Type {
+		case AutomationActionCommand:
+			if x.DeviceID <= 0 {
+				return errors.New("device_command needs device_id")
+			}
+			if err := ValidateCommand(x.Command, x.Value); err != nil {
+				return err
+			}
+		case AutomationActionNotify:
+			if len(x.Message) > 512 {
+				return errors.New("notify message is too long")
+			}
+		case AutomationActionChecklist:
+			if x.ChecklistID <= 0 || x.Title == "" {
+				return errors.New("checklist_item needs checklist_id and title")
+			}
+			if len(x.Title) > 255 || len(x.Description) > 255 {
+				return errors.New("checklist item title or description is too long")
+			}
+		default:
+			return errors.New("action type must be one of: device_command, notify, checklist_item")
+		}
+	}
+
+	if a.CooldownSec < MinAutomationCooldown || a.CooldownSec > MaxAutomationCooldown {
+		return errors.New("cooldown_sec must be between 10 and 86400")
+	}
+	if a.MaxRunsPerHour < 1 || a.MaxRunsPerHour > MaxAutomationRunsPerHour {
+		return errors.New("max_runs_per_hour must be between 1 and 60")
+	}
+
+	return nil
+}
+
+// Сенсоры условий, устройства команд и чек-листы действий - должны принадлежать аккаунту правила
+func (a Automation) References() (sensors, devices, checklists []int) {
+	for _, c := range a.Conditions {
+		sensors = append(sensors, c.SensorID)
+	}
+	for _, x := range a.Actions {
+		switch x.Type {
+		case AutomationActionCommand:
+			devices = append(devices, x.DeviceID)
+		case AutomationActionChecklist:
+			checklists = append(checklists, x.ChecklistID)
+		}
+	}
+	return sensors, devices, checklists
+}
+
+// Правило целиком; при изменении состояние условий сбрасывается
+type SetAutomation struct {
+	AccountID      int                  `json:"account_id" binding:"required" example:"1"`
+	Title          string               `json:"title" binding:"required" example:"Cool the reef tank"`
+	Enabled        *bool                `json:"enabled,omitempty" example:"true"`
+	DryRun         bool                 `json:"dry_run" example:"false"`
+	Conditions     AutomationConditions `json:"conditions,omitempty"`
+	Schedule       *AutomationSchedule  `json:"schedule,omitempty"`
+	Actions        AutomationActions    `json:"actions" binding:"required"`
+	CooldownSec    *int                 `json:"cooldown_sec,omitempty" example:"600"`
+	MaxRunsPerHour *int                 `json:"max_runs_per_hour,omitempty" example:"4"`
+}
+
+func (i SetAutomation) Automation() Automation {
+	a := Automation{
+		AccountID:      i.AccountID,
+		Title:          i.Title,
+		Enabled:        true,
+		DryRun:         i.DryRun,
+		Conditions:     i.Conditions,
+		Schedule:       i.Schedule,
+		Actions:        i.Actions,
+		CooldownSec:    DefaultAutomationCooldown,
+		MaxRunsPerHour: DefaultAutomationRunsPerHour,
+	}
+	if i.Enabled != nil {
+		a.Enabled = *i.Enabled
+	}
+	if i.CooldownSec != nil {
+		a.CooldownSec = *i.CooldownSec
+	}
+	if i.MaxRunsPerHour != nil {
+		a.MaxRunsPerHour = *i.MaxRunsPerHour
+	}
+	return a
+}
+
+func (i SetAutomation) Validate() error {
+	if i.AccountID <= 0 {
+		return errors.New("invalid account_id")
+	}
+	return i.Automation().Validate()
+}
+
+// Результат действия в журнале запуска
+type AutomationActionResult struct {
+	Type   string `json:"type" example:"device_command"`
+	Result string `json:"result,omitempty" example:"command 15 queued"`
+	Error  string `json:"error,omitempty"`
+}
+
+type AutomationActionResults []AutomationActionResult
+
+// Scan supports reading the AutomationActionResults value from the jsonb column.
+func (r *AutomationActionResults) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, r)
+}
+
+// Value converts the AutomationActionResults value to be stored in the jsonb column.
+func (r AutomationActionResults) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+// Запись журнала запусков
+type AutomationRun struct {
+	ID           int                     `json:"id" db:"id" example:"1"`
+	AutomationID int                     `json:"automation_id" db:"automation_id" example:"1"`
+	AccountID    int                     `json:"account_id" db:"account_id" example:"1"`
+	Trigger      string                  `json:"trigger" db:"trigger" example:"sensor"`
+	Status       string                  `json:"status" db:"status" example:"ok"`
+	Message      string                  `json:"message" db:"message" example:"sensor 12 > 28 for 600s"`
+	Actions      AutomationActionResults `json:"actions" db:"actions"`
+	CreatedAt    time.Time               `json:"created_at" db:"created_at"`
+}
+
+// Проверка условия в тестовом запуске
+type AutomationConditionResult struct {
+	SensorID int        `json:"sensor_id" example:"12"`
+	Value    *float64   `json:"value,omitempty" example:"28.4"`
+	At       *time.Time `json:"at,omitempty"`
+	Holds    bool       `json:"holds" example:"true"`
+}
+
+// Результат тестового запуска: что было бы сделано сейчас; действия не выполняются
+type AutomationTestResult struct {
+	Conditions []AutomationConditionResult `json:"conditions"`
+	NextRunAt  *time.Time                  `json:"next_run_at,omitempty"`
+	WouldRun   bool                        `json:"would_run" example:"true"`
+	Blocked    string                      `json:"blocked,omitempty"`
+	Actions    AutomationActionResults     `json:"actions"`
+}
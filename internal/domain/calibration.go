@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Калибровка сенсора. Действует с момента ValidFrom до начала следующей калибровки того же сенсора.
+// Если задана таблица точек (не меньше двух), значение вычисляется кусочно-линейной интерполяцией,
+// иначе - как raw * Scale + Offset.
+
+type SensorCalibration struct {
+	ID          int               `json:"id" db:"id" example:"1"`
+	SensorID    int               `json:"sensor_id" db:"sensor_id" example:"12"`
+	Offset      float64           `json:"offset" db:"offset_value" example:"-0.15"`
+	Scale       float64           `json:"scale" db:"scale" example:"1"`
+	Points      CalibrationPoints `json:"points,omitempty" db:"points" swaggertype:"array,object"`
+	ValidFrom   time.Time         `json:"valid_from" db:"valid_from" example:"2022-09-01T00:00:00Z"`
+	Description string            `json:"description" db:"description" example:"pH 4.01 / 7.00 buffers"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Точка калибровочной таблицы: показание сенсора => истинное значение
+type CalibrationPoint struct {
+	Raw   float64 `json:"raw" example:"4.12"`
+	Value float64 `json:"value" example:"4.01"`
+}
+
+type CalibrationPoints []CalibrationPoint
+
+// Scan supports reading the CalibrationPoints value from the jsonb column.
+func (p *CalibrationPoints) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, p)
+}
+
+// Value converts the CalibrationPoints value to be stored in the jsonb column.
+func (p CalibrationPoints) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	return string(b), err
+}
+
+type CreateSensorCalibration struct {
+	Offset      *float64          `json:"offset,omitempty" example:"-0.15"`
+	Scale       *float64          `json:"scale,omitempty" example:"1"`
+	Points      CalibrationPoints `json:"points,omitempty"`
+	ValidFrom   *time.Time        `json:"valid_from,omitempty" example:"2022-09-01T00:00:00Z"`
+	Description *string           `json:"description,omitempty" example:"pH 4.01 / 7.00 buffers"`
+}
+
+func (i CreateSensorCalibration) Validate() error {
+	if i.Offset == nil && i.Scale == nil && len(i.Points) == 0 {
+		return errors.New("calibration has no offset, scale or points")
+	}
+	if len(i.Points) == 1 {
+		return errors.New("calibration table needs at least two points")
+	}
+	// По таблице точек offset и scale не применяются (см. Apply)
+	if len(i.Points) > 0 && (i.Offset != nil || i.Scale != nil) {
+		return errors.New("calibration has either points or offset and scale, not both")
+	}
+	if i.Scale != nil && *i.Scale == 0 {
+		return errors.New("calibration scale must not be zero")
+	}
+
+	seen := make(map[float64]bool, len(i.Points))
+	for _, p := range i.Points {
+		if seen[p.Raw] {
+			return errors.New("calibration table has duplicate raw values")
+		}
+		seen[p.Raw] = true
+	}
+
+	return nil
+}
+
+type RecomputeCalibration struct {
+	From time.Time `json:"from" binding:"required" example:"2022-09-01T00:00:00Z"`
+}
+
+// Результат пересчёта. Пересчитываются только показания самого сенсора: показания виртуальных сенсоров,
+// вычисленные из прежних значений, и пометки детекторов аномалий сенсора остаются прежними.
+// StaleVirtualSensors - виртуальные сенсоры, формулы которых (в том числе через другие виртуальные сенсоры)
+// зависят от сенсора; StaleFlags - у сенсора есть детекторы. Заполняются, только если показания изменились.
+type RecomputeResult struct {
+	Updated             int   `json:"updated" example:"1440"`
+	StaleVirtualSensors []int `json:"stale_virtual_sensors,omitempty" example:"31,32"`
+	StaleFlags          bool  `json:"stale_flags,omitempty" example:"true"`
+}
+
+// Apply переводит показание сенсора в калиброванное значение
+func (c SensorCalibration) Apply(raw float64) float64 {
+
+	if len(c.Points) < 2 {
+		return raw*c.Scale + c.Offset
+	}
+
+	points := append(CalibrationPoints(nil), c.Points...)
+	sort.Slice(points, func(i, j int) bool { return points[i].Raw < points[j].Raw })
+
+	// Находим отрезок таблицы; за её пределами продолжаем крайние отрезки
+	i := sort.Search(len(points), func(i int) bool { return points[i].Raw >= raw })
+	switch {
+	case i == 0:
+		i = 1
+	case i == len(points):
+		i = len(points) - 1
+	}
+
+	a, b := points[i-1], points[i]
+	return a.Value + (raw-a.Raw)*(b.Value-a.Value)/(b.Raw-a.Raw)
+}
+
+// CalibrationAt возвращает калибровку, действующую в момент at.
+// Список должен быть отсортирован по ValidFrom по возрастанию.
+func CalibrationAt(list []SensorCalibration, at time.Time) (SensorCalibration, bool) {
+	for i := len(list) - 1; i >= 0; i-- {
+		if !list[i].ValidFrom.After(at) {
+			return list[i], true
+		}
+	}
+	return SensorCalibration{}, false
+}
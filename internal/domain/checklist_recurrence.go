@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Повторение чек-листа: правило RRULE (FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY, BYMONTHDAY, UNTIL)
+// и начало повторений; время суток начала задаёт время создания экземпляров во временной зоне аккаунта
+type SetChecklistRecurrence struct {
+	RRule string     `json:"rrule" binding:"required" example:"FREQ=WEEKLY;BYDAY=SA"`
+	Start *time.Time `json:"start,omitempty" example:"2022-10-01T09:00:00+03:00"`
+}
+
+func (i SetChecklistRecurrence) Validate() error {
+	if i.RRule == "" || len(i.RRule) > 255 {
+		return errors.New("rrule must be 1..255 characters")
+	}
+	return nil
+}
+
+// Повторяющийся чек-лист - шаблон экземпляров
+type ChecklistRecurrence struct {
+	ChecklistID int        `json:"checklist_id" db:"id" example:"33"`
+	AccountID   int        `json:"-" db:"account_id"`
+	Title       string     `json:"-" db:"title"`
+	Description string     `json:"-" db:"description"`
+	RRule       string     `json:"rrule" db:"recurrence" example:"FREQ=WEEKLY;BYDAY=SA"`
+	Start       *time.Time `json:"start,omitempty" db:"recurrence_start"`
+	NextRunAt   *time.Time `json:"next_run_at,omitempty" db:"next_run_at"`
+	Timezone    string     `json:"timezone" db:"timezone" example:"Europe/Kiev"`
+}
+
+// Экземпляр повторяющегося чек-листа за период с выполнением пунктов
+type ChecklistInstance struct {
+	ID          int        `json:"id" db:"id" example:"40"`
+	TemplateID  int        `json:"template_id" db:"template_id" example:"33"`
+	Title       string     `json:"title" db:"title" example:"Weekly water change"`
+	PeriodStart time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty" db:"period_end"`
+	ItemsTotal  int        `json:"items_total" db:"items_total" example:"4"`
+	ItemsDone   int        `json:"items_done" db:"items_done" example:"3"`
+	Completed   bool       `json:"completed" db:"completed" example:"false"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
@@ -3,8 +3,6 @@ package domain
 import (
 	"errors"
 	"time"
-
-	"github.com/lib/pq"
 )
 
 // Поля полностью совпадают с БД
@@ -34,7 +32,13 @@ type Checklist struct {
 	Status      ChecklistStatus `json:"status" db:"status" validate:"omitempty,oneof=active disabled" enums:"active,disabled" swaggertype:"string" example:"active"`
 	CreatedAt   time.Time       `json:"-" db:"created_at" truss:"api-read"`
 	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at" truss:"api-read"`
-	ArchivedAt  *pq.NullTime    `json:"-" db:"archived_at" truss:"api-hide" swaggertype:"string"`
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty" db:"archived_at" truss:"api-hide"`
+
+	// Повторение шаблона (RRULE) или ссылка экземпляра на шаблон и его период
+	Recurrence  string     `json:"recurrence,omitempty" db:"recurrence" example:"FREQ=WEEKLY;BYDAY=SA"`
+	TemplateID  *int       `json:"template_id,omitempty" db:"template_id"`
+	PeriodStart *time.Time `json:"period_start,omitempty" db:"period_start"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty" db:"period_end"`
 }
 
 // ChecklistStatus represents the status of checklist.
@@ -52,6 +56,7 @@ type CreateChecklist struct {
 	Title       *string          `json:"title" binding:"required" validate:"required" example:"Title Checklist"`
 	Description *string          `json:"description,omitempty" example:"Description Checklist"`
 	Status      *ChecklistStatus `json:"status,omitempty" validate:"omitempty,oneof=active disabled" enums:"active,disabled" swaggertype:"string" example:"active"`
+	AccountID   *int             `json:"account_id,omitempty" example:"1"` // по умолчанию - аккаунт, зарегистрированный пользователем
 }
 
 type UpdateChecklist struct {
@@ -70,14 +75,14 @@ func (i UpdateChecklist) Validate() error {
 }
 
 type ChecklistItem struct {
-	ID          int          `json:"-" db:"id"`
-	ChecklistID int          `json:"-" db:"checklist_id" validate:"required" truss:"api-create"`
-	Title       string       `json:"title,omitempty" db:"title" validate:"required" example:"Rocket Launch"`
-	Description string       `json:"description,omitempty" db:"description"`
-	Done        bool         `json:"done,omitempty" db:"done"`
-	CreatedAt   time.Time    `json:"-" db:"created_at" truss:"api-read"`
-	UpdatedAt   time.Time    `json:"updated_at,omitempty" db:"updated_at" truss:"api-read"`
-	ArchivedAt  *pq.NullTime `json:"-,omitempty" db:"archived_at" truss:"api-hide" swaggertype:"string"`
+	ID          int        `json:"id" db:"id" example:"1"`
+	ChecklistID int        `json:"-" db:"checklist_id" validate:"required" truss:"api-create"`
+	Title       string     `json:"title,omitempty" db:"title" validate:"required" example:"Rocket Launch"`
+	Description string     `json:"description,omitempty" db:"description"`
+	Done        bool       `json:"done,omitempty" db:"done"`
+	CreatedAt   time.Time  `json:"-" db:"created_at" truss:"api-read"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty" db:"updated_at" truss:"api-read"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty" db:"archived_at" truss:"api-hide"`
 }
 
 type UpdateChecklistItem struct {
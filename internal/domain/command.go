@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Статусы команды устройству: хаб забирает ожидающие команды и подтверждает выполнение.
+// Неподтверждённая команда выдаётся хабу повторно, пока не истечёт её срок.
+const (
+	CommandPending   = "pending"
+	CommandDelivered = "delivered"
+	CommandAcked     = "acked"
+	CommandExpired   = "expired"
+)
+
+// Срок жизни команды: устаревшая команда ("включить вентилятор") опаснее невыполненной
+const CommandTTL = time.Hour
+
+// Команда устройству хаба; DeviceLocalID - ID устройства на хабе.
+// У команды хабу целиком (factory_reset) DeviceID и DeviceLocalID равны 0.
+type DeviceCommand struct {
+	ID            int        `json:"id" db:"id" example:"15"`
+	AccountID     int        `json:"account_id" db:"account_id" example:"1"`
+	AquahubID     int        `json:"aquahub_id" db:"aquahub_id" example:"1"`
+	DeviceID      int        `json:"device_id" db:"device_id" example:"3"`
+	DeviceLocalID int        `json:"device_local_id" db:"device_local_id" example:"110"`
+	AutomationID  *int       `json:"automation_id,omitempty" db:"automation_id" example:"2"`
+	Command       string     `json:"command" db:"command" example:"fan"`
+	Value         string     `json:"value" db:"value" example:"on"`
+	Status        string     `json:"status" db:"status" example:"pending"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	AckedAt       *time.Time `json:"acked_at,omitempty" db:"acked_at"`
+}
+
+// Команда в ответе хабу; Device = 0 - команда хабу целиком
+type HubCommand struct {
+	ID      int    `json:"id" db:"id" example:"15"`
+	Device  int    `json:"device" db:"device_local_id" example:"110"`
+	Command string `json:"command" db:"command" example:"fan"`
+	Value   string `json:"value" db:"value" example:"on"`
+}
+
+func ValidateCommand(command, value string) error {
+	if command == "" || len(command) > 64 {
+		return errors.New("command must be 1..64 characters")
+	}
+	if len(value) > 255 {
+		return errors.New("command value is too long")
+	}
+	return nil
+}
+
+// Фильтр списка команд
+type DeviceCommandsFilter struct {
+	DeviceID     int
+	AutomationID int
+	Status       string
+	From         time.Time
+	To           time.Time
+}
+
+type CreateDeviceCommand struct {
+	DeviceID int    `json:"device_id" binding:"required" example:"3"`
+	Command  string `json:"command" binding:"required" example:"fan"`
+	Value    string `json:"value" example:"on"`
+}
+
+func (i CreateDeviceCommand) Validate() error {
+	if i.DeviceID <= 0 {
+		return errors.New("invalid device_id")
+	}
+	return ValidateCommand(i.Command, i.Value)
+}
+
+type HubCommandsResponse struct {
+	Data []HubCommand `json:"data"`
+}
@@ -34,6 +34,20 @@ type CfgProject struct {
 	WebApiBaseUrl     string `default:"http://127.0.0.1:3001" envconfig:"WEB_API_BASE_URL"  example:"http://api.example.com"`
 }
 
+// Доставка уведомлений. Email отправляется, только если задан SMTP_HOST,
+// файловый канал (для разработки) - если задан OUTBOX_DIR.
+type CfgNotify struct {
+	SMTPHost      string        `default:"" envconfig:"SMTP_HOST"`
+	SMTPPort      int           `default:"587" envconfig:"SMTP_PORT"`
+	SMTPUser      string        `default:"" envconfig:"SMTP_USER"`
+	SMTPPass      string        `default:"" envconfig:"SMTP_PASS" json:"-"` // don't print
+	OutboxDir     string        `default:"" envconfig:"OUTBOX_DIR"`
+	Timeout       time.Duration `default:"30s" envconfig:"TIMEOUT"`
+	MaxAttempts   int           `default:"6" envconfig:"MAX_ATTEMPTS"`
+	RetryDelay    time.Duration `default:"1m" envconfig:"RETRY_DELAY"`
+	MaxRetryDelay time.Duration `default:"1h" envconfig:"MAX_RETRY_DELAY"`
+}
+
 type CfgDB struct {
 	Host       string `default:"http://127.0.0.1:5432" envconfig:"HOST"`
 	User       string `default:"postgres" envconfig:"USER"`
@@ -107,6 +121,7 @@ type CfgApplication struct {
 	HTTPS     CfgHTTPS
 	Service   CfgService
 	Project   CfgProject
+	Notify    CfgNotify
 	Redis     CfgRedis
 	DB        CfgDB
 	Trace     CfgTrace
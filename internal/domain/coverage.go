@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Уровни, на которые сводится покрытие данными
+const (
+	CoverageLevel_Sensor  = "sensor"
+	CoverageLevel_Device  = "device"
+	CoverageLevel_Aquahub = "aquahub"
+	CoverageLevel_Account = "account"
+)
+
+// Откуда взят ожидаемый интервал показаний
+const (
+	IntervalSource_Configured = "configured"
+	IntervalSource_Learned    = "learned"
+)
+
+// Максимальный период отчёта, рассчитываемого по запросу
+const MaxCoveragePeriod = 31 * 24 * time.Hour
+
+var ErrInvalidCoverageLevel = errors.New("level must be one of sensor, device, aquahub, account")
+
+func ValidCoverageLevel(level string) bool {
+	switch level {
+	case CoverageLevel_Sensor, CoverageLevel_Device, CoverageLevel_Aquahub, CoverageLevel_Account:
+		return true
+	}
+	return false
+}
+
+// Сенсор с привязкой к устройству, хабу и аккаунту
+type CoverageSensor struct {
+	ID                int    `db:"id"`
+	DeviceID          int    `db:"device_id"`
+	AquahubID         int    `db:"aquahub_id"`
+	AccountID         int    `db:"account_id"`
+	Title             string `db:"title"`
+	ReportIntervalSec *int   `db:"report_interval_sec"`
+}
+
+// Пропуск в данных
+type CoverageGap struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Seconds int       `json:"seconds"`
+}
+
+type CoverageGaps []CoverageGap
+
+// Scan supports reading the CoverageGaps value from the jsonb column.
+func (g *CoverageGaps) Scan(value interface{}) error {
+	if value == nil {
+		*g = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, g)
+}
+
+// Value converts the CoverageGaps value to be stored in the jsonb column.
+func (g CoverageGaps) Value() (driver.Value, error) {
+	if g == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(g)
+	return string(b), err
+}
+
+// Покрытие данными одного сенсора за период
+type SensorCoverage struct {
+	SensorID        int          `json:"sensor_id" example:"12"`
+	DeviceID        int          `json:"device_id" example:"4"`
+	AquahubID       int          `json:"aquahub_id" example:"2"`
+	Title           string       `json:"title" example:"pH"`
+	IntervalSeconds int          `json:"interval_seconds" example:"60"`
+	IntervalSource  string       `json:"interval_source" enums:"configured,learned" example:"learned"`
+	Readings        int          `json:"readings" example:"1260"`
+	Coverage        float64      `json:"coverage" example:"87.5"`
+	MissingSeconds  int          `json:"missing_seconds" example:"10800"`
+	Gaps            CoverageGaps `json:"gaps"`
+}
+
+// Покрытие данными на уровне сенсора, устройства, хаба или аккаунта
+type CoverageReport struct {
+	Level          string           `json:"level" enums:"sensor,device,aquahub,account" example:"aquahub"`
+	ID             int              `json:"id" example:"2"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	Coverage       float64          `json:"coverage" example:"87.5"`
+	MissingSeconds int              `json:"missing_seconds" example:"10800"`
+	Sensors        []SensorCoverage `json:"sensors"`
+}
+
+// Суточное покрытие сенсора, рассчитанное плановой задачей
+type SensorCoverageDaily struct {
+	SensorID       int          `json:"sensor_id" db:"sensor_id"`
+	Day            time.Time    `json:"day" db:"day"`
+	IntervalSec    int          `json:"interval_seconds" db:"interval_sec"`
+	Readings       int          `json:"readings" db:"readings"`
+	Coverage       float64      `json:"coverage" db:"coverage"`
+	MissingSeconds int          `json:"missing_seconds" db:"missing_sec"`
+	Gaps           CoverageGaps `json:"gaps" db:"gaps"`
+	ComputedAt     time.Time    `json:"computed_at" db:"computed_at"`
+}
+
+// Суточное покрытие, сведённое на уровень отчёта
+type CoverageDay struct {
+	Day            string  `json:"day" example:"2022-09-01"`
+	Sensors        int     `json:"sensors" example:"6"`
+	Coverage       float64 `json:"coverage" example:"99.3"`
+	MissingSeconds int     `json:"missing_seconds" example:"600"`
+}
+
+// Ожидаемый интервал показаний сенсора; null - определять по данным
+type SetReportInterval struct {
+	ReportIntervalSec *int `json:"report_interval_sec" example:"60"`
+}
+
+func (i SetReportInterval) Validate() error {
+	if i.ReportIntervalSec != nil && (*i.ReportIntervalSec <= 0 || *i.ReportIntervalSec > 24*3600) {
+		return errors.New("report_interval_sec must be between 1 and 86400")
+	}
+	return nil
+}
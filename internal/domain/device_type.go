@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Каталог типов устройств: по модели, о которой хаб сообщает в /v1/device/add, устройство и его сенсоры
+// создаются описанными - с названиями, единицами, типом данных и правилами оповещения по умолчанию.
+// Типы без account_id - общий каталог, шаблоны аккаунта перекрывают общий каталог по той же модели.
+
+// Типы данных сенсора
+const (
+	SensorDataFloat  = "float"
+	SensorDataInt    = "int"
+	SensorDataBool   = "bool"
+	SensorDataString = "string"
+)
+
+const DeviceTypeSensorsMax = 100
+
+var (
+	ErrUnknownSensorDataType = errors.New("data_type must be one of: float, int, bool, string")
+	ErrDeviceTypeReadOnly    = errors.New("device type of the common catalog can not be changed")
+)
+
+// Сенсор типа устройства; AlertLow и AlertHigh - диапазон правила оповещения по умолчанию
+type DeviceTypeSensor struct {
+	LocalID           int      `json:"local_id" db:"local_id" example:"0"`
+	Title             string   `json:"title" db:"title" example:"Temperature"`
+	Description       string   `json:"description" db:"description" example:"Room temperature"`
+	Unit              string   `json:"unit" db:"unit" example:"°C"`
+	DataType          string   `json:"data_type" db:"data_type" enums:"float,int,bool,string" example:"float"`
+	ReportIntervalSec *int     `json:"report_interval_sec,omitempty" db:"report_interval_sec" example:"60"`
+	ForAnalytics      bool     `json:"for_analytics" db:"for_analytics" example:"true"`
+	AlertLow          *float64 `json:"alert_low,omitempty" db:"alert_low" example:"15"`
+	AlertHigh         *float64 `json:"alert_high,omitempty" db:"alert_high" example:"30"`
+}
+
+// AlertRule - правило оповещения по умолчанию для сенсора sensorId аккаунта accountId;
+// false, если у сенсора типа нет диапазона
+func (s DeviceTypeSensor) AlertRule(accountId, sensorId int) (AlertRule, bool) {
+	rule := AlertRule{
+		AccountID:     accountId,
+		SensorID:      sensorId,
+		Title:         fmt.Sprintf("%s is out of range", s.Title),
+		ThresholdLow:  s.AlertLow,
+		ThresholdHigh: s.AlertHigh,
+		Enabled:       true,
+	}
+
+	switch {
+	case s.AlertLow != nil && s.AlertHigh != nil:
+		rule.Kind = AlertOutside
+	case s.AlertHigh != nil:
+		rule.Kind = AlertAbove
+	case s.AlertLow != nil:
+		rule.Kind = AlertBelow
+	default:
+		return AlertRule{}, false
+	}
+	return rule, true
+}
+
+type DeviceType struct {
+	ID           int                `json:"id" db:"id" example:"1"`
+	AccountID    *int               `json:"account_id,omitempty" db:"account_id" example:"1"`
+	Model        string             `json:"model" db:"model" example:"Home_Weather"`
+	Title        string             `json:"title" db:"title" example:"Home weather"`
+	Description  string             `json:"description" db:"description" example:"Room temperature, humidity and pressure"`
+	Manufacturer string             `json:"manufacturer" db:"manufacturer" example:"AquaHub"`
+	Sensors      []DeviceTypeSensor `json:"sensors" db:"-"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// Описание типа устройства аккаунта; сенсоры заменяются целиком
+type DeviceTypeInput struct {
+	Model        string             `json:"model" binding:"required" example:"Home_Weather"`
+	Title        string             `json:"title" binding:"required" example:"Home weather"`
+	Description  string             `json:"description" example:"Room temperature, humidity and pressure"`
+	Manufacturer string             `json:"manufacturer" example:"AquaHub"`
+	Sensors      []DeviceTypeSensor `json:"sensors"`
+}
+
+func (i *DeviceTypeInput) Validate() error {
+	i.Model = strings.TrimSpace(i.Model)
+	i.Title = strings.TrimSpace(i.Title)
+
+	if i.Model == "" || len(i.Model) > 64 {
+		return errors.New("model must be 1..64 characters")
+	}
+	if i.Title == "" || len(i.Title) > 255 || len(i.Description) > 255 {
+		return errors.New("title must be 1..255 characters, description - up to 255")
+	}
+	if len(i.Manufacturer) > 100 {
+		return errors.New("manufacturer is too long")
+	}
+	if len(i.Sensors) > DeviceTypeSensorsMax {
+		return fmt.Errorf("device type has more than %d sensors", DeviceTypeSensorsMax)
+	}
+
+	seen := make(map[int]bool, len(i.Sensors))
+	for n := range i.Sensors {
+		s := &i.Sensors[n]
+		s.Title = strings.TrimSpace(s.Title)
+		if s.DataType == "" {
+			s.DataType = SensorDataFloat
+		}
+
+		// Локальный ID сенсора - две младшие цифры ключа показания f<device><sensor>
+		if s.LocalID < 0 || s.LocalID > 99 {
+			return errors.New("sensor local_id must be between 0 and 99")
+		}
+		if seen[s.LocalID] {
+			return fmt.Errorf("sensor local_id %d is repeated", s.LocalID)
+		}
+		seen[s.LocalID] = true
+
+		if s.Title == "" || len(s.Title) > 255 || len(s.Description) > 255 {
+			return errors.New("sensor title must be 1..255 characters, description - up to 255")
+		}
+		if len(s.Unit) > 32 {
+			return errors.New("sensor unit is too long")
+		}
+		switch s.DataType {
+		case SensorDataFloat, SensorDataInt, SensorDataBool, SensorDataString:
+		default:
+			return ErrUnknownSensorDataType
+		}
+		if s.ReportIntervalSec != nil && *s.ReportIntervalSec <= 0 {
+			return errors.New("sensor report_interval_sec must be positive")
+		}
+		if s.AlertLow != nil && s.AlertHigh != nil && *s.AlertLow >= *s.AlertHigh {
+			return errors.New("sensor alert_low must be less than alert_high")
+		}
+		if (s.AlertLow != nil || s.AlertHigh != nil) && (s.DataType == SensorDataBool || s.DataType == SensorDataString) {
+			return errors.New("alert range is allowed only for numeric sensors")
+		}
+	}
+	return nil
+}
+
+// Шаблон аккаунта
+type CreateDeviceType struct {
+	AccountID int `json:"account_id" binding:"required" example:"1"`
+	DeviceTypeInput
+}
+
+func (i *CreateDeviceType) Validate() error {
+	if i.AccountID == 0 {
+		return errors.New("account_id is required")
+	}
+	return i.DeviceTypeInput.Validate()
+}
+
+// Применение типа к устройству
+type SetDeviceType struct {
+	DeviceTypeID int `json:"device_type_id" binding:"required" example:"1"`
+}
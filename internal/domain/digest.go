@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Виды сводок: за прошедшие сутки и за прошедшую неделю (с понедельника)
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+var ErrUnknownDigestKind = errors.New("kind must be one of: daily, weekly")
+
+// Подписка участника аккаунта на сводки. Сводка отправляется в час Hour по часовому поясу пользователя.
+type DigestSubscription struct {
+	UserID          int        `json:"-" db:"user_id"`
+	AccountID       int        `json:"account_id" db:"account_id" example:"1"`
+	Daily           bool       `json:"daily" db:"daily" example:"true"`
+	Weekly          bool       `json:"weekly" db:"weekly" example:"false"`
+	Hour            int        `json:"hour" db:"hour" example:"8"`
+	Timezone        string     `json:"timezone" db:"timezone" example:"Europe/Kiev"`
+	LastDailyStart  *time.Time `json:"last_daily_start,omitempty" db:"last_daily_start"`
+	LastWeeklyStart *time.Time `json:"last_weekly_start,omitempty" db:"last_weekly_start"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type SetDigestSubscription struct {
+	Daily  *bool `json:"daily" binding:"required" example:"true"`
+	Weekly *bool `json:"weekly" binding:"required" example:"false"`
+	Hour   *int  `json:"hour,omitempty" example:"8"`
+}
+
+func (i SetDigestSubscription) Validate() error {
+	if i.Daily == nil || i.Weekly == nil {
+		return errors.New("daily and weekly are required")
+	}
+	if i.Hour != nil && (*i.Hour < 0 || *i.Hour > 23) {
+		return errors.New("hour must be 0..23")
+	}
+	return nil
+}
+
+// Сводка из архива
+type DigestReport struct {
+	ID          int        `json:"id" db:"id" example:"1"`
+	UserID      int        `json:"-" db:"user_id"`
+	AccountID   int        `json:"account_id" db:"account_id" example:"1"`
+	Kind        string     `json:"kind" db:"kind" example:"daily"`
+	PeriodStart time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end" db:"period_end"`
+	Timezone    string     `json:"timezone" db:"timezone" example:"Europe/Kiev"`
+	Report      DigestData `json:"report" db:"report"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type DigestReportsFilter struct {
+	AccountID int
+	Kind      string
+	From      time.Time
+	To        time.Time
+}
+
+// Содержимое сводки
+type DigestData struct {
+	Hubs         []DigestHub   `json:"hubs"`
+	Alerts       []DigestAlert `json:"alerts"`
+	OverdueItems int           `json:"overdue_items" example:"2"`
+}
+
+// Scan supports reading the DigestData value from the jsonb column.
+func (d *DigestData) Scan(value interface{}) error {
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, d)
+}
+
+// Value converts the DigestData value to be stored in the jsonb column.
+func (d DigestData) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	return string(b), err
+}
+
+// Хаб в сводке; Timezone - часовой пояс места хаба (без местоположения - аккаунта)
+type DigestHub struct {
+	AquahubID int            `json:"aquahub_id" db:"id" example:"2"`
+	Title     string         `json:"title" db:"title" example:"AquaHub More"`
+	Place     string         `json:"place,omitempty" db:"place" example:"Kyiv"`
+	Timezone  string         `json:"timezone" db:"timezone" example:"Europe/Kiev"`
+	Sensors   []DigestSensor `json:"sensors"`
+	Offline   []DigestPeriod `json:"offline"`
+}
+
+// Статистика показаний сенсора за период (помеченные детекторами показания не учитываются)
+type DigestSensor struct {
+	AquahubID int     `json:"-" db:"aquahub_id"`
+	SensorID  int     `json:"sensor_id" db:"sensor_id" example:"5"`
+	Device    string  `json:"device" db:"device" example:"Thermometer"`
+	Sensor    string  `json:"sensor" db:"sensor" example:"Water"`
+	Min       float64 `json:"min" db:"min" example:"25.1"`
+	Max       float64 `json:"max" db:"max" example:"26.4"`
+	Avg       float64 `json:"avg" db:"avg" example:"25.7"`
+	Count     int     `json:"count" db:"count" example:"1440"`
+}
+
+// Промежуток без показаний хаба дольше HubOfflineAfter
+type DigestPeriod struct {
+	AquahubID int       `json:"-" db:"aquahub_id"`
+	From      time.Time `json:"from" db:"from_at"`
+	To        time.Time `json:"to" db:"to_at"`
+}
+
+// Оповещение, активное в течение периода
+type DigestAlert struct {
+	AlertID    int        `json:"alert_id" db:"id" example:"15"`
+	RuleID     int        `json:"rule_id" db:"rule_id" example:"3"`
+	SensorID   int        `json:"sensor_id" db:"sensor_id" example:"5"`
+	Status     string     `json:"status" db:"status" example:"resolved"`
+	Message    string     `json:"message" db:"message" example:"Water temperature is above 28"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Местоположение хаба или аккаунта определяется по стране и почтовому индексу через справочник geonames,
+// часовой пояс по умолчанию - из country_timezones страны.
+
+var (
+	ErrLocationNotFound = errors.New("postal code is not found in the country")
+	ErrInvalidTimezone  = errors.New("timezone is unknown or does not belong to the country")
+)
+
+// Автодополнение: минимальная длина запроса, число мест по умолчанию и наибольшее
+const (
+	GeoQueryMinLen    = 2
+	GeoPlacesLimit    = 10
+	GeoPlacesLimitMax = 50
+)
+
+type Country struct {
+	Code             string `json:"code" db:"code" example:"UA"`
+	Name             string `json:"name" db:"name" example:"Ukraine"`
+	PostalCodeFormat string `json:"postal_code_format" db:"postal_code_format" example:"#####"`
+}
+
+// Место из справочника geonames
+type GeoPlace struct {
+	CountryCode string   `json:"country" db:"country_code" example:"UA"`
+	PostalCode  string   `json:"postal_code" db:"postal_code" example:"04207"`
+	PlaceName   string   `json:"place_name" db:"place_name" example:"Kyiv"`
+	StateName   string   `json:"state_name" db:"state_name" example:"Kyiv City"`
+	CountyName  string   `json:"county_name" db:"county_name" example:""`
+	Latitude    *float64 `json:"latitude,omitempty" db:"latitude" example:"50.5167"`
+	Longitude   *float64 `json:"longitude,omitempty" db:"longitude" example:"30.4996"`
+}
+
+// Местоположение хаба или аккаунта; у хаба без местоположения Timezone - часовой пояс аккаунта
+type Location struct {
+	Country    string   `json:"country" db:"country" example:"UA"`
+	PostalCode string   `json:"postal_code" db:"postal_code" example:"04207"`
+	PlaceName  string   `json:"place_name" db:"place_name" example:"Kyiv"`
+	StateName  string   `json:"state_name" db:"state_name" example:"Kyiv City"`
+	Latitude   *float64 `json:"latitude,omitempty" db:"latitude" example:"50.5167"`
+	Longitude  *float64 `json:"longitude,omitempty" db:"longitude" example:"30.4996"`
+	Timezone   string   `json:"timezone" db:"timezone" example:"Europe/Kiev"`
+}
+
+// Установка местоположения; PlaceName выбирает место, если у индекса их несколько,
+// без Timezone часовой пояс выбирается по стране
+type SetLocation struct {
+	Country    string `json:"country" binding:"required" example:"UA"`
+	PostalCode string `json:"postal_code" binding:"required" example:"04207"`
+	PlaceName  string `json:"place_name,omitempty" example:"Kyiv"`
+	Timezone   string `json:"timezone,omitempty" example:"Europe/Kiev"`
+}
+
+func (i *SetLocation) Validate() error {
+	i.Country = strings.ToUpper(strings.TrimSpace(i.Country))
+	i.PostalCode = strings.TrimSpace(i.PostalCode)
+	i.PlaceName = strings.TrimSpace(i.PlaceName)
+	i.Timezone = strings.TrimSpace(i.Timezone)
+
+	if len(i.Country) != 2 {
+		return errors.New("country must be ISO 3166-1 alpha-2 code")
+	}
+	if i.PostalCode == "" || len(i.PostalCode) > 60 {
+		return errors.New("postal_code must be 1..60 characters")
+	}
+	if len(i.PlaceName) > 200 {
+		return errors.New("place_name is too long")
+	}
+	if i.Timezone != "" {
+		if _, err := time.LoadLocation(i.Timezone); err != nil {
+			return ErrInvalidTimezone
+		}
+	}
+	return nil
+}
+
+// LocationTimezone - часовой пояс места в стране с часовыми поясами countryTimezones:
+// заданный tz должен принадлежать стране; без tz сохраняется текущий current, если он есть в стране,
+// иначе берётся первый пояс страны. Для страны без поясов в справочнике - tz или current.
+func LocationTimezone(tz string, countryTimezones []string, current string) (string, error) {
+	if len(countryTimezones) == 0 {
+		if tz != "" {
+			return tz, nil
+		}
+		return current, nil
+	}
+
+	if tz != "" {
+		for _, x := range countryTimezones {
+			if x == tz {
+				return tz, nil
+			}
+		}
+		return "", ErrInvalidTimezone
+	}
+
+	for _, x := range countryTimezones {
+		if x == current {
+			return current, nil
+		}
+	}
+	return countryTimezones[0], nil
+}
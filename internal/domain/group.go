@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Группы хабов аккаунта: площадка (site) -> зона (zone) -> резервуар (tank).
+// Зона входит в площадку, резервуар - в площадку или зону. Хаб относится к одной группе любого уровня.
+const (
+	GroupSite = "site"
+	GroupZone = "zone"
+	GroupTank = "tank"
+)
+
+var (
+	ErrUnknownGroupKind = errors.New("kind must be one of: site, zone, tank")
+	ErrInvalidGroupTree = errors.New("zone must belong to a site, tank - to a site or zone, site has no parent")
+	ErrGroupNotEmpty    = errors.New("group has nested groups")
+)
+
+// ValidGroupParent - может ли группа вида kind входить в группу вида parentKind ("" - без родителя)
+func ValidGroupParent(kind, parentKind string) bool {
+	switch kind {
+	case GroupSite:
+		return parentKind == ""
+	case GroupZone:
+		return parentKind == GroupSite
+	case GroupTank:
+		return parentKind == GroupSite || parentKind == GroupZone
+	}
+	return false
+}
+
+type HubGroup struct {
+	ID          int       `json:"id" db:"id" example:"3"`
+	AccountID   int       `json:"account_id" db:"account_id" example:"1"`
+	ParentID    *int      `json:"parent_id,omitempty" db:"parent_id" example:"2"`
+	Kind        string    `json:"kind" db:"kind" enums:"site,zone,tank" example:"tank"`
+	Title       string    `json:"title" db:"title" example:"Tank 12"`
+	Description string    `json:"description" db:"description" example:"Discus, 300L"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateHubGroup struct {
+	AccountID   int    `json:"account_id" binding:"required" example:"1"`
+	ParentID    *int   `json:"parent_id,omitempty" example:"2"`
+	Kind        string `json:"kind" binding:"required" enums:"site,zone,tank" example:"tank"`
+	Title       string `json:"title" binding:"required" example:"Tank 12"`
+	Description string `json:"description" example:"Discus, 300L"`
+}
+
+func (i CreateHubGroup) Validate() error {
+	switch i.Kind {
+	case GroupSite, GroupZone, GroupTank:
+	default:
+		return ErrUnknownGroupKind
+	}
+	if i.AccountID == 0 || strings.TrimSpace(i.Title) == "" {
+		return errors.New("account_id and title are required")
+	}
+	if len(i.Title) > 255 || len(i.Description) > 255 {
+		return errors.New("title or description is too long")
+	}
+	if (i.Kind == GroupSite) != (i.ParentID == nil) {
+		return ErrInvalidGroupTree
+	}
+	return nil
+}
+
+// Обновление группы; ParentID переносит зону или резервуар в другую группу того же аккаунта
+type UpdateHubGroup struct {
+	ParentID    *int    `json:"parent_id,omitempty" example:"2"`
+	Title       *string `json:"title" example:"Tank 12"`
+	Description *string `json:"description" example:"Discus, 300L"`
+}
+
+func (i UpdateHubGroup) Validate() error {
+	if i.ParentID == nil && i.Title == nil && i.Description == nil {
+		return errors.New("update has no values")
+	}
+	if i.Title != nil && (strings.TrimSpace(*i.Title) == "" || len(*i.Title) > 255) {
+		return errors.New("title must be 1..255 characters")
+	}
+	if i.Description != nil && len(*i.Description) > 255 {
+		return errors.New("description is too long")
+	}
+	return nil
+}
+
+// Привязка хаба к группе; GroupID = 0 - хаб вне групп
+type SetAquahubGroup struct {
+	GroupID int `json:"group_id" example:"3"`
+}
+
+//=========================================================================================
+
+// Сущности, которые помечаются тегами. Тег хаба или устройства относится и ко всему, что в него входит.
+const (
+	TagAquahub = "aquahub"
+	TagDevice  = "device"
+	TagSensor  = "sensor"
+)
+
+const (
+	MaxTagLen       = 64
+	MaxTagsOfEntity = 20
+)
+
+var (
+	ErrInvalidTag  = errors.New("tag must be 1..64 characters")
+	ErrTooManyTags = errors.New("too many tags (max 20)")
+)
+
+// Теги сущности; теги хранятся в нижнем регистре без повторов
+type SetTags struct {
+	Tags []string `json:"tags" example:"reef,quarantine"`
+}
+
+// Normalize приводит теги к нижнему регистру, убирает пробелы по краям и повторы
+func (i *SetTags) Normalize() error {
+	seen := make(map[string]bool, len(i.Tags))
+	tags := make([]string, 0, len(i.Tags))
+
+	for _, t := range i.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || len(t) > MaxTagLen {
+			return ErrInvalidTag
+		}
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > MaxTagsOfEntity {
+		return ErrTooManyTags
+	}
+
+	i.Tags = tags
+	return nil
+}
+
+// Тег аккаунта с числом помеченных сущностей
+type TagCount struct {
+	Tag   string `json:"tag" db:"tag" example:"reef"`
+	Count int    `json:"count" db:"count" example:"4"`
+}
+
+// Фильтр по группе и тегу для списков, отчётов и оповещений.
+// GroupID - группа вместе с вложенными группами; нулевые поля не ограничивают выборку.
+type GroupFilter struct {
+	GroupID int
+	Tag     string
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidHubToken = errors.New("invalid hub token")
+
+// Правило сопоставления точек InfluxDB line protocol сенсорам аккаунта.
+// Поле Field точки с измерением Measurement и всеми тегами Tags записывается в сенсор SensorID.
+// Правила проверяются по порядку ID, используется первое подходящее.
+type InfluxMapping struct {
+	ID          int        `json:"id" db:"id" example:"1"`
+	AccountID   int        `json:"account_id" db:"account_id" example:"1"`
+	Measurement string     `json:"measurement" db:"measurement" example:"water"`
+	Tags        InfluxTags `json:"tags" db:"tags" swaggertype:"object,string" example:"tank:reef"`
+	Field       string     `json:"field" db:"field" example:"temp"`
+	SensorID    int        `json:"sensor_id" db:"sensor_id" example:"12"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type InfluxTags map[string]string
+
+// Scan supports reading the InfluxTags value from the jsonb column.
+func (t *InfluxTags) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	return json.Unmarshal(asBytes, t)
+}
+
+// Value converts the InfluxTags value to be stored in the jsonb column.
+func (t InfluxTags) Value() (driver.Value, error) {
+	if t == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+// Match проверяет, подходит ли правило полю точки
+func (m InfluxMapping) Match(measurement string, tags map[string]string, field string) bool {
+	if m.Measurement != measurement || m.Field != field {
+		return false
+	}
+	for k, v := range m.Tags {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type CreateInfluxMapping struct {
+	Measurement string            `json:"measurement" binding:"required" example:"water"`
+	Tags        map[string]string `json:"tags,omitempty" swaggertype:"object,string" example:"tank:reef"`
+	Field       string            `json:"field" binding:"required" example:"temp"`
+	SensorID    int               `json:"sensor_id" binding:"required" example:"12"`
+}
+
+func (i CreateInfluxMapping) Validate() error {
+	if i.Measurement == "" || i.Field == "" {
+		return errors.New("measurement and field are required")
+	}
+	if i.SensorID <= 0 {
+		return errors.New("invalid sensor_id")
+	}
+	for k, v := range i.Tags {
+		if k == "" || v == "" {
+			return errors.New("tag keys and values must not be empty")
+		}
+	}
+	return nil
+}
+
+// Результат приёма пакета line protocol
+type InfluxWriteResult struct {
+	Accepted int
+	Unmapped int
+	Errors   []string
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Приглашение пользователя в аккаунт:
+//  1. менеджер приглашает адрес email с ролями (POST /api/accounts/:id/invites), письмо с токеном
+//     уходит через очередь уведомлений;
+//  2. получатель принимает приглашение токеном (POST /auth/invites/accept): новый пользователь
+//     регистрируется, существующий подтверждает свой пароль; в обоих случаях он становится участником аккаунта.
+//
+// Существующий пользователь до принятия приглашения числится участником аккаунта в статусе invited.
+// Токен подписан и содержит ID приглашения и одноразовую часть; при повторной отправке токен
+// меняется, и прежнее письмо перестаёт действовать.
+
+const (
+	InvitePending  = "pending"
+	InviteAccepted = "accepted"
+	InviteRevoked  = "revoked"
+	InviteExpired  = "expired" // ожидающее приглашение с истёкшим токеном
+)
+
+// Срок действия приглашения и длина одноразовой части токена
+const (
+	InviteTTL      = 7 * 24 * time.Hour
+	InviteNonceLen = 32
+)
+
+var (
+	ErrInvalidInvite  = errors.New("invite is invalid or expired")
+	ErrInvitePending  = errors.New("invite for the email is already pending")
+	ErrAlreadyMember  = errors.New("user is already a member of the account")
+	ErrInviteNotFound = errors.New("invite not found")
+	ErrInvitePassword = errors.New("password does not match the invited user")
+	ErrInviteUserInfo = errors.New("first_name and last_name are required for a new user")
+	ErrInviteStatus   = errors.New("status must be one of: pending, accepted, revoked, expired")
+)
+
+type AccountInvite struct {
+	ID         int              `json:"id" db:"id" example:"3"`
+	AccountID  int              `json:"account_id" db:"account_id" example:"1"`
+	Email      string           `json:"email" db:"email" example:"friend@example.com"`
+	Roles      UserAccountRoles `json:"roles" db:"roles" enums:"manager,user" swaggertype:"array,string" example:"user"`
+	Status     string           `json:"status" db:"status" enums:"pending,accepted,revoked,expired" example:"pending"`
+	InvitedBy  *int             `json:"invited_by,omitempty" db:"invited_by" example:"1"`
+	AcceptedBy *int             `json:"accepted_by,omitempty" db:"accepted_by" example:"30"`
+	RevokedBy  *int             `json:"revoked_by,omitempty" db:"revoked_by"`
+	SentCount  int              `json:"sent_count" db:"sent_count" example:"1"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+	ExpiresAt  time.Time        `json:"expires_at" db:"expires_at"`
+	SentAt     *time.Time       `json:"sent_at,omitempty" db:"sent_at"`
+	AcceptedAt *time.Time       `json:"accepted_at,omitempty" db:"accepted_at"`
+	RevokedAt  *time.Time       `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type CreateAccountInvite struct {
+	Email string           `json:"email" binding:"required" example:"friend@example.com"`
+	Roles UserAccountRoles `json:"roles,omitempty" enums:"manager,user" swaggertype:"array,string" example:"user"`
+}
+
+// Validate приводит адрес к нижнему регистру; без ролей приглашённый получает роль user
+func (i *CreateAccountInvite) Validate() error {
+	i.Email = strings.ToLower(strings.TrimSpace(i.Email))
+	if len(i.Email) > 200 || !strings.Contains(i.Email, "@") || strings.ContainsAny(i.Email, " \r\n<>") {
+		return errors.New("email must be an email address")
+	}
+
+	if len(i.Roles) == 0 {
+		i.Roles = UserAccountRoles{UserAccountRole_User}
+	}
+	for _, role := range i.Roles {
+		if !role.Valid() {
+			return ErrUnknownRole
+		}
+	}
+	return nil
+}
+
+type AccountInviteSent struct {
+	ID        int       `json:"id" example:"3"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Принятие приглашения. Новый пользователь указывает имя, фамилию и пароль,
+// существующий - только свой пароль.
+type AcceptAccountInvite struct {
+	Token     string `json:"token" binding:"required"`
+	FirstName string `json:"first_name" example:"Andy"`
+	LastName  string `json:"last_name" example:"Sokol"`
+	Password  string `json:"password" binding:"required" example:"jyWtbKg76by"`
+}
+
+func (i *AcceptAccountInvite) Validate() error {
+	i.Token = strings.TrimSpace(i.Token)
+	if i.Token == "" {
+		return ErrInvalidInvite
+	}
+	if i.Password == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+type AccountInviteAccepted struct {
+	UserID    int `json:"user_id" example:"30"`
+	AccountID int `json:"account_id" example:"1"`
+	AuthTokens
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Токен для сбора метрик аккаунта (Prometheus scrape).
+// Хранится только SHA-256 хеш токена; сам токен показывается один раз при создании.
+type MetricsToken struct {
+	ID         int        `json:"id" db:"id" example:"1"`
+	AccountID  int        `json:"account_id" db:"account_id" example:"1"`
+	Name       string     `json:"name" db:"name" example:"grafana"`
+	Prefix     string     `json:"prefix" db:"prefix" example:"mt_3fa8"`
+	CreatedBy  int        `json:"created_by" db:"created_by" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+type CreateMetricsToken struct {
+	Name string `json:"name" binding:"required" example:"grafana"`
+}
+
+type MetricsTokenCreated struct {
+	ID    int    `json:"id" example:"1"`
+	Token string `json:"token" example:"mt_3fa8c1d2e4b5a6978877665544332211"`
+}
+
+// Последнее показание сенсора с названиями хаба, устройства и сенсора
+type SensorMetric struct {
+	AquahubID    int       `db:"aquahub_id"`
+	AquahubTitle string    `db:"aquahub_title"`
+	DeviceID     int       `db:"device_id"`
+	DeviceTitle  string    `db:"device_title"`
+	SensorID     int       `db:"sensor_id"`
+	SensorTitle  string    `db:"sensor_title"`
+	Unit         string    `db:"unit"`
+	Value        string    `db:"value"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// Единица измерения сенсора
+type SetSensorUnit struct {
+	Unit string `json:"unit" example:"°C"`
+}
+
+func (i SetSensorUnit) Validate() error {
+	if len(i.Unit) > 32 {
+		return errors.New("unit must not exceed 32 characters")
+	}
+	return nil
+}
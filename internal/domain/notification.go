@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/pkg/netguard"
+)
+
+// События, о которых отправляются уведомления
+const (
+	NotifyAlertFiring   = "alert.firing"
+	NotifyAlertResolved = "alert.resolved"
+	NotifyAlertReminder = "alert.reminder" // оповещение всё ещё активно и не подтверждено
+	NotifyAutomation    = "automation"     // действие notify правила автоматизации
+	NotifyDigest        = "digest"         // ежедневная или еженедельная сводка
+	NotifyInvite        = "account.invite" // приглашение в аккаунт, отправляется на адрес приглашённого
+	NotifyTest          = "test"
+)
+
+// Письма пользователю, не связанные с аккаунтом (шаблоны аккаунтов к ним не применяются)
+const (
+	NotifyPasswordReset = "user.password_reset"
+)
+
+var NotifyEvents = []string{NotifyAlertFiring, NotifyAlertResolved, NotifyAlertReminder, NotifyAutomation, NotifyDigest, NotifyInvite, NotifyTest}
+
+// Каналы доставки
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+)
+
+// Статусы доставки
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+var ErrUnknownChannel = errors.New("channel must be one of: email, webhook, file")
+var ErrUnknownNotifyEvent = errors.New("unknown notification event")
+
+func IsNotifyEvent(event string) bool {
+	for _, e := range NotifyEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Настройка канала пользователя. Без настройки email включён и отправляется на адрес пользователя.
+// Target - адрес канала: email (по умолчанию - адрес пользователя), URL для webhook, имя получателя для file.
+type NotificationPreference struct {
+	UserID    int       `json:"-" db:"user_id"`
+	Channel   string    `json:"channel" db:"channel" example:"webhook"`
+	Target    string    `json:"target" db:"target" example:"https://example.com/hooks/aquahub"`
+	Enabled   bool      `json:"enabled" db:"enabled" example:"true"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetNotificationPreference struct {
+	Target  string `json:"target" example:"https://example.com/hooks/aquahub"`
+	Enabled *bool  `json:"enabled" binding:"required" example:"true"`
+}
+
+func (i SetNotificationPreference) Validate(channel string) error {
+	if i.Enabled == nil {
+		return errors.New("enabled is required")
+	}
+	if len(i.Target) > 512 {
+		return errors.New("target is too long")
+	}
+
+	switch channel {
+	case ChannelEmail:
+		if i.Target != "" && (!strings.Contains(i.Target, "@") || strings.ContainsAny(i.Target, " \r\n<>")) {
+			return errors.New("target must be an email address")
+		}
+	case ChannelWebhook:
+		u, err := url.Parse(i.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("target must be an http(s) url")
+		}
+		if err := netguard.CheckURL(i.Target); err != nil {
+			return fmt.Errorf("target: %w", err)
+		}
+	case ChannelFile:
+	default:
+		return ErrUnknownChannel
+	}
+
+	return nil
+}
+
+// Шаблон уведомления аккаунта (text/template). Поля данных шаблона:
+// .Event, .Message, .Value, .SensorID, .RuleID, .AlertID, .Since, .Time (во временной зоне получателя), .FirstName,
+// .Report (текст сводки), .Token (токен приглашения или сброса пароля)
+type NotificationTemplate struct {
+	AccountID int       `json:"-" db:"account_id"`
+	Event     string    `json:"event" db:"event" example:"alert.firing"`
+	Subject   string    `json:"subject" db:"subject" example:"Alert: {{.Message}}"`
+	Body      string    `json:"body" db:"body" example:"{{.Message}} at {{.Time}}"`
+	Custom    bool      `json:"custom" db:"-"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetNotificationTemplate struct {
+	Subject string `json:"subject" binding:"required" example:"Alert: {{.Message}}"`
+	Body    string `json:"body" binding:"required" example:"{{.Message}} at {{.Time}}"`
+}
+
+func (i SetNotificationTemplate) Validate() error {
+	if strings.TrimSpace(i.Subject) == "" || strings.TrimSpace(i.Body) == "" {
+		return errors.New("subject and body are required")
+	}
+	if len(i.Subject) > 255 || len(i.Body) > 8192 {
+		return errors.New("template is too long")
+	}
+	return nil
+}
+
+// Уведомление о событии аккаунта - рассылается участникам аккаунта по их каналам
+type Notification struct {
+	AccountID int
+	Event     string
+	SourceID  int
+	SensorID  int
+	RuleID    int
+	AlertID   int
+	Message   string
+	Report    string // текст сводки
+	Token     string // токен приглашения или сброса пароля
+	Value     string
+	Since     time.Time // начало оповещения для напоминаний
+	Time      time.Time
+}
+
+// Получатель уведомлений - активный участник аккаунта
+type NotificationRecipient struct {
+	UserID    int    `db:"user_id"`
+	Email     string `db:"email"`
+	FirstName string `db:"first_name"`
+	Timezone  string `db:"timezone"`
+}
+
+// Запись журнала доставки
+type NotificationDelivery struct {
+	ID            int        `json:"id" db:"id" example:"1"`
+	UserID        int        `json:"-" db:"user_id"`
+	AccountID     int        `json:"account_id" db:"account_id" example:"1"`
+	Channel       string     `json:"channel" db:"channel" example:"email"`
+	Target        string     `json:"target" db:"target" example:"owner@example.com"`
+	Event         string     `json:"event" db:"event" example:"alert.firing"`
+	SourceID      int        `json:"source_id" db:"source_id" example:"15"`
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"body" db:"body"`
+	Status        string     `json:"status" db:"status" example:"sent"`
+	Attempts      int        `json:"attempts" db:"attempts" example:"1"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+type NotificationTestResult struct {
+	Queued int `json:"queued" example:"2"`
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Привязка хаба к аккаунту:
+//  1. хаб с заводским h_token запрашивает код привязки (POST /v1/pairing) и показывает его пользователю;
+//  2. пользователь вводит код (POST /api/aquahubs/claim), в аккаунте создаётся аквахаб с h_token хаба;
+//  3. хаб повторяет запрос /v1/pairing и получает u_token аккаунта для API v1.
+//
+// Отвязка (unclaim) архивирует аквахаб вместе с данными и меняет его h_token,
+// хаб с заводским токеном снова получает код привязки.
+// Сброс к заводским настройкам (factory-reset) ставит хабу команду factory_reset;
+// хаб отвязывается, когда после сброса заново запросит код привязки.
+// Аквахаб в архиве сохраняет h_token, чтобы его можно было восстановить: хаб получает статус archived
+// без кода привязки, пока аквахаб не восстановлен, не отвязан после восстановления или не удалён из архива.
+
+const (
+	PairingPending  = "pending"  // хаб ждёт привязки, показывает код
+	PairingClaimed  = "claimed"  // хаб привязан к аккаунту
+	PairingArchived = "archived" // аквахаб хаба в архиве аккаунта
+)
+
+// Срок действия кода привязки и длина кода
+const (
+	PairingCodeTTL = 10 * time.Minute
+	PairingCodeLen = 8
+)
+
+// Команда хабу сбросить настройки к заводским
+const CommandFactoryReset = "factory_reset"
+
+var (
+	ErrInvalidPairingCode = errors.New("pairing code is invalid or expired")
+	ErrHubAlreadyClaimed  = errors.New("hub is already claimed")
+)
+
+// Ответ хабу на запрос привязки
+type HubPairing struct {
+	Status    string     `json:"status" enums:"pending,claimed,archived" example:"pending"`
+	Code      string     `json:"code,omitempty" example:"K7QH2MXA"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UToken    string     `json:"u_token,omitempty" example:"a39831d103eb4c0d"`
+}
+
+// Ожидающий код привязки хаба
+type PairingCode struct {
+	HToken    string    `db:"h_token"`
+	Code      string    `db:"code"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// Аквахаб, найденный по h_token при запросе привязки
+type PairedHub struct {
+	ID               int        `db:"id"`
+	AccountID        int        `db:"account_id"`
+	UToken           string     `db:"u_token"`
+	ResetRequestedAt *time.Time `db:"reset_requested_at"`
+	ArchivedAt       *time.Time `db:"archived_at"`
+}
+
+type ClaimAquahub struct {
+	AccountID   int    `json:"account_id" binding:"required" example:"1"`
+	Code        string `json:"code" binding:"required" example:"K7QH2MXA"`
+	Title       string `json:"title" binding:"required" example:"Aquarium 120L"`
+	Description string `json:"description" example:"Living room"`
+}
+
+func (i *ClaimAquahub) Validate() error {
+	i.Code = strings.ToUpper(strings.TrimSpace(i.Code))
+	if len(i.Code) != PairingCodeLen {
+		return ErrInvalidPairingCode
+	}
+	return CreateAquahub{AccountID: i.AccountID, Title: i.Title, Description: i.Description}.Validate()
+}
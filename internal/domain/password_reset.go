@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Сброс пароля:
+//  1. пользователь запрашивает сброс по адресу email (POST /auth/forgot-password); письмо с токеном
+//     уходит через очередь уведомлений, ответ не зависит от того, есть ли пользователь с этим адресом;
+//  2. пользователь задаёт новый пароль с токеном из письма (POST /auth/reset-password).
+//
+// Токен одноразовый: в users.password_reset хранится часть хеша его одноразовой части, после смены
+// пароля она очищается, новый запрос сброса заменяет её. Смена пароля завершает все сеансы пользователя.
+
+// Срок действия токена сброса пароля и длина его одноразовой части
+const (
+	PasswordResetTTL      = time.Hour
+	PasswordResetNonceLen = 32
+)
+
+var (
+	ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+	ErrSessionExpired    = errors.New("session expired, sign in again")
+)
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required" example:"ae@ae.ae"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required" example:"jyWtbKg76by"`
+}
+
+func (i *ResetPassword) Validate() error {
+	i.Token = strings.TrimSpace(i.Token)
+	if i.Token == "" {
+		return ErrInvalidResetToken
+	}
+	if i.Password == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Сеансы пользователя: при входе выдаётся короткий токен доступа (JWT с jti) и токен обновления.
+// Токен обновления обменивается на новую пару (POST /auth/refresh) и при этом заменяется новым;
+// повторное использование обменянного токена считается утечкой и завершает весь сеанс.
+// Выход (POST /auth/logout) отзывает токен доступа по jti и токены обновления сеанса.
+
+// Срок действия токена обновления и длина токена
+const (
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	RefreshTokenLen = 48
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrTokenRevoked        = errors.New("token is revoked")
+)
+
+// Токен обновления в БД
+type RefreshToken struct {
+	ID              int        `db:"id"`
+	UserID          int        `db:"user_id"`
+	Family          string     `db:"family"`
+	TokenHash       string     `db:"token_hash"`
+	AccessJTI       string     `db:"access_jti"`
+	AccessExpiresAt time.Time  `db:"access_expires_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	ExpiresAt       time.Time  `db:"expires_at"`
+	UsedAt          *time.Time `db:"used_at"`
+	RevokedAt       *time.Time `db:"revoked_at"`
+}
+
+// Пара токенов сеанса; token - с префиксом Bearer для заголовка Authorization
+type AuthTokens struct {
+	Token            string    `json:"token" example:"Bearer eyJhbGciOiJIUzI1NiIs..."`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token" example:"c2VjcmV0LXJlZnJlc2gtdG9rZW4..."`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type RefreshSession struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Выход: all - завершить все сеансы пользователя, иначе только текущий
+type Logout struct {
+	All bool `json:"all" example:"false"`
+}
+
+// Удалено плановой задачей
+type SessionsPurged struct {
+	RefreshTokens int
+	RevokedTokens int
+}
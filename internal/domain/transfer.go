@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Передача хаба другому аккаунту (например, при продаже аквариума с контроллером):
+//  1. владелец создаёт передачу (POST /api/aquahubs/:id/transfer) и сообщает код получателю;
+//  2. получатель принимает передачу кодом в свой аккаунт (POST /api/transfers/accept).
+//
+// Режим move - хаб переходит вместе с устройствами, сенсорами и показаниями;
+// правила оповещений, теги и группа прежнего аккаунта снимаются, правила автоматизации прежнего аккаунта
+// с сенсорами и устройствами хаба выключаются. Виртуальные сенсоры, формулы которых после передачи
+// ссылались бы на сенсоры другого аккаунта, переносятся в архив.
+// Режим keep - хаб с данными остаётся в архиве прежнего аккаунта, получатель получает хаб
+// с теми же устройствами и сенсорами без показаний.
+// В обоих режимах токен хаба меняется. Новый h_token получает только получатель в ответе на приём передачи
+// и сам записывает его в хаб; по прежнему токену хаб получает лишь код привязки.
+
+const (
+	TransferMove = "move"
+	TransferKeep = "keep"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferCompleted = "completed"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired" // ожидающая передача с истёкшим кодом
+)
+
+// Срок действия кода передачи (и выдачи новых токенов хабу после передачи) и длина кода
+const (
+	TransferCodeTTL = 7 * 24 * time.Hour
+	TransferCodeLen = 8
+)
+
+var (
+	ErrInvalidTransferCode = errors.New("transfer code is invalid or expired")
+	ErrTransferSameAccount = errors.New("aquahub already belongs to the account")
+	ErrUnknownTransferMode = errors.New("mode must be one of: move, keep")
+)
+
+type HubTransfer struct {
+	ID            int        `json:"id" db:"id" example:"4"`
+	AquahubID     int        `json:"aquahub_id" db:"aquahub_id" example:"2"`
+	FromAccountID int        `json:"from_account_id" db:"from_account_id" example:"1"`
+	ToAccountID   *int       `json:"to_account_id,omitempty" db:"to_account_id" example:"25"`
+	NewAquahubID  *int       `json:"new_aquahub_id,omitempty" db:"new_aquahub_id" example:"2"`
+	Mode          string     `json:"mode" db:"mode" enums:"move,keep" example:"move"`
+	Code          string     `json:"code,omitempty" db:"code" example:"Q4ZP8NWE"`
+	Status        string     `json:"status" db:"status" enums:"pending,completed,cancelled,expired" example:"pending"`
+	CreatedBy     *int       `json:"created_by,omitempty" db:"created_by" example:"1"`
+	AcceptedBy    *int       `json:"accepted_by,omitempty" db:"accepted_by" example:"30"`
+	CancelledBy   *int       `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type CreateHubTransfer struct {
+	Mode string `json:"mode" binding:"required" enums:"move,keep" example:"move"`
+}
+
+func (i CreateHubTransfer) Validate() error {
+	if i.Mode != TransferMove && i.Mode != TransferKeep {
+		return ErrUnknownTransferMode
+	}
+	return nil
+}
+
+type HubTransferCreated struct {
+	ID        int       `json:"id" example:"4"`
+	Code      string    `json:"code" example:"Q4ZP8NWE"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Принятая передача с новым токеном хаба для получателя
+type HubTransferAccepted struct {
+	HubTransfer
+	HToken string `json:"h_token" example:"k2VbA9x0QmT7sLw4"`
+}
+
+type AcceptHubTransfer struct {
+	AccountID int    `json:"account_id" binding:"required" example:"25"`
+	Code      string `json:"code" binding:"required" example:"Q4ZP8NWE"`
+}
+
+func (i *AcceptHubTransfer) Validate() error {
+	i.Code = strings.ToUpper(strings.TrimSpace(i.Code))
+	if len(i.Code) != TransferCodeLen {
+		return ErrInvalidTransferCode
+	}
+	if i.AccountID == 0 {
+		return errors.New("account_id is required")
+	}
+	return nil
+}
+
+// Фильтр журнала передач; нулевые поля не ограничивают выборку
+type HubTransfersFilter struct {
+	AccountID int
+	AquahubID int
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"errors"
 	"time"
 
 	"github.com/lib/pq"
@@ -61,9 +63,138 @@ const (
 	// UserAccountRole_Manager defines the state of a user when they have manager
 	// privileges for accessing an account. This role provides a user with full
 	// access to an account.
-	UserAccountRole_Manager UserAccountRole = "Manager"
+	UserAccountRole_Manager UserAccountRole = "manager"
 	// UserAccountRole_User defines the state of a user when they have basic
 	// privileges for accessing an account. This role provies a user with the most
 	// limited access to an account.
-	UserAccountRole_User UserAccountRole = "User"
+	UserAccountRole_User UserAccountRole = "user"
 )
+
+// UserAccountRole_Values provides list of valid UserAccountRole values.
+var UserAccountRole_Values = []UserAccountRole{
+	UserAccountRole_Manager,
+	UserAccountRole_User,
+}
+
+// String converts the UserAccountRole value to a string.
+func (s UserAccountRole) String() string {
+	return string(s)
+}
+
+// Scan supports reading the UserAccountRoles value from the database.
+func (s *UserAccountRoles) Scan(value interface{}) error {
+	var arr pq.StringArray
+	if err := arr.Scan(value); err != nil {
+		return err
+	}
+
+	*s = make(UserAccountRoles, 0, len(arr))
+	for _, v := range arr {
+		*s = append(*s, UserAccountRole(v))
+	}
+	return nil
+}
+
+// Value converts the UserAccountRoles value to be stored in the database.
+func (s UserAccountRoles) Value() (driver.Value, error) {
+	arr := make(pq.StringArray, 0, len(s))
+	for _, v := range s {
+		if !v.Valid() {
+			return nil, ErrUnknownRole
+		}
+		arr = append(arr, string(v))
+	}
+	return arr.Value()
+}
+
+//-------------------------------------------------------------------------
+// Права доступа к аккаунту: менеджер управляет хабами и участниками аккаунта,
+// пользователь только просматривает данные аккаунта.
+
+type Permission string
+
+const (
+	PermissionRead   Permission = "read"
+	PermissionManage Permission = "manage"
+)
+
+var rolePermissions = map[UserAccountRole][]Permission{
+	UserAccountRole_Manager: {PermissionRead, PermissionManage},
+	UserAccountRole_User:    {PermissionRead},
+}
+
+var (
+	ErrForbidden   = errors.New("access denied: not enough permissions for the account")
+	ErrUnknownRole = errors.New("roles must be one of: manager, user")
+	ErrLastManager = errors.New("account must have at least one active manager")
+)
+
+// Valid - роль из списка известных ролей
+func (s UserAccountRole) Valid() bool {
+	_, ok := rolePermissions[s]
+	return ok
+}
+
+// Can - хотя бы одна из ролей даёт право perm
+func (s UserAccountRoles) Can(perm Permission) bool {
+	for _, role := range s {
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RolesWith - роли, дающие право perm
+func RolesWith(perm Permission) UserAccountRoles {
+	var roles UserAccountRoles
+	for _, role := range UserAccountRole_Values {
+		if (UserAccountRoles{role}).Can(perm) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+//-------------------------------------------------------------------------
+
+// Участник аккаунта
+type AccountMember struct {
+	UserID    int               `json:"user_id" db:"user_id" example:"2"`
+	FirstName string            `json:"first_name" db:"first_name" example:"Andy"`
+	LastName  string            `json:"last_name" db:"last_name" example:"Sokol"`
+	Email     string            `json:"email" db:"email" example:"ae@ae.ae"`
+	Roles     UserAccountRoles  `json:"roles" db:"roles" enums:"manager,user" swaggertype:"array,string" example:"manager"`
+	Status    UserAccountStatus `json:"status" db:"status" enums:"active,invited,disabled" swaggertype:"string" example:"active"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Изменение ролей и статуса участника; пустые поля не меняются
+type UpdateAccountMember struct {
+	Roles  *UserAccountRoles  `json:"roles,omitempty" enums:"manager,user" swaggertype:"array,string" example:"user"`
+	Status *UserAccountStatus `json:"status,omitempty" enums:"active,disabled" swaggertype:"string" example:"active"`
+}
+
+func (i UpdateAccountMember) Validate() error {
+	if i.Roles == nil && i.Status == nil {
+		return errors.New("update structure has no values")
+	}
+	if i.Roles != nil {
+		if len(*i.Roles) == 0 {
+			return ErrUnknownRole
+		}
+		for _, role := range *i.Roles {
+			if !role.Valid() {
+				return ErrUnknownRole
+			}
+		}
+	}
+	// Статус invited назначается только приглашением
+	if i.Status != nil && *i.Status != UserAccountStatus_Active && *i.Status != UserAccountStatus_Disabled {
+		return errors.New("status must be one of: active, disabled")
+	}
+	return nil
+}
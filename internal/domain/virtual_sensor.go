@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidFormula - формула виртуального сенсора отклонена (синтаксис, чужие сенсоры, циклы)
+var ErrInvalidFormula = errors.New("invalid formula")
+
+// Длина формулы - как у столбца sensors.formula (varchar(1024)); проверяется до разбора
+const MaxFormulaLen = 1024
+
+var ErrFormulaTooLong = fmt.Errorf("%w: formula is longer than %d bytes", ErrInvalidFormula, MaxFormulaLen)
+
+// Виртуальный (вычисляемый) сенсор - строка таблицы sensors с заполненным полем formula.
+// Значение вычисляется сервером при приёме данных из значений других сенсоров того же аккаунта
+// и сохраняется в sensors_dataset как обычное показание.
+
+type VirtualSensor struct {
+	ID          int    `json:"id" db:"id" example:"31"`
+	AccountID   int    `json:"-" db:"account_id"`
+	AquahubID   int    `json:"aquahub_id" db:"aquahub_id" example:"1"`
+	DeviceID    int    `json:"device_id" db:"device_id" example:"3"`
+	LocalID     int    `json:"-" db:"local_id"`
+	Title       string `json:"title" db:"title" example:"Average temperature"`
+	Description string `json:"description" db:"description" example:"Average of three probes"`
+	Formula     string `json:"formula" db:"formula" example:"avg(s12, s13, s14)"`
+	// Сенсор в архиве не вычисляется
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+}
+
+type CreateVirtualSensor struct {
+	DeviceID    int     `json:"device_id" binding:"required" example:"3"`
+	Title       *string `json:"title" binding:"required" example:"Average temperature"`
+	Description *string `json:"description,omitempty" example:"Average of three probes"`
+	Formula     string  `json:"formula" binding:"required" example:"avg(s12, s13, s14)"`
+}
+
+func (i CreateVirtualSensor) Validate() error {
+	if i.DeviceID == 0 || i.Title == nil || *i.Title == "" || i.Formula == "" {
+		return errors.New("the input data does not have the desired values")
+	}
+	if len(i.Formula) > MaxFormulaLen {
+		return ErrFormulaTooLong
+	}
+
+	return nil
+}
+
+type UpdateVirtualSensor struct {
+	ID          int     `json:"-"`
+	Title       *string `json:"title" example:"Average temperature"`
+	Description *string `json:"description" example:"Average of three probes"`
+	Formula     *string `json:"formula" example:"(s12 + s13 + s14) / 3"`
+}
+
+func (i UpdateVirtualSensor) Validate() error {
+	if (i.Title == nil && i.Description == nil && i.Formula == nil) || i.ID == 0 {
+		return errors.New("update has no values")
+	}
+	if i.Formula != nil && len(*i.Formula) > MaxFormulaLen {
+		return ErrFormulaTooLong
+	}
+
+	return nil
+}
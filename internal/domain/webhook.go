@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/pkg/netguard"
+)
+
+// События платформы для webhook_ов
+const (
+	WebhookReadingReceived    = "reading.received"
+	WebhookDeviceAdded        = "device.added"
+	WebhookSensorAdded        = "sensor.added"
+	WebhookHubOffline         = "hub.offline"
+	WebhookAlertFired         = "alert.fired"
+	WebhookChecklistCompleted = "checklist.completed"
+)
+
+var WebhookEvents = []string{WebhookReadingReceived, WebhookDeviceAdded, WebhookSensorAdded,
+	WebhookHubOffline, WebhookAlertFired, WebhookChecklistCompleted}
+
+// Доставка: повторы с растущей задержкой, пока получатель не ответит 2xx
+const (
+	WebhookTimeout       = 10 * time.Second
+	WebhookMaxAttempts   = 8
+	WebhookRetryDelay    = 30 * time.Second
+	WebhookMaxRetryDelay = time.Hour
+	MaxWebhooksOfAccount = 20
+)
+
+// Хаб без показаний дольше HubOfflineAfter считается отключённым
+const HubOfflineAfter = 15 * time.Minute
+
+var ErrUnknownWebhookEvent = errors.New("events must be some of: reading.received, device.added, sensor.added, hub.offline, alert.fired, checklist.completed")
+
+func IsWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Подписка аккаунта; секрет выдаётся только при создании
+type Webhook struct {
+	ID          int            `json:"id" db:"id" example:"1"`
+	AccountID   int            `json:"account_id" db:"account_id" example:"1"`
+	URL         string         `json:"url" db:"url" example:"https://example.com/hooks/aquahub"`
+	Secret      string         `json:"-" db:"secret"`
+	Events      pq.StringArray `json:"events" db:"events" swaggertype:"array,string" example:"alert.fired,hub.offline"`
+	Description string         `json:"description" db:"description" example:"Back office"`
+	Enabled     bool           `json:"enabled" db:"enabled" example:"true"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+func (w Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type SetWebhook struct {
+	URL         string   `json:"url" binding:"required" example:"https://example.com/hooks/aquahub"`
+	Events      []string `json:"events" binding:"required" example:"alert.fired,hub.offline"`
+	Description string   `json:"description" example:"Back office"`
+	Enabled     *bool    `json:"enabled,omitempty" example:"true"`
+}
+
+func (i SetWebhook) Validate() error {
+	u, err := url.Parse(i.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(i.URL) > 512 {
+		return errors.New("url must be an http(s) url")
+	}
+	// Запросы на адреса внутренней сети сервера запрещены (SSRF)
+	if err := netguard.CheckURL(i.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if len(i.Events) == 0 {
+		return ErrUnknownWebhookEvent
+	}
+	for _, e := range i.Events {
+		if !IsWebhookEvent(e) {
+			return ErrUnknownWebhookEvent
+		}
+	}
+	if len(i.Description) > 255 {
+		return errors.New("description is too long")
+	}
+	return nil
+}
+
+// Созданная подписка с секретом для проверки подписи
+type WebhookCreated struct {
+	ID     int    `json:"id" example:"1"`
+	Secret string `json:"secret" example:"k3Jd9sL0qP2mX7vB1nC4zT6yW8eR5uA0"`
+}
+
+// Тело запроса: одинаково для всех подписок события; id - ID события для отбрасывания дубликатов
+type WebhookEvent struct {
+	ID        string      `json:"id" example:"6f1c0a9e2b7d4c3e8a5f0b1d2c3e4f5a"`
+	Event     string      `json:"event" example:"alert.fired"`
+	AccountID int         `json:"account_id" example:"1"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Данные событий
+type WebhookReading struct {
+	SensorID  int       `json:"sensor_id"`
+	DeviceID  int       `json:"device_id"`
+	AquahubID int       `json:"aquahub_id"`
+	Value     string    `json:"value"`
+	Flag      *string   `json:"flag,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookReadings struct {
+	Readings []WebhookReading `json:"readings"`
+}
+
+type WebhookDevice struct {
+	AquahubID int    `json:"aquahub_id"`
+	DeviceID  int    `json:"device_id"`
+	LocalID   int    `json:"local_id"`
+	Title     string `json:"title"`
+}
+
+type WebhookSensor struct {
+	AquahubID     int `json:"aquahub_id"`
+	SensorID      int `json:"sensor_id"`
+	DeviceLocalID int `json:"device_local_id"`
+	LocalID       int `json:"local_id"`
+}
+
+type WebhookHub struct {
+	AquahubID  int       `json:"aquahub_id" db:"id"`
+	AccountID  int       `json:"-" db:"account_id"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+type WebhookAlert struct {
+	AlertID  int     `json:"alert_id"`
+	RuleID   int     `json:"rule_id"`
+	SensorID int     `json:"sensor_id"`
+	Value    *string `json:"value,omitempty"`
+	Message  string  `json:"message"`
+}
+
+type WebhookChecklist struct {
+	ChecklistID int    `json:"checklist_id" db:"id"`
+	AccountID   int    `json:"-" db:"account_id"`
+	TemplateID  *int   `json:"template_id,omitempty" db:"template_id"`
+	Title       string `json:"title" db:"title"`
+}
+
+// Тело запроса в jsonb
+type WebhookPayload []byte
+
+// Scan supports reading the WebhookPayload value from the jsonb column.
+func (p *WebhookPayload) Scan(value interface{}) error {
+	asBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("Scan source is not []byte")
+	}
+	*p = append((*p)[:0], asBytes...)
+	return nil
+}
+
+// Value converts the WebhookPayload value to be stored in the jsonb column.
+func (p WebhookPayload) Value() (driver.Value, error) {
+	return string(p), nil
+}
+
+func (p WebhookPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// Запись очереди и журнала доставки
+type WebhookDelivery struct {
+	ID             int            `json:"id" db:"id" example:"1"`
+	WebhookID      int            `json:"webhook_id" db:"webhook_id" example:"1"`
+	AccountID      int            `json:"-" db:"account_id"`
+	URL            string         `json:"-" db:"url"`
+	Secret         string         `json:"-" db:"secret"`
+	Enabled        bool           `json:"-" db:"enabled"`
+	Event          string         `json:"event" db:"event" example:"alert.fired"`
+	EventID        string         `json:"event_id" db:"event_id" example:"6f1c0a9e2b7d4c3e8a5f0b1d2c3e4f5a"`
+	Payload        WebhookPayload `json:"payload" db:"payload" swaggertype:"object"`
+	Status         string         `json:"status" db:"status" example:"sent"`
+	Attempts       int            `json:"attempts" db:"attempts" example:"1"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	ResponseStatus int            `json:"response_status" db:"response_status" example:"200"`
+	LastError      string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" db:"delivered_at"`
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Роли и участники аккаунтов (users_accounts)
+
+type AccessPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewAccessPostgres(log *logrus.Logger, db *sqlx.DB) *AccessPostgres {
+	return &AccessPostgres{log: log, db: db}
+}
+
+// Роли активного участника аккаунта; не участник - пустой список
+func (r *AccessPostgres) GetRoles_OfUser(userId, accountId int) (domain.UserAccountRoles, error) {
+
+	query := fmt.Sprintf(`SELECT roles FROM %s WHERE user_id = $1 AND account_id = $2 AND status = 'active' AND archived_at IS NULL`,
+		userAccountTableName)
+
+	var roles domain.UserAccountRoles
+	if err := r.db.Get(&roles, query, userId, accountId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log.Errorf("db: error GetRoles Access: %s", err.Error())
+		return nil, errors.New("db: error GetRoles Access")
+	}
+
+	return roles, nil
+}
+
+// Есть ли у пользователя право perm хотя бы в одном аккаунте
+func (r *AccessPostgres) HasPermission(userId int, perm domain.Permission) (bool, error) {
+
+	query := fmt.Sprintf(`SELECT EXISTS (%s)`, accountsQuery(1, perm))
+
+	var ok bool
+	if err := r.db.Get(&ok, query, userId); err != nil {
+		r.log.Errorf("db: error HasPermission Access: %s", err.Error())
+		return false, errors.New("db: error HasPermission Access")
+	}
+
+	return ok, nil
+}
+
+const accountMemberColumns = `ua.user_id, u.first_name, u.last_name, u.email, ua.roles, ua.status, ua.created_at, ua.updated_at`
+
+func (r *AccessPostgres) GetMembers(accountId int) ([]domain.AccountMember, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ua INNER JOIN %s u ON u.id = ua.user_id
+							WHERE ua.account_id = $1 AND ua.archived_at IS NULL ORDER BY ua.user_id`,
+		accountMemberColumns, userAccountTableName, usersTable)
+
+	list := []domain.AccountMember{}
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetMembers Access: %s", err.Error())
+		return nil, errors.New("db: error GetMembers Access")
+	}
+
+	return list, nil
+}
+
+// Изменение ролей и статуса участника. Последний активный менеджер аккаунта
+// не может лишиться роли менеджера или быть отключён.
+func (r *AccessPostgres) UpdateMember(accountId, userId int, input domain.UpdateAccountMember) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error UpdateMember Access: %s", err.Error())
+		return errors.New("db: error UpdateMember Access")
+	}
+
+	if err := lockManagers(tx, accountId); err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error UpdateMember Access: %s", err.Error())
+		return errors.New("db: error UpdateMember Access")
+	}
+
+	values := map[string]interface{}{}
+	if input.Roles != nil {
+		values["roles"] = *input.Roles
+	}
+	if input.Status != nil {
+		values["status"] = *input.Status
+	}
+	setQuery, args := setColumns(values, []string{"roles", "status"}, 1)
+	argId := len(args) + 1
+
+	// Приглашённый пользователь становится активным участником, только приняв приглашение
+	query := fmt.Sprintf(`UPDATE %s SET %s, updated_at = CURRENT_TIMESTAMP
+							WHERE account_id = $%d AND user_id = $%d AND archived_at IS NULL AND status <> 'invited'`,
+		userAccountTableName, setQuery, argId, argId+1)
+	args = append(args, accountId, userId)
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error UpdateMember Access: %s", err.Error())
+		return errors.New("db: error UpdateMember Access")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errors.New("db: member not found")
+	}
+
+	if err := checkManagers(tx, accountId); err != nil {
+		tx.Rollback()
+		if err == domain.ErrLastManager {
+			return err
+		}
+		r.log.Errorf("db: error UpdateMember Access: %s", err.Error())
+		return errors.New("db: error UpdateMember Access")
+	}
+
+	return tx.Commit()
+}
+
+// Исключение участника из аккаунта (запись переносится в архив)
+func (r *AccessPostgres) RemoveMember(accountId, userId int) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error RemoveMember Access: %s", err.Error())
+		return errors.New("db: error RemoveMember Access")
+	}
+
+	if err := lockManagers(tx, accountId); err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error RemoveMember Access: %s", err.Error())
+		return errors.New("db: error RemoveMember Access")
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+							WHERE account_id = $1 AND user_id = $2 AND archived_at IS NULL`, userAccountTableName)
+
+	res, err := tx.Exec(query, accountId, userId)
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error RemoveMember Access: %s", err.Error())
+		return errors.New("db: error RemoveMember Access")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return errors.New("db: member not found")
+	}
+
+	if err := checkManagers(tx, accountId); err != nil {
+		tx.Rollback()
+		if err == domain.ErrLastManager {
+			return err
+		}
+		r.log.Errorf("db: error RemoveMember Access: %s", err.Error())
+		return errors.New("db: error RemoveMember Access")
+	}
+
+	return tx.Commit()
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// Блокировка участников аккаунта: параллельные изменения не оставят аккаунт без менеджера
+func lockManagers(tx *sqlx.Tx, accountId int) error {
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE account_id = $1 FOR UPDATE`, userAccountTableName)
+	_, err := tx.Exec(query, accountId)
+	return err
+}
+
+// У аккаунта должен остаться хотя бы один активный менеджер
+func checkManagers(tx *sqlx.Tx, accountId int) error {
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE account_id = $1 AND status = 'active' AND archived_at IS NULL
+							AND '%s' = ANY(roles)`, userAccountTableName, domain.UserAccountRole_Manager)
+
+	var n int
+	if err := tx.Get(&n, query, accountId); err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrLastManager
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type AlertPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewAlertPostgres(log *logrus.Logger, db *sqlx.DB) *AlertPostgres {
+	return &AlertPostgres{log: log, db: db}
+}
+
+const alertRuleColumns = `id, account_id, sensor_id, title, kind, threshold_low, threshold_high, hysteresis,
+							min_duration_sec, no_data_sec, renotify_sec, enabled, state, pending_since, last_reading_at,
+							state_changed_at, created_at`
+
+const alertEventColumns = `id, alert_id, rule_id, account_id, sensor_id, kind, value, message, user_id, created_at`
+
+// Экземпляры оповещений с названием правила и хабом сенсора
+const alertColumns = `a.id, a.rule_id, ar.title AS rule_title, a.account_id, dt.aquahub_id, a.sensor_id, a.status,
+							a.value, a.message, a.started_at, a.acknowledged_at, a.acknowledged_by, a.snoozed_until,
+							a.resolved_at, a.resolved_by, a.last_notified_at, a.notify_count, ar.renotify_sec`
+
+func alertsFrom() string {
+	return fmt.Sprintf(`%s a
+							INNER JOIN %s ar ON ar.id = a.rule_id
+							INNER JOIN %s s ON s.id = a.sensor_id
+							INNER JOIN %s dt ON dt.id = s.device_id`, alertsTable, alertRulesTable, sensorsTable, devicesTable)
+}
+
+func (r *AlertPostgres) GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error) {
+	accountId, err := getSensorAccount_OfUser(r.db, userId, sensorId, perm)
+	if err != nil {
+		r.log.Errorf("db: error GetSensorAccount Alert: %s", err.Error())
+		return 0, errors.New("db: sensor not found")
+	}
+	return accountId, nil
+}
+
+func (r *AlertPostgres) Create(rule domain.AlertRule) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, sensor_id, title, kind, threshold_low, threshold_high,
+								hysteresis, min_duration_sec, no_data_sec, renotify_sec, enabled)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`, alertRulesTable)
+
+	var id int
+	err := r.db.Get(&id, query, rule.AccountID, rule.SensorID, rule.Title, rule.Kind, rule.ThresholdLow, rule.ThresholdHigh,
+		rule.Hysteresis, rule.MinDurationSec, rule.NoDataSec, rule.RenotifySec, rule.Enabled)
+	if err != nil {
+		r.log.Errorf("db: error Create AlertRule: %s", err.Error())
+		return 0, errors.New("db: error Create AlertRule")
+	}
+
+	return id, nil
+}
+
+// Правила аккаунтов пользователя; sensorId = 0 - правила всех сенсоров
+func (r *AlertPostgres) GetAll_OfUser(userId, sensorId int, f domain.GroupFilter) ([]domain.AlertRule, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE account_id IN (%s) AND ($2 = 0 OR sensor_id = $2) AND %s ORDER BY id`,
+		alertRuleColumns, alertRulesTable, userAccountsQuery(1), sensorGroupFilter("sensor_id", 3, 4))
+
+	var list []domain.AlertRule
+	if err := r.db.Select(&list, query, userId, sensorId, f.GroupID, f.Tag); err != nil {
+		r.log.Errorf("db: error GetAll AlertRule: %s", err.Error())
+		return nil, errors.New("db: error GetAll AlertRule")
+	}
+
+	return list, nil
+}
+
+func (r *AlertPostgres) GetById(userId, id int) (*domain.AlertRule, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND account_id IN (%s)`,
+		alertRuleColumns, alertRulesTable, userAccountsQuery(2))
+
+	var rule domain.AlertRule
+	if err := r.db.Get(&rule, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById AlertRule: %s", err.Error())
+		return nil, errors.New("db: alert rule not found")
+	}
+
+	return &rule, nil
+}
+
+// Обновление настроек правила; выключенное правило сбрасывается в ok
+func (r *AlertPostgres) Update(rule domain.AlertRule) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET title = $1, threshold_low = $2, threshold_high = $3, hysteresis = $4,
+								min_duration_sec = $5, no_data_sec = $6, enabled = $7, renotify_sec = $8,
+								state = CASE WHEN $7 THEN state ELSE 'ok' END,
+								pending_since = CASE WHEN $7 THEN pending_since ELSE NULL END,
+								updated_at = CURRENT_TIMESTAMP
+							WHERE id = $9`, alertRulesTable)
+
+	_, err := r.db.Exec(query, rule.Title, rule.ThresholdLow, rule.ThresholdHigh, rule.Hysteresis,
+		rule.MinDurationSec, rule.NoDataSec, rule.Enabled, rule.RenotifySec, rule.ID)
+	if err != nil {
+		r.log.Errorf("db: error Update AlertRule: %s", err.Error())
+		return errors.New("db: error Update AlertRule")
+	}
+
+	return nil
+}
+
+func (r *AlertPostgres) Delete(userId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND account_id IN (%s)`, alertRulesTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Delete AlertRule: %s", err.Error())
+		return errors.New("db: error Delete AlertRule")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: alert rule not found")
+	}
+
+	return nil
+}
+
+// Включённые правила сенсоров
+func (r *AlertPostgres) GetEnabled_OfSensors(sensorIds []int) ([]domain.AlertRule, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE sensor_id = ANY($1) AND enabled ORDER BY id`,
+		alertRuleColumns, alertRulesTable)
+
+	var list []domain.AlertRule
+	if err := r.db.Select(&list, query, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetEnabled AlertRule: %s", err.Error())
+		return nil, errors.New("db: error GetEnabled AlertRule")
+	}
+
+	return list, nil
+}
+
+// Включённые несработавшие правила "нет данных". Если правило ещё не видело показаний,
+// last_reading_at - время последнего показания сенсора или создания правила.
+func (r *AlertPostgres) GetNoData_NotFiring() ([]domain.AlertRule, error) {
+
+	columns := strings.Replace(alertRuleColumns, "last_reading_at", fmt.Sprintf(`COALESCE(last_reading_at,
+								(SELECT max(created_at) FROM %s WHERE sensor_id = ar.sensor_id), created_at) AS last_reading_at`,
+		sensorDataSetTable), 1)
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ar WHERE kind = $1 AND enabled AND state <> $2`,
+		columns, alertRulesTable)
+
+	var list []domain.AlertRule
+	if err := r.db.Select(&list, query, domain.AlertNoData, domain.AlertStateFiring); err != nil {
+		r.log.Errorf("db: error GetNoData AlertRule: %s", err.Error())
+		return nil, errors.New("db: error GetNoData AlertRule")
+	}
+
+	return list, nil
+}
+
+// Сохранение состояний правил и новых событий одной транзакцией. Срабатывание открывает экземпляр
+// оповещения, снятие - закрывает открытый; ID события и экземпляра записываются в events.
+func (r *AlertPostgres) SaveStates(rules []domain.AlertRule, events []domain.AlertEvent) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error SaveStates Alert: %s", err.Error())
+		return errors.New("db: error SaveStates Alert")
+	}
+
+	// last_reading_at = NULL - показаний не было, время последнего показания не меняется
+	queryRule := fmt.Sprintf(`UPDATE %s SET state = :state, pending_since = :pending_since,
+									last_reading_at = COALESCE(:last_reading_at, last_reading_at),
+									state_changed_at = :state_changed_at
+								WHERE id = :id`, alertRulesTable)
+
+	// Уведомление о срабатывании отправляется сразу, это первое уведомление экземпляра
+	queryOpen := fmt.Sprintf(`INSERT INTO %s (rule_id, account_id, sensor_id, status, value, message, started_at,
+									last_notified_at, notify_count)
+								VALUES ($1, $2, $3, 'firing', $4, $5, $6, CURRENT_TIMESTAMP, 1)
+								ON CONFLICT (rule_id) WHERE status <> 'resolved' DO UPDATE SET
+									value = EXCLUDED.value, message = EXCLUDED.message, updated_at = CURRENT_TIMESTAMP
+								RETURNING id`, alertsTable)
+
+	queryClose := fmt.Sprintf(`UPDATE %s SET status = 'resolved', resolved_at = $2, snoozed_until = NULL,
+									updated_at = CURRENT_TIMESTAMP
+								WHERE rule_id = $1 AND status <> 'resolved' RETURNING id`, alertsTable)
+
+	queryEvent := fmt.Sprintf(`INSERT INTO %s (alert_id, rule_id, account_id, sensor_id, kind, value, message, created_at)
+								VALUES (:alert_id, :rule_id, :account_id, :sensor_id, :kind, :value, :message, :created_at)
+								RETURNING id`, alertEventsTable)
+
+	for _, rule := range rules {
+		if _, err = tx.NamedExec(queryRule, rule); err != nil {
+			break
+		}
+	}
+	if err == nil && len(events) > 0 {
+		var stmt *sqlx.NamedStmt
+		if stmt, err = tx.PrepareNamed(queryEvent); err == nil {
+			defer stmt.Close()
+			for i := range events {
+				e := &events[i]
+
+				var alertIds []int
+				if e.Kind == domain.AlertEventFiring {
+					err = tx.Select(&alertIds, queryOpen, e.RuleID, e.AccountID, e.SensorID, e.Value, e.Message, e.CreatedAt)
+				} else {
+					err = tx.Select(&alertIds, queryClose, e.RuleID, e.CreatedAt)
+				}
+				if err != nil {
+					break
+				}
+				if len(alertIds) > 0 {
+					e.AlertID = &alertIds[0]
+				}
+
+				if err = stmt.Get(&e.ID, *e); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error SaveStates Alert: %s", err.Error())
+		return errors.New("db: error SaveStates Alert")
+	}
+
+	return tx.Commit()
+}
+
+func (r *AlertPostgres) GetEvents_OfUser(userId int, f domain.AlertEventsFilter) ([]domain.AlertEvent, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE account_id IN (%s) AND created_at >= $2 AND created_at < $3
+							AND ($4 = 0 OR sensor_id = $4) AND ($5 = 0 OR rule_id = $5) AND ($6 = 0 OR alert_id = $6)
+							AND ($7 = 0 OR sensor_id IN (
+								SELECT s.id FROM %s s INNER JOIN %s dt ON dt.id = s.device_id WHERE dt.aquahub_id = $7))
+							AND %s
+							ORDER BY created_at DESC, id DESC`,
+		alertEventColumns, alertEventsTable, userAccountsQuery(1), sensorsTable, devicesTable, sensorGroupFilter("sensor_id", 8, 9))
+
+	var list []domain.AlertEvent
+	if err := r.db.Select(&list, query, userId, f.From, f.To, f.SensorID, f.RuleID, f.AlertID, f.AquahubID, f.GroupID, f.Tag); err != nil {
+		r.log.Errorf("db: error GetEvents Alert: %s", err.Error())
+		return nil, errors.New("db: error GetEvents Alert")
+	}
+
+	return list, nil
+}
+
+// Оповещения аккаунтов пользователя, активные в периоде фильтра; status = "" - все статусы
+func (r *AlertPostgres) GetAlerts_OfUser(userId int, f domain.AlertsFilter) ([]domain.Alert, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE a.account_id IN (%s) AND a.started_at < $3 AND (a.resolved_at IS NULL OR a.resolved_at >= $2)
+							AND ($4 = '' OR a.status = $4) AND ($5 = 0 OR dt.aquahub_id = $5) AND ($6 = 0 OR a.sensor_id = $6)
+							AND %s
+							ORDER BY a.started_at DESC, a.id DESC`, alertColumns, alertsFrom(), userAccountsQuery(1), sensorGroupFilter("a.sensor_id", 7, 8))
+
+	var list []domain.Alert
+	if err := r.db.Select(&list, query, userId, f.From, f.To, f.Status, f.AquahubID, f.SensorID, f.GroupID, f.Tag); err != nil {
+		r.log.Errorf("db: error GetAlerts Alert: %s", err.Error())
+		return nil, errors.New("db: error GetAlerts Alert")
+	}
+
+	return list, nil
+}
+
+func (r *AlertPostgres) GetAlert_OfUser(userId, id int) (*domain.Alert, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE a.id = $1 AND a.account_id IN (%s)`,
+		alertColumns, alertsFrom(), userAccountsQuery(2))
+
+	var a domain.Alert
+	if err := r.db.Get(&a, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetAlert Alert: %s", err.Error())
+		return nil, errors.New("db: alert not found")
+	}
+
+	return &a, nil
+}
+
+// Открытые оповещения, о которых пора напомнить: неподтверждённые с истёкшим интервалом
+// напоминаний правила и отложенные с истёкшей отсрочкой
+func (r *AlertPostgres) GetAlerts_Due() ([]domain.Alert, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE (a.status = 'firing' AND ar.renotify_sec > 0
+								AND COALESCE(a.last_notified_at, a.started_at) + ar.renotify_sec * interval '1 second' <= CURRENT_TIMESTAMP)
+							OR (a.status = 'snoozed' AND a.snoozed_until <= CURRENT_TIMESTAMP)
+							ORDER BY a.id`, alertColumns, alertsFrom())
+
+	var list []domain.Alert
+	if err := r.db.Select(&list, query); err != nil {
+		r.log.Errorf("db: error GetDue Alert: %s", err.Error())
+		return nil, errors.New("db: error GetDue Alert")
+	}
+
+	return list, nil
+}
+
+// Сохранение экземпляра оповещения и события о нём (e = nil - без события) одной транзакцией.
+// Закрытое вручную оповещение сбрасывает правило в ok: если условие сохраняется, правило сработает снова.
+func (r *AlertPostgres) UpdateAlert(a domain.Alert, e *domain.AlertEvent) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error UpdateAlert Alert: %s", err.Error())
+		return errors.New("db: error UpdateAlert Alert")
+	}
+
+	queryAlert := fmt.Sprintf(`UPDATE %s SET status = :status, acknowledged_at = :acknowledged_at,
+									acknowledged_by = :acknowledged_by, snoozed_until = :snoozed_until,
+									resolved_at = :resolved_at, resolved_by = :resolved_by,
+									last_notified_at = :last_notified_at, notify_count = :notify_count,
+									updated_at = CURRENT_TIMESTAMP
+								WHERE id = :id AND status <> 'resolved'`, alertsTable)
+
+	queryRule := fmt.Sprintf(`UPDATE %s SET state = 'ok', pending_since = NULL, state_changed_at = $2
+								WHERE id = $1`, alertRulesTable)
+
+	queryEvent := fmt.Sprintf(`INSERT INTO %s (alert_id, rule_id, account_id, sensor_id, kind, value, message, user_id, created_at)
+								VALUES (:alert_id, :rule_id, :account_id, :sensor_id, :kind, :value, :message, :user_id, :created_at)`,
+		alertEventsTable)
+
+	res, err := tx.NamedExec(queryAlert, a)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			tx.Rollback()
+			return errors.New("db: alert not found or already resolved")
+		}
+	}
+	if err == nil && a.Status == domain.AlertResolved && a.ResolvedAt != nil {
+		_, err = tx.Exec(queryRule, a.RuleID, *a.ResolvedAt)
+	}
+	if err == nil && e != nil {
+		_, err = tx.NamedExec(queryEvent, *e)
+	}
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error UpdateAlert Alert: %s", err.Error())
+		return errors.New("db: error UpdateAlert Alert")
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type AnomalyPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewAnomalyPostgres(log *logrus.Logger, db *sqlx.DB) *AnomalyPostgres {
+	return &AnomalyPostgres{log: log, db: db}
+}
+
+func (r *AnomalyPostgres) GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error) {
+	accountId, err := getSensorAccount_OfUser(r.db, userId, sensorId, perm)
+	if err != nil {
+		r.log.Errorf("db: error GetSensorAccount Anomaly: %s", err.Error())
+		return 0, errors.New("db: sensor not found")
+	}
+	return accountId, nil
+}
+
+func (r *AnomalyPostgres) SetDetectors(d domain.SensorDetectors) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (sensor_id, max_rate, zscore_window, zscore_threshold, flatline_minutes, updated_at)
+							VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+							ON CONFLICT (sensor_id) DO UPDATE SET
+								max_rate = EXCLUDED.max_rate,
+								zscore_window = EXCLUDED.zscore_window,
+								zscore_threshold = EXCLUDED.zscore_threshold,
+								flatline_minutes = EXCLUDED.flatline_minutes,
+								updated_at = EXCLUDED.updated_at`, sensorDetectorsTable)
+
+	if _, err := r.db.Exec(query, d.SensorID, d.MaxRate, d.ZScoreWindow, d.ZScoreThreshold, d.FlatlineMinutes); err != nil {
+		r.log.Errorf("db: error SetDetectors: %s", err.Error())
+		return errors.New("db: error SetDetectors")
+	}
+
+	return nil
+}
+
+func (r *AnomalyPostgres) DeleteDetectors(sensorId int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE sensor_id = $1`, sensorDetectorsTable)
+
+	if _, err := r.db.Exec(query, sensorId); err != nil {
+		r.log.Errorf("db: error DeleteDetectors: %s", err.Error())
+		return errors.New("db: error DeleteDetectors")
+	}
+
+	return nil
+}
+
+func (r *AnomalyPostgres) GetDetectors_OfSensors(sensorIds []int) ([]domain.SensorDetectors, error) {
+
+	query := fmt.Sprintf(`SELECT sensor_id, max_rate, zscore_window, zscore_threshold, flatline_minutes, updated_at
+							FROM %s WHERE sensor_id = ANY($1)`, sensorDetectorsTable)
+
+	var list []domain.SensorDetectors
+	if err := r.db.Select(&list, query, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetDetectors: %s", err.Error())
+		return nil, errors.New("db: error GetDetectors")
+	}
+
+	return list, nil
+}
+
+// Последние limit показаний каждого из сенсоров, по возрастанию времени
+func (r *AnomalyPostgres) GetRecent_OfSensors(sensorIds []int, limit int) ([]domain.SensorDataSet, error) {
+
+	query := fmt.Sprintf(`SELECT sensor_id, created_at, value FROM (
+								SELECT sensor_id, created_at, value,
+									row_number() OVER (PARTITION BY sensor_id ORDER BY created_at DESC) AS rn
+								FROM %s WHERE sensor_id = ANY($1)
+							) t
+							WHERE rn <= $2 ORDER BY sensor_id, created_at`, sensorDataSetTable)
+
+	var list []domain.SensorDataSet
+	if err := r.db.Select(&list, query, pq.Array(sensorIds), limit); err != nil {
+		r.log.Errorf("db: error GetRecent Anomaly: %s", err.Error())
+		return nil, errors.New("db: error GetRecent Anomaly")
+	}
+
+	return list, nil
+}
+
+// Время первого показания в последней серии показаний сенсора, равных value
+func (r *AnomalyPostgres) GetRunStart(sensorId int, value string) (time.Time, error) {
+
+	query := fmt.Sprintf(`SELECT COALESCE(min(created_at), CURRENT_TIMESTAMP) FROM %s
+							WHERE sensor_id = $1 AND created_at > COALESCE(
+								(SELECT max(created_at) FROM %s WHERE sensor_id = $1 AND value <> $2), '-infinity')`,
+		sensorDataSetTable, sensorDataSetTable)
+
+	var since time.Time
+	if err := r.db.Get(&since, query, sensorId, value); err != nil {
+		r.log.Errorf("db: error GetRunStart Anomaly: %s", err.Error())
+		return time.Time{}, errors.New("db: error GetRunStart Anomaly")
+	}
+
+	return since, nil
+}
+
+func (r *AnomalyPostgres) GetFlags_OfSensor(sensorId int, from, to time.Time) ([]domain.SensorFlag, error) {
+
+	query := fmt.Sprintf(`SELECT id, sensor_id, created_at, value, flag, COALESCE(flag_reason, '') AS flag_reason
+							FROM %s WHERE sensor_id = $1 AND flag IS NOT NULL AND created_at >= $2 AND created_at < $3
+							ORDER BY created_at`, sensorDataSetTable)
+
+	var list []domain.SensorFlag
+	if err := r.db.Select(&list, query, sensorId, from, to); err != nil {
+		r.log.Errorf("db: error GetFlags Anomaly: %s", err.Error())
+		return nil, errors.New("db: error GetFlags Anomaly")
+	}
+
+	return list, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	// "strings"
 
@@ -127,54 +128,69 @@ func (r *AquahubListPostgres) Update(userId, listId int, input domain.UpdateAqua
 */
 //_____________________________________________________________________________________________________
 
-func (r *AquahubListPostgres) GetAquahubs_OfUser(userId int) ([]domain.AquahubList, error) {
+func (r *AquahubListPostgres) GetAquahubs_OfUser(userId int, f domain.GroupFilter, includeArchived bool) ([]domain.AquahubList, error) {
 
 	var lists []domain.AquahubList // Создадим слайс списка
 
-	// Подготовим запрос.
-	// В нем мы будем делать выборку из базы, используя конструкцию INNER JOIN.
-	// Команда INNER JOIN при SELECT помогает выбрать только те записи,
-	// которые имеют одинаковое значение в обеих таблицах.
-	// В нашем случае нам нужно выбрать все записи из таблицы списков, которые также есть в таблице usersLists,
-	// и при этом связаны id пользователя.
-	// tl.id, tl.title, tl.description - возвращаемые поля
-	query := fmt.Sprintf("SELECT id, title, description FROM %s WHERE user_id = $1", aquahubsTable)
+	// Аквахабы принадлежат аккаунтам: выбираем хабы аккаунтов, участником которых является пользователь.
+	// Фильтр: хабы группы (с вложенными группами) и хабы с тегом; архивные хабы - только с includeArchived.
+	query := fmt.Sprintf(`SELECT %s FROM %s aht WHERE aht.account_id IN (%s)
+							AND ($2 = 0 OR aht.group_id IN (%s)) AND ($3 = '' OR %s) AND ($4 OR aht.archived_at IS NULL)
+							ORDER BY aht.id`,
+		aquahubColumns, aquahubsTable, userAccountsQuery(1), groupTreeQuery(2), taggedQuery(domain.TagAquahub, "aht.id", 3))
 
 	// На этот раз мы используем для выборки из базы метод селект.
 	// Он работает аналогично с методом Get только применяется при выборке больше одного элемента
 	// и результат записывает в слайс.
-	// Также нам нужно добавить такие дебилы нашу структуру, чтобы иметь возможность делать выборки из базы.
-	err := r.db.Select(&lists, query, userId)
+	if err := r.db.Select(&lists, query, userId, f.GroupID, f.Tag, includeArchived); err != nil {
+		r.log.Errorf("db: error GetAll Aquahub: %s", err.Error())
+		return nil, errors.New("db: error GetAll Aquahub")
+	}
 
-	return lists, err
+	return lists, nil
 }
 
 //__________________________________________________________________________________________________________________________________________________________________
 
-func (r *AquahubListPostgres) GetDevices_OfAquahub(aquahubId int) ([]domain.AquahubList, error) {
-	var list []domain.AquahubList
-
-	query := fmt.Sprintf(`SELECT id, title, description, status FROM %s WHERE aquahub_id = $1 ORDER BY title`, devicesTable)
-	err := r.db.Select(&list, query, aquahubId)
-
-	// r.log.Infof("Query: %s,   %d", query, aquahubId)
-	// r.log.Info(list)
+// Устройства хаба; фильтр по тегу учитывает теги устройства и хаба
+func (r *AquahubListPostgres) GetDevices_OfAquahub(userId, aquahubId int, f domain.GroupFilter, includeArchived bool) ([]domain.Device, error) {
+	var list []domain.Device
+
+	query := fmt.Sprintf(`SELECT %s FROM %s dt
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.aquahub_id = $1 AND aht.account_id IN (%s)
+							AND ($3 = 0 OR aht.group_id IN (%s)) AND ($4 = '' OR %s OR %s) AND ($5 OR dt.archived_at IS NULL)
+							ORDER BY dt.local_id, dt.id`,
+		deviceColumns, devicesTable, aquahubsTable, userAccountsQuery(2), groupTreeQuery(3),
+		taggedQuery(domain.TagDevice, "dt.id", 4), taggedQuery(domain.TagAquahub, "aht.id", 4))
+
+	if err := r.db.Select(&list, query, aquahubId, userId, f.GroupID, f.Tag, includeArchived); err != nil {
+		r.log.Errorf("db: error GetAll Device: %s", err.Error())
+		return nil, errors.New("db: error GetAll Device")
+	}
 
-	return list, err
+	return list, nil
 }
 
 //__________________________________________________________________________________________________________________________________________________________________
 
-func (r *AquahubListPostgres) GetSensors_OfDevice(deviceId int) ([]domain.AquahubList, error) {
-	var list []domain.AquahubList
+// Сенсоры устройства; фильтр по тегу учитывает теги сенсора, устройства и хаба
+func (r *AquahubListPostgres) GetSensors_OfDevice(userId, deviceId int, f domain.GroupFilter, includeArchived bool) ([]domain.Sensor, error) {
+	var list []domain.Sensor
 
-	query := fmt.Sprintf(`SELECT id, title, description FROM %s WHERE device_id = $1 ORDER BY title`, sensorsTable)
-	err := r.db.Select(&list, query, deviceId)
+	query := fmt.Sprintf(`SELECT %s FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE s.device_id = $1 AND aht.account_id IN (%s) AND %s AND ($5 OR s.archived_at IS NULL)
+							ORDER BY s.local_id, s.id`,
+		sensorColumns, sensorsTable, devicesTable, aquahubsTable, userAccountsQuery(2), sensorGroupFilter("s.id", 3, 4))
 
-	// r.log.Infof("Query: %s,   %d", query, deviceId)
-	// r.log.Info(list)
+	if err := r.db.Select(&list, query, deviceId, userId, f.GroupID, f.Tag, includeArchived); err != nil {
+		r.log.Errorf("db: error GetAll Sensor: %s", err.Error())
+		return nil, errors.New("db: error GetAll Sensor")
+	}
 
-	return list, err
+	return list, nil
 }
 
 //__________________________________________________________________________________________________________________________________________________________________
@@ -200,8 +216,8 @@ func (r *AquahubListPostgres) AppendData_OfSensor(list []domain.SensorDataSet) e
 
 	// Сделаем вставку в таблицу usersLists, в которой свяжем id пользователя и id нового списка.
 	query := fmt.Sprintf(`INSERT INTO %s
-	(account_id, aquahub_id, device_id, sensor_id, local_device_id, local_sensor_id, value, created_at)
-	VALUES (:account_id, :aquahub_id, :device_id, :sensor_id, :local_device_id, :local_sensor_id, :value, :created_at)`, sensorDataSetTable)
+	(account_id, aquahub_id, device_id, sensor_id, local_device_id, local_sensor_id, value, raw_value, flag, flag_reason, created_at)
+	VALUES (:account_id, :aquahub_id, :device_id, :sensor_id, :local_device_id, :local_sensor_id, :value, :raw_value, :flag, :flag_reason, :created_at)`, sensorDataSetTable)
 
 	_, err := r.db.NamedExec(query, list)
 
@@ -215,7 +231,8 @@ func (r *AquahubListPostgres) AppendData_OfSensor(list []domain.SensorDataSet) e
 
 //__________________________________________________________________________________________________________________________________________________________________
 
-func (r *AquahubListPostgres) Device_CreateOrUpdate(aquahub_id int, device_local_id int, value string) error {
+// Device_CreateOrUpdate возвращает ID устройства хаба и признак, что устройство создано
+func (r *AquahubListPostgres) Device_CreateOrUpdate(aquahub_id int, device_local_id int, value string) (int, bool, error) {
 
 	// Команда INNER JOIN при SELECT помогает выбрать только те записи,
 	// которые имеют одинаковое значение в обеих таблицах.
@@ -232,18 +249,19 @@ func (r *AquahubListPostgres) Device_CreateOrUpdate(aquahub_id int, device_local
 	err := r.db.Get(&device_id, query, aquahub_id, device_local_id)
 	if err != nil {
 
-		query := fmt.Sprintf(`INSERT INTO %s (aquahub_id, local_id, title, description) VALUES ($1, $2, $3, $4)`, devicesTable)
+		query := fmt.Sprintf(`INSERT INTO %s (aquahub_id, local_id, title, description) VALUES ($1, $2, $3, $4) RETURNING id`, devicesTable)
 		fmt.Printf("\n\nQuery: \n%s\n\n", query)
 
 		args = append(args, aquahub_id, device_local_id, value, "Description of the "+value)
 
-		_, err = r.db.Exec(query, args...)
+		err = r.db.Get(&device_id, query, args...)
 
-		return err
+		return device_id, err == nil, err
 	}
 
 	// Сделаем вставку в таблицу usersLists, в которой свяжем id пользователя и id нового списка.
-	query = fmt.Sprintf(`UPDATE %s SET title = $3  WHERE aquahub_id = $1 AND id = $2`, devicesTable)
+	// Название устройства, описанного по типу, не заменяется
+	query = fmt.Sprintf(`UPDATE %s SET title = $3  WHERE aquahub_id = $1 AND id = $2 AND device_type_id IS NULL`, devicesTable)
 	// fmt.Printf("\n\nQuery: \n%s\n\n", query)
 
 	args = append(args, aquahub_id, device_id, value)
@@ -254,12 +272,13 @@ func (r *AquahubListPostgres) Device_CreateOrUpdate(aquahub_id int, device_local
 
 	// fmt.Printf("\n\nQuery: \n%s\n%v\n", query, err.Error())
 
-	return err
+	return device_id, false, err
 }
 
 //__________________________________________________________________________________________________________________________________________________________________
 
-func (r *AquahubListPostgres) Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) error {
+// Sensor_CreateOrUpdate возвращает ID сенсора и признак, что сенсор создан
+func (r *AquahubListPostgres) Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) (int, bool, error) {
 
 	args := make([]interface{}, 0)
 	var device_id int
@@ -290,18 +309,20 @@ func (r *AquahubListPostgres) Sensor_CreateOrUpdate(aquahub_id, device_local_id,
 		err := r.db.Get(&sensor_id, query, device_id, sensor_local_id)
 		if err != nil {
 
-			query := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description) VALUES ($1, $2, $3, $4)`, sensorsTable)
+			query := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description) VALUES ($1, $2, $3, $4) RETURNING id`, sensorsTable)
 			// fmt.Printf("\nSensor_CreateOrUpdate INSERT:\n%s\n\n", query)
 
 			args = append(args, device_id, sensor_local_id, fmt.Sprintf(`Sensor %d`, sensor_local_id), fmt.Sprintf(`Description of the sensor %d`, sensor_local_id))
 
-			_, err = r.db.Exec(query, args...)
+			err = r.db.Get(&sensor_id, query, args...)
 
-			return err
+			return sensor_id, err == nil, err
 		}
+
+		return sensor_id, false, nil
 	}
 
-	return err
+	return 0, false, err
 }
 
 //__________________________________________________________________________________________________________________________________________________________________
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+// Аквахабы, устройства и сенсоры аккаунтов пользователя
+
+var aquahubColumns = `aht.id, aht.account_id, COALESCE(aht.title, '') AS title, COALESCE(aht.description, '') AS description,
+							COALESCE(aht.status, 'active') AS status, aht.created_at, COALESCE(aht.updated_at, aht.created_at) AS updated_at,
+							aht.archived_at, aht.group_id, ` + tagsColumn(domain.TagAquahub, "aht.id")
+
+var deviceColumns = `dt.id, dt.aquahub_id, dt.local_id, COALESCE(dt.title, '') AS title,
+							COALESCE(dt.description, '') AS description, COALESCE(dt.status, 'active') AS status, dt.device_type_id, dt.archived_at, ` +
+	tagsColumn(domain.TagDevice, "dt.id")
+
+var sensorColumns = `s.id, s.device_id, dt.aquahub_id, COALESCE(s.local_id, -1) AS local_id, COALESCE(s.title, '') AS title,
+							COALESCE(s.description, '') AS description, COALESCE(s.for_engineer, true) AS for_engineer,
+							COALESCE(s.for_analytics, false) AS for_analytics, s.unit, s.data_type, s.report_interval_sec,
+							s.formula IS NOT NULL AS virtual, s.archived_at, ` + tagsColumn(domain.TagSensor, "s.id")
+
+// Строки SET для обновления по непустым полям; argId - номер первого плейсхолдера
+func setColumns(values map[string]interface{}, order []string, argId int) (string, []interface{}) {
+	setValues := make([]string, 0, len(order))
+	args := make([]interface{}, 0, len(order))
+
+	for _, column := range order {
+		if v, ok := values[column]; ok {
+			setValues = append(setValues, fmt.Sprintf("%s=$%d", column, argId))
+			args = append(args, v)
+			argId++
+		}
+	}
+
+	return strings.Join(setValues, ", "), args
+}
+
+// Строка SET для archived_at, следующего статусу хаба или устройства; column - archived_at с псевдонимом таблицы
+func archivedAtColumn(status *domain.AquaHubStatus, column string) string {
+	switch {
+	case status == nil:
+		return ""
+	case *status == domain.AquaHubStatus_Archived:
+		return fmt.Sprintf("archived_at=COALESCE(%s, CURRENT_TIMESTAMP)", column)
+	default:
+		return "archived_at=NULL"
+	}
+}
+
+func (r *AquahubListPostgres) GetAquahub_OfUser(userId, id int) (*domain.AquahubList, error) {
+	return r.getAquahub_OfUser(userId, id, domain.PermissionRead)
+}
+
+// Аквахаб аккаунта, в котором у пользователя есть право perm
+func (r *AquahubListPostgres) getAquahub_OfUser(userId, id int, perm domain.Permission) (*domain.AquahubList, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s aht WHERE aht.id = $1 AND aht.account_id IN (%s)`,
+		aquahubColumns, aquahubsTable, accountsQuery(2, perm))
+
+	var hub domain.AquahubList
+	if err := r.db.Get(&hub, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById Aquahub: %s", err.Error())
+		return nil, errors.New("db: aquahub not found")
+	}
+
+	return &hub, nil
+}
+
+func (r *AquahubListPostgres) CreateAquahub(userId int, input domain.CreateAquahub, hToken string) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, h_token, title, description, status, updated_at)
+							SELECT id, $3, $4, $5, 'active', CURRENT_TIMESTAMP FROM %s
+							WHERE id = $1 AND id IN (%s)
+							RETURNING id`, aquahubsTable, accountTable, managedAccountsQuery(2))
+
+	var id int
+	if err := r.db.Get(&id, query, input.AccountID, userId, hToken, input.Title, input.Description); err != nil {
+		r.log.Errorf("db: error Create Aquahub: %s", err.Error())
+		return 0, errors.New("db: error Create Aquahub (account not found?)")
+	}
+
+	return id, nil
+}
+
+func (r *AquahubListPostgres) UpdateAquahub(userId, id int, input domain.UpdateAquahub) error {
+
+	values := map[string]interface{}{}
+	if input.Title != nil {
+		values["title"] = *input.Title
+	}
+	if input.Description != nil {
+		values["description"] = *input.Description
+	}
+	if input.Status != nil {
+		values["status"] = *input.Status
+	}
+	setQuery, args := setColumns(values, []string{"title", "description", "status"}, 1)
+	if archived := archivedAtColumn(input.Status, "archived_at"); archived != "" {
+		setQuery += ", " + archived
+	}
+	if setQuery != "" {
+		setQuery += ", "
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %supdated_at = CURRENT_TIMESTAMP WHERE id = $%d AND account_id IN (%s)`,
+		aquahubsTable, setQuery, len(args)+1, managedAccountsQuery(len(args)+2))
+	args = append(args, id, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		r.log.Errorf("db: error Update Aquahub: %s", err.Error())
+		return errors.New("db: error Update Aquahub")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: aquahub not found")
+	}
+
+	return nil
+}
+
+// Перенос аквахаба в архив: хаб перестаёт принимать показания, устройства и сенсоры остаются при нём.
+// h_token сохраняется для восстановления, на запрос привязки хаб получает статус archived без кода.
+func (r *AquahubListPostgres) ArchiveAquahub(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'archived', archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND archived_at IS NULL AND account_id IN (%s)`,
+		aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Archive Aquahub: %s", err.Error())
+		return errors.New("db: error Archive Aquahub")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: aquahub not found")
+	}
+
+	return nil
+}
+
+func (r *AquahubListPostgres) RestoreAquahub(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'active', archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND archived_at IS NOT NULL AND account_id IN (%s)`,
+		aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Restore Aquahub: %s", err.Error())
+		return errors.New("db: error Restore Aquahub")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: archived aquahub not found")
+	}
+
+	return nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+func (r *AquahubListPostgres) GetDevice_OfUser(userId, id int) (*domain.Device, error) {
+	return r.getDevice_OfUser(userId, id, domain.PermissionRead)
+}
+
+// Устройство аккаунта, в котором у пользователя есть право perm
+func (r *AquahubListPostgres) getDevice_OfUser(userId, id int, perm domain.Permission) (*domain.Device, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s dt
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = $1 AND aht.account_id IN (%s)`,
+		deviceColumns, devicesTable, aquahubsTable, accountsQuery(2, perm))
+
+	var device domain.Device
+	if err := r.db.Get(&device, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById Device: %s", err.Error())
+		return nil, errors.New("db: device not found")
+	}
+
+	return &device, nil
+}
+
+// Создание устройства хаба; local_id уникален в пределах хаба
+func (r *AquahubListPostgres) CreateDevice(userId int, input domain.CreateDevice) (int, error) {
+
+	if _, err := r.getAquahub_OfUser(userId, input.AquahubID, domain.PermissionManage); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (aquahub_id, local_id, title, description, status)
+							SELECT $1, $2, $3, $4, 'active'
+							WHERE NOT EXISTS (SELECT 1 FROM %s WHERE aquahub_id = $1 AND local_id = $2)
+							RETURNING id`, devicesTable, devicesTable)
+
+	var list []int
+	if err := r.db.Select(&list, query, input.AquahubID, input.LocalID, input.Title, input.Description); err != nil {
+		r.log.Errorf("db: error Create Device: %s", err.Error())
+		return 0, errors.New("db: error Create Device")
+	}
+	if len(list) == 0 {
+		return 0, domain.ErrLocalIdInUse
+	}
+
+	return list[0], nil
+}
+
+func (r *AquahubListPostgres) UpdateDevice(userId, id int, input domain.UpdateDevice) error {
+
+	values := map[string]interface{}{}
+	if input.Title != nil {
+		values["title"] = *input.Title
+	}
+	if input.Description != nil {
+		values["description"] = *input.Description
+	}
+	if input.Status != nil {
+		values["status"] = *input.Status
+	}
+	setQuery, args := setColumns(values, []string{"title", "description", "status"}, 1)
+	if archived := archivedAtColumn(input.Status, "dt.archived_at"); archived != "" {
+		setQuery += ", " + archived
+	}
+
+	query := fmt.Sprintf(`UPDATE %s dt SET %s FROM %s aht
+							WHERE aht.id = dt.aquahub_id AND dt.id = $%d AND aht.account_id IN (%s)`,
+		devicesTable, setQuery, aquahubsTable, len(args)+1, managedAccountsQuery(len(args)+2))
+	args = append(args, id, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		r.log.Errorf("db: error Update Device: %s", err.Error())
+		return errors.New("db: error Update Device")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: device not found")
+	}
+
+	return nil
+}
+
+// Перенос устройства в архив; сенсоры остаются при устройстве
+func (r *AquahubListPostgres) ArchiveDevice(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s dt SET status = 'archived', archived_at = CURRENT_TIMESTAMP FROM %s aht
+							WHERE aht.id = dt.aquahub_id AND dt.id = $1 AND dt.archived_at IS NULL AND aht.account_id IN (%s)`,
+		devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Archive Device: %s", err.Error())
+		return errors.New("db: error Archive Device")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: device not found")
+	}
+
+	return nil
+}
+
+func (r *AquahubListPostgres) RestoreDevice(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s dt SET status = 'active', archived_at = NULL FROM %s aht
+							WHERE aht.id = dt.aquahub_id AND dt.id = $1 AND dt.archived_at IS NOT NULL AND aht.account_id IN (%s)`,
+		devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Restore Device: %s", err.Error())
+		return errors.New("db: error Restore Device")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: archived device not found")
+	}
+
+	return nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+func (r *AquahubListPostgres) GetSensor_OfUser(userId, id int) (*domain.Sensor, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE s.id = $1 AND aht.account_id IN (%s)`,
+		sensorColumns, sensorsTable, devicesTable, aquahubsTable, userAccountsQuery(2))
+
+	var sensor domain.Sensor
+	if err := r.db.Get(&sensor, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById Sensor: %s", err.Error())
+		return nil, errors.New("db: sensor not found")
+	}
+
+	return &sensor, nil
+}
+
+// Создание сенсора устройства; local_id уникален в пределах устройства
+func (r *AquahubListPostgres) CreateSensor(userId int, input domain.CreateSensor) (int, error) {
+
+	if _, err := r.getDevice_OfUser(userId, input.DeviceID, domain.PermissionManage); err != nil {
+		return 0, err
+	}
+
+	forEngineer, forAnalytics := true, false
+	if input.ForEngineer != nil {
+		forEngineer = *input.ForEngineer
+	}
+	if input.ForAnalytics != nil {
+		forAnalytics = *input.ForAnalytics
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description, for_engineer, for_analytics)
+							SELECT $1, $2, $3, $4, $5, $6
+							WHERE NOT EXISTS (SELECT 1 FROM %s WHERE device_id = $1 AND local_id = $2)
+							RETURNING id`, sensorsTable, sensorsTable)
+
+	var list []int
+	err := r.db.Select(&list, query, input.DeviceID, input.LocalID, input.Title, input.Description, forEngineer, forAnalytics)
+	if err != nil {
+		r.log.Errorf("db: error Create Sensor: %s", err.Error())
+		return 0, errors.New("db: error Create Sensor")
+	}
+	if len(list) == 0 {
+		return 0, domain.ErrLocalIdInUse
+	}
+
+	return list[0], nil
+}
+
+func (r *AquahubListPostgres) UpdateSensor(userId, id int, input domain.UpdateSensor) error {
+
+	values := map[string]interface{}{}
+	if input.Title != nil {
+		values["title"] = *input.Title
+	}
+	if input.Description != nil {
+		values["description"] = *input.Description
+	}
+	if input.ForEngineer != nil {
+		values["for_engineer"] = *input.ForEngineer
+	}
+	if input.ForAnalytics != nil {
+		values["for_analytics"] = *input.ForAnalytics
+	}
+	setQuery, args := setColumns(values, []string{"title", "description", "for_engineer", "for_analytics"}, 1)
+
+	query := fmt.Sprintf(`UPDATE %s s SET %s FROM %s dt, %s aht
+							WHERE dt.id = s.device_id AND aht.id = dt.aquahub_id AND s.id = $%d AND aht.account_id IN (%s)`,
+		sensorsTable, setQuery, devicesTable, aquahubsTable, len(args)+1, managedAccountsQuery(len(args)+2))
+	args = append(args, id, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		r.log.Errorf("db: error Update Sensor: %s", err.Error())
+		return errors.New("db: error Update Sensor")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: sensor not found")
+	}
+
+	return nil
+}
+
+// Перенос сенсора в архив; показания, правила и калибровки сенсора сохраняются до окончательного удаления
+func (r *AquahubListPostgres) ArchiveSensor(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s s SET archived_at = CURRENT_TIMESTAMP FROM %s dt, %s aht
+							WHERE dt.id = s.device_id AND aht.id = dt.aquahub_id AND s.id = $1 AND s.archived_at IS NULL
+							AND aht.account_id IN (%s)`,
+		sensorsTable, devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Archive Sensor: %s", err.Error())
+		return errors.New("db: error Archive Sensor")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: sensor not found")
+	}
+
+	return nil
+}
+
+func (r *AquahubListPostgres) RestoreSensor(userId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s s SET archived_at = NULL FROM %s dt, %s aht
+							WHERE dt.id = s.device_id AND aht.id = dt.aquahub_id AND s.id = $1 AND s.archived_at IS NOT NULL
+							AND aht.account_id IN (%s)`,
+		sensorsTable, devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Restore Sensor: %s", err.Error())
+		return errors.New("db: error Restore Sensor")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: archived sensor not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Окончательное удаление записей, пролежавших в архиве дольше срока хранения
+
+type ArchivePostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewArchivePostgres(log *logrus.Logger, db *sqlx.DB) *ArchivePostgres {
+	return &ArchivePostgres{log: log, db: db}
+}
+
+// Удаление записей, перенесённых в архив раньше before. Хабы, оставленные в архиве прежнего
+// аккаунта при передаче с сохранением истории (режим keep), не удаляются.
+func (r *ArchivePostgres) Purge(before time.Time) (domain.ArchivePurged, error) {
+	var purged domain.ArchivePurged
+	var err error
+
+	if purged.ChecklistItems, err = r.purgeChecklistItems(before); err != nil {
+		return purged, err
+	}
+	if purged.Checklists, err = r.purgeChecklists(before); err != nil {
+		return purged, err
+	}
+
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE archived_at < $1 ORDER BY id`, sensorsTable)
+	if purged.Sensors, err = r.purgeEach("Sensor", query, before, purgeSensor); err != nil {
+		return purged, err
+	}
+
+	query = fmt.Sprintf(`SELECT id FROM %s WHERE archived_at < $1 ORDER BY id`, devicesTable)
+	if purged.Devices, err = r.purgeEach("Device", query, before, func(tx *sqlx.Tx, id int) error {
+		return deleteDevices(tx, "SELECT $1::integer", id)
+	}); err != nil {
+		return purged, err
+	}
+
+	query = fmt.Sprintf(`SELECT aht.id FROM %s aht WHERE aht.archived_at < $1
+							AND NOT EXISTS (SELECT 1 FROM %s t WHERE t.aquahub_id = aht.id AND t.mode = '%s' AND t.status = '%s')
+							ORDER BY aht.id`, aquahubsTable, hubTransfersTable, domain.TransferKeep, domain.TransferCompleted)
+	if purged.Aquahubs, err = r.purgeEach("Aquahub", query, before, purgeAquahub); err != nil {
+		return purged, err
+	}
+
+	return purged, nil
+}
+
+// Каждая запись удаляется в своей транзакции: ошибка одной записи не откатывает уже удалённые
+func (r *ArchivePostgres) purgeEach(entity, query string, before time.Time, purge func(tx *sqlx.Tx, id int) error) (int, error) {
+
+	var ids []int
+	if err := r.db.Select(&ids, query, before); err != nil {
+		r.log.Errorf("db: error Purge %s: %s", entity, err.Error())
+		return 0, fmt.Errorf("db: error Purge %s", entity)
+	}
+
+	for n, id := range ids {
+		tx, err := r.db.Beginx()
+		if err != nil {
+			r.log.Errorf("db: error Purge %s: %s", entity, err.Error())
+			return n, fmt.Errorf("db: error Purge %s", entity)
+		}
+
+		if err := purge(tx, id); err != nil {
+			tx.Rollback()
+			r.log.Errorf("db: error Purge %s %d: %s", entity, id, err.Error())
+			return n, fmt.Errorf("db: error Purge %s", entity)
+		}
+
+		if err := tx.Commit(); err != nil {
+			r.log.Errorf("db: error Purge %s %d: %s", entity, id, err.Error())
+			return n, fmt.Errorf("db: error Purge %s", entity)
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (r *ArchivePostgres) purgeChecklistItems(before time.Time) (int, error) {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE archived_at < $1`, checklistItemsTable)
+
+	res, err := r.db.Exec(query, before)
+	if err != nil {
+		r.log.Errorf("db: error Purge ChecklistItem: %s", err.Error())
+		return 0, errors.New("db: error Purge ChecklistItem")
+	}
+
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Пункты удаляются вместе с чек-листом: checklist_id пункта не может быть пустым
+func (r *ArchivePostgres) purgeChecklists(before time.Time) (int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Purge Checklist: %s", err.Error())
+		return 0, errors.New("db: error Purge Checklist")
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE checklist_id IN (SELECT id FROM %s WHERE archived_at < $1)`,
+		checklistItemsTable, checklistsTable)
+	if _, err := tx.Exec(query, before); err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error Purge Checklist: %s", err.Error())
+		return 0, errors.New("db: error Purge Checklist")
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE archived_at < $1`, checklistsTable)
+	res, err := tx.Exec(query, before)
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error Purge Checklist: %s", err.Error())
+		return 0, errors.New("db: error Purge Checklist")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Errorf("db: error Purge Checklist: %s", err.Error())
+		return 0, errors.New("db: error Purge Checklist")
+	}
+
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// Удаление аквахаба вместе с устройствами, сенсорами и их показаниями
+func purgeAquahub(tx *sqlx.Tx, id int) error {
+
+	devices := fmt.Sprintf(`SELECT id FROM %s WHERE aquahub_id = $1`, devicesTable)
+	if err := deleteDevices(tx, devices, id); err != nil {
+		return err
+	}
+
+	queries := []string{
+		fmt.Sprintf(`DELETE FROM %s WHERE aquahub_id = $1`, sensorDataSetTable),
+		fmt.Sprintf(`DELETE FROM %s WHERE entity = '%s' AND entity_id = $1`, entityTagsTable, domain.TagAquahub),
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, aquahubsTable),
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Удаление устройств, выбранных подзапросом devices с параметром $1, с сенсорами и показаниями
+func deleteDevices(tx *sqlx.Tx, devices string, arg int) error {
+	sensors := fmt.Sprintf(`SELECT id FROM %s WHERE device_id IN (%s)`, sensorsTable, devices)
+
+	queries := []string{
+		fmt.Sprintf(`DELETE FROM %s WHERE entity = '%s' AND entity_id IN (%s)`, entityTagsTable, domain.TagSensor, sensors),
+		fmt.Sprintf(`DELETE FROM %s WHERE entity = '%s' AND entity_id IN (%s)`, entityTagsTable, domain.TagDevice, devices),
+		fmt.Sprintf(`DELETE FROM %s WHERE device_id IN (%s)`, sensorDataSetTable, devices),
+		fmt.Sprintf(`DELETE FROM %s WHERE device_id IN (%s) OR sensor_id IN (%s)`, propertiesTable, devices, sensors),
+		fmt.Sprintf(`DELETE FROM %s WHERE device_id IN (%s)`, sensorsTable, devices),
+		fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s)`, devicesTable, devices),
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Удаление сенсора вместе с показаниями; правила и калибровки сенсора удаляются каскадом
+func purgeSensor(tx *sqlx.Tx, id int) error {
+
+	queries := []string{
+		fmt.Sprintf(`DELETE FROM %s WHERE sensor_id = $1`, sensorDataSetTable),
+		fmt.Sprintf(`DELETE FROM %s WHERE sensor_id = $1`, propertiesTable),
+		fmt.Sprintf(`DELETE FROM %s WHERE entity = '%s' AND entity_id = $1`, entityTagsTable, domain.TagSensor),
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, sensorsTable),
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
@@ -101,7 +102,7 @@ func (r *AuthPostgres) CreateUser(user domain.User) (int, error) {
 		//ID:        uuid.NewRandom().String(),
 		UserID:    user_id,
 		AccountID: account_id,
-		Roles:     []domain.UserAccountRole{domain.UserAccountRole_User}, // {user_account.UserAccountRole_Manager},
+		Roles:     domain.UserAccountRoles{domain.UserAccountRole_Manager},
 		Status:    domain.UserAccountStatus_Active,
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
@@ -175,7 +176,7 @@ func (r *AuthPostgres) GetUserHW_fromTokens(h_token, u_token string) ([]domain.S
 							INNER JOIN %s a on aht.account_id = a.id
 							INNER JOIN %s dlt on dlt.aquahub_id = aht.id
 							INNER JOIN %s slt on slt.device_id = dlt.id
-							WHERE a.u_token = $2 AND aht.h_token = $1
+							WHERE a.u_token = $2 AND aht.h_token = $1 AND aht.archived_at IS NULL
 							ORDER BY aht.id, dlt.id, slt.id`,
 		aquahubsTable, accountTable, devicesTable, sensorsTable)
 
@@ -193,9 +194,9 @@ func (r *AuthPostgres) GetAquahubId_fromTokens(h_token, u_token string) (int, er
 	// которые имеют одинаковое значение в обеих таблицах.
 	query := fmt.Sprintf(`SELECT aht.id
 							FROM  %s aht
-							INNER JOIN %s ut on aht.user_id = ut.id
-							WHERE aht.h_token = $1 AND ut.u_token = $2`,
-		aquahubsTable, usersTable)
+							INNER JOIN %s a on aht.account_id = a.id
+							WHERE aht.h_token = $1 AND a.u_token = $2 AND aht.archived_at IS NULL`,
+		aquahubsTable, accountTable)
 
 	// fmt.Printf("\n\n%s\n\n", query)
 
@@ -204,3 +205,44 @@ func (r *AuthPostgres) GetAquahubId_fromTokens(h_token, u_token string) (int, er
 
 	return aId, err
 }
+
+// Сохранить хеш одноразовой части токена сброса пароля; прежний токен перестаёт действовать
+func (r *AuthPostgres) SetPasswordReset(userId int, reset string) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET password_reset = $2 WHERE id = $1 AND archived_at IS NULL`, usersTable)
+
+	_, err := r.db.Exec(query, userId, reset)
+	return errors.Wrap(err, "db: set password reset")
+}
+
+// Смена пароля по токену сброса: токен очищается, время смены пароля завершает прежние сеансы.
+// Время хранится с точностью до секунды, как время выдачи в токене доступа. Возвращает время смены пароля.
+func (r *AuthPostgres) ResetPassword(userId int, reset, passwordHash, passwordSalt string) (time.Time, error) {
+
+	query := fmt.Sprintf(`UPDATE %s SET password_hash = $3, password_salt = $4, password_reset = NULL,
+							password_changed_at = date_trunc('second', CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND password_reset = $2 AND archived_at IS NULL RETURNING password_changed_at`, usersTable)
+
+	var changedAt time.Time
+	if err := r.db.Get(&changedAt, query, userId, reset, passwordHash, passwordSalt); err != nil {
+		if err == sql.ErrNoRows {
+			return changedAt, domain.ErrInvalidResetToken
+		}
+		return changedAt, errors.Wrap(err, "db: reset password")
+	}
+
+	return changedAt, nil
+}
+
+// Время последней смены пароля; нулевое, если пароль не менялся
+func (r *AuthPostgres) GetPasswordChangedAt(userId int) (time.Time, error) {
+
+	query := fmt.Sprintf(`SELECT password_changed_at FROM %s WHERE id = $1`, usersTable)
+
+	var changedAt pq.NullTime
+	if err := r.db.Get(&changedAt, query, userId); err != nil {
+		return time.Time{}, errors.Wrap(err, "db: get password changed at")
+	}
+
+	return changedAt.Time, nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type AutomationPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewAutomationPostgres(log *logrus.Logger, db *sqlx.DB) *AutomationPostgres {
+	return &AutomationPostgres{log: log, db: db}
+}
+
+// Правила с временной зоной аккаунта - по ней считается расписание
+const automationColumns = `au.id, au.account_id, au.title, au.enabled, au.dry_run, au.conditions, au.schedule, au.actions,
+							au.cooldown_sec, au.max_runs_per_hour, au.state, ac.timezone, au.checked_at, au.last_run_at,
+							au.created_at, au.updated_at`
+
+func automationsFrom() string {
+	return fmt.Sprintf(`%s au INNER JOIN %s ac ON ac.id = au.account_id`, automationsTable, accountTable)
+}
+
+const automationRunColumns = `id, automation_id, account_id, "trigger", status, message, actions, created_at`
+
+func (r *AutomationPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Automation: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+// Все сенсоры, устройства и чек-листы правила принадлежат аккаунту
+func (r *AutomationPostgres) CheckReferences(accountId int, sensorIds, deviceIds, checklistIds []int) error {
+
+	checks := []struct {
+		name  string
+		query string
+		ids   []int
+	}{
+		{"sensor", fmt.Sprintf(`SELECT count(DISTINCT s.id) FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE s.id = ANY($1) AND aht.account_id = $2`, sensorsTable, devicesTable, aquahubsTable), sensorIds},
+		{"device", fmt.Sprintf(`SELECT count(DISTINCT dt.id) FROM %s dt
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = ANY($1) AND aht.account_id = $2`, devicesTable, aquahubsTable), deviceIds},
+		{"checklist", fmt.Sprintf(`SELECT count(*) FROM %s
+							WHERE id = ANY($1) AND account_id = $2 AND archived_at IS NULL`, checklistsTable), checklistIds},
+	}
+
+	for _, c := range checks {
+		want := distinctCount(c.ids)
+		if want == 0 {
+			continue
+		}
+
+		var n int
+		if err := r.db.Get(&n, c.query, pq.Array(c.ids), accountId); err != nil {
+			r.log.Errorf("db: error CheckReferences Automation: %s", err.Error())
+			return errors.New("db: error CheckReferences Automation")
+		}
+		if n != want {
+			return fmt.Errorf("db: %s not found", c.name)
+		}
+	}
+
+	return nil
+}
+
+func distinctCount(ids []int) int {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
+
+func (r *AutomationPostgres) Create(a domain.Automation) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, title, enabled, dry_run, conditions, schedule, actions,
+								cooldown_sec, max_runs_per_hour)
+							VALUES (:account_id, :title, :enabled, :dry_run, :conditions, :schedule, :actions,
+								:cooldown_sec, :max_runs_per_hour) RETURNING id`, automationsTable)
+
+	stmt, err := r.db.PrepareNamed(query)
+	if err != nil {
+		r.log.Errorf("db: error Create Automation: %s", err.Error())
+		return 0, errors.New("db: error Create Automation")
+	}
+	defer stmt.Close()
+
+	var id int
+	if err := stmt.Get(&id, a); err != nil {
+		r.log.Errorf("db: error Create Automation: %s", err.Error())
+		return 0, errors.New("db: error Create Automation")
+	}
+
+	return id, nil
+}
+
+func (r *AutomationPostgres) GetAll_OfUser(userId int) ([]domain.Automation, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE au.account_id IN (%s) ORDER BY au.id`,
+		automationColumns, automationsFrom(), userAccountsQuery(1))
+
+	var list []domain.Automation
+	if err := r.db.Select(&list, query, userId); err != nil {
+		r.log.Errorf("db: error GetAll Automation: %s", err.Error())
+		return nil, errors.New("db: error GetAll Automation")
+	}
+
+	return list, nil
+}
+
+func (r *AutomationPostgres) GetById(userId, id int) (*domain.Automation, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE au.id = $1 AND au.account_id IN (%s)`,
+		automationColumns, automationsFrom(), userAccountsQuery(2))
+
+	var a domain.Automation
+	if err := r.db.Get(&a, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById Automation: %s", err.Error())
+		return nil, errors.New("db: automation not found")
+	}
+
+	return &a, nil
+}
+
+// Замена определения правила; состояние условий сбрасывается
+func (r *AutomationPostgres) Update(a domain.Automation) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET account_id = :account_id, title = :title, enabled = :enabled, dry_run = :dry_run,
+								conditions = :conditions, schedule = :schedule, actions = :actions,
+								cooldown_sec = :cooldown_sec, max_runs_per_hour = :max_runs_per_hour,
+								state = '{}', checked_at = NULL, updated_at = CURRENT_TIMESTAMP
+							WHERE id = :id`, automationsTable)
+
+	if _, err := r.db.NamedExec(query, a); err != nil {
+		r.log.Errorf("db: error Update Automation: %s", err.Error())
+		return errors.New("db: error Update Automation")
+	}
+
+	return nil
+}
+
+func (r *AutomationPostgres) Delete(userId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND account_id IN (%s)`, automationsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Delete Automation: %s", err.Error())
+		return errors.New("db: error Delete Automation")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: automation not found")
+	}
+
+	return nil
+}
+
+// Включённые правила, в условиях которых есть хотя бы один из сенсоров аккаунта правила
+// (сенсор переданного хаба не запускает правила прежнего аккаунта)
+func (r *AutomationPostgres) GetEnabled_OfSensors(sensorIds []int) ([]domain.Automation, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE au.enabled AND EXISTS (
+								SELECT 1 FROM jsonb_array_elements(au.conditions) c
+								INNER JOIN %s s ON s.id = (c->>'sensor_id')::int
+								INNER JOIN %s dt ON dt.id = s.device_id
+								INNER JOIN %s aht ON aht.id = dt.aquahub_id
+								WHERE s.id = ANY($1) AND aht.account_id = au.account_id)
+							ORDER BY au.id`, automationColumns, automationsFrom(), sensorsTable, devicesTable, aquahubsTable)
+
+	var list []domain.Automation
+	if err := r.db.Select(&list, query, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetEnabled Automation: %s", err.Error())
+		return nil, errors.New("db: error GetEnabled Automation")
+	}
+
+	return list, nil
+}
+
+// Включённые правила, которые проверяются по времени: с расписанием или с длительностью условий
+func (r *AutomationPostgres) GetEnabled_Timed() ([]domain.Automation, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE au.enabled AND (au.schedule IS NOT NULL OR EXISTS (
+								SELECT 1 FROM jsonb_array_elements(au.conditions) c WHERE (c->>'for_sec')::int > 0))
+							ORDER BY au.id`, automationColumns, automationsFrom())
+
+	var list []domain.Automation
+	if err := r.db.Select(&list, query); err != nil {
+		r.log.Errorf("db: error GetTimed Automation: %s", err.Error())
+		return nil, errors.New("db: error GetTimed Automation")
+	}
+
+	return list, nil
+}
+
+func (r *AutomationPostgres) SaveState(a domain.Automation) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET state = :state, checked_at = :checked_at WHERE id = :id`, automationsTable)
+
+	if _, err := r.db.NamedExec(query, a); err != nil {
+		r.log.Errorf("db: error SaveState Automation: %s", err.Error())
+		return errors.New("db: error SaveState Automation")
+	}
+
+	return nil
+}
+
+// Время запусков правила с выполнением действий (в том числе dry_run, без тестовых) начиная с since
+func (r *AutomationPostgres) GetRunTimes(id int, since time.Time) ([]time.Time, error) {
+
+	query := fmt.Sprintf(`SELECT created_at FROM %s WHERE automation_id = $1 AND created_at >= $2
+							AND status IN ('ok', 'dry_run', 'failed') AND "trigger" <> 'test' ORDER BY created_at`, automationRunsTable)
+
+	var list []time.Time
+	if err := r.db.Select(&list, query, id, since); err != nil {
+		r.log.Errorf("db: error GetRunTimes Automation: %s", err.Error())
+		return nil, errors.New("db: error GetRunTimes Automation")
+	}
+
+	return list, nil
+}
+
+// Запись журнала запуска; запуск с действиями обновляет время последнего запуска правила
+func (r *AutomationPostgres) CreateRun(run domain.AutomationRun) (int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error CreateRun Automation: %s", err.Error())
+		return 0, errors.New("db: error CreateRun Automation")
+	}
+
+	queryRun := fmt.Sprintf(`INSERT INTO %s (automation_id, account_id, "trigger", status, message, actions, created_at)
+								VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, automationRunsTable)
+
+	queryLast := fmt.Sprintf(`UPDATE %s SET last_run_at = $2 WHERE id = $1`, automationsTable)
+
+	var id int
+	err = tx.Get(&id, queryRun, run.AutomationID, run.AccountID, run.Trigger, run.Status, run.Message, run.Actions, run.CreatedAt)
+	if err == nil && run.Status != domain.AutomationRunSkipped && run.Trigger != domain.AutomationTriggerTest {
+		_, err = tx.Exec(queryLast, run.AutomationID, run.CreatedAt)
+	}
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error CreateRun Automation: %s", err.Error())
+		return 0, errors.New("db: error CreateRun Automation")
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *AutomationPostgres) GetRuns_OfUser(userId, id int, from, to time.Time) ([]domain.AutomationRun, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE automation_id = $1 AND account_id IN (%s)
+							AND created_at >= $3 AND created_at < $4 ORDER BY created_at DESC, id DESC`,
+		automationRunColumns, automationRunsTable, userAccountsQuery(2))
+
+	var list []domain.AutomationRun
+	if err := r.db.Select(&list, query, id, userId, from, to); err != nil {
+		r.log.Errorf("db: error GetRuns Automation: %s", err.Error())
+		return nil, errors.New("db: error GetRuns Automation")
+	}
+
+	return list, nil
+}
+
+// Последние показания сенсоров, полученные аккаунтом
+func (r *AutomationPostgres) GetLatest_OfSensors(accountId int, sensorIds []int) ([]domain.SensorDataSet, error) {
+
+	query := fmt.Sprintf(`SELECT DISTINCT ON (sensor_id) sensor_id, value, created_at FROM %s
+							WHERE account_id = $1 AND sensor_id = ANY($2) ORDER BY sensor_id, created_at DESC`, sensorDataSetTable)
+
+	var list []domain.SensorDataSet
+	if err := r.db.Select(&list, query, accountId, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetLatest Automation: %s", err.Error())
+		return nil, errors.New("db: error GetLatest Automation")
+	}
+
+	return list, nil
+}
+
+// Пункт в чек-листе аккаунта
+func (r *AutomationPostgres) CreateChecklistItem(accountId, checklistId int, title, description string) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (checklist_id, title, description)
+							SELECT id, $3, $4 FROM %s WHERE id = $1 AND account_id = $2 AND archived_at IS NULL
+							RETURNING id`, checklistItemsTable, checklistsTable)
+
+	var id int
+	if err := r.db.Get(&id, query, checklistId, accountId, title, description); err != nil {
+		r.log.Errorf("db: error CreateChecklistItem Automation: %s", err.Error())
+		return 0, errors.New("db: checklist not found")
+	}
+
+	return id, nil
+}
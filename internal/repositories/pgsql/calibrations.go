@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type CalibrationPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewCalibrationPostgres(log *logrus.Logger, db *sqlx.DB) *CalibrationPostgres {
+	return &CalibrationPostgres{log: log, db: db}
+}
+
+func (r *CalibrationPostgres) GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error) {
+	accountId, err := getSensorAccount_OfUser(r.db, userId, sensorId, perm)
+	if err != nil {
+		r.log.Errorf("db: error GetSensorAccount Calibration: %s", err.Error())
+		return 0, errors.New("db: sensor not found")
+	}
+	return accountId, nil
+}
+
+func (r *CalibrationPostgres) Create(c domain.SensorCalibration) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (sensor_id, offset_value, scale, points, valid_from, description)
+							VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, sensorCalibrationsTable)
+
+	var id int
+	if err := r.db.Get(&id, query, c.SensorID, c.Offset, c.Scale, c.Points, c.ValidFrom, c.Description); err != nil {
+		r.log.Errorf("db: error Create Calibration: %s", err.Error())
+		return 0, errors.New("db: error Create Calibration")
+	}
+
+	return id, nil
+}
+
+// Калибровки сенсоров, отсортированные по valid_from
+func (r *CalibrationPostgres) GetAll_OfSensors(sensorIds []int) ([]domain.SensorCalibration, error) {
+
+	query := fmt.Sprintf(`SELECT id, sensor_id, offset_value, scale, points, valid_from, description, created_at
+							FROM %s WHERE sensor_id = ANY($1) ORDER BY sensor_id, valid_from, id`, sensorCalibrationsTable)
+
+	var list []domain.SensorCalibration
+	if err := r.db.Select(&list, query, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetAll Calibration: %s", err.Error())
+		return nil, errors.New("db: error GetAll Calibration")
+	}
+
+	return list, nil
+}
+
+func (r *CalibrationPostgres) Delete(sensorId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE sensor_id = $1 AND id = $2`, sensorCalibrationsTable)
+
+	res, err := r.db.Exec(query, sensorId, id)
+	if err != nil {
+		r.log.Errorf("db: error Delete Calibration: %s", err.Error())
+		return errors.New("db: error Delete Calibration")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: calibration not found")
+	}
+
+	return nil
+}
+
+// Исходные показания сенсора начиная с from - страница не длиннее limit по возрастанию (created_at, id).
+// Следующая страница - после последнего показания предыдущей (afterTime, afterId); первая - (from, 0).
+// Для строк, сохранённых до появления raw_value, исходным считается value.
+func (r *CalibrationPostgres) GetRawData_OfSensor(sensorId int, from, afterTime time.Time, afterId, limit int) ([]domain.SensorDataSet, error) {
+
+	query := fmt.Sprintf(`SELECT id, sensor_id, created_at, value, COALESCE(raw_value, value) AS raw_value
+							FROM %s WHERE sensor_id = $1 AND created_at >= $2 AND (created_at, id) > ($3, $4)
+							ORDER BY created_at, id LIMIT $5`, sensorDataSetTable)
+
+	var list []domain.SensorDataSet
+	if err := r.db.Select(&list, query, sensorId, from, afterTime, afterId, limit); err != nil {
+		r.log.Errorf("db: error GetRawData Calibration: %s", err.Error())
+		return nil, errors.New("db: error GetRawData Calibration")
+	}
+
+	return list, nil
+}
+
+// Перезапись значений показаний одним запросом
+func (r *CalibrationPostgres) UpdateValues(list []domain.SensorDataSet) error {
+
+	ids := make([]int64, 0, len(list))
+	values := make([]string, 0, len(list))
+	raws := make([]string, 0, len(list))
+	for _, x := range list {
+		if x.Raw_value == nil {
+			continue
+		}
+		ids = append(ids, int64(x.ID))
+		values = append(values, x.Value)
+		raws = append(raws, *x.Raw_value)
+	}
+
+	query := fmt.Sprintf(`UPDATE %s d SET value = v.value, raw_value = v.raw_value
+							FROM unnest($1::int[], $2::text[], $3::text[]) AS v (id, value, raw_value)
+							WHERE d.id = v.id`, sensorDataSetTable)
+
+	if _, err := r.db.Exec(query, pq.Array(ids), pq.Array(values), pq.Array(raws)); err != nil {
+		r.log.Errorf("db: error UpdateValues Calibration: %s", err.Error())
+		return errors.New("db: error UpdateValues Calibration")
+	}
+
+	return nil
+}
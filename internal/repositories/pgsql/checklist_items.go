@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
@@ -17,15 +18,20 @@ func NewChecklistItemPostgres(db *sqlx.DB) *ChecklistItemPostgres {
 	return &ChecklistItemPostgres{db: db}
 }
 
-func (r *ChecklistItemPostgres) Create(listId int, item domain.ChecklistItem) (int, error) {
+// Пункт добавляется в список аккаунта, которым управляет пользователь
+func (r *ChecklistItemPostgres) Create(userId, listId int, item domain.ChecklistItem) (int, error) {
 
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (checklist_id, title, description) values ($1, $2, $3) RETURNING id",
-		checklistItemsTable)
+	createItemQuery := fmt.Sprintf(`INSERT INTO %s (checklist_id, title, description)
+									SELECT id, $2, $3 FROM %s WHERE id = $1 AND account_id IN (%s) RETURNING id`,
+		checklistItemsTable, checklistsTable, managedAccountsQuery(4))
 
-	row := r.db.QueryRow(createItemQuery, listId, item.Title, item.Description)
+	row := r.db.QueryRow(createItemQuery, listId, item.Title, item.Description, userId)
 
 	var itemId int
 	err := row.Scan(&itemId)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("db: checklist not found")
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -33,19 +39,16 @@ func (r *ChecklistItemPostgres) Create(listId int, item domain.ChecklistItem) (i
 	return itemId, nil
 }
 
-func (r *ChecklistItemPostgres) GetAll(userId, listId int) ([]domain.ChecklistItem, error) {
-
-	// query := fmt.Sprintf(`SELECT it.id, it.title, it.description, it.done FROM %s ti INNER JOIN %s clt on clt.checklist_id = it.id
-	// 								INNER JOIN %s a on a.id = clt.account_id WHERE clt.id = $1 AND a.signup_user_id = $2`,
+// Пункты списка; архивные пункты - только с includeArchived
+func (r *ChecklistItemPostgres) GetAll(userId, listId int, includeArchived bool) ([]domain.ChecklistItem, error) {
 
-	query := fmt.Sprintf(`SELECT it.id, it.title, it.description, it.done, it.updated_at FROM %s it 
+	query := fmt.Sprintf(`SELECT it.id, it.title, it.description, it.done, it.updated_at, it.archived_at FROM %s it 
 		INNER JOIN %s clt on clt.id = it.checklist_id
-		INNER JOIN %s a on a.id = clt.account_id 
-		WHERE clt.id = $1 AND a.signup_user_id = $2`,
-		checklistItemsTable, checklistsTable, accountTable)
+		WHERE clt.id = $1 AND clt.account_id IN (%s) AND ($3 OR it.archived_at IS NULL)`,
+		checklistItemsTable, checklistsTable, userAccountsQuery(2))
 
 	var items []domain.ChecklistItem
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
+	if err := r.db.Select(&items, query, listId, userId, includeArchived); err != nil {
 		return nil, err
 	}
 
@@ -53,9 +56,9 @@ func (r *ChecklistItemPostgres) GetAll(userId, listId int) ([]domain.ChecklistIt
 }
 
 func (r *ChecklistItemPostgres) GetById(userId, listId, itemId int) (domain.ChecklistItem, error) {
-	query := fmt.Sprintf(`SELECT it.id, it.title, it.description, it.done, it.updated_at FROM %s it INNER JOIN %s clt on clt.id = it.checklist_id
-									INNER JOIN %s a on a.id = clt.account_id WHERE it.id = $1 AND clt.id = $2 AND a.signup_user_id = $3`,
-		checklistItemsTable, checklistsTable, accountTable)
+	query := fmt.Sprintf(`SELECT it.id, it.title, it.description, it.done, it.updated_at, it.archived_at FROM %s it INNER JOIN %s clt on clt.id = it.checklist_id
+									WHERE it.id = $1 AND clt.id = $2 AND clt.account_id IN (%s)`,
+		checklistItemsTable, checklistsTable, userAccountsQuery(3))
 
 	var item domain.ChecklistItem
 	if err := r.db.Get(&item, query, itemId, listId, userId); err != nil {
@@ -89,13 +92,16 @@ func (r *ChecklistItemPostgres) Update(userId, listId, itemId int, input domain.
 		argId++
 	}
 
+	// По времени изменения пунктов считается время выполнения экземпляра повторяющегося чек-листа
+	setValues = append(setValues, "updated_at=CURRENT_TIMESTAMP")
+
 	setQuery := strings.Join(setValues, ", ")
 
-	query := fmt.Sprintf(`UPDATE %s it SET %s FROM %s clt, %s a
-						  WHERE it.checklist_id = clt.id AND clt.account_id = a.id 
-								AND a.signup_user_id = $%d AND clt.id = $%d AND it.id = $%d RETURNING it.id`,
-		checklistItemsTable, setQuery, checklistsTable, accountTable,
-		argId, argId+1, argId+2)
+	query := fmt.Sprintf(`UPDATE %s it SET %s FROM %s clt
+						  WHERE it.checklist_id = clt.id AND clt.account_id IN (%s)
+								AND clt.id = $%d AND it.id = $%d RETURNING it.id`,
+		checklistItemsTable, setQuery, checklistsTable, managedAccountsQuery(argId),
+		argId+1, argId+2)
 	args = append(args, userId, listId, itemId)
 
 	var item domain.ChecklistItem
@@ -110,14 +116,39 @@ func (r *ChecklistItemPostgres) Update(userId, listId, itemId int, input domain.
 	return nil
 }
 
-func (r *ChecklistItemPostgres) Delete(userId, listId, itemId int) error {
+// Перенос пункта в архив
+func (r *ChecklistItemPostgres) Archive(userId, listId, itemId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s it SET archived_at = CURRENT_TIMESTAMP FROM %s clt
+							WHERE it.checklist_id = clt.id AND clt.account_id IN (%s)
+							AND clt.id = $2 AND it.id = $3 AND it.archived_at IS NULL`,
+		checklistItemsTable, checklistsTable, managedAccountsQuery(1))
+
+	res, err := r.db.Exec(query, userId, listId, itemId)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("item not found")
+	}
+
+	return nil
+}
+
+func (r *ChecklistItemPostgres) Restore(userId, listId, itemId int) error {
 
-	query := fmt.Sprintf(`DELETE FROM %s it USING %s clt, %s a 
-							WHERE it.checklist_id = clt.id AND clt.account_id = a.id 
-							AND a.signup_user_id = $1 AND clt.id = $2 AND it.id = $3`,
-		checklistItemsTable, checklistsTable, accountTable)
+	query := fmt.Sprintf(`UPDATE %s it SET archived_at = NULL FROM %s clt
+							WHERE it.checklist_id = clt.id AND clt.account_id IN (%s)
+							AND clt.id = $2 AND it.id = $3 AND it.archived_at IS NOT NULL`,
+		checklistItemsTable, checklistsTable, managedAccountsQuery(1))
 
-	_, err := r.db.Exec(query, userId, listId, itemId)
+	res, err := r.db.Exec(query, userId, listId, itemId)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("archived item not found")
+	}
 
-	return err
+	return nil
 }
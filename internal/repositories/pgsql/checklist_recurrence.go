@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type ChecklistRecurrencePostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewChecklistRecurrencePostgres(log *logrus.Logger, db *sqlx.DB) *ChecklistRecurrencePostgres {
+	return &ChecklistRecurrencePostgres{log: log, db: db}
+}
+
+// Шаблоны с временной зоной аккаунта - по ней считаются повторения
+const checklistRecurrenceColumns = `cl.id, cl.account_id, cl.title, cl.description, cl.recurrence, cl.recurrence_start,
+									cl.next_run_at, ac.timezone`
+
+// Чек-лист аккаунта, в котором у пользователя есть право perm, который может быть шаблоном (не экземпляр)
+func (r *ChecklistRecurrencePostgres) GetById(userId, checklistId int, perm domain.Permission) (*domain.ChecklistRecurrence, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s cl INNER JOIN %s ac ON ac.id = cl.account_id
+							WHERE cl.id = $1 AND cl.template_id IS NULL AND cl.archived_at IS NULL
+							AND cl.account_id IN (%s)`,
+		checklistRecurrenceColumns, checklistsTable, accountTable, accountsQuery(2, perm))
+
+	var rec domain.ChecklistRecurrence
+	if err := r.db.Get(&rec, query, checklistId, userId); err != nil {
+		r.log.Errorf("db: error GetById ChecklistRecurrence: %s", err.Error())
+		return nil, errors.New("db: checklist not found")
+	}
+
+	return &rec, nil
+}
+
+// Установка или снятие (пустое правило) повторения
+func (r *ChecklistRecurrencePostgres) Set(rec domain.ChecklistRecurrence) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET recurrence = :recurrence, recurrence_start = :recurrence_start,
+								next_run_at = :next_run_at, updated_at = CURRENT_TIMESTAMP
+							WHERE id = :id`, checklistsTable)
+
+	if _, err := r.db.NamedExec(query, rec); err != nil {
+		r.log.Errorf("db: error Set ChecklistRecurrence: %s", err.Error())
+		return errors.New("db: error Set ChecklistRecurrence")
+	}
+
+	return nil
+}
+
+// Шаблоны, для которых наступил следующий период
+func (r *ChecklistRecurrencePostgres) GetDue(now time.Time) ([]domain.ChecklistRecurrence, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s cl INNER JOIN %s ac ON ac.id = cl.account_id
+							WHERE cl.next_run_at <= $1 AND cl.recurrence <> '' AND cl.archived_at IS NULL
+							ORDER BY cl.next_run_at`,
+		checklistRecurrenceColumns, checklistsTable, accountTable)
+
+	var list []domain.ChecklistRecurrence
+	if err := r.db.Select(&list, query, now); err != nil {
+		r.log.Errorf("db: error GetDue ChecklistRecurrence: %s", err.Error())
+		return nil, errors.New("db: error GetDue ChecklistRecurrence")
+	}
+
+	return list, nil
+}
+
+// CreateInstance создаёт экземпляр шаблона за период с неотмеченными пунктами шаблона
+// и переносит начало следующего периода на to. Экземпляр за период создаётся один раз:
+// если он уже есть, переносится только next_run_at и возвращается 0.
+func (r *ChecklistRecurrencePostgres) CreateInstance(rec domain.ChecklistRecurrence, from time.Time, to *time.Time) (int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error CreateInstance ChecklistRecurrence: %s", err.Error())
+		return 0, errors.New("db: error CreateInstance ChecklistRecurrence")
+	}
+
+	queryList := fmt.Sprintf(`INSERT INTO %s (account_id, title, description, template_id, period_start, period_end)
+								VALUES ($1, $2, $3, $4, $5, $6)
+								ON CONFLICT (template_id, period_start) WHERE template_id IS NOT NULL DO NOTHING
+								RETURNING id`, checklistsTable)
+
+	queryItems := fmt.Sprintf(`INSERT INTO %s (checklist_id, title, description)
+								SELECT $1, title, description FROM %s
+								WHERE checklist_id = $2 AND archived_at IS NULL ORDER BY id`,
+		checklistItemsTable, checklistItemsTable)
+
+	queryNext := fmt.Sprintf(`UPDATE %s SET next_run_at = $2 WHERE id = $1`, checklistsTable)
+
+	var id int
+	err = tx.Get(&id, queryList, rec.AccountID, rec.Title, rec.Description, rec.ChecklistID, from, to)
+	if err == sql.ErrNoRows {
+		err = nil
+	} else if err == nil {
+		_, err = tx.Exec(queryItems, id, rec.ChecklistID)
+	}
+	if err == nil {
+		_, err = tx.Exec(queryNext, rec.ChecklistID, to)
+	}
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error CreateInstance ChecklistRecurrence: %s", err.Error())
+		return 0, errors.New("db: error CreateInstance ChecklistRecurrence")
+	}
+
+	return id, tx.Commit()
+}
+
+// Экземпляры шаблона, период которых начался в [from, to), с выполнением пунктов.
+// Экземпляр выполнен, когда отмечены все его пункты; время выполнения - последнее изменение пунктов.
+func (r *ChecklistRecurrencePostgres) GetInstances_OfUser(userId, templateId int, from, to time.Time) ([]domain.ChecklistInstance, error) {
+
+	query := fmt.Sprintf(`SELECT cl.id, cl.template_id, cl.title, cl.period_start, cl.period_end, cl.created_at,
+								count(it.id) AS items_total,
+								count(it.id) FILTER (WHERE it.done) AS items_done,
+								count(it.id) > 0 AND bool_and(it.done) IS TRUE AS completed,
+								CASE WHEN count(it.id) > 0 AND bool_and(it.done) THEN max(it.updated_at) END AS completed_at
+							FROM %s cl
+							LEFT JOIN %s it ON it.checklist_id = cl.id AND it.archived_at IS NULL
+							WHERE cl.template_id = $1 AND cl.account_id IN (%s)
+							AND cl.period_start >= $3 AND cl.period_start < $4 AND cl.archived_at IS NULL
+							GROUP BY cl.id ORDER BY cl.period_start DESC`,
+		checklistsTable, checklistItemsTable, userAccountsQuery(2))
+
+	var list []domain.ChecklistInstance
+	if err := r.db.Select(&list, query, templateId, userId, from, to); err != nil {
+		r.log.Errorf("db: error GetInstances ChecklistRecurrence: %s", err.Error())
+		return nil, errors.New("db: error GetInstances ChecklistRecurrence")
+	}
+
+	return list, nil
+}
@@ -33,12 +33,17 @@ func (r *ChecklistPostgres) Create(userId int, list domain.CreateChecklist) (int
 		return 0, errors.New("db: error Create Checklist")
 	}
 
-	query := fmt.Sprintf(`SELECT id FROM %s WHERE signup_user_id = $1`, accountTable)
+	// Аккаунт списка - указанный или аккаунт, зарегистрированный пользователем, иначе первый из аккаунтов,
+	// которыми он управляет
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id IN (%s) AND ($2 = 0 OR id = $2)
+							ORDER BY signup_user_id IS DISTINCT FROM $1, id LIMIT 1`, accountTable, managedAccountsQuery(1))
 	var account_id int
-	// err = r.db.Get(&account_id, query, userId)
-	// if err != nil {
+	accountId := 0
+	if list.AccountID != nil {
+		accountId = *list.AccountID
+	}
 
-	row := tx.QueryRow(query, userId)
+	row := tx.QueryRow(query, userId, accountId)
 	if err := row.Scan(&account_id); err != nil {
 		tx.Rollback() // В случае ошибок мы вызываем метод Rollback, которая откатывает все изменения БД до начала выполнения транзакции.
 
@@ -64,21 +69,18 @@ func (r *ChecklistPostgres) Create(userId int, list domain.CreateChecklist) (int
 
 //_____________________________________________________________________________________________________
 
-func (r *ChecklistPostgres) GetAll_ChecklistOfUser(userId int) ([]domain.Checklist, error) {
+func (r *ChecklistPostgres) GetAll_ChecklistOfUser(userId int, includeArchived bool) ([]domain.Checklist, error) {
 
 	var lists []domain.Checklist
 
-	// В запросе сделаем выборку из базы, используя конструкцию INNER JOIN.
-	// Команда INNER JOIN при SELECT помогает выбрать только те записи,
-	// которые имеют одинаковое значение в обеих таблицах.
-	// В нашем случае нам нужно выбрать запись из таблицы checklistsTable,
-	// которая принадлежит аккаунту из таблицы accountTable,
-	// и при этом они связаны по id аккаунта, а аккаунт принадлежит пользователю.
-	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, tl.status, tl.updated_at FROM %s tl INNER JOIN %s a on a.id = tl.account_id WHERE a.signup_user_id = $1",
-		checklistsTable, accountTable)
+	// В запросе сделаем выборку из таблицы checklistsTable тех списков,
+	// которые принадлежат аккаунтам, участником которых является пользователь.
+	// Архивные списки выбираются только с includeArchived.
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, tl.status, tl.updated_at, tl.archived_at, tl.recurrence, tl.template_id, tl.period_start, tl.period_end FROM %s tl WHERE tl.account_id IN (%s) AND ($2 OR tl.archived_at IS NULL)",
+		checklistsTable, userAccountsQuery(1))
 
 	// Результат записываем в слайс.
-	err := r.db.Select(&lists, query, userId)
+	err := r.db.Select(&lists, query, userId, includeArchived)
 
 	if err != nil {
 		r.log.Errorf("db: error GetAll Checklist: %s", err.Error())
@@ -90,8 +92,8 @@ func (r *ChecklistPostgres) GetAll_ChecklistOfUser(userId int) ([]domain.Checkli
 
 func (r *ChecklistPostgres) GetById(userId, listId int) (*domain.Checklist, error) {
 
-	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.status, tl.updated_at FROM %s tl INNER JOIN %s a on a.id = tl.account_id WHERE a.signup_user_id = $1 AND tl.id = $2`,
-		checklistsTable, accountTable)
+	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.status, tl.updated_at, tl.archived_at, tl.recurrence, tl.template_id, tl.period_start, tl.period_end FROM %s tl WHERE tl.account_id IN (%s) AND tl.id = $2`,
+		checklistsTable, userAccountsQuery(1))
 
 	var list domain.Checklist
 	err := r.db.Get(&list, query, userId, listId)
@@ -136,8 +138,8 @@ func (r *ChecklistPostgres) Update(userId int, input domain.UpdateChecklist) err
 
 	setQuery := strings.Join(setValues, ", ")
 
-	query := fmt.Sprintf("UPDATE %s tl SET %s FROM %s a WHERE tl.account_id = a.id AND a.signup_user_id = $%d AND tl.id = $%d RETURNING tl.id",
-		checklistsTable, setQuery, accountTable, argId, argId+1)
+	query := fmt.Sprintf("UPDATE %s tl SET %s WHERE tl.account_id IN (%s) AND tl.id = $%d RETURNING tl.id",
+		checklistsTable, setQuery, managedAccountsQuery(argId), argId+1)
 	args = append(args, userId, input.ID)
 
 	r.log.Debugf("updateQuery: %s", query)
@@ -158,14 +160,36 @@ func (r *ChecklistPostgres) Update(userId int, input domain.UpdateChecklist) err
 	return nil
 }
 
-func (r *ChecklistPostgres) Delete(userId, listId int) error {
-	query := fmt.Sprintf("DELETE FROM %s tl USING %s a WHERE tl.account_id = a.id AND a.signup_user_id = $1 AND tl.id = $2",
-		checklistsTable, accountTable)
-	_, err := r.db.Exec(query, userId, listId)
+// Перенос списка в архив; пункты остаются при списке
+func (r *ChecklistPostgres) Archive(userId, listId int) error {
+	query := fmt.Sprintf(`UPDATE %s tl SET archived_at = CURRENT_TIMESTAMP
+							WHERE tl.account_id IN (%s) AND tl.id = $2 AND tl.archived_at IS NULL`,
+		checklistsTable, managedAccountsQuery(1))
 
+	res, err := r.db.Exec(query, userId, listId)
 	if err != nil {
-		r.log.Errorf("db: error Update Checklist: %s", err.Error())
-		return errors.New("db: error Delete Checklist")
+		r.log.Errorf("db: error Archive Checklist: %s", err.Error())
+		return errors.New("db: error Archive Checklist")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: checklist not found")
+	}
+
+	return nil
+}
+
+func (r *ChecklistPostgres) Restore(userId, listId int) error {
+	query := fmt.Sprintf(`UPDATE %s tl SET archived_at = NULL
+							WHERE tl.account_id IN (%s) AND tl.id = $2 AND tl.archived_at IS NOT NULL`,
+		checklistsTable, managedAccountsQuery(1))
+
+	res, err := r.db.Exec(query, userId, listId)
+	if err != nil {
+		r.log.Errorf("db: error Restore Checklist: %s", err.Error())
+		return errors.New("db: error Restore Checklist")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: archived checklist not found")
 	}
 
 	return nil
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type CommandPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewCommandPostgres(log *logrus.Logger, db *sqlx.DB) *CommandPostgres {
+	return &CommandPostgres{log: log, db: db}
+}
+
+// Команда хабу целиком (без устройства) имеет device_id и device_local_id = 0
+const deviceCommandColumns = `dc.id, dc.account_id, dc.aquahub_id, COALESCE(dc.device_id, 0) AS device_id,
+							COALESCE(dt.local_id, 0) AS device_local_id, dc.automation_id,
+							dc.command, dc.value, dc.status, dc.created_at, dc.expires_at, dc.delivered_at, dc.acked_at`
+
+// ID аккаунта устройства, если устройство принадлежит аккаунту, в котором у пользователя есть право perm
+func (r *CommandPostgres) GetDeviceAccount_OfUser(userId, deviceId int, perm domain.Permission) (int, error) {
+
+	query := fmt.Sprintf(`SELECT aht.account_id FROM %s dt INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = $1 AND aht.account_id IN (%s)`, devicesTable, aquahubsTable, accountsQuery(2, perm))
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, deviceId, userId); err != nil {
+		r.log.Errorf("db: error GetDeviceAccount Command: %s", err.Error())
+		return 0, errors.New("db: device not found")
+	}
+
+	return accountId, nil
+}
+
+// Постановка команды в очередь хаба устройства; устройство должно принадлежать аккаунту
+func (r *CommandPostgres) Create(c domain.DeviceCommand) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, aquahub_id, device_id, automation_id, command, value, expires_at)
+							SELECT aht.account_id, dt.aquahub_id, dt.id, $3, $4, $5, $6
+							FROM %s dt INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = $1 AND aht.account_id = $2
+							RETURNING id`, deviceCommandsTable, devicesTable, aquahubsTable)
+
+	var id int
+	err := r.db.Get(&id, query, c.DeviceID, c.AccountID, c.AutomationID, c.Command, c.Value, c.ExpiresAt)
+	if err != nil {
+		r.log.Errorf("db: error Create Command: %s", err.Error())
+		return 0, errors.New("db: device not found")
+	}
+
+	return id, nil
+}
+
+// Постановка в очередь команды хабу целиком; хаб должен принадлежать аккаунту
+func (r *CommandPostgres) Create_OfAquahub(c domain.DeviceCommand) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, aquahub_id, automation_id, command, value, expires_at)
+							SELECT account_id, id, $3, $4, $5, $6 FROM %s
+							WHERE id = $1 AND account_id = $2 AND archived_at IS NULL
+							RETURNING id`, deviceCommandsTable, aquahubsTable)
+
+	var id int
+	err := r.db.Get(&id, query, c.AquahubID, c.AccountID, c.AutomationID, c.Command, c.Value, c.ExpiresAt)
+	if err != nil {
+		r.log.Errorf("db: error Create Command: %s", err.Error())
+		return 0, errors.New("db: aquahub not found")
+	}
+
+	return id, nil
+}
+
+// Выдача хабу до limit команд: истёкшие помечаются, ожидающие и неподтверждённые выдаются (повторно)
+func (r *CommandPostgres) Claim_OfAquahub(aquahubId, limit int) ([]domain.HubCommand, error) {
+
+	queryExpire := fmt.Sprintf(`UPDATE %s SET status = 'expired'
+								WHERE aquahub_id = $1 AND status IN ('pending', 'delivered') AND expires_at <= CURRENT_TIMESTAMP`,
+		deviceCommandsTable)
+
+	queryClaim := fmt.Sprintf(`WITH c AS (
+									UPDATE %s SET status = 'delivered', delivered_at = CURRENT_TIMESTAMP
+									WHERE id IN (
+										SELECT id FROM %s WHERE aquahub_id = $1 AND status IN ('pending', 'delivered')
+										ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED
+									) RETURNING id, device_id, command, value
+								)
+								SELECT c.id, COALESCE(dt.local_id, 0) AS device_local_id, c.command, c.value
+								FROM c LEFT JOIN %s dt ON dt.id = c.device_id ORDER BY c.id`,
+		deviceCommandsTable, deviceCommandsTable, devicesTable)
+
+	var list []domain.HubCommand
+
+	tx, err := r.db.Beginx()
+	if err == nil {
+		if _, err = tx.Exec(queryExpire, aquahubId); err == nil {
+			err = tx.Select(&list, queryClaim, aquahubId, limit)
+		}
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}
+	if err != nil {
+		r.log.Errorf("db: error Claim Command: %s", err.Error())
+		return nil, errors.New("db: error Claim Command")
+	}
+
+	return list, nil
+}
+
+func (r *CommandPostgres) Ack(aquahubId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'acked', acked_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND aquahub_id = $2 AND status IN ('pending', 'delivered')`, deviceCommandsTable)
+
+	res, err := r.db.Exec(query, id, aquahubId)
+	if err != nil {
+		r.log.Errorf("db: error Ack Command: %s", err.Error())
+		return errors.New("db: error Ack Command")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: command not found")
+	}
+
+	return nil
+}
+
+// Команды аккаунтов пользователя; нулевые поля фильтра не ограничивают выборку
+func (r *CommandPostgres) GetAll_OfUser(userId int, f domain.DeviceCommandsFilter) ([]domain.DeviceCommand, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s dc LEFT JOIN %s dt ON dt.id = dc.device_id
+							WHERE dc.account_id IN (%s) AND dc.created_at >= $2 AND dc.created_at < $3
+							AND ($4 = 0 OR dc.device_id = $4) AND ($5 = 0 OR dc.automation_id = $5) AND ($6 = '' OR dc.status = $6)
+							ORDER BY dc.created_at DESC, dc.id DESC`,
+		deviceCommandColumns, deviceCommandsTable, devicesTable, userAccountsQuery(1))
+
+	var list []domain.DeviceCommand
+	if err := r.db.Select(&list, query, userId, f.From, f.To, f.DeviceID, f.AutomationID, f.Status); err != nil {
+		r.log.Errorf("db: error GetAll Command: %s", err.Error())
+		return nil, errors.New("db: error GetAll Command")
+	}
+
+	return list, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type CoveragePostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewCoveragePostgres(log *logrus.Logger, db *sqlx.DB) *CoveragePostgres {
+	return &CoveragePostgres{log: log, db: db}
+}
+
+func coverageSensorsQuery(where string) string {
+	return fmt.Sprintf(`SELECT s.id, s.device_id, dt.aquahub_id, aht.account_id, COALESCE(s.title, '') AS title, s.report_interval_sec
+							FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE %s ORDER BY s.id`,
+		sensorsTable, devicesTable, aquahubsTable, where)
+}
+
+// Сенсоры уровня отчёта (сенсор, устройство, хаб или аккаунт), доступные пользователю,
+// с учётом фильтра по группе и тегу
+func (r *CoveragePostgres) GetSensors_OfUser(userId int, level string, id int, f domain.GroupFilter) ([]domain.CoverageSensor, error) {
+
+	var where string
+	switch level {
+	case domain.CoverageLevel_Sensor:
+		where = "s.id = $1"
+	case domain.CoverageLevel_Device:
+		where = "dt.id = $1"
+	case domain.CoverageLevel_Aquahub:
+		where = "aht.id = $1"
+	case domain.CoverageLevel_Account:
+		where = "aht.account_id = $1"
+	default:
+		return nil, domain.ErrInvalidCoverageLevel
+	}
+
+	query := coverageSensorsQuery(where + fmt.Sprintf(" AND aht.account_id IN (%s) AND %s",
+		userAccountsQuery(2), sensorGroupFilter("s.id", 3, 4)))
+
+	var list []domain.CoverageSensor
+	if err := r.db.Select(&list, query, id, userId, f.GroupID, f.Tag); err != nil {
+		r.log.Errorf("db: error GetSensors Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetSensors Coverage")
+	}
+
+	return list, nil
+}
+
+// Сенсоры активных хабов - для плановой задачи
+func (r *CoveragePostgres) GetSensors_Active() ([]domain.CoverageSensor, error) {
+
+	query := coverageSensorsQuery("aht.status = 'active' AND aht.archived_at IS NULL")
+
+	var list []domain.CoverageSensor
+	if err := r.db.Select(&list, query); err != nil {
+		r.log.Errorf("db: error GetSensors Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetSensors Coverage")
+	}
+
+	return list, nil
+}
+
+func (r *CoveragePostgres) SetReportInterval(userId, sensorId int, seconds *int) error {
+
+	query := fmt.Sprintf(`UPDATE %s s SET report_interval_sec = $1
+							FROM %s dt, %s aht
+							WHERE s.id = $2 AND dt.id = s.device_id AND aht.id = dt.aquahub_id
+								AND aht.account_id IN (%s)`,
+		sensorsTable, devicesTable, aquahubsTable, managedAccountsQuery(3))
+
+	res, err := r.db.Exec(query, seconds, sensorId, userId)
+	if err != nil {
+		r.log.Errorf("db: error SetReportInterval: %s", err.Error())
+		return errors.New("db: error SetReportInterval")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: sensor not found")
+	}
+
+	return nil
+}
+
+// Моменты показаний сенсора за период [from, to) по возрастанию
+func (r *CoveragePostgres) GetTimes_OfSensor(sensorId int, from, to time.Time) ([]time.Time, error) {
+
+	query := fmt.Sprintf(`SELECT created_at FROM %s WHERE sensor_id = $1 AND created_at >= $2 AND created_at < $3
+							ORDER BY created_at`, sensorDataSetTable)
+
+	var list []time.Time
+	if err := r.db.Select(&list, query, sensorId, from, to); err != nil {
+		r.log.Errorf("db: error GetTimes Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetTimes Coverage")
+	}
+
+	return list, nil
+}
+
+// Моменты последних limit показаний сенсора до момента before, по возрастанию
+func (r *CoveragePostgres) GetLastTimes_OfSensor(sensorId int, before time.Time, limit int) ([]time.Time, error) {
+
+	query := fmt.Sprintf(`SELECT created_at FROM (
+								SELECT created_at FROM %s WHERE sensor_id = $1 AND created_at < $2
+								ORDER BY created_at DESC LIMIT $3
+							) t ORDER BY created_at`, sensorDataSetTable)
+
+	var list []time.Time
+	if err := r.db.Select(&list, query, sensorId, before, limit); err != nil {
+		r.log.Errorf("db: error GetLastTimes Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetLastTimes Coverage")
+	}
+
+	return list, nil
+}
+
+func (r *CoveragePostgres) SaveDaily(c domain.SensorCoverageDaily) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (sensor_id, day, interval_sec, readings, coverage, missing_sec, gaps, computed_at)
+							VALUES (:sensor_id, :day, :interval_sec, :readings, :coverage, :missing_sec, :gaps, CURRENT_TIMESTAMP)
+							ON CONFLICT (sensor_id, day) DO UPDATE SET
+								interval_sec = EXCLUDED.interval_sec,
+								readings = EXCLUDED.readings,
+								coverage = EXCLUDED.coverage,
+								missing_sec = EXCLUDED.missing_sec,
+								gaps = EXCLUDED.gaps,
+								computed_at = EXCLUDED.computed_at`, sensorCoverageDailyTable)
+
+	if _, err := r.db.NamedExec(query, c); err != nil {
+		r.log.Errorf("db: error SaveDaily Coverage: %s", err.Error())
+		return errors.New("db: error SaveDaily Coverage")
+	}
+
+	return nil
+}
+
+// ID сенсоров, для которых покрытие за сутки day уже рассчитано
+func (r *CoveragePostgres) GetDailyComputed(day time.Time) ([]int, error) {
+
+	query := fmt.Sprintf(`SELECT sensor_id FROM %s WHERE day = $1`, sensorCoverageDailyTable)
+
+	var list []int
+	if err := r.db.Select(&list, query, day); err != nil {
+		r.log.Errorf("db: error GetDailyComputed Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetDailyComputed Coverage")
+	}
+
+	return list, nil
+}
+
+func (r *CoveragePostgres) GetDaily_OfSensors(sensorIds []int, from, to time.Time) ([]domain.SensorCoverageDaily, error) {
+
+	query := fmt.Sprintf(`SELECT sensor_id, day, interval_sec, readings, coverage, missing_sec, gaps, computed_at
+							FROM %s WHERE sensor_id = ANY($1) AND day >= $2 AND day < $3
+							ORDER BY day, sensor_id`, sensorCoverageDailyTable)
+
+	var list []domain.SensorCoverageDaily
+	if err := r.db.Select(&list, query, pq.Array(sensorIds), from, to); err != nil {
+		r.log.Errorf("db: error GetDaily Coverage: %s", err.Error())
+		return nil, errors.New("db: error GetDaily Coverage")
+	}
+
+	return list, nil
+}
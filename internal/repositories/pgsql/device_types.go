@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type DeviceTypePostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewDeviceTypePostgres(log *logrus.Logger, db *sqlx.DB) *DeviceTypePostgres {
+	return &DeviceTypePostgres{log: log, db: db}
+}
+
+const deviceTypeColumns = `t.id, t.account_id, t.model, t.title, t.description, t.manufacturer, t.created_at, t.updated_at`
+
+const deviceTypeSensorColumns = `local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high`
+
+// Сенсор типа с ID типа - для выборки сенсоров нескольких типов и для вставки
+type typeSensor struct {
+	DeviceTypeID int `db:"device_type_id"`
+	domain.DeviceTypeSensor
+}
+
+// Сенсоры типов списка
+func selectTypeSensors(q sqlx.Queryer, list []domain.DeviceType) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(list))
+	for _, t := range list {
+		ids = append(ids, int64(t.ID))
+	}
+
+	query := fmt.Sprintf(`SELECT device_type_id, %s FROM %s WHERE device_type_id = ANY($1) ORDER BY device_type_id, local_id`,
+		deviceTypeSensorColumns, deviceTypeSensorsTable)
+
+	var sensors []typeSensor
+	if err := sqlx.Select(q, &sensors, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	for n := range list {
+		list[n].Sensors = []domain.DeviceTypeSensor{}
+		for _, s := range sensors {
+			if s.DeviceTypeID == list[n].ID {
+				list[n].Sensors = append(list[n].Sensors, s.DeviceTypeSensor)
+			}
+		}
+	}
+	return nil
+}
+
+func insertTypeSensors(tx *sqlx.Tx, typeId int, sensors []domain.DeviceTypeSensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+
+	rows := make([]typeSensor, 0, len(sensors))
+	for _, s := range sensors {
+		rows = append(rows, typeSensor{DeviceTypeID: typeId, DeviceTypeSensor: s})
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (device_type_id, %s)
+							VALUES (:device_type_id, :local_id, :title, :description, :unit, :data_type, :report_interval_sec,
+								:for_analytics, :alert_low, :alert_high)`, deviceTypeSensorsTable, deviceTypeSensorColumns)
+
+	_, err := tx.NamedExec(query, rows)
+	return err
+}
+
+// Правило оповещения по умолчанию для сенсора без правил
+func insertDefaultAlertRule(tx *sqlx.Tx, s domain.DeviceTypeSensor, accountId, sensorId int) error {
+	rule, ok := s.AlertRule(accountId, sensorId)
+	if !ok {
+		return nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, sensor_id, title, kind, threshold_low, threshold_high, enabled)
+							SELECT $1, $2, $3, $4, $5, $6, $7 WHERE NOT EXISTS (SELECT 1 FROM %s WHERE sensor_id = $2)`,
+		alertRulesTable, alertRulesTable)
+
+	_, err := tx.Exec(query, rule.AccountID, rule.SensorID, rule.Title, rule.Kind, rule.ThresholdLow, rule.ThresholdHigh, rule.Enabled)
+	return err
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// Общий каталог и шаблоны аккаунтов пользователя; accountId = 0 - шаблоны всех аккаунтов
+func (r *DeviceTypePostgres) GetAll_OfUser(userId, accountId int) ([]domain.DeviceType, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s t
+							WHERE t.account_id IS NULL OR (t.account_id IN (%s) AND ($2 = 0 OR t.account_id = $2))
+							ORDER BY t.model, t.account_id NULLS FIRST`, deviceTypeColumns, deviceTypesTable, userAccountsQuery(1))
+
+	list := []domain.DeviceType{}
+	err := r.db.Select(&list, query, userId, accountId)
+	if err == nil {
+		err = selectTypeSensors(r.db, list)
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetAll DeviceType: %s", err.Error())
+		return nil, errors.New("db: error GetAll DeviceType")
+	}
+
+	return list, nil
+}
+
+func (r *DeviceTypePostgres) GetById(userId, id int) (*domain.DeviceType, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s t WHERE t.id = $1 AND (t.account_id IS NULL OR t.account_id IN (%s))`,
+		deviceTypeColumns, deviceTypesTable, userAccountsQuery(2))
+
+	list := []domain.DeviceType{}
+	err := r.db.Select(&list, query, id, userId)
+	if err == nil {
+		err = selectTypeSensors(r.db, list)
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetById DeviceType: %s", err.Error())
+		return nil, errors.New("db: error GetById DeviceType")
+	}
+	if len(list) == 0 {
+		return nil, errors.New("db: device type not found")
+	}
+
+	return &list[0], nil
+}
+
+// Тип модели model для хаба: шаблон аккаунта хаба или тип общего каталога; nil, если модель неизвестна
+func (r *DeviceTypePostgres) GetByModel_OfAquahub(aquahubId int, model string) (*domain.DeviceType, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s t INNER JOIN %s aht ON aht.id = $1
+							WHERE lower(t.model) = lower($2) AND (t.account_id IS NULL OR t.account_id = aht.account_id)
+							ORDER BY t.account_id NULLS LAST LIMIT 1`, deviceTypeColumns, deviceTypesTable, aquahubsTable)
+
+	list := []domain.DeviceType{}
+	err := r.db.Select(&list, query, aquahubId, model)
+	if err == nil {
+		err = selectTypeSensors(r.db, list)
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetByModel DeviceType: %s", err.Error())
+		return nil, errors.New("db: error GetByModel DeviceType")
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return &list[0], nil
+}
+
+func (r *DeviceTypePostgres) Create(userId int, input domain.CreateDeviceType) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, model, title, description, manufacturer)
+							SELECT id, $3, $4, $5, $6 FROM %s WHERE id = $1 AND id IN (%s)
+							RETURNING id`, deviceTypesTable, accountTable, managedAccountsQuery(2))
+
+	var id int
+	tx, err := r.db.Beginx()
+	if err == nil {
+		err = tx.Get(&id, query, input.AccountID, userId, input.Model, input.Title, input.Description, input.Manufacturer)
+		if err == nil {
+			err = insertTypeSensors(tx, id, input.Sensors)
+		}
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}
+	if err != nil {
+		r.log.Errorf("db: error Create DeviceType: %s", err.Error())
+		return 0, errors.New("db: error Create DeviceType (account not found or model already exists?)")
+	}
+
+	return id, nil
+}
+
+// Замена шаблона аккаунта вместе с сенсорами
+func (r *DeviceTypePostgres) Update(userId, id int, input domain.DeviceTypeInput) error {
+
+	queryType := fmt.Sprintf(`UPDATE %s SET model = $3, title = $4, description = $5, manufacturer = $6,
+									updated_at = CURRENT_TIMESTAMP
+								WHERE id = $1 AND account_id IN (%s)`, deviceTypesTable, managedAccountsQuery(2))
+	querySensors := fmt.Sprintf(`DELETE FROM %s WHERE device_type_id = $1`, deviceTypeSensorsTable)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Update DeviceType: %s", err.Error())
+		return errors.New("db: error Update DeviceType")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryType, id, userId, input.Model, input.Title, input.Description, input.Manufacturer)
+	if err != nil {
+		r.log.Errorf("db: error Update DeviceType: %s", err.Error())
+		return errors.New("db: error Update DeviceType (model already exists?)")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: device type not found")
+	}
+
+	if _, err = tx.Exec(querySensors, id); err == nil {
+		if err = insertTypeSensors(tx, id, input.Sensors); err == nil {
+			err = tx.Commit()
+		}
+	}
+	if err != nil {
+		r.log.Errorf("db: error Update DeviceType: %s", err.Error())
+		return errors.New("db: error Update DeviceType")
+	}
+
+	return nil
+}
+
+// Удаление шаблона аккаунта; устройства типа остаются описанными, но без типа
+func (r *DeviceTypePostgres) Delete(userId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND account_id IN (%s)`, deviceTypesTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Delete DeviceType: %s", err.Error())
+		return errors.New("db: error Delete DeviceType")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: device type not found")
+	}
+
+	return nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// ID аккаунта устройства, если оно принадлежит аккаунту, в котором у пользователя есть право perm
+func (r *DeviceTypePostgres) GetDeviceAccount_OfUser(userId, deviceId int, perm domain.Permission) (int, error) {
+
+	query := fmt.Sprintf(`SELECT aht.account_id FROM %s dt INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = $1 AND aht.account_id IN (%s)`, devicesTable, aquahubsTable, accountsQuery(2, perm))
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, deviceId, userId); err != nil {
+		r.log.Errorf("db: error GetDeviceAccount DeviceType: %s", err.Error())
+		return 0, errors.New("db: device not found")
+	}
+
+	return accountId, nil
+}
+
+// Описание устройства по типу t: название устройства, сенсоры раскладки (недостающие создаются)
+// и правила оповещения по умолчанию для сенсоров без правил. Без force устройство, у которого уже есть тип,
+// не меняется. Возвращает ID описанных сенсоров и созданные сенсоры.
+func (r *DeviceTypePostgres) Apply(deviceId int, t domain.DeviceType, force bool) ([]int, []domain.WebhookSensor, error) {
+
+	queryDevice := fmt.Sprintf(`SELECT dt.aquahub_id, dt.local_id, dt.device_type_id, aht.account_id
+								FROM %s dt INNER JOIN %s aht ON aht.id = dt.aquahub_id
+								WHERE dt.id = $1 FOR UPDATE OF dt`, devicesTable, aquahubsTable)
+
+	queryType := fmt.Sprintf(`UPDATE %s SET device_type_id = $2, title = $3, description = $4 WHERE id = $1`, devicesTable)
+
+	queryUpdate := fmt.Sprintf(`UPDATE %s SET title = $3, description = $4, unit = $5, data_type = $6,
+									report_interval_sec = $7, for_analytics = $8
+								WHERE device_id = $1 AND local_id = $2 AND formula IS NULL RETURNING id`, sensorsTable)
+
+	queryInsert := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description, unit, data_type,
+									report_interval_sec, for_analytics)
+								VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, sensorsTable)
+
+	var device struct {
+		AquahubID    int  `db:"aquahub_id"`
+		LocalID      int  `db:"local_id"`
+		DeviceTypeID *int `db:"device_type_id"`
+		AccountID    int  `db:"account_id"`
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Apply DeviceType: %s", err.Error())
+		return nil, nil, errors.New("db: error Apply DeviceType")
+	}
+	defer tx.Rollback()
+
+	err = tx.Get(&device, queryDevice, deviceId)
+	if err == sql.ErrNoRows {
+		return nil, nil, errors.New("db: device not found")
+	}
+	if err != nil {
+		r.log.Errorf("db: error Apply DeviceType: %s", err.Error())
+		return nil, nil, errors.New("db: error Apply DeviceType")
+	}
+	if device.DeviceTypeID != nil && !force {
+		return nil, nil, nil
+	}
+
+	if _, err = tx.Exec(queryType, deviceId, t.ID, t.Title, t.Description); err != nil {
+		r.log.Errorf("db: error Apply DeviceType: %s", err.Error())
+		return nil, nil, errors.New("db: error Apply DeviceType")
+	}
+
+	described := []int{}
+	created := []domain.WebhookSensor{}
+
+	for _, s := range t.Sensors {
+		args := []interface{}{deviceId, s.LocalID, s.Title, s.Description, s.Unit, s.DataType, s.ReportIntervalSec, s.ForAnalytics}
+
+		var ids []int
+		if err = tx.Select(&ids, queryUpdate, args...); err != nil {
+			break
+		}
+		if len(ids) == 0 {
+			var id int
+			if err = tx.Get(&id, queryInsert, args...); err != nil {
+				break
+			}
+			ids = append(ids, id)
+			created = append(created, domain.WebhookSensor{
+				AquahubID:     device.AquahubID,
+				SensorID:      id,
+				DeviceLocalID: device.LocalID,
+				LocalID:       s.LocalID,
+			})
+		}
+
+		for _, id := range ids {
+			if err = insertDefaultAlertRule(tx, s, device.AccountID, id); err != nil {
+				break
+			}
+			described = append(described, id)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log.Errorf("db: error Apply DeviceType: %s", err.Error())
+		return nil, nil, errors.New("db: error Apply DeviceType")
+	}
+
+	return described, created, nil
+}
+
+// Описание сенсора по раскладке типа его устройства и правило оповещения по умолчанию;
+// false, если у устройства нет типа или в раскладке нет сенсора
+func (r *DeviceTypePostgres) DescribeSensor(sensorId int) (bool, error) {
+
+	query := fmt.Sprintf(`UPDATE %s s SET title = ts.title, description = ts.description, unit = ts.unit, data_type = ts.data_type,
+								report_interval_sec = ts.report_interval_sec, for_analytics = ts.for_analytics
+							FROM %s dt, %s aht, %s ts
+							WHERE s.id = $1 AND s.formula IS NULL AND dt.id = s.device_id AND aht.id = dt.aquahub_id
+								AND ts.device_type_id = dt.device_type_id AND ts.local_id = s.local_id
+							RETURNING aht.account_id, ts.local_id, ts.title, ts.description, ts.unit, ts.data_type,
+								ts.report_interval_sec, ts.for_analytics, ts.alert_low, ts.alert_high`,
+		sensorsTable, devicesTable, aquahubsTable, deviceTypeSensorsTable)
+
+	var s struct {
+		AccountID int `db:"account_id"`
+		domain.DeviceTypeSensor
+	}
+
+	tx, err := r.db.Beginx()
+	if err == nil {
+		err = tx.Get(&s, query, sensorId)
+		if err == nil {
+			err = insertDefaultAlertRule(tx, s.DeviceTypeSensor, s.AccountID, sensorId)
+		}
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.log.Errorf("db: error DescribeSensor DeviceType: %s", err.Error())
+		return false, errors.New("db: error DescribeSensor DeviceType")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type DigestPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewDigestPostgres(log *logrus.Logger, db *sqlx.DB) *DigestPostgres {
+	return &DigestPostgres{log: log, db: db}
+}
+
+const digestSubscriptionColumns = `ds.user_id, ds.account_id, ds.daily, ds.weekly, ds.hour, u.timezone,
+							ds.last_daily_start, ds.last_weekly_start, ds.updated_at`
+
+const digestReportColumns = `id, user_id, account_id, kind, period_start, period_end, timezone, report, created_at`
+
+// Числовые показания; остальные значения в статистику не попадают
+const numericValue = `'^\s*-?[0-9]+(\.[0-9]+)?\s*$'`
+
+func (r *DigestPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Digest: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *DigestPostgres) GetTimezone_OfUser(userId int) (string, error) {
+
+	query := fmt.Sprintf(`SELECT timezone FROM %s WHERE id = $1`, usersTable)
+
+	var tz string
+	if err := r.db.Get(&tz, query, userId); err != nil {
+		r.log.Errorf("db: error GetTimezone Digest: %s", err.Error())
+		return "", errors.New("db: user not found")
+	}
+
+	return tz, nil
+}
+
+// Подписка пользователя; nil - подписки нет
+func (r *DigestPostgres) GetSubscription(userId, accountId int) (*domain.DigestSubscription, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ds INNER JOIN %s u ON u.id = ds.user_id
+							WHERE ds.user_id = $1 AND ds.account_id = $2`,
+		digestSubscriptionColumns, digestSubscriptionsTable, usersTable)
+
+	var list []domain.DigestSubscription
+	if err := r.db.Select(&list, query, userId, accountId); err != nil {
+		r.log.Errorf("db: error GetSubscription Digest: %s", err.Error())
+		return nil, errors.New("db: error GetSubscription Digest")
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return &list[0], nil
+}
+
+func (r *DigestPostgres) SetSubscription(sub domain.DigestSubscription) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, account_id, daily, weekly, hour, updated_at)
+							VALUES (:user_id, :account_id, :daily, :weekly, :hour, CURRENT_TIMESTAMP)
+							ON CONFLICT (user_id, account_id) DO UPDATE SET
+								daily = EXCLUDED.daily,
+								weekly = EXCLUDED.weekly,
+								hour = EXCLUDED.hour,
+								updated_at = EXCLUDED.updated_at`, digestSubscriptionsTable)
+
+	if _, err := r.db.NamedExec(query, sub); err != nil {
+		r.log.Errorf("db: error SetSubscription Digest: %s", err.Error())
+		return errors.New("db: error SetSubscription Digest")
+	}
+
+	return nil
+}
+
+func (r *DigestPostgres) DeleteSubscription(userId, accountId int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND account_id = $2`, digestSubscriptionsTable)
+
+	if _, err := r.db.Exec(query, userId, accountId); err != nil {
+		r.log.Errorf("db: error DeleteSubscription Digest: %s", err.Error())
+		return errors.New("db: error DeleteSubscription Digest")
+	}
+
+	return nil
+}
+
+// Включённые подписки активных участников аккаунтов
+func (r *DigestPostgres) GetEnabled() ([]domain.DigestSubscription, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s ds
+							INNER JOIN %s u ON u.id = ds.user_id AND u.archived_at IS NULL
+							INNER JOIN %s ua ON ua.user_id = ds.user_id AND ua.account_id = ds.account_id
+								AND ua.status = 'active' AND ua.archived_at IS NULL
+							WHERE ds.daily OR ds.weekly`,
+		digestSubscriptionColumns, digestSubscriptionsTable, usersTable, userAccountTableName)
+
+	var list []domain.DigestSubscription
+	if err := r.db.Select(&list, query); err != nil {
+		r.log.Errorf("db: error GetEnabled Digest: %s", err.Error())
+		return nil, errors.New("db: error GetEnabled Digest")
+	}
+
+	return list, nil
+}
+
+// Запоминает начало последнего отправленного периода
+func (r *DigestPostgres) SetLastStart(userId, accountId int, kind string, start time.Time) error {
+
+	column := "last_daily_start"
+	if kind == domain.DigestWeekly {
+		column = "last_weekly_start"
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s = $3 WHERE user_id = $1 AND account_id = $2`, digestSubscriptionsTable, column)
+
+	if _, err := r.db.Exec(query, userId, accountId, start); err != nil {
+		r.log.Errorf("db: error SetLastStart Digest: %s", err.Error())
+		return errors.New("db: error SetLastStart Digest")
+	}
+
+	return nil
+}
+
+func (r *DigestPostgres) GetHubs_OfAccount(accountId int) ([]domain.DigestHub, error) {
+
+	query := fmt.Sprintf(`SELECT aht.id, COALESCE(aht.title, '') AS title, COALESCE(aht.place_name, '') AS place,
+								COALESCE(aht.timezone, a.timezone) AS timezone
+							FROM %s aht INNER JOIN %s a ON a.id = aht.account_id
+							WHERE aht.account_id = $1 AND aht.archived_at IS NULL ORDER BY aht.id`, aquahubsTable, accountTable)
+
+	var list []domain.DigestHub
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetHubs Digest: %s", err.Error())
+		return nil, errors.New("db: error GetHubs Digest")
+	}
+
+	return list, nil
+}
+
+// Минимум, максимум и среднее числовых показаний ключевых сенсоров аккаунта (for_analytics;
+// если ключевые сенсоры не отмечены - всех сенсоров)
+func (r *DigestPostgres) GetSensorStats(accountId int, from, to time.Time) ([]domain.DigestSensor, error) {
+
+	query := fmt.Sprintf(`SELECT sd.aquahub_id, sd.sensor_id,
+								COALESCE(dt.title, '') AS device, COALESCE(s.title, '') AS sensor,
+								min(sd.value::double precision) AS min, max(sd.value::double precision) AS max,
+								avg(sd.value::double precision) AS avg, count(*) AS count
+							FROM %s sd
+							INNER JOIN %s s ON s.id = sd.sensor_id
+							INNER JOIN %s dt ON dt.id = s.device_id
+							WHERE sd.account_id = $1 AND sd.created_at >= $2 AND sd.created_at < $3
+								AND sd.flag IS NULL AND sd.value ~ %s
+								AND (s.for_analytics OR NOT EXISTS (
+									SELECT 1 FROM %s ks
+									INNER JOIN %s kd ON kd.id = ks.device_id
+									INNER JOIN %s ka ON ka.id = kd.aquahub_id
+									WHERE ka.account_id = $1 AND ks.for_analytics))
+							GROUP BY sd.aquahub_id, sd.sensor_id, dt.title, s.title
+							ORDER BY sd.aquahub_id, sd.sensor_id`,
+		sensorDataSetTable, sensorsTable, devicesTable, numericValue, sensorsTable, devicesTable, aquahubsTable)
+
+	var list []domain.DigestSensor
+	if err := r.db.Select(&list, query, accountId, from, to); err != nil {
+		r.log.Errorf("db: error GetSensorStats Digest: %s", err.Error())
+		return nil, errors.New("db: error GetSensorStats Digest")
+	}
+
+	return list, nil
+}
+
+// Промежутки без показаний хабов аккаунта дольше gap, включая промежутки от начала и до конца периода;
+// хаб без показаний за период отключён весь период
+func (r *DigestPostgres) GetOfflinePeriods(accountId int, from, to time.Time, gap time.Duration) ([]domain.DigestPeriod, error) {
+
+	query := fmt.Sprintf(`WITH hubs AS (
+								SELECT id FROM %s WHERE account_id = $1 AND archived_at IS NULL
+							), readings AS (
+								SELECT aquahub_id, created_at FROM %s
+								WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+								UNION ALL SELECT id, $2::timestamptz FROM hubs
+								UNION ALL SELECT id, $3::timestamptz FROM hubs
+							), gaps AS (
+								SELECT aquahub_id, lag(created_at) OVER (PARTITION BY aquahub_id ORDER BY created_at) AS from_at,
+									created_at AS to_at
+								FROM readings
+							)
+							SELECT aquahub_id, from_at, to_at FROM gaps
+							WHERE from_at IS NOT NULL AND to_at - from_at > $4 * interval '1 second'
+								AND aquahub_id IN (SELECT id FROM hubs)
+							ORDER BY aquahub_id, from_at`,
+		aquahubsTable, sensorDataSetTable)
+
+	var list []domain.DigestPeriod
+	if err := r.db.Select(&list, query, accountId, from, to, gap.Seconds()); err != nil {
+		r.log.Errorf("db: error GetOfflinePeriods Digest: %s", err.Error())
+		return nil, errors.New("db: error GetOfflinePeriods Digest")
+	}
+
+	return list, nil
+}
+
+// Оповещения аккаунта, активные хотя бы часть периода
+func (r *DigestPostgres) GetAlerts(accountId int, from, to time.Time) ([]domain.DigestAlert, error) {
+
+	query := fmt.Sprintf(`SELECT id, rule_id, sensor_id, status, message, started_at, resolved_at FROM %s
+							WHERE account_id = $1 AND started_at < $3 AND (resolved_at IS NULL OR resolved_at >= $2)
+							ORDER BY started_at, id`, alertsTable)
+
+	var list []domain.DigestAlert
+	if err := r.db.Select(&list, query, accountId, from, to); err != nil {
+		r.log.Errorf("db: error GetAlerts Digest: %s", err.Error())
+		return nil, errors.New("db: error GetAlerts Digest")
+	}
+
+	return list, nil
+}
+
+// Невыполненные пункты экземпляров повторяющихся чек-листов, срок которых истёк к моменту at
+func (r *DigestPostgres) CountOverdueItems(accountId int, at time.Time) (int, error) {
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s i
+							INNER JOIN %s cl ON cl.id = i.checklist_id
+							WHERE cl.account_id = $1 AND cl.archived_at IS NULL AND cl.period_end <= $2
+								AND i.archived_at IS NULL AND NOT i.done`,
+		checklistItemsTable, checklistsTable)
+
+	var n int
+	if err := r.db.Get(&n, query, accountId, at); err != nil {
+		r.log.Errorf("db: error CountOverdueItems Digest: %s", err.Error())
+		return 0, errors.New("db: error CountOverdueItems Digest")
+	}
+
+	return n, nil
+}
+
+// Сохранение сводки; 0 - сводка за период уже есть
+func (r *DigestPostgres) CreateReport(rep domain.DigestReport) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, account_id, kind, period_start, period_end, timezone, report)
+							VALUES (:user_id, :account_id, :kind, :period_start, :period_end, :timezone, :report)
+							ON CONFLICT (user_id, account_id, kind, period_start) DO NOTHING
+							RETURNING id`, digestReportsTable)
+
+	rows, err := r.db.NamedQuery(query, rep)
+	if err != nil {
+		r.log.Errorf("db: error CreateReport Digest: %s", err.Error())
+		return 0, errors.New("db: error CreateReport Digest")
+	}
+	defer rows.Close()
+
+	var id int
+	if rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			r.log.Errorf("db: error CreateReport Digest: %s", err.Error())
+			return 0, errors.New("db: error CreateReport Digest")
+		}
+	}
+
+	return id, rows.Err()
+}
+
+func (r *DigestPostgres) GetReports_OfUser(userId int, filter domain.DigestReportsFilter) ([]domain.DigestReport, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE user_id = $1 AND period_start >= $2 AND period_start < $3
+								AND ($4 = 0 OR account_id = $4) AND ($5 = '' OR kind = $5)
+								AND account_id IN (%s)
+							ORDER BY period_start DESC, id DESC`,
+		digestReportColumns, digestReportsTable, userAccountsQuery(1))
+
+	var list []domain.DigestReport
+	if err := r.db.Select(&list, query, userId, filter.From, filter.To, filter.AccountID, filter.Kind); err != nil {
+		r.log.Errorf("db: error GetReports Digest: %s", err.Error())
+		return nil, errors.New("db: error GetReports Digest")
+	}
+
+	return list, nil
+}
+
+func (r *DigestPostgres) GetReport_OfUser(userId, id int) (*domain.DigestReport, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND user_id = $2 AND account_id IN (%s)`,
+		digestReportColumns, digestReportsTable, userAccountsQuery(2))
+
+	var rep domain.DigestReport
+	if err := r.db.Get(&rep, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetReport Digest: %s", err.Error())
+		return nil, errors.New("db: digest report not found")
+	}
+
+	return &rep, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type GeoPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewGeoPostgres(log *logrus.Logger, db *sqlx.DB) *GeoPostgres {
+	return &GeoPostgres{log: log, db: db}
+}
+
+// Шаблон LIKE для поиска по началу строки: спецсимволы запроса экранируются
+func likePattern(q string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q)
+}
+
+const geoPlaceColumns = `country_code, COALESCE(postal_code, '') AS postal_code, COALESCE(place_name, '') AS place_name,
+						COALESCE(state_name, '') AS state_name, COALESCE(county_name, '') AS county_name, latitude, longitude`
+
+func (r *GeoPostgres) GetCountries() ([]domain.Country, error) {
+
+	query := fmt.Sprintf(`SELECT code, COALESCE(name, '') AS name, COALESCE(postal_code_format, '') AS postal_code_format
+							FROM %s ORDER BY name`, countriesTable)
+
+	var list []domain.Country
+	if err := r.db.Select(&list, query); err != nil {
+		r.log.Errorf("db: error GetCountries Geo: %s", err.Error())
+		return nil, errors.New("db: error GetCountries Geo")
+	}
+
+	return list, nil
+}
+
+func (r *GeoPostgres) GetTimezones(country string) ([]string, error) {
+
+	query := fmt.Sprintf(`SELECT timezone_id FROM %s WHERE country_code = $1 ORDER BY timezone_id`, countryTimezonesTable)
+
+	list := []string{}
+	if err := r.db.Select(&list, query, country); err != nil {
+		r.log.Errorf("db: error GetTimezones Geo: %s", err.Error())
+		return nil, errors.New("db: error GetTimezones Geo")
+	}
+
+	return list, nil
+}
+
+// Места страны, индекс или название которых начинается с q (без учёта регистра)
+func (r *GeoPostgres) FindPlaces(country, q string, limit int) ([]domain.GeoPlace, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE country_code = $1 AND (postal_code LIKE $2 || '%%' OR lower(place_name) LIKE lower($2) || '%%')
+							ORDER BY place_name, postal_code LIMIT $3`, geoPlaceColumns, geonamesTable)
+
+	var list []domain.GeoPlace
+	if err := r.db.Select(&list, query, country, likePattern(q), limit); err != nil {
+		r.log.Errorf("db: error FindPlaces Geo: %s", err.Error())
+		return nil, errors.New("db: error FindPlaces Geo")
+	}
+
+	return list, nil
+}
+
+// Место с почтовым индексом страны; placeName выбирает одно из мест индекса ("" - самое точное)
+func (r *GeoPostgres) GetPlace(country, postalCode, placeName string) (*domain.GeoPlace, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE country_code = $1 AND postal_code = $2 AND ($3 = '' OR lower(place_name) = lower($3))
+							ORDER BY accuracy DESC NULLS LAST, place_name LIMIT 1`, geoPlaceColumns, geonamesTable)
+
+	var place domain.GeoPlace
+	err := r.db.Get(&place, query, country, postalCode, placeName)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrLocationNotFound
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetPlace Geo: %s", err.Error())
+		return nil, errors.New("db: error GetPlace Geo")
+	}
+
+	return &place, nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// Местоположение аквахаба, если хаб принадлежит аккаунту, в котором у пользователя есть право perm;
+// без часового пояса хаба - часовой пояс аккаунта
+func (r *GeoPostgres) GetAquahubLocation_OfUser(userId, aquahubId int, perm domain.Permission) (*domain.Location, error) {
+
+	query := fmt.Sprintf(`SELECT COALESCE(aht.country, '') AS country, COALESCE(aht.postal_code, '') AS postal_code,
+								COALESCE(aht.place_name, '') AS place_name, COALESCE(aht.state_name, '') AS state_name,
+								aht.latitude, aht.longitude, COALESCE(aht.timezone, a.timezone) AS timezone
+							FROM %s aht INNER JOIN %s a ON a.id = aht.account_id
+							WHERE aht.id = $1 AND aht.account_id IN (%s)`, aquahubsTable, accountTable, accountsQuery(2, perm))
+
+	var loc domain.Location
+	if err := r.db.Get(&loc, query, aquahubId, userId); err != nil {
+		r.log.Errorf("db: error GetAquahubLocation Geo: %s", err.Error())
+		return nil, errors.New("db: aquahub not found")
+	}
+
+	return &loc, nil
+}
+
+func (r *GeoPostgres) SetAquahubLocation(aquahubId int, loc domain.Location) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET country = $2, postal_code = $3, place_name = $4, state_name = $5,
+								latitude = $6, longitude = $7, timezone = $8, updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1`, aquahubsTable)
+
+	_, err := r.db.Exec(query, aquahubId, loc.Country, loc.PostalCode, loc.PlaceName, loc.StateName,
+		loc.Latitude, loc.Longitude, loc.Timezone)
+	if err != nil {
+		r.log.Errorf("db: error SetAquahubLocation Geo: %s", err.Error())
+		return errors.New("db: error SetAquahubLocation Geo")
+	}
+
+	return nil
+}
+
+// Сброс местоположения: хаб живёт по часовому поясу аккаунта
+func (r *GeoPostgres) DeleteAquahubLocation(aquahubId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET country = NULL, postal_code = NULL, place_name = NULL, state_name = NULL,
+								latitude = NULL, longitude = NULL, timezone = NULL, updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1`, aquahubsTable)
+
+	if _, err := r.db.Exec(query, aquahubId); err != nil {
+		r.log.Errorf("db: error DeleteAquahubLocation Geo: %s", err.Error())
+		return errors.New("db: error DeleteAquahubLocation Geo")
+	}
+
+	return nil
+}
+
+func (r *GeoPostgres) GetAccountLocation_OfUser(userId, accountId int, perm domain.Permission) (*domain.Location, error) {
+
+	query := fmt.Sprintf(`SELECT COALESCE(country, '') AS country, COALESCE(zipcode, '') AS postal_code,
+								COALESCE(city, '') AS place_name, COALESCE(region, '') AS state_name,
+								latitude, longitude, timezone
+							FROM %s WHERE id = $1 AND id IN (%s)`, accountTable, accountsQuery(2, perm))
+
+	var loc domain.Location
+	if err := r.db.Get(&loc, query, accountId, userId); err != nil {
+		r.log.Errorf("db: error GetAccountLocation Geo: %s", err.Error())
+		return nil, errors.New("db: account not found")
+	}
+
+	return &loc, nil
+}
+
+func (r *GeoPostgres) SetAccountLocation(accountId int, loc domain.Location) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET country = $2, zipcode = left($3, 20), city = left($4, 100), region = $5,
+								latitude = $6, longitude = $7, timezone = $8, updated_at = CURRENT_TIMESTAMP
+							WHERE id = $1`, accountTable)
+
+	_, err := r.db.Exec(query, accountId, loc.Country, loc.PostalCode, loc.PlaceName, loc.StateName,
+		loc.Latitude, loc.Longitude, loc.Timezone)
+	if err != nil {
+		r.log.Errorf("db: error SetAccountLocation Geo: %s", err.Error())
+		return errors.New("db: error SetAccountLocation Geo")
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewGroupPostgres(log *logrus.Logger, db *sqlx.DB) *GroupPostgres {
+	return &GroupPostgres{log: log, db: db}
+}
+
+const hubGroupColumns = `id, account_id, parent_id, kind, title, description, created_at`
+
+func (r *GroupPostgres) Create(userId int, input domain.CreateHubGroup) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, parent_id, kind, title, description)
+							SELECT id, $3, $4, $5, $6 FROM %s WHERE id = $1 AND id IN (%s)
+							RETURNING id`, hubGroupsTable, accountTable, managedAccountsQuery(2))
+
+	var id int
+	err := r.db.Get(&id, query, input.AccountID, userId, input.ParentID, input.Kind, input.Title, input.Description)
+	if err != nil {
+		r.log.Errorf("db: error Create HubGroup: %s", err.Error())
+		return 0, errors.New("db: error Create HubGroup (account not found?)")
+	}
+
+	return id, nil
+}
+
+// Группы аккаунтов пользователя; accountId = 0 и kind = "" не ограничивают выборку
+func (r *GroupPostgres) GetAll_OfUser(userId, accountId int, kind string) ([]domain.HubGroup, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE account_id IN (%s) AND ($2 = 0 OR account_id = $2) AND ($3 = '' OR kind = $3)
+							ORDER BY account_id, id`, hubGroupColumns, hubGroupsTable, userAccountsQuery(1))
+
+	var list []domain.HubGroup
+	if err := r.db.Select(&list, query, userId, accountId, kind); err != nil {
+		r.log.Errorf("db: error GetAll HubGroup: %s", err.Error())
+		return nil, errors.New("db: error GetAll HubGroup")
+	}
+
+	return list, nil
+}
+
+func (r *GroupPostgres) GetById(userId, id int) (*domain.HubGroup, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND account_id IN (%s)`,
+		hubGroupColumns, hubGroupsTable, userAccountsQuery(2))
+
+	var group domain.HubGroup
+	if err := r.db.Get(&group, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById HubGroup: %s", err.Error())
+		return nil, errors.New("db: group not found")
+	}
+
+	return &group, nil
+}
+
+func (r *GroupPostgres) Update(userId, id int, input domain.UpdateHubGroup) error {
+
+	values := map[string]interface{}{}
+	if input.ParentID != nil {
+		values["parent_id"] = *input.ParentID
+	}
+	if input.Title != nil {
+		values["title"] = *input.Title
+	}
+	if input.Description != nil {
+		values["description"] = *input.Description
+	}
+	setQuery, args := setColumns(values, []string{"parent_id", "title", "description"}, 1)
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d AND account_id IN (%s)`,
+		hubGroupsTable, setQuery, len(args)+1, managedAccountsQuery(len(args)+2))
+	args = append(args, id, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		r.log.Errorf("db: error Update HubGroup: %s", err.Error())
+		return errors.New("db: error Update HubGroup")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: group not found")
+	}
+
+	return nil
+}
+
+// Удаление группы без вложенных групп; хабы группы остаются вне групп
+func (r *GroupPostgres) Delete(userId, id int) error {
+
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE parent_id = $1 AND account_id IN (%s)`,
+		hubGroupsTable, managedAccountsQuery(2))
+
+	var n int
+	if err := r.db.Get(&n, query, id, userId); err != nil {
+		r.log.Errorf("db: error Delete HubGroup: %s", err.Error())
+		return errors.New("db: error Delete HubGroup")
+	}
+	if n > 0 {
+		return domain.ErrGroupNotEmpty
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND account_id IN (%s)`, hubGroupsTable, managedAccountsQuery(2))
+
+	res, err := r.db.Exec(query, id, userId)
+	if err != nil {
+		r.log.Errorf("db: error Delete HubGroup: %s", err.Error())
+		return errors.New("db: error Delete HubGroup")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: group not found")
+	}
+
+	return nil
+}
+
+// Привязка хаба к группе того же аккаунта; groupId = 0 - хаб вне групп
+func (r *GroupPostgres) SetAquahubGroup(userId, aquahubId, groupId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s aht SET group_id = NULLIF($3, 0), updated_at = CURRENT_TIMESTAMP
+							WHERE aht.id = $1 AND aht.account_id IN (%s)
+							AND ($3 = 0 OR EXISTS (SELECT 1 FROM %s g WHERE g.id = $3 AND g.account_id = aht.account_id))`,
+		aquahubsTable, managedAccountsQuery(2), hubGroupsTable)
+
+	res, err := r.db.Exec(query, aquahubId, userId, groupId)
+	if err != nil {
+		r.log.Errorf("db: error SetAquahubGroup HubGroup: %s", err.Error())
+		return errors.New("db: error SetAquahubGroup HubGroup")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: aquahub or group not found")
+	}
+
+	return nil
+}
+
+//__________________________________________________________________________________________________________________________________________________________________
+
+// ID аккаунта хаба, устройства или сенсора, если он принадлежит аккаунту, в котором у пользователя есть право perm
+func (r *GroupPostgres) GetEntityAccount_OfUser(userId int, entity string, id int, perm domain.Permission) (int, error) {
+
+	var query string
+	switch entity {
+	case domain.TagAquahub:
+		query = fmt.Sprintf(`SELECT account_id FROM %s WHERE id = $1 AND account_id IN (%s)`,
+			aquahubsTable, accountsQuery(2, perm))
+	case domain.TagDevice:
+		query = fmt.Sprintf(`SELECT aht.account_id FROM %s dt INNER JOIN %s aht ON aht.id = dt.aquahub_id
+								WHERE dt.id = $1 AND aht.account_id IN (%s)`, devicesTable, aquahubsTable, accountsQuery(2, perm))
+	case domain.TagSensor:
+		accountId, err := getSensorAccount_OfUser(r.db, userId, id, perm)
+		if err != nil {
+			r.log.Errorf("db: error GetEntityAccount Tags: %s", err.Error())
+			return 0, errors.New("db: sensor not found")
+		}
+		return accountId, nil
+	default:
+		return 0, fmt.Errorf("unknown entity %q", entity)
+	}
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetEntityAccount Tags: %s", err.Error())
+		return 0, fmt.Errorf("db: %s not found", entity)
+	}
+
+	return accountId, nil
+}
+
+func (r *GroupPostgres) GetTags(entity string, id int) ([]string, error) {
+
+	query := fmt.Sprintf(`SELECT tag FROM %s WHERE entity = $1 AND entity_id = $2 ORDER BY tag`, entityTagsTable)
+
+	list := []string{}
+	if err := r.db.Select(&list, query, entity, id); err != nil {
+		r.log.Errorf("db: error GetTags Tags: %s", err.Error())
+		return nil, errors.New("db: error GetTags Tags")
+	}
+
+	return list, nil
+}
+
+// Замена тегов сущности
+func (r *GroupPostgres) SetTags(accountId int, entity string, id int, tags []string) error {
+
+	queryDelete := fmt.Sprintf(`DELETE FROM %s WHERE entity = $1 AND entity_id = $2`, entityTagsTable)
+	queryInsert := fmt.Sprintf(`INSERT INTO %s (account_id, entity, entity_id, tag) SELECT $1, $2, $3, unnest($4::varchar[])`,
+		entityTagsTable)
+
+	tx, err := r.db.Beginx()
+	if err == nil {
+		if _, err = tx.Exec(queryDelete, entity, id); err == nil && len(tags) > 0 {
+			_, err = tx.Exec(queryInsert, accountId, entity, id, pq.Array(tags))
+		}
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}
+	if err != nil {
+		r.log.Errorf("db: error SetTags Tags: %s", err.Error())
+		return errors.New("db: error SetTags Tags")
+	}
+
+	return nil
+}
+
+// Теги аккаунтов пользователя с числом помеченных сущностей; accountId = 0 - все аккаунты
+func (r *GroupPostgres) GetTags_OfUser(userId, accountId int) ([]domain.TagCount, error) {
+
+	query := fmt.Sprintf(`SELECT tag, count(*) AS count FROM %s
+							WHERE account_id IN (%s) AND ($2 = 0 OR account_id = $2)
+							GROUP BY tag ORDER BY tag`, entityTagsTable, userAccountsQuery(1))
+
+	var list []domain.TagCount
+	if err := r.db.Select(&list, query, userId, accountId); err != nil {
+		r.log.Errorf("db: error GetAll Tags: %s", err.Error())
+		return nil, errors.New("db: error GetAll Tags")
+	}
+
+	return list, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type InfluxPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewInfluxPostgres(log *logrus.Logger, db *sqlx.DB) *InfluxPostgres {
+	return &InfluxPostgres{log: log, db: db}
+}
+
+func (r *InfluxPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Influx: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *InfluxPostgres) GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error) {
+	accountId, err := getSensorAccount_OfUser(r.db, userId, sensorId, perm)
+	if err != nil {
+		r.log.Errorf("db: error GetSensorAccount Influx: %s", err.Error())
+		return 0, errors.New("db: sensor not found")
+	}
+	return accountId, nil
+}
+
+func (r *InfluxPostgres) Create(m domain.InfluxMapping) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, measurement, tags, field, sensor_id)
+							VALUES ($1, $2, $3, $4, $5) RETURNING id`, influxMappingsTable)
+
+	var id int
+	if err := r.db.Get(&id, query, m.AccountID, m.Measurement, m.Tags, m.Field, m.SensorID); err != nil {
+		r.log.Errorf("db: error Create InfluxMapping: %s", err.Error())
+		return 0, errors.New("db: error Create InfluxMapping")
+	}
+
+	return id, nil
+}
+
+func (r *InfluxPostgres) GetAll_OfAccount(accountId int) ([]domain.InfluxMapping, error) {
+
+	query := fmt.Sprintf(`SELECT id, account_id, measurement, tags, field, sensor_id, created_at
+							FROM %s WHERE account_id = $1 ORDER BY id`, influxMappingsTable)
+
+	var list []domain.InfluxMapping
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetAll InfluxMapping: %s", err.Error())
+		return nil, errors.New("db: error GetAll InfluxMapping")
+	}
+
+	return list, nil
+}
+
+func (r *InfluxPostgres) Delete(accountId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE account_id = $1 AND id = $2`, influxMappingsTable)
+
+	res, err := r.db.Exec(query, accountId, id)
+	if err != nil {
+		r.log.Errorf("db: error Delete InfluxMapping: %s", err.Error())
+		return errors.New("db: error Delete InfluxMapping")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: mapping not found")
+	}
+
+	return nil
+}
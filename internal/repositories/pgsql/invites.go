@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Приглашения в аккаунт (см. domain/invite.go)
+
+type InvitePostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewInvitePostgres(log *logrus.Logger, db *sqlx.DB) *InvitePostgres {
+	return &InvitePostgres{log: log, db: db}
+}
+
+// Ожидающее приглашение с истёкшим токеном показывается как expired
+const accountInviteColumns = `id, account_id, email, roles,
+	CASE WHEN status = 'pending' AND expires_at <= CURRENT_TIMESTAMP THEN 'expired' ELSE status END AS status,
+	invited_by, accepted_by, revoked_by, sent_count, created_at, expires_at, sent_at, accepted_at, revoked_at`
+
+func (r *InvitePostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Invite: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *InvitePostgres) GetAccountName(accountId int) (string, error) {
+
+	query := fmt.Sprintf(`SELECT name FROM %s WHERE id = $1`, accountTable)
+
+	var name string
+	if err := r.db.Get(&name, query, accountId); err != nil {
+		r.log.Errorf("db: error GetAccountName Invite: %s", err.Error())
+		return "", errors.New("db: account not found")
+	}
+
+	return name, nil
+}
+
+// Новое приглашение. Существующий пользователь с этим адресом сразу становится участником
+// аккаунта в статусе invited; активный или отключённый участник повторно не приглашается.
+func (r *InvitePostgres) Create(userId, accountId int, input domain.CreateAccountInvite, tokenHash string, expiresAt time.Time) (int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Create Invite: %s", err.Error())
+		return 0, errors.New("db: error Create Invite")
+	}
+	defer tx.Rollback()
+
+	id, err := createInvite(tx, userId, accountId, input, tokenHash, expiresAt)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		if err == domain.ErrInvitePending || err == domain.ErrAlreadyMember {
+			return 0, err
+		}
+		r.log.Errorf("db: error Create Invite: %s", err.Error())
+		return 0, errors.New("db: error Create Invite")
+	}
+
+	return id, nil
+}
+
+func createInvite(tx *sqlx.Tx, userId, accountId int, input domain.CreateAccountInvite, tokenHash string, expiresAt time.Time) (int, error) {
+
+	// Ожидающее приглашение с истёкшим токеном не мешает пригласить адрес заново
+	query := fmt.Sprintf(`UPDATE %s SET status = 'expired'
+							WHERE account_id = $1 AND lower(email) = $2 AND status = 'pending' AND expires_at <= CURRENT_TIMESTAMP`,
+		accountInvitesTable)
+	if _, err := tx.Exec(query, accountId, input.Email); err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE account_id = $1 AND lower(email) = $2 AND status = 'pending')`,
+		accountInvitesTable)
+	var pending bool
+	if err := tx.Get(&pending, query, accountId, input.Email); err != nil {
+		return 0, err
+	}
+	if pending {
+		return 0, domain.ErrInvitePending
+	}
+
+	var memberId int
+	query = fmt.Sprintf(`SELECT id FROM %s WHERE lower(email) = $1 AND archived_at IS NULL ORDER BY id LIMIT 1`, usersTable)
+	if err := tx.Get(&memberId, query, input.Email); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	if memberId != 0 {
+		query = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE user_id = $1 AND account_id = $2
+								AND archived_at IS NULL AND status <> 'invited')`, userAccountTableName)
+		var member bool
+		if err := tx.Get(&member, query, memberId, accountId); err != nil {
+			return 0, err
+		}
+		if member {
+			return 0, domain.ErrAlreadyMember
+		}
+
+		query = fmt.Sprintf(`INSERT INTO %s (user_id, account_id, roles, status) VALUES ($1, $2, $3, 'invited')
+								ON CONFLICT (user_id, account_id) DO UPDATE SET
+									roles = EXCLUDED.roles, status = 'invited', archived_at = NULL, updated_at = CURRENT_TIMESTAMP`,
+			userAccountTableName)
+		if _, err := tx.Exec(query, memberId, accountId, input.Roles); err != nil {
+			return 0, err
+		}
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (account_id, email, roles, token_hash, invited_by, expires_at)
+							VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, accountInvitesTable)
+
+	var id int
+	if err := tx.Get(&id, query, accountId, input.Email, input.Roles, tokenHash, userId, expiresAt); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Отметка об отправке письма с приглашением
+func (r *InvitePostgres) MarkSent(inviteId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET sent_count = sent_count + 1, sent_at = CURRENT_TIMESTAMP WHERE id = $1`,
+		accountInvitesTable)
+
+	if _, err := r.db.Exec(query, inviteId); err != nil {
+		r.log.Errorf("db: error MarkSent Invite: %s", err.Error())
+		return errors.New("db: error MarkSent Invite")
+	}
+
+	return nil
+}
+
+// Приглашения аккаунта, новые первыми; status - фильтр по статусу (пустой - все)
+func (r *InvitePostgres) GetAll(accountId int, status string) ([]domain.AccountInvite, error) {
+
+	query := fmt.Sprintf(`SELECT * FROM (SELECT %s FROM %s WHERE account_id = $1) i
+							WHERE ($2 = '' OR status = $2) ORDER BY id DESC`, accountInviteColumns, accountInvitesTable)
+
+	list := []domain.AccountInvite{}
+	if err := r.db.Select(&list, query, accountId, status); err != nil {
+		r.log.Errorf("db: error GetAll Invite: %s", err.Error())
+		return nil, errors.New("db: error GetAll Invite")
+	}
+
+	return list, nil
+}
+
+// Новый токен ожидающего приглашения (в том числе с истёкшим сроком) и новый срок действия
+func (r *InvitePostgres) Resend(accountId, inviteId int, tokenHash string, expiresAt time.Time) (*domain.AccountInvite, error) {
+
+	query := fmt.Sprintf(`UPDATE %s SET token_hash = $3, expires_at = $4
+							WHERE id = $1 AND account_id = $2 AND status = 'pending' RETURNING %s`,
+		accountInvitesTable, accountInviteColumns)
+
+	var invite domain.AccountInvite
+	if err := r.db.Get(&invite, query, inviteId, accountId, tokenHash, expiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInviteNotFound
+		}
+		r.log.Errorf("db: error Resend Invite: %s", err.Error())
+		return nil, errors.New("db: error Resend Invite")
+	}
+
+	return &invite, nil
+}
+
+// Отзыв ожидающего приглашения; участие приглашённого пользователя в статусе invited переносится в архив
+func (r *InvitePostgres) Revoke(userId, accountId, inviteId int) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Revoke Invite: %s", err.Error())
+		return errors.New("db: error Revoke Invite")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'revoked', revoked_by = $3, revoked_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND account_id = $2 AND status = 'pending' RETURNING email`, accountInvitesTable)
+
+	var email string
+	if err := tx.Get(&email, query, inviteId, accountId, userId); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrInviteNotFound
+		}
+		r.log.Errorf("db: error Revoke Invite: %s", err.Error())
+		return errors.New("db: error Revoke Invite")
+	}
+
+	query = fmt.Sprintf(`UPDATE %s ua SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+							FROM %s u WHERE u.id = ua.user_id AND lower(u.email) = $1 AND ua.account_id = $2
+							AND ua.status = 'invited' AND ua.archived_at IS NULL`, userAccountTableName, usersTable)
+
+	if _, err = tx.Exec(query, email, accountId); err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log.Errorf("db: error Revoke Invite: %s", err.Error())
+		return errors.New("db: error Revoke Invite")
+	}
+
+	return nil
+}
+
+// Действующее приглашение по ID и хешу одноразовой части токена
+func (r *InvitePostgres) GetPending(inviteId int, tokenHash string) (*domain.AccountInvite, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND token_hash = $2 AND status = 'pending'
+							AND expires_at > CURRENT_TIMESTAMP`, accountInviteColumns, accountInvitesTable)
+
+	var invite domain.AccountInvite
+	if err := r.db.Get(&invite, query, inviteId, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidInvite
+		}
+		r.log.Errorf("db: error GetPending Invite: %s", err.Error())
+		return nil, errors.New("db: error GetPending Invite")
+	}
+
+	return &invite, nil
+}
+
+// Пользователь с адресом email без учёта регистра; nil, если его нет
+func (r *InvitePostgres) GetUser_ByEmail(email string) (*domain.User, error) {
+
+	query := fmt.Sprintf(`SELECT id, email FROM %s WHERE lower(email) = lower($1) AND archived_at IS NULL ORDER BY id LIMIT 1`,
+		usersTable)
+
+	var user domain.User
+	if err := r.db.Get(&user, query, email); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.log.Errorf("db: error GetUser Invite: %s", err.Error())
+		return nil, errors.New("db: error GetUser Invite")
+	}
+
+	return &user, nil
+}
+
+// Принятие приглашения: newUser != nil - регистрация нового пользователя, иначе участником
+// становится пользователь userId. Действующее участие (активное или отключённое) не меняется.
+// Возвращает ID пользователя и аккаунта.
+func (r *InvitePostgres) Accept(inviteId int, tokenHash string, userId int, newUser *domain.User) (int, int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Accept Invite: %s", err.Error())
+		return 0, 0, errors.New("db: error Accept Invite")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`SELECT account_id FROM %s WHERE id = $1 AND token_hash = $2 AND status = 'pending'
+							AND expires_at > CURRENT_TIMESTAMP FOR UPDATE`, accountInvitesTable)
+
+	var accountId int
+	if err := tx.Get(&accountId, query, inviteId, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, domain.ErrInvalidInvite
+		}
+		r.log.Errorf("db: error Accept Invite: %s", err.Error())
+		return 0, 0, errors.New("db: error Accept Invite")
+	}
+
+	if newUser != nil {
+		query = fmt.Sprintf(`INSERT INTO %s (first_name, last_name, email, password_hash, password_salt)
+								VALUES ($1, $2, $3, $4, $5) RETURNING id`, usersTable)
+		if err := tx.Get(&userId, query, newUser.FirstName, newUser.LastName, newUser.Email,
+			newUser.PasswordHash, newUser.PasswordSalt); err != nil {
+			r.log.Errorf("db: error Accept Invite: %s", err.Error())
+			return 0, 0, errors.New("db: error Accept Invite (user already exists?)")
+		}
+	}
+
+	queries := []string{
+		fmt.Sprintf(`INSERT INTO %s (user_id, account_id, roles, status)
+						SELECT $2, account_id, roles, 'active' FROM %s WHERE id = $1
+						ON CONFLICT (user_id, account_id) DO UPDATE SET
+							roles = EXCLUDED.roles, status = 'active', archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+						WHERE %s.archived_at IS NOT NULL OR %s.status = 'invited'`,
+			userAccountTableName, accountInvitesTable, userAccountTableName, userAccountTableName),
+		fmt.Sprintf(`UPDATE %s SET status = 'accepted', accepted_by = $2, accepted_at = CURRENT_TIMESTAMP WHERE id = $1`,
+			accountInvitesTable),
+	}
+	for _, q := range queries {
+		if _, err = tx.Exec(q, inviteId, userId); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log.Errorf("db: error Accept Invite: %s", err.Error())
+		return 0, 0, errors.New("db: error Accept Invite")
+	}
+
+	return userId, accountId, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type MetricsPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewMetricsPostgres(log *logrus.Logger, db *sqlx.DB) *MetricsPostgres {
+	return &MetricsPostgres{log: log, db: db}
+}
+
+func (r *MetricsPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Metrics: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *MetricsPostgres) CreateToken(t domain.MetricsToken, tokenHash string) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, name, prefix, token_hash, created_by)
+							VALUES ($1, $2, $3, $4, $5) RETURNING id`, metricsTokensTable)
+
+	var id int
+	if err := r.db.Get(&id, query, t.AccountID, t.Name, t.Prefix, tokenHash, t.CreatedBy); err != nil {
+		r.log.Errorf("db: error CreateToken Metrics: %s", err.Error())
+		return 0, errors.New("db: error CreateToken Metrics")
+	}
+
+	return id, nil
+}
+
+func (r *MetricsPostgres) GetTokens_OfAccount(accountId int) ([]domain.MetricsToken, error) {
+
+	query := fmt.Sprintf(`SELECT id, account_id, name, prefix, COALESCE(created_by, 0) AS created_by, created_at, last_used_at, revoked_at
+							FROM %s WHERE account_id = $1 ORDER BY id`, metricsTokensTable)
+
+	var list []domain.MetricsToken
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetTokens Metrics: %s", err.Error())
+		return nil, errors.New("db: error GetTokens Metrics")
+	}
+
+	return list, nil
+}
+
+func (r *MetricsPostgres) RevokeToken(accountId, id int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET revoked_at = CURRENT_TIMESTAMP
+							WHERE account_id = $1 AND id = $2 AND revoked_at IS NULL`, metricsTokensTable)
+
+	res, err := r.db.Exec(query, accountId, id)
+	if err != nil {
+		r.log.Errorf("db: error RevokeToken Metrics: %s", err.Error())
+		return errors.New("db: error RevokeToken Metrics")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: token not found")
+	}
+
+	return nil
+}
+
+// ID аккаунта по хешу действующего токена; отмечает время использования токена
+func (r *MetricsPostgres) GetAccount_ByToken(tokenHash string) (int, error) {
+
+	query := fmt.Sprintf(`UPDATE %s SET last_used_at = CURRENT_TIMESTAMP
+							WHERE token_hash = $1 AND revoked_at IS NULL RETURNING account_id`, metricsTokensTable)
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, tokenHash); err != nil {
+		return 0, errors.New("db: token not found")
+	}
+
+	return accountId, nil
+}
+
+// Последние показания всех сенсоров активных хабов аккаунта
+func (r *MetricsPostgres) GetLatestValues_OfAccount(accountId int) ([]domain.SensorMetric, error) {
+
+	query := fmt.Sprintf(`SELECT aht.id AS aquahub_id, COALESCE(aht.title, '') AS aquahub_title,
+								dt.id AS device_id, COALESCE(dt.title, '') AS device_title,
+								s.id AS sensor_id, COALESCE(s.title, '') AS sensor_title, s.unit,
+								d.value, d.created_at
+							FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							INNER JOIN LATERAL (
+								SELECT value, created_at FROM %s
+								WHERE sensor_id = s.id ORDER BY created_at DESC LIMIT 1
+							) d ON true
+							WHERE aht.account_id = $1 AND aht.archived_at IS NULL
+							ORDER BY aht.id, dt.id, s.id`,
+		sensorsTable, devicesTable, aquahubsTable, sensorDataSetTable)
+
+	var list []domain.SensorMetric
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetLatestValues Metrics: %s", err.Error())
+		return nil, errors.New("db: error GetLatestValues Metrics")
+	}
+
+	return list, nil
+}
+
+func (r *MetricsPostgres) SetSensorUnit(userId, sensorId int, unit string) error {
+
+	query := fmt.Sprintf(`UPDATE %s s SET unit = $1
+							FROM %s dt, %s aht
+							WHERE s.id = $2 AND dt.id = s.device_id AND aht.id = dt.aquahub_id
+								AND aht.account_id IN (%s)`,
+		sensorsTable, devicesTable, aquahubsTable, managedAccountsQuery(3))
+
+	res, err := r.db.Exec(query, unit, sensorId, userId)
+	if err != nil {
+		r.log.Errorf("db: error SetSensorUnit: %s", err.Error())
+		return errors.New("db: error SetSensorUnit")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: sensor not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewNotificationPostgres(log *logrus.Logger, db *sqlx.DB) *NotificationPostgres {
+	return &NotificationPostgres{log: log, db: db}
+}
+
+const notificationDeliveryColumns = `id, COALESCE(user_id, 0) AS user_id, COALESCE(account_id, 0) AS account_id, channel, target, event, source_id,
+							subject, body, status, attempts, next_attempt_at, last_error, created_at, sent_at`
+
+func (r *NotificationPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Notification: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) GetPreferences_OfUsers(userIds []int) ([]domain.NotificationPreference, error) {
+
+	query := fmt.Sprintf(`SELECT user_id, channel, target, enabled, updated_at FROM %s
+							WHERE user_id = ANY($1) ORDER BY user_id, channel`, notificationPreferencesTable)
+
+	var list []domain.NotificationPreference
+	if err := r.db.Select(&list, query, pq.Array(userIds)); err != nil {
+		r.log.Errorf("db: error GetPreferences Notification: %s", err.Error())
+		return nil, errors.New("db: error GetPreferences Notification")
+	}
+
+	return list, nil
+}
+
+func (r *NotificationPostgres) SetPreference(p domain.NotificationPreference) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, channel, target, enabled, updated_at)
+							VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+							ON CONFLICT (user_id, channel) DO UPDATE SET
+								target = EXCLUDED.target,
+								enabled = EXCLUDED.enabled,
+								updated_at = EXCLUDED.updated_at`, notificationPreferencesTable)
+
+	if _, err := r.db.Exec(query, p.UserID, p.Channel, p.Target, p.Enabled); err != nil {
+		r.log.Errorf("db: error SetPreference Notification: %s", err.Error())
+		return errors.New("db: error SetPreference Notification")
+	}
+
+	return nil
+}
+
+func (r *NotificationPostgres) DeletePreference(userId int, channel string) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND channel = $2`, notificationPreferencesTable)
+
+	if _, err := r.db.Exec(query, userId, channel); err != nil {
+		r.log.Errorf("db: error DeletePreference Notification: %s", err.Error())
+		return errors.New("db: error DeletePreference Notification")
+	}
+
+	return nil
+}
+
+func (r *NotificationPostgres) GetTemplates_OfAccount(accountId int) ([]domain.NotificationTemplate, error) {
+
+	query := fmt.Sprintf(`SELECT account_id, event, subject, body, updated_at FROM %s WHERE account_id = $1`,
+		notificationTemplatesTable)
+
+	var list []domain.NotificationTemplate
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetTemplates Notification: %s", err.Error())
+		return nil, errors.New("db: error GetTemplates Notification")
+	}
+
+	return list, nil
+}
+
+func (r *NotificationPostgres) SetTemplate(t domain.NotificationTemplate) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, event, subject, body, updated_at)
+							VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+							ON CONFLICT (account_id, event) DO UPDATE SET
+								subject = EXCLUDED.subject,
+								body = EXCLUDED.body,
+								updated_at = EXCLUDED.updated_at`, notificationTemplatesTable)
+
+	if _, err := r.db.Exec(query, t.AccountID, t.Event, t.Subject, t.Body); err != nil {
+		r.log.Errorf("db: error SetTemplate Notification: %s", err.Error())
+		return errors.New("db: error SetTemplate Notification")
+	}
+
+	return nil
+}
+
+func (r *NotificationPostgres) DeleteTemplate(accountId int, event string) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE account_id = $1 AND event = $2`, notificationTemplatesTable)
+
+	if _, err := r.db.Exec(query, accountId, event); err != nil {
+		r.log.Errorf("db: error DeleteTemplate Notification: %s", err.Error())
+		return errors.New("db: error DeleteTemplate Notification")
+	}
+
+	return nil
+}
+
+// Активные участники аккаунта
+func (r *NotificationPostgres) GetRecipients_OfAccount(accountId int) ([]domain.NotificationRecipient, error) {
+
+	query := fmt.Sprintf(`SELECT u.id AS user_id, u.email, u.first_name, u.timezone
+							FROM %s u INNER JOIN %s ua ON ua.user_id = u.id
+							WHERE ua.account_id = $1 AND ua.status = 'active' AND ua.archived_at IS NULL
+							AND u.archived_at IS NULL ORDER BY u.id`, usersTable, userAccountTableName)
+
+	var list []domain.NotificationRecipient
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetRecipients Notification: %s", err.Error())
+		return nil, errors.New("db: error GetRecipients Notification")
+	}
+
+	return list, nil
+}
+
+func (r *NotificationPostgres) GetRecipient(userId int) (domain.NotificationRecipient, error) {
+
+	query := fmt.Sprintf(`SELECT id AS user_id, email, first_name, timezone FROM %s WHERE id = $1`, usersTable)
+
+	var rcpt domain.NotificationRecipient
+	if err := r.db.Get(&rcpt, query, userId); err != nil {
+		r.log.Errorf("db: error GetRecipient Notification: %s", err.Error())
+		return rcpt, errors.New("db: user not found")
+	}
+
+	return rcpt, nil
+}
+
+func (r *NotificationPostgres) CreateDeliveries(list []domain.NotificationDelivery) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error CreateDeliveries Notification: %s", err.Error())
+		return errors.New("db: error CreateDeliveries Notification")
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, account_id, channel, target, event, source_id, subject, body, status)
+							VALUES (NULLIF(:user_id, 0), NULLIF(:account_id, 0), :channel, :target, :event, :source_id, :subject, :body, :status)`,
+		notificationDeliveriesTable)
+
+	for _, d := range list {
+		if _, err = tx.NamedExec(query, d); err != nil {
+			tx.Rollback()
+			r.log.Errorf("db: error CreateDeliveries Notification: %s", err.Error())
+			return errors.New("db: error CreateDeliveries Notification")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Забирает до limit доставок, время которых подошло, и откладывает их на lease,
+// чтобы параллельный запуск задачи не отправил их повторно
+func (r *NotificationPostgres) ClaimDue(limit int, lease time.Duration) ([]domain.NotificationDelivery, error) {
+
+	query := fmt.Sprintf(`UPDATE %s SET next_attempt_at = CURRENT_TIMESTAMP + $2 * interval '1 second'
+							WHERE id IN (
+								SELECT id FROM %s WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+								ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+							) RETURNING %s`,
+		notificationDeliveriesTable, notificationDeliveriesTable, notificationDeliveryColumns)
+
+	var list []domain.NotificationDelivery
+	if err := r.db.Select(&list, query, limit, lease.Seconds()); err != nil {
+		r.log.Errorf("db: error ClaimDue Notification: %s", err.Error())
+		return nil, errors.New("db: error ClaimDue Notification")
+	}
+
+	return list, nil
+}
+
+func (r *NotificationPostgres) UpdateDelivery(d domain.NotificationDelivery) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+								last_error = :last_error, sent_at = :sent_at
+							WHERE id = :id`, notificationDeliveriesTable)
+
+	if _, err := r.db.NamedExec(query, d); err != nil {
+		r.log.Errorf("db: error UpdateDelivery Notification: %s", err.Error())
+		return errors.New("db: error UpdateDelivery Notification")
+	}
+
+	return nil
+}
+
+// Журнал доставки пользователя; status = "" - все статусы
+func (r *NotificationPostgres) GetDeliveries_OfUser(userId int, status string, from, to time.Time) ([]domain.NotificationDelivery, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+							AND ($4 = '' OR status = $4) ORDER BY created_at DESC, id DESC`,
+		notificationDeliveryColumns, notificationDeliveriesTable)
+
+	var list []domain.NotificationDelivery
+	if err := r.db.Select(&list, query, userId, from, to, status); err != nil {
+		r.log.Errorf("db: error GetDeliveries Notification: %s", err.Error())
+		return nil, errors.New("db: error GetDeliveries Notification")
+	}
+
+	return list, nil
+}
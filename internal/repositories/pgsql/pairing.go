@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type HubPairingPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewHubPairingPostgres(log *logrus.Logger, db *sqlx.DB) *HubPairingPostgres {
+	return &HubPairingPostgres{log: log, db: db}
+}
+
+// Аквахаб с токеном h_token, в том числе в архиве (как и в Claim, токен аквахаба в архиве занят);
+// nil, если хаб не привязан
+func (r *HubPairingPostgres) GetHub_ByToken(hToken string) (*domain.PairedHub, error) {
+
+	query := fmt.Sprintf(`SELECT aht.id, aht.account_id, a.u_token, aht.reset_requested_at, aht.archived_at
+							FROM %s aht INNER JOIN %s a ON a.id = aht.account_id
+							WHERE aht.h_token = $1`, aquahubsTable, accountTable)
+
+	var hub domain.PairedHub
+	err := r.db.Get(&hub, query, hToken)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetHub Pairing: %s", err.Error())
+		return nil, errors.New("db: error GetHub Pairing")
+	}
+
+	return &hub, nil
+}
+
+// ID аккаунта аквахаба, если хаб принадлежит аккаунту, в котором у пользователя есть право perm
+func (r *HubPairingPostgres) GetAquahubAccount_OfUser(userId, aquahubId int, perm domain.Permission) (int, error) {
+
+	query := fmt.Sprintf(`SELECT account_id FROM %s WHERE id = $1 AND archived_at IS NULL AND account_id IN (%s)`,
+		aquahubsTable, accountsQuery(2, perm))
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, aquahubId, userId); err != nil {
+		r.log.Errorf("db: error GetAquahubAccount Pairing: %s", err.Error())
+		return 0, errors.New("db: aquahub not found")
+	}
+
+	return accountId, nil
+}
+
+// Код привязки хаба: действующий код сохраняется, истёкший заменяется на code.
+// Конфликт по коду (код уже выдан другому хабу) возвращается ошибкой.
+func (r *HubPairingPostgres) SetCode(hToken, code string, expiresAt time.Time) (domain.PairingCode, error) {
+
+	queryPurge := fmt.Sprintf(`DELETE FROM %s WHERE expires_at < CURRENT_TIMESTAMP - interval '1 day'`, hubPairingsTable)
+
+	queryUpsert := fmt.Sprintf(`INSERT INTO %s (h_token, code, expires_at) VALUES ($1, $2, $3)
+								ON CONFLICT (h_token) DO UPDATE SET code = EXCLUDED.code, created_at = CURRENT_TIMESTAMP,
+									expires_at = EXCLUDED.expires_at
+								WHERE %s.expires_at <= CURRENT_TIMESTAMP
+								RETURNING h_token, code, expires_at`, hubPairingsTable, hubPairingsTable)
+
+	querySelect := fmt.Sprintf(`SELECT h_token, code, expires_at FROM %s WHERE h_token = $1`, hubPairingsTable)
+
+	if _, err := r.db.Exec(queryPurge); err != nil {
+		r.log.Errorf("db: error Purge Pairing: %s", err.Error())
+	}
+
+	var list []domain.PairingCode
+	if err := r.db.Select(&list, queryUpsert, hToken, code, expiresAt); err != nil {
+		r.log.Errorf("db: error SetCode Pairing: %s", err.Error())
+		return domain.PairingCode{}, errors.New("db: error SetCode Pairing")
+	}
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	// Код хаба ещё действует
+	var pairing domain.PairingCode
+	if err := r.db.Get(&pairing, querySelect, hToken); err != nil {
+		r.log.Errorf("db: error SetCode Pairing: %s", err.Error())
+		return domain.PairingCode{}, errors.New("db: error SetCode Pairing")
+	}
+
+	return pairing, nil
+}
+
+// Привязка хаба по коду: в аккаунте пользователя создаётся аквахаб с h_token хаба, код удаляется
+func (r *HubPairingPostgres) Claim(userId int, input domain.ClaimAquahub) (int, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		return 0, errors.New("db: error Claim Pairing")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`SELECT h_token FROM %s WHERE code = $1 AND expires_at > CURRENT_TIMESTAMP FOR UPDATE`,
+		hubPairingsTable)
+
+	var hToken string
+	if err := tx.Get(&hToken, query, input.Code); err != nil {
+		if err != sql.ErrNoRows {
+			r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		}
+		return 0, domain.ErrInvalidPairingCode
+	}
+
+	// Токен аквахаба в архиве тоже занят: аквахаб можно восстановить
+	query = fmt.Sprintf(`SELECT count(*) FROM %s WHERE h_token = $1`, aquahubsTable)
+
+	var n int
+	if err := tx.Get(&n, query, hToken); err != nil {
+		r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		return 0, errors.New("db: error Claim Pairing")
+	}
+	if n > 0 {
+		return 0, domain.ErrHubAlreadyClaimed
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (account_id, h_token, title, description, status, updated_at)
+							SELECT id, $3, $4, $5, 'active', CURRENT_TIMESTAMP FROM %s
+							WHERE id = $1 AND id IN (%s)
+							RETURNING id`, aquahubsTable, accountTable, managedAccountsQuery(2))
+
+	var id int
+	if err := tx.Get(&id, query, input.AccountID, userId, hToken, input.Title, input.Description); err != nil {
+		r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		return 0, errors.New("db: error Claim Pairing (account not found?)")
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE h_token = $1`, hubPairingsTable)
+	if _, err := tx.Exec(query, hToken); err != nil {
+		r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		return 0, errors.New("db: error Claim Pairing")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Errorf("db: error Claim Pairing: %s", err.Error())
+		return 0, errors.New("db: error Claim Pairing")
+	}
+
+	return id, nil
+}
+
+// Отвязка хаба: аквахаб с данными остаётся в архиве аккаунта, его h_token заменяется на hToken,
+// невыполненные команды хабу истекают
+func (r *HubPairingPostgres) Unclaim(aquahubId int, hToken string) error {
+
+	queryHub := fmt.Sprintf(`UPDATE %s SET h_token = $2, status = 'archived', archived_at = CURRENT_TIMESTAMP,
+								updated_at = CURRENT_TIMESTAMP, reset_requested_at = NULL
+								WHERE id = $1 AND archived_at IS NULL`, aquahubsTable)
+
+	queryCommands := fmt.Sprintf(`UPDATE %s SET status = 'expired' WHERE aquahub_id = $1 AND status IN ('pending', 'delivered')`,
+		deviceCommandsTable)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Unclaim Pairing: %s", err.Error())
+		return errors.New("db: error Unclaim Pairing")
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(queryHub, aquahubId, hToken)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			return errors.New("db: aquahub not found")
+		}
+		_, err = tx.Exec(queryCommands, aquahubId)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log.Errorf("db: error Unclaim Pairing: %s", err.Error())
+		return errors.New("db: error Unclaim Pairing")
+	}
+
+	return nil
+}
+
+// Отметка о запрошенном сбросе хаба к заводским настройкам
+func (r *HubPairingPostgres) RequestReset(aquahubId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET reset_requested_at = CURRENT_TIMESTAMP WHERE id = $1 AND archived_at IS NULL`,
+		aquahubsTable)
+
+	if _, err := r.db.Exec(query, aquahubId); err != nil {
+		r.log.Errorf("db: error RequestReset Pairing: %s", err.Error())
+		return errors.New("db: error RequestReset Pairing")
+	}
+
+	return nil
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"database/sql"
+	"fmt"
+	"strings"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/o-sokol-o/hub/internal/domain"
 	"github.com/sirupsen/logrus"
@@ -20,20 +24,188 @@ const (
 	devicesTable       = "devices"
 	sensorsTable       = "sensors"
 	sensorDataSetTable = "sensors_dataset"
+	propertiesTable    = "properties"
+
+	sensorCalibrationsTable = "sensor_calibrations"
+	sensorDetectorsTable    = "sensor_detectors"
+
+	sensorCoverageDailyTable = "sensor_coverage_daily"
+	metricsTokensTable       = "metrics_tokens"
+	influxMappingsTable      = "influx_mappings"
+
+	alertRulesTable  = "alert_rules"
+	alertEventsTable = "alert_events"
+	alertsTable      = "alerts"
+
+	automationsTable    = "automations"
+	automationRunsTable = "automation_runs"
+	deviceCommandsTable = "device_commands"
+
+	notificationPreferencesTable = "notification_preferences"
+	notificationTemplatesTable   = "notification_templates"
+	notificationDeliveriesTable  = "notification_deliveries"
+
+	webhooksTable          = "webhooks"
+	webhookDeliveriesTable = "webhook_deliveries"
+
+	digestSubscriptionsTable = "digest_subscriptions"
+	digestReportsTable       = "digest_reports"
+
+	hubPairingsTable = "hub_pairings"
+
+	hubGroupsTable  = "hub_groups"
+	entityTagsTable = "entity_tags"
+
+	hubTransfersTable = "hub_transfers"
+
+	accountInvitesTable = "account_invites"
+
+	refreshTokensTable = "refresh_tokens"
+	revokedTokensTable = "revoked_tokens"
+
+	deviceTypesTable       = "device_types"
+	deviceTypeSensorsTable = "device_type_sensors"
+
+	countriesTable        = "countries"
+	countryTimezonesTable = "country_timezones"
+	geonamesTable         = "geonames"
 )
 
+// Подзапрос ID аккаунтов, в которых роль активного участника даёт пользователю право perm.
+// argId - номер плейсхолдера с ID пользователя.
+func accountsQuery(argId int, perm domain.Permission) string {
+	var roles []string
+	for _, role := range domain.RolesWith(perm) {
+		roles = append(roles, role.String())
+	}
+	return fmt.Sprintf("SELECT account_id FROM %s WHERE user_id = $%d AND status = 'active' AND archived_at IS NULL AND roles && '{%s}'::user_account_role_t[]",
+		userAccountTableName, argId, strings.Join(roles, ","))
+}
+
+// Подзапрос ID аккаунтов, участником которых является пользователь (право просмотра)
+func userAccountsQuery(argId int) string {
+	return accountsQuery(argId, domain.PermissionRead)
+}
+
+// Подзапрос ID аккаунтов, которыми пользователь управляет (право изменения)
+func managedAccountsQuery(argId int) string {
+	return accountsQuery(argId, domain.PermissionManage)
+}
+
+// Подзапрос ID групп: группа с ID из плейсхолдера argId и вложенные в неё зоны и резервуары
+func groupTreeQuery(argId int) string {
+	return fmt.Sprintf("SELECT id FROM %[1]s WHERE id = $%[2]d OR parent_id = $%[2]d OR parent_id IN (SELECT id FROM %[1]s WHERE parent_id = $%[2]d)",
+		hubGroupsTable, argId)
+}
+
+// Условие "сущность entity с ID из колонки idColumn помечена тегом из плейсхолдера argTag"
+func taggedQuery(entity, idColumn string, argTag int) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s t WHERE t.entity = '%s' AND t.entity_id = %s AND t.tag = $%d)",
+		entityTagsTable, entity, idColumn, argTag)
+}
+
+// Теги сущности entity с ID из колонки idColumn - колонка tags
+func tagsColumn(entity, idColumn string) string {
+	return fmt.Sprintf("ARRAY(SELECT t.tag FROM %s t WHERE t.entity = '%s' AND t.entity_id = %s ORDER BY t.tag) AS tags",
+		entityTagsTable, entity, idColumn)
+}
+
+// Условие отбора сенсоров по domain.GroupFilter: сенсор хаба из группы (с вложенными) и помеченный тегом
+// сам или через своё устройство или хаб. sensorColumn - колонка с ID сенсора,
+// argGroup/argTag - номера плейсхолдеров группы и тега; нулевой фильтр не ограничивает выборку.
+func sensorGroupFilter(sensorColumn string, argGroup, argTag int) string {
+	return fmt.Sprintf(`(($%[2]d = 0 AND $%[3]d = '') OR %[1]s IN (
+			SELECT fs.id FROM %[4]s fs INNER JOIN %[5]s fd ON fd.id = fs.device_id INNER JOIN %[6]s fh ON fh.id = fd.aquahub_id
+			WHERE ($%[2]d = 0 OR fh.group_id IN (%[7]s))
+			AND ($%[3]d = '' OR %[8]s OR %[9]s OR %[10]s)))`,
+		sensorColumn, argGroup, argTag, sensorsTable, devicesTable, aquahubsTable, groupTreeQuery(argGroup),
+		taggedQuery(domain.TagSensor, "fs.id", argTag), taggedQuery(domain.TagDevice, "fd.id", argTag),
+		taggedQuery(domain.TagAquahub, "fh.id", argTag))
+}
+
+// ID аккаунта сенсора, если сенсор принадлежит аккаунту, в котором у пользователя есть право perm
+func getSensorAccount_OfUser(db *sqlx.DB, userId, sensorId int, perm domain.Permission) (int, error) {
+	query := fmt.Sprintf(`SELECT aht.account_id
+							FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE s.id = $1 AND aht.account_id IN (%s)`,
+		sensorsTable, devicesTable, aquahubsTable, accountsQuery(2, perm))
+
+	var accountId int
+	err := db.Get(&accountId, query, sensorId, userId)
+	return accountId, err
+}
+
+// Проверка, что пользователь - активный участник аккаунта с правом perm
+func checkAccount_OfUser(db sqlx.Queryer, userId, accountId int, perm domain.Permission) error {
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE id = $1 AND id IN (%s)`,
+		accountTable, accountsQuery(2, perm))
+
+	var n int
+	if err := sqlx.Get(db, &n, query, accountId, userId); err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func NewRepositories(log *logrus.Logger, cache domain.Cache, db *sqlx.DB) (
 	*logrus.Logger, domain.Cache,
 
 	*AuthPostgres,
 	*ChecklistPostgres,
 	*ChecklistItemPostgres,
-	*AquahubListPostgres) {
+	*AquahubListPostgres,
+	*VirtualSensorPostgres,
+	*CalibrationPostgres,
+	*AnomalyPostgres,
+	*CoveragePostgres,
+	*MetricsPostgres,
+	*InfluxPostgres,
+	*AlertPostgres,
+	*NotificationPostgres,
+	*AutomationPostgres,
+	*CommandPostgres,
+	*ChecklistRecurrencePostgres,
+	*WebhookPostgres,
+	*DigestPostgres,
+	*HubPairingPostgres,
+	*GroupPostgres,
+	*GeoPostgres,
+	*TransferPostgres,
+	*DeviceTypePostgres,
+	*ArchivePostgres,
+	*AccessPostgres,
+	*InvitePostgres) {
 
 	return log, cache,
 
 		NewAuthPostgres(db),
 		NewChecklistPostgres(log, db),
 		NewChecklistItemPostgres(db),
-		NewAquahubListPostgres(log, db)
+		NewAquahubListPostgres(log, db),
+		NewVirtualSensorPostgres(log, db),
+		NewCalibrationPostgres(log, db),
+		NewAnomalyPostgres(log, db),
+		NewCoveragePostgres(log, db),
+		NewMetricsPostgres(log, db),
+		NewInfluxPostgres(log, db),
+		NewAlertPostgres(log, db),
+		NewNotificationPostgres(log, db),
+		NewAutomationPostgres(log, db),
+		NewCommandPostgres(log, db),
+		NewChecklistRecurrencePostgres(log, db),
+		NewWebhookPostgres(log, db),
+		NewDigestPostgres(log, db),
+		NewHubPairingPostgres(log, db),
+		NewGroupPostgres(log, db),
+		NewGeoPostgres(log, db),
+		NewTransferPostgres(log, db),
+		NewDeviceTypePostgres(log, db),
+		NewArchivePostgres(log, db),
+		NewAccessPostgres(log, db),
+		NewInvitePostgres(log, db)
 }
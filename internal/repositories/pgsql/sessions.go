@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// Сеансы пользователя: токены обновления и отозванные токены доступа (см. domain/session.go)
+
+func insertRefreshToken(tx sqlx.Ext, t domain.RefreshToken) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, family, token_hash, access_jti, access_expires_at, expires_at)
+							VALUES (:user_id, :family, :token_hash, :access_jti, :access_expires_at, :expires_at)`, refreshTokensTable)
+
+	_, err := sqlx.NamedExec(tx, query, t)
+	return err
+}
+
+func (r *AuthPostgres) CreateRefreshToken(t domain.RefreshToken) error {
+	return errors.Wrap(insertRefreshToken(r.db, t), "db: create refresh token")
+}
+
+// Токен обновления по хешу; неизвестный токен - ErrInvalidRefreshToken
+func (r *AuthPostgres) GetRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE token_hash = $1`, refreshTokensTable)
+
+	var t domain.RefreshToken
+	if err := r.db.Get(&t, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, errors.Wrap(err, "db: get refresh token")
+	}
+
+	return &t, nil
+}
+
+// Обмен токена обновления: прежний помечается использованным, в том же семействе создаётся следующий.
+// Если прежний токен уже использован, отозван или истёк (в том числе в параллельном запросе) - ErrInvalidRefreshToken.
+func (r *AuthPostgres) RotateRefreshToken(oldId int, next domain.RefreshToken) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db: rotate refresh token")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`UPDATE %s SET used_at = CURRENT_TIMESTAMP
+							WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		refreshTokensTable)
+
+	res, err := tx.Exec(query, oldId)
+	if err != nil {
+		return errors.Wrap(err, "db: rotate refresh token")
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return domain.ErrInvalidRefreshToken
+	}
+
+	if err := insertRefreshToken(tx, next); err != nil {
+		return errors.Wrap(err, "db: rotate refresh token")
+	}
+
+	return errors.Wrap(tx.Commit(), "db: rotate refresh token")
+}
+
+// Отзыв токенов обновления: ещё действующие токены доступа, выданные вместе с ними, попадают в revoked_tokens
+func (r *AuthPostgres) revokeRefreshTokens(where string, args ...interface{}) error {
+
+	query := fmt.Sprintf(`WITH revoked AS (
+								UPDATE %s SET revoked_at = CURRENT_TIMESTAMP
+								WHERE revoked_at IS NULL AND %s
+								RETURNING user_id, access_jti, access_expires_at
+							)
+							INSERT INTO %s (jti, user_id, expires_at)
+							SELECT access_jti, user_id, access_expires_at FROM revoked
+							WHERE access_expires_at > CURRENT_TIMESTAMP
+							ON CONFLICT (jti) DO NOTHING`,
+		refreshTokensTable, where, revokedTokensTable)
+
+	_, err := r.db.Exec(query, args...)
+	return err
+}
+
+// Завершить сеанс: отозвать семейство токенов обновления
+func (r *AuthPostgres) RevokeRefreshFamily(userId int, family string) error {
+	return errors.Wrap(r.revokeRefreshTokens("user_id = $1 AND family = $2", userId, family), "db: revoke refresh family")
+}
+
+// Завершить все сеансы пользователя
+func (r *AuthPostgres) RevokeSessions_OfUser(userId int) error {
+	return errors.Wrap(r.revokeRefreshTokens("user_id = $1", userId), "db: revoke sessions")
+}
+
+// Семейство токена обновления, выданного вместе с токеном доступа jti; пустая строка, если такого нет
+func (r *AuthPostgres) GetFamily_ByAccess(userId int, jti string) (string, error) {
+
+	query := fmt.Sprintf(`SELECT family FROM %s WHERE user_id = $1 AND access_jti = $2`, refreshTokensTable)
+
+	var family string
+	if err := r.db.Get(&family, query, userId, jti); err != nil && err != sql.ErrNoRows {
+		return "", errors.Wrap(err, "db: get refresh family")
+	}
+
+	return family, nil
+}
+
+// Отозвать токен доступа до окончания срока его действия
+func (r *AuthPostgres) RevokeToken(jti string, userId int, expiresAt time.Time) error {
+
+	query := fmt.Sprintf(`INSERT INTO %s (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`,
+		revokedTokensTable)
+
+	_, err := r.db.Exec(query, jti, userId, expiresAt)
+	return errors.Wrap(err, "db: revoke token")
+}
+
+func (r *AuthPostgres) IsTokenRevoked(jti string) (bool, error) {
+
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE jti = $1)`, revokedTokensTable)
+
+	var revoked bool
+	if err := r.db.Get(&revoked, query, jti); err != nil {
+		return false, errors.Wrap(err, "db: is token revoked")
+	}
+
+	return revoked, nil
+}
+
+// Удалить истёкшие токены обновления и записи об отозванных токенах доступа с истёкшим сроком
+func (r *AuthPostgres) PurgeSessions() (domain.SessionsPurged, error) {
+
+	var purged domain.SessionsPurged
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE expires_at < CURRENT_TIMESTAMP`, refreshTokensTable)
+	res, err := r.db.Exec(query)
+	if err != nil {
+		return purged, errors.Wrap(err, "db: purge refresh tokens")
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		purged.RefreshTokens = int(n)
+	}
+
+	query = fmt.Sprintf(`DELETE FROM %s WHERE expires_at < CURRENT_TIMESTAMP`, revokedTokensTable)
+	res, err = r.db.Exec(query)
+	if err != nil {
+		return purged, errors.Wrap(err, "db: purge revoked tokens")
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		purged.RevokedTokens = int(n)
+	}
+
+	return purged, nil
+}
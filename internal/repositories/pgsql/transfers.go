@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/formula"
+	"github.com/sirupsen/logrus"
+)
+
+type TransferPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewTransferPostgres(log *logrus.Logger, db *sqlx.DB) *TransferPostgres {
+	return &TransferPostgres{log: log, db: db}
+}
+
+// Ожидающая передача с истёкшим кодом показывается как expired
+const hubTransferColumns = `id, aquahub_id, from_account_id, to_account_id, new_aquahub_id, mode, code,
+	CASE WHEN status = 'pending' AND expires_at <= CURRENT_TIMESTAMP THEN 'expired' ELSE status END AS status,
+	created_by, accepted_by, cancelled_by, created_at, expires_at, completed_at`
+
+// ID аккаунта аквахаба, если хаб принадлежит аккаунту, в котором у пользователя есть право perm
+func (r *TransferPostgres) GetAquahubAccount_OfUser(userId, aquahubId int, perm domain.Permission) (int, error) {
+
+	query := fmt.Sprintf(`SELECT account_id FROM %s WHERE id = $1 AND archived_at IS NULL AND account_id IN (%s)`,
+		aquahubsTable, accountsQuery(2, perm))
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, aquahubId, userId); err != nil {
+		r.log.Errorf("db: error GetAquahubAccount Transfer: %s", err.Error())
+		return 0, errors.New("db: aquahub not found")
+	}
+
+	return accountId, nil
+}
+
+// Новая передача хаба; прежняя ожидающая передача хаба отменяется.
+// Конфликт по коду (код уже выдан другой передаче) возвращается ошибкой.
+func (r *TransferPostgres) Create(userId, accountId, aquahubId int, mode, code string, expiresAt time.Time) (int, error) {
+
+	queryCancel := fmt.Sprintf(`UPDATE %s SET status = 'cancelled', cancelled_by = $2 WHERE aquahub_id = $1 AND status = 'pending'`,
+		hubTransfersTable)
+
+	queryInsert := fmt.Sprintf(`INSERT INTO %s (aquahub_id, from_account_id, mode, code, created_by, expires_at)
+								VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, hubTransfersTable)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Create Transfer: %s", err.Error())
+		return 0, errors.New("db: error Create Transfer")
+	}
+	defer tx.Rollback()
+
+	var id int
+	if _, err = tx.Exec(queryCancel, aquahubId, userId); err == nil {
+		err = tx.Get(&id, queryInsert, aquahubId, accountId, mode, code, userId, expiresAt)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log.Errorf("db: error Create Transfer: %s", err.Error())
+		return 0, errors.New("db: error Create Transfer")
+	}
+
+	return id, nil
+}
+
+// Ожидающая передача хаба; nil, если её нет
+func (r *TransferPostgres) GetPending_OfAquahub(aquahubId int) (*domain.HubTransfer, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE aquahub_id = $1 AND status = 'pending'`, hubTransferColumns, hubTransfersTable)
+
+	var t domain.HubTransfer
+	err := r.db.Get(&t, query, aquahubId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.log.Errorf("db: error GetPending Transfer: %s", err.Error())
+		return nil, errors.New("db: error GetPending Transfer")
+	}
+
+	return &t, nil
+}
+
+func (r *TransferPostgres) Cancel(userId, aquahubId int) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = 'cancelled', cancelled_by = $2 WHERE aquahub_id = $1 AND status = 'pending'`,
+		hubTransfersTable)
+
+	res, err := r.db.Exec(query, aquahubId, userId)
+	if err != nil {
+		r.log.Errorf("db: error Cancel Transfer: %s", err.Error())
+		return errors.New("db: error Cancel Transfer")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: transfer not found")
+	}
+
+	return nil
+}
+
+// Журнал передач хабов из аккаунтов пользователя и в них, новые первыми
+func (r *TransferPostgres) GetAll_OfUser(userId int, f domain.HubTransfersFilter) ([]domain.HubTransfer, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s
+							WHERE (from_account_id IN (%s) OR to_account_id IN (%s))
+							AND ($2 = 0 OR from_account_id = $2 OR to_account_id = $2)
+							AND ($3 = 0 OR aquahub_id = $3 OR new_aquahub_id = $3)
+							ORDER BY created_at DESC, id DESC`,
+		hubTransferColumns, hubTransfersTable, userAccountsQuery(1), userAccountsQuery(1))
+
+	var list []domain.HubTransfer
+	if err := r.db.Select(&list, query, userId, f.AccountID, f.AquahubID); err != nil {
+		r.log.Errorf("db: error GetAll Transfer: %s", err.Error())
+		return nil, errors.New("db: error GetAll Transfer")
+	}
+
+	return list, nil
+}
+
+// Приём передачи по коду в аккаунт accountId пользователя - одной транзакцией.
+// hToken - новый токен хаба, archivedToken - токен архивной копии хаба (режим keep).
+func (r *TransferPostgres) Accept(userId, accountId int, code, hToken, archivedToken string) (*domain.HubTransfer, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: error Accept Transfer")
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE code = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP FOR UPDATE`,
+		hubTransferColumns, hubTransfersTable)
+
+	var t domain.HubTransfer
+	if err := tx.Get(&t, query, code); err != nil {
+		if err != sql.ErrNoRows {
+			r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		}
+		return nil, domain.ErrInvalidTransferCode
+	}
+
+	if err := checkAccount_OfUser(tx, userId, accountId, domain.PermissionManage); err != nil {
+		return nil, errors.New("db: account not found")
+	}
+	if accountId == t.FromAccountID {
+		return nil, domain.ErrTransferSameAccount
+	}
+
+	query = fmt.Sprintf(`SELECT h_token FROM %s WHERE id = $1 AND account_id = $2 AND archived_at IS NULL FOR UPDATE`,
+		aquahubsTable)
+
+	var oldToken string
+	if err := tx.Get(&oldToken, query, t.AquahubID, t.FromAccountID); err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: aquahub not found")
+	}
+
+	// Невыполненные команды прежнего аккаунта истекают
+	query = fmt.Sprintf(`UPDATE %s SET status = 'expired' WHERE aquahub_id = $1 AND status IN ('pending', 'delivered')`,
+		deviceCommandsTable)
+	if _, err := tx.Exec(query, t.AquahubID); err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: error Accept Transfer")
+	}
+
+	newHubId := t.AquahubID
+	if t.Mode == domain.TransferMove {
+		err = moveAquahub(tx, t.AquahubID, t.FromAccountID, accountId, hToken)
+	} else {
+		newHubId, err = copyAquahub(tx, t.AquahubID, accountId, hToken, archivedToken)
+	}
+	if err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: error Accept Transfer")
+	}
+
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{fmt.Sprintf(`UPDATE %s SET status = 'completed', to_account_id = $2, new_aquahub_id = $3, accepted_by = $4,
+						completed_at = CURRENT_TIMESTAMP WHERE id = $1`, hubTransfersTable),
+			[]interface{}{t.ID, accountId, newHubId, userId}},
+		{fmt.Sprintf(`DELETE FROM %s WHERE h_token = $1`, hubPairingsTable), []interface{}{oldToken}},
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q.query, q.args...); err != nil {
+			r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+			return nil, errors.New("db: error Accept Transfer")
+		}
+	}
+
+	query = fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, hubTransferColumns, hubTransfersTable)
+	if err := tx.Get(&t, query, t.ID); err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: error Accept Transfer")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Errorf("db: error Accept Transfer: %s", err.Error())
+		return nil, errors.New("db: error Accept Transfer")
+	}
+
+	return &t, nil
+}
+
+// Перенос хаба с устройствами, сенсорами и показаниями в аккаунт toAccountId;
+// правила оповещений, выгрузки в InfluxDB, теги, группа и типы устройств из шаблонов прежнего аккаунта снимаются.
+// Правила автоматизации прежнего аккаунта с сенсорами или устройствами хаба выключаются,
+// виртуальные сенсоры, формулы которых связывают хаб с остальными сенсорами прежнего аккаунта, переносятся в архив.
+func moveAquahub(tx *sqlx.Tx, aquahubId, fromAccountId, toAccountId int, hToken string) error {
+	devices := fmt.Sprintf(`SELECT id FROM %s WHERE aquahub_id = $1`, devicesTable)
+	sensors := fmt.Sprintf(`SELECT id FROM %s WHERE device_id IN (%s)`, sensorsTable, devices)
+
+	if err := archiveSplitVirtualSensors(tx, aquahubId, fromAccountId); err != nil {
+		return err
+	}
+
+	queryHub := fmt.Sprintf(`UPDATE %s SET account_id = $3, h_token = $4, group_id = NULL, reset_requested_at = NULL,
+								updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND account_id = $2`, aquahubsTable)
+	queryData := fmt.Sprintf(`UPDATE %s SET account_id = $3 WHERE aquahub_id = $1 AND account_id = $2`, sensorDataSetTable)
+
+	if _, err := tx.Exec(queryHub, aquahubId, fromAccountId, toAccountId, hToken); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(queryData, aquahubId, fromAccountId, toAccountId); err != nil {
+		return err
+	}
+
+	queries := []string{
+		fmt.Sprintf(`DELETE FROM %s WHERE account_id = $2 AND sensor_id IN (%s)`, alertRulesTable, sensors),
+		fmt.Sprintf(`DELETE FROM %s WHERE account_id = $2 AND sensor_id IN (%s)`, alertEventsTable, sensors),
+		fmt.Sprintf(`DELETE FROM %s WHERE account_id = $2 AND sensor_id IN (%s)`, influxMappingsTable, sensors),
+		fmt.Sprintf(`DELETE FROM %s WHERE account_id = $2 AND ((entity = '%s' AND entity_id = $1)
+						OR (entity = '%s' AND entity_id IN (%s)) OR (entity = '%s' AND entity_id IN (%s)))`,
+			entityTagsTable, domain.TagAquahub, domain.TagDevice, devices, domain.TagSensor, sensors),
+		fmt.Sprintf(`UPDATE %s SET device_type_id = NULL WHERE aquahub_id = $1
+						AND device_type_id IN (SELECT id FROM %s WHERE account_id = $2)`, devicesTable, deviceTypesTable),
+		fmt.Sprintf(`UPDATE %s SET enabled = false, state = '{}', checked_at = NULL, updated_at = CURRENT_TIMESTAMP
+						WHERE account_id = $2 AND enabled AND (
+							EXISTS (SELECT 1 FROM jsonb_array_elements(conditions) c WHERE (c->>'sensor_id')::int IN (%s))
+							OR EXISTS (SELECT 1 FROM jsonb_array_elements(actions) x WHERE (x->>'device_id')::int IN (%s)))`,
+			automationsTable, sensors, devices),
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q, aquahubId, fromAccountId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Виртуальные сенсоры прежнего аккаунта, которые после передачи ссылались бы на сенсоры другого аккаунта:
+// сенсоры хаба с формулами по остальным сенсорам аккаунта, сенсоры аккаунта с формулами по сенсорам хаба
+// и зависящие от них виртуальные сенсоры. Они переносятся в архив и перестают вычисляться.
+func archiveSplitVirtualSensors(tx *sqlx.Tx, aquahubId, fromAccountId int) error {
+
+	query := fmt.Sprintf(`SELECT s.id FROM %s s INNER JOIN %s dt ON dt.id = s.device_id WHERE dt.aquahub_id = $1`,
+		sensorsTable, devicesTable)
+
+	var moved []int
+	if err := tx.Select(&moved, query, aquahubId); err != nil {
+		return err
+	}
+	onHub := make(map[int]bool, len(moved))
+	for _, id := range moved {
+		onHub[id] = true
+	}
+
+	query = selectVirtualSensors() + ` AND aht.account_id = $1 AND s.archived_at IS NULL ORDER BY s.id`
+
+	var list []domain.VirtualSensor
+	if err := tx.Select(&list, query, fromAccountId); err != nil {
+		return err
+	}
+
+	refs := make(map[int][]int, len(list))
+	for _, vs := range list {
+		if expr, err := formula.Parse(vs.Formula); err == nil {
+			refs[vs.ID] = expr.Refs()
+		}
+	}
+
+	split := make(map[int]bool)
+	for changed := true; changed; {
+		changed = false
+		for id, ids := range refs {
+			if split[id] {
+				continue
+			}
+			for _, ref := range ids {
+				if onHub[ref] != onHub[id] || split[ref] {
+					split[id] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	if len(split) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(split))
+	for id := range split {
+		ids = append(ids, id)
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET archived_at = CURRENT_TIMESTAMP WHERE id = ANY($1)`, sensorsTable)
+	_, err := tx.Exec(query, pq.Array(ids))
+	return err
+}
+
+// Хаб с данными остаётся в архиве прежнего аккаунта с токеном archivedToken,
+// в аккаунте toAccountId создаётся хаб с токеном hToken и теми же устройствами и сенсорами (без вычисляемых)
+func copyAquahub(tx *sqlx.Tx, aquahubId, toAccountId int, hToken, archivedToken string) (int, error) {
+
+	query := fmt.Sprintf(`UPDATE %s SET h_token = $2, status = 'archived', archived_at = CURRENT_TIMESTAMP,
+							updated_at = CURRENT_TIMESTAMP, reset_requested_at = NULL WHERE id = $1`, aquahubsTable)
+	if _, err := tx.Exec(query, aquahubId, archivedToken); err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (account_id, h_token, title, description, status, updated_at,
+								country, postal_code, place_name, state_name, latitude, longitude, timezone)
+							SELECT $2, $3, title, description, 'active', CURRENT_TIMESTAMP,
+								country, postal_code, place_name, state_name, latitude, longitude, timezone
+							FROM %s WHERE id = $1
+							RETURNING id`, aquahubsTable, aquahubsTable)
+
+	var newHubId int
+	if err := tx.Get(&newHubId, query, aquahubId, toAccountId, hToken); err != nil {
+		return 0, err
+	}
+
+	query = fmt.Sprintf(`SELECT id FROM %s WHERE aquahub_id = $1 ORDER BY id`, devicesTable)
+
+	var devices []int
+	if err := tx.Select(&devices, query, aquahubId); err != nil {
+		return 0, err
+	}
+
+	// Тип устройства переносится, если он из общего каталога
+	queryDevice := fmt.Sprintf(`INSERT INTO %s (aquahub_id, local_id, title, description, status, device_type_id)
+								SELECT $2, local_id, title, description, status,
+									(SELECT t.id FROM %s t WHERE t.id = device_type_id AND t.account_id IS NULL)
+								FROM %s WHERE id = $1
+								RETURNING id`, devicesTable, deviceTypesTable, devicesTable)
+
+	querySensors := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description, for_engineer, for_analytics,
+									report_interval_sec, unit, data_type)
+								SELECT $2, local_id, title, description, for_engineer, for_analytics, report_interval_sec, unit, data_type
+								FROM %s WHERE device_id = $1 AND formula IS NULL ORDER BY id`, sensorsTable, sensorsTable)
+
+	for _, deviceId := range devices {
+		var newDeviceId int
+		if err := tx.Get(&newDeviceId, queryDevice, deviceId, newHubId); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(querySensors, deviceId, newDeviceId); err != nil {
+			return 0, err
+		}
+	}
+
+	return newHubId, nil
+}
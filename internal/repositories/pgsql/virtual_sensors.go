@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type VirtualSensorPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewVirtualSensorPostgres(log *logrus.Logger, db *sqlx.DB) *VirtualSensorPostgres {
+	return &VirtualSensorPostgres{log: log, db: db}
+}
+
+// Общая часть выборки виртуальных сенсоров вместе с ID аквахаба и аккаунта
+func selectVirtualSensors() string {
+	return fmt.Sprintf(`SELECT s.id, aht.account_id, aht.id AS aquahub_id, s.device_id, s.local_id,
+							COALESCE(s.title, '') AS title, COALESCE(s.description, '') AS description, s.formula, s.archived_at
+						FROM %s s
+						INNER JOIN %s dt ON dt.id = s.device_id
+						INNER JOIN %s aht ON aht.id = dt.aquahub_id
+						WHERE s.formula IS NOT NULL`,
+		sensorsTable, devicesTable, aquahubsTable)
+}
+
+// Устройство аккаунта, которым управляет пользователь: возвращает ID аккаунта и аквахаба, к которым принадлежит устройство
+func (r *VirtualSensorPostgres) GetDevice_OfUser(userId, deviceId int) (domain.VirtualSensor, error) {
+
+	query := fmt.Sprintf(`SELECT aht.account_id, aht.id AS aquahub_id, dt.id AS device_id
+							FROM %s dt
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE dt.id = $1 AND aht.account_id IN (%s)`,
+		devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	var device domain.VirtualSensor
+	if err := r.db.Get(&device, query, deviceId, userId); err != nil {
+		r.log.Errorf("db: error GetDevice VirtualSensor: %s", err.Error())
+		return device, errors.New("db: device not found")
+	}
+
+	return device, nil
+}
+
+// Из списка ID сенсоров возвращает те, что принадлежат аккаунту
+func (r *VirtualSensorPostgres) GetSensorIds_OfAccount(accountId int, sensorIds []int) ([]int, error) {
+
+	query := fmt.Sprintf(`SELECT s.id
+							FROM %s s
+							INNER JOIN %s dt ON dt.id = s.device_id
+							INNER JOIN %s aht ON aht.id = dt.aquahub_id
+							WHERE aht.account_id = $1 AND s.id = ANY($2)`,
+		sensorsTable, devicesTable, aquahubsTable)
+
+	var ids []int
+	if err := r.db.Select(&ids, query, accountId, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetSensorIds VirtualSensor: %s", err.Error())
+		return nil, errors.New("db: error GetSensorIds VirtualSensor")
+	}
+
+	return ids, nil
+}
+
+func (r *VirtualSensorPostgres) Create(deviceId int, input domain.CreateVirtualSensor) (int, error) {
+
+	description := ""
+	if input.Description != nil {
+		description = *input.Description
+	}
+
+	// local_id = -1: хаб никогда не присылает данные виртуальных сенсоров
+	query := fmt.Sprintf(`INSERT INTO %s (device_id, local_id, title, description, formula, for_engineer, for_analytics)
+							VALUES ($1, -1, $2, $3, $4, true, true) RETURNING id`, sensorsTable)
+
+	var id int
+	if err := r.db.Get(&id, query, deviceId, *input.Title, description, input.Formula); err != nil {
+		r.log.Errorf("db: error Create VirtualSensor: %s", err.Error())
+		return 0, errors.New("db: error Create VirtualSensor")
+	}
+
+	return id, nil
+}
+
+func (r *VirtualSensorPostgres) GetAll_OfUser(userId int) ([]domain.VirtualSensor, error) {
+
+	query := selectVirtualSensors() + fmt.Sprintf(` AND aht.account_id IN (%s) ORDER BY s.id`, userAccountsQuery(1))
+
+	var list []domain.VirtualSensor
+	if err := r.db.Select(&list, query, userId); err != nil {
+		r.log.Errorf("db: error GetAll VirtualSensor: %s", err.Error())
+		return nil, errors.New("db: error GetAll VirtualSensor")
+	}
+
+	return list, nil
+}
+
+func (r *VirtualSensorPostgres) GetAll_OfAccount(accountId int) ([]domain.VirtualSensor, error) {
+
+	query := selectVirtualSensors() + ` AND aht.account_id = $1 ORDER BY s.id`
+
+	var list []domain.VirtualSensor
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetAll_OfAccount VirtualSensor: %s", err.Error())
+		return nil, errors.New("db: error GetAll VirtualSensor")
+	}
+
+	return list, nil
+}
+
+func (r *VirtualSensorPostgres) GetById(userId, id int) (*domain.VirtualSensor, error) {
+
+	query := selectVirtualSensors() + fmt.Sprintf(` AND s.id = $1 AND aht.account_id IN (%s)`, userAccountsQuery(2))
+
+	var sensor domain.VirtualSensor
+	if err := r.db.Get(&sensor, query, id, userId); err != nil {
+		r.log.Errorf("db: error GetById VirtualSensor: %s", err.Error())
+		return nil, errors.New("db: error GetById VirtualSensor")
+	}
+
+	return &sensor, nil
+}
+
+func (r *VirtualSensorPostgres) Update(userId int, input domain.UpdateVirtualSensor) error {
+
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Title != nil {
+		setValues = append(setValues, fmt.Sprintf("title=$%d", argId))
+		args = append(args, *input.Title)
+		argId++
+	}
+
+	if input.Description != nil {
+		setValues = append(setValues, fmt.Sprintf("description=$%d", argId))
+		args = append(args, *input.Description)
+		argId++
+	}
+
+	if input.Formula != nil {
+		setValues = append(setValues, fmt.Sprintf("formula=$%d", argId))
+		args = append(args, *input.Formula)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf(`UPDATE %s s SET %s FROM %s dt, %s aht
+							WHERE s.device_id = dt.id AND dt.aquahub_id = aht.id AND s.formula IS NOT NULL
+							AND s.id = $%d AND aht.account_id IN (%s) RETURNING s.id`,
+		sensorsTable, setQuery, devicesTable, aquahubsTable, argId, managedAccountsQuery(argId+1))
+	args = append(args, input.ID, userId)
+
+	var id int
+	if err := r.db.Get(&id, query, args...); err != nil {
+		r.log.Errorf("db: error Update VirtualSensor: %s", err.Error())
+		return errors.New("db: error Update VirtualSensor")
+	}
+
+	return nil
+}
+
+func (r *VirtualSensorPostgres) Delete(userId, id int) error {
+
+	// Вычисленные показания удаляются вместе с сенсором
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error Delete VirtualSensor: %s", err.Error())
+		return errors.New("db: error Delete VirtualSensor")
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s s USING %s dt, %s aht
+							WHERE s.device_id = dt.id AND dt.aquahub_id = aht.id AND s.formula IS NOT NULL
+							AND s.id = $1 AND aht.account_id IN (%s) RETURNING s.id`,
+		sensorsTable, devicesTable, aquahubsTable, managedAccountsQuery(2))
+
+	queryData := fmt.Sprintf(`DELETE FROM %s WHERE sensor_id = $1`, sensorDataSetTable)
+
+	var deleted int
+	if _, err = tx.Exec(queryData, id); err == nil {
+		err = tx.Get(&deleted, query, id, userId)
+	}
+	if err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error Delete VirtualSensor: %s", err.Error())
+		return errors.New("db: error Delete VirtualSensor")
+	}
+
+	return tx.Commit()
+}
+
+// Последние сохранённые значения сенсоров, полученные аккаунтом (ID сенсора => значение)
+func (r *VirtualSensorPostgres) GetLatestValues(accountId int, sensorIds []int) (map[int]string, error) {
+
+	query := fmt.Sprintf(`SELECT DISTINCT ON (sensor_id) sensor_id, value
+							FROM %s WHERE account_id = $1 AND sensor_id = ANY($2)
+							ORDER BY sensor_id, created_at DESC`, sensorDataSetTable)
+
+	var rows []domain.SensorDataSet
+	if err := r.db.Select(&rows, query, accountId, pq.Array(sensorIds)); err != nil {
+		r.log.Errorf("db: error GetLatestValues VirtualSensor: %s", err.Error())
+		return nil, errors.New("db: error GetLatestValues")
+	}
+
+	values := make(map[int]string, len(rows))
+	for _, row := range rows {
+		values[row.Sensor_id] = row.Value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookPostgres struct {
+	db  *sqlx.DB
+	log *logrus.Logger
+}
+
+func NewWebhookPostgres(log *logrus.Logger, db *sqlx.DB) *WebhookPostgres {
+	return &WebhookPostgres{log: log, db: db}
+}
+
+const webhookColumns = `id, account_id, url, secret, events, description, enabled, created_at, updated_at`
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.account_id, d.event, d.event_id, d.payload, d.status, d.attempts,
+							d.next_attempt_at, d.response_status, d.last_error, d.created_at, d.delivered_at`
+
+func (r *WebhookPostgres) CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error {
+	if err := checkAccount_OfUser(r.db, userId, accountId, perm); err != nil {
+		r.log.Errorf("db: error CheckAccount Webhook: %s", err.Error())
+		return errors.New("db: account not found")
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) Create(w domain.Webhook) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (account_id, url, secret, events, description, enabled)
+							SELECT $1, $2, $3, $4, $5, $6 WHERE (SELECT count(*) FROM %s WHERE account_id = $1) < $7
+							RETURNING id`, webhooksTable, webhooksTable)
+
+	var id int
+	err := r.db.Get(&id, query, w.AccountID, w.URL, w.Secret, w.Events, w.Description, w.Enabled, domain.MaxWebhooksOfAccount)
+	if err != nil {
+		r.log.Errorf("db: error Create Webhook: %s", err.Error())
+		return 0, errors.New("db: error Create Webhook (limit of webhooks reached?)")
+	}
+
+	return id, nil
+}
+
+func (r *WebhookPostgres) GetAll_OfAccount(accountId int) ([]domain.Webhook, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE account_id = $1 ORDER BY id`, webhookColumns, webhooksTable)
+
+	var list []domain.Webhook
+	if err := r.db.Select(&list, query, accountId); err != nil {
+		r.log.Errorf("db: error GetAll Webhook: %s", err.Error())
+		return nil, errors.New("db: error GetAll Webhook")
+	}
+
+	return list, nil
+}
+
+func (r *WebhookPostgres) GetById(accountId, id int) (*domain.Webhook, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 AND account_id = $2`, webhookColumns, webhooksTable)
+
+	var w domain.Webhook
+	if err := r.db.Get(&w, query, id, accountId); err != nil {
+		r.log.Errorf("db: error GetById Webhook: %s", err.Error())
+		return nil, errors.New("db: webhook not found")
+	}
+
+	return &w, nil
+}
+
+func (r *WebhookPostgres) Update(w domain.Webhook) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET url = :url, events = :events, description = :description, enabled = :enabled,
+								updated_at = CURRENT_TIMESTAMP
+							WHERE id = :id AND account_id = :account_id`, webhooksTable)
+
+	res, err := r.db.NamedExec(query, w)
+	if err != nil {
+		r.log.Errorf("db: error Update Webhook: %s", err.Error())
+		return errors.New("db: error Update Webhook")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: webhook not found")
+	}
+
+	return nil
+}
+
+func (r *WebhookPostgres) Delete(accountId, id int) error {
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND account_id = $2`, webhooksTable)
+
+	res, err := r.db.Exec(query, id, accountId)
+	if err != nil {
+		r.log.Errorf("db: error Delete Webhook: %s", err.Error())
+		return errors.New("db: error Delete Webhook")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("db: webhook not found")
+	}
+
+	return nil
+}
+
+// ID аккаунта хаба
+func (r *WebhookPostgres) GetAquahubAccount(aquahubId int) (int, error) {
+
+	query := fmt.Sprintf(`SELECT account_id FROM %s WHERE id = $1`, aquahubsTable)
+
+	var accountId int
+	if err := r.db.Get(&accountId, query, aquahubId); err != nil {
+		r.log.Errorf("db: error GetAquahubAccount Webhook: %s", err.Error())
+		return 0, errors.New("db: aquahub not found")
+	}
+
+	return accountId, nil
+}
+
+// Чек-лист, если все его активные пункты выполнены
+func (r *WebhookPostgres) GetCompletedChecklist(checklistId int) (*domain.WebhookChecklist, error) {
+
+	query := fmt.Sprintf(`SELECT c.id, c.account_id, c.template_id, c.title FROM %s c
+							WHERE c.id = $1
+								AND EXISTS (SELECT 1 FROM %s i WHERE i.checklist_id = c.id AND i.archived_at IS NULL)
+								AND NOT EXISTS (SELECT 1 FROM %s i WHERE i.checklist_id = c.id AND i.archived_at IS NULL AND NOT i.done)`,
+		checklistsTable, checklistItemsTable, checklistItemsTable)
+
+	var list []domain.WebhookChecklist
+	if err := r.db.Select(&list, query, checklistId); err != nil {
+		r.log.Errorf("db: error GetCompletedChecklist Webhook: %s", err.Error())
+		return nil, errors.New("db: error GetCompletedChecklist Webhook")
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return &list[0], nil
+}
+
+// Отмечает хабы без показаний дольше after отключёнными и возвращает их;
+// хабы, от которых снова пришли показания, снова считаются подключёнными
+func (r *WebhookPostgres) SetHubsOffline(after time.Duration) ([]domain.WebhookHub, error) {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error SetHubsOffline Webhook: %s", err.Error())
+		return nil, errors.New("db: error SetHubsOffline Webhook")
+	}
+
+	online := fmt.Sprintf(`UPDATE %s aht SET offline_at = NULL
+							WHERE aht.offline_at IS NOT NULL
+								AND EXISTS (SELECT 1 FROM %s sd WHERE sd.aquahub_id = aht.id AND sd.created_at > aht.offline_at)`,
+		aquahubsTable, sensorDataSetTable)
+
+	if _, err = tx.Exec(online); err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error SetHubsOffline Webhook: %s", err.Error())
+		return nil, errors.New("db: error SetHubsOffline Webhook")
+	}
+
+	offline := fmt.Sprintf(`UPDATE %s aht SET offline_at = CURRENT_TIMESTAMP
+							FROM (
+								SELECT a.id, (SELECT max(sd.created_at) FROM %s sd WHERE sd.aquahub_id = a.id) AS last_seen_at
+								FROM %s a WHERE a.offline_at IS NULL
+							) seen
+							WHERE aht.id = seen.id AND seen.last_seen_at < CURRENT_TIMESTAMP - $1 * interval '1 second'
+							RETURNING aht.id, aht.account_id, seen.last_seen_at`,
+		aquahubsTable, sensorDataSetTable, aquahubsTable)
+
+	var list []domain.WebhookHub
+	if err = tx.Select(&list, offline, after.Seconds()); err != nil {
+		tx.Rollback()
+		r.log.Errorf("db: error SetHubsOffline Webhook: %s", err.Error())
+		return nil, errors.New("db: error SetHubsOffline Webhook")
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.log.Errorf("db: error SetHubsOffline Webhook: %s", err.Error())
+		return nil, errors.New("db: error SetHubsOffline Webhook")
+	}
+
+	return list, nil
+}
+
+func (r *WebhookPostgres) CreateDeliveries(list []domain.WebhookDelivery) error {
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		r.log.Errorf("db: error CreateDeliveries Webhook: %s", err.Error())
+		return errors.New("db: error CreateDeliveries Webhook")
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (webhook_id, account_id, event, event_id, payload)
+							VALUES (:webhook_id, :account_id, :event, :event_id, :payload)`, webhookDeliveriesTable)
+
+	for _, d := range list {
+		if _, err = tx.NamedExec(query, d); err != nil {
+			tx.Rollback()
+			r.log.Errorf("db: error CreateDeliveries Webhook: %s", err.Error())
+			return errors.New("db: error CreateDeliveries Webhook")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Забирает до limit доставок, время которых подошло, и откладывает их на lease,
+// чтобы параллельный запуск задачи не отправил их повторно; адрес и секрет берутся из подписки
+func (r *WebhookPostgres) ClaimDue(limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+
+	query := fmt.Sprintf(`WITH claimed AS (
+								UPDATE %s SET next_attempt_at = CURRENT_TIMESTAMP + $2 * interval '1 second'
+								WHERE id IN (
+									SELECT id FROM %s WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+									ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+								) RETURNING *
+							)
+							SELECT %s, w.url, w.secret, w.enabled
+							FROM claimed d INNER JOIN %s w ON w.id = d.webhook_id
+							ORDER BY d.id`,
+		webhookDeliveriesTable, webhookDeliveriesTable, webhookDeliveryColumns, webhooksTable)
+
+	var list []domain.WebhookDelivery
+	if err := r.db.Select(&list, query, limit, lease.Seconds()); err != nil {
+		r.log.Errorf("db: error ClaimDue Webhook: %s", err.Error())
+		return nil, errors.New("db: error ClaimDue Webhook")
+	}
+
+	return list, nil
+}
+
+func (r *WebhookPostgres) UpdateDelivery(d domain.WebhookDelivery) error {
+
+	query := fmt.Sprintf(`UPDATE %s SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+								response_status = :response_status, last_error = :last_error, delivered_at = :delivered_at
+							WHERE id = :id`, webhookDeliveriesTable)
+
+	if _, err := r.db.NamedExec(query, d); err != nil {
+		r.log.Errorf("db: error UpdateDelivery Webhook: %s", err.Error())
+		return errors.New("db: error UpdateDelivery Webhook")
+	}
+
+	return nil
+}
+
+// Журнал доставки подписки; status = "" - все статусы
+func (r *WebhookPostgres) GetDeliveries(accountId, webhookId int, status string, from, to time.Time) ([]domain.WebhookDelivery, error) {
+
+	query := fmt.Sprintf(`SELECT %s FROM %s d
+							WHERE d.webhook_id = $1 AND d.account_id = $2 AND d.created_at >= $3 AND d.created_at < $4
+								AND ($5 = '' OR d.status = $5)
+							ORDER BY d.created_at DESC, d.id DESC`,
+		webhookDeliveryColumns, webhookDeliveriesTable)
+
+	var list []domain.WebhookDelivery
+	if err := r.db.Select(&list, query, webhookId, accountId, from, to, status); err != nil {
+		r.log.Errorf("db: error GetDeliveries Webhook: %s", err.Error())
+		return nil, errors.New("db: error GetDeliveries Webhook")
+	}
+
+	return list, nil
+}
+
+// Повторная доставка: новая запись очереди с тем же событием, его ID и телом
+func (r *WebhookPostgres) Redeliver(accountId, webhookId, deliveryId int) (int, error) {
+
+	query := fmt.Sprintf(`INSERT INTO %s (webhook_id, account_id, event, event_id, payload)
+							SELECT webhook_id, account_id, event, event_id, payload FROM %s
+							WHERE id = $1 AND webhook_id = $2 AND account_id = $3
+							RETURNING id`, webhookDeliveriesTable, webhookDeliveriesTable)
+
+	var id int
+	if err := r.db.Get(&id, query, deliveryId, webhookId, accountId); err != nil {
+		r.log.Errorf("db: error Redeliver Webhook: %s", err.Error())
+		return 0, errors.New("db: webhook delivery not found")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Права доступа по ролям участников аккаунта: менеджер управляет хабами и участниками,
+// пользователь только просматривает данные аккаунта
+
+type AccessService struct {
+	repo IStoreAccess
+	log  *logrus.Logger
+}
+
+func NewAccessService(log *logrus.Logger, repo IStoreAccess) *AccessService {
+	return &AccessService{repo: repo, log: log}
+}
+
+// Authorize - у пользователя есть право perm в аккаунте accountId
+func (s *AccessService) Authorize(userId, accountId int, perm domain.Permission) error {
+	roles, err := s.repo.GetRoles_OfUser(userId, accountId)
+	if err != nil {
+		return err
+	}
+	if !roles.Can(perm) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeAny - у пользователя есть право perm хотя бы в одном аккаунте
+func (s *AccessService) AuthorizeAny(userId int, perm domain.Permission) error {
+	ok, err := s.repo.HasPermission(userId, perm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func (s *AccessService) GetMembers(userId, accountId int) ([]domain.AccountMember, error) {
+	if err := s.Authorize(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(accountId)
+}
+
+// UpdateMember меняет роли и статус участника; последний менеджер аккаунта остаётся менеджером
+func (s *AccessService) UpdateMember(userId, accountId, memberId int, input domain.UpdateAccountMember) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if err := s.Authorize(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+	return s.repo.UpdateMember(accountId, memberId, input)
+}
+
+// RemoveMember исключает участника из аккаунта. Участник без роли менеджера может выйти из аккаунта сам;
+// последний менеджер аккаунта не исключается
+func (s *AccessService) RemoveMember(userId, accountId, memberId int) error {
+	if err := s.Authorize(userId, accountId, domain.PermissionManage); err != nil {
+		if !errors.Is(err, domain.ErrForbidden) || userId != memberId {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(accountId, memberId)
+}
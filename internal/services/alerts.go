@@ -0,0 +1,590 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/alerting"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис правил оповещения: правила проверяются при приёме показаний,
+// правила "нет данных" - плановой задачей. О событиях уведомляются участники аккаунта.
+// Срабатывание правила открывает экземпляр оповещения, который можно подтвердить, отложить или закрыть;
+// о неподтверждённых оповещениях плановая задача напоминает с интервалом правила.
+
+type AlertService struct {
+	repo     IStoreAlert
+	cache    domain.Cache
+	log      *logrus.Logger
+	notify   *NotificationService
+	webhooks *WebhookService
+}
+
+func NewAlertService(log *logrus.Logger, cache domain.Cache, repo IStoreAlert, notify *NotificationService,
+	webhooks *WebhookService) *AlertService {
+	return &AlertService{log: log, cache: cache, repo: repo, notify: notify, webhooks: webhooks}
+}
+
+// В кеше по сенсору хранится признак наличия включённых правил
+func alertRulesCacheKey(sensorId int) string {
+	return fmt.Sprintf("alert-rules-%d", sensorId)
+}
+
+func (s *AlertService) Create(userId int, input domain.CreateAlertRule) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	accountId, err := s.repo.GetSensorAccount_OfUser(userId, input.SensorID, domain.PermissionManage)
+	if err != nil {
+		return 0, err
+	}
+
+	rule := input.Rule()
+	rule.AccountID = accountId
+
+	id, err := s.repo.Create(rule)
+	if err != nil {
+		return 0, err
+	}
+
+	s.resetCache(input.SensorID)
+	return id, nil
+}
+
+func (s *AlertService) GetAll(userId, sensorId int, filter domain.GroupFilter) ([]domain.AlertRule, error) {
+	return s.repo.GetAll_OfUser(userId, sensorId, filter)
+}
+
+func (s *AlertService) GetById(userId, id int) (*domain.AlertRule, error) {
+	return s.repo.GetById(userId, id)
+}
+
+func (s *AlertService) Update(userId int, input domain.UpdateAlertRule) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	rule, err := s.repo.GetById(userId, input.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, rule.SensorID, domain.PermissionManage); err != nil {
+		return domain.ErrForbidden
+	}
+
+	updated := input.Apply(*rule)
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(updated); err != nil {
+		return err
+	}
+
+	s.resetCache(rule.SensorID)
+	return nil
+}
+
+func (s *AlertService) Delete(userId, id int) error {
+	rule, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userId, id); err != nil {
+		return err
+	}
+
+	s.resetCache(rule.SensorID)
+	return nil
+}
+
+func (s *AlertService) GetEvents(userId int, filter domain.AlertEventsFilter) ([]domain.AlertEvent, error) {
+	if !filter.To.After(filter.From) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetEvents_OfUser(userId, filter)
+}
+
+func (s *AlertService) resetCache(sensorId int) {
+	if s.cache != nil {
+		s.cache.Delete(alertRulesCacheKey(sensorId))
+	}
+}
+
+// Включённые правила сенсоров; сенсоры без правил запоминаются в кеше, чтобы не ходить в БД на каждое показание
+func (s *AlertService) getRules(sensorIds []int) ([]domain.AlertRule, error) {
+
+	var ids []int
+	for _, id := range sensorIds {
+		if s.cache != nil {
+			if has, err := s.cache.Get(alertRulesCacheKey(id)); err == nil && !has.(bool) {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rules, err := s.repo.GetEnabled_OfSensors(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		for _, id := range ids {
+			has := false
+			for _, r := range rules {
+				if r.SensorID == id {
+					has = true
+					break
+				}
+			}
+			s.cache.Set(alertRulesCacheKey(id), has)
+		}
+	}
+
+	return rules, nil
+}
+
+func alertingRule(r domain.AlertRule) alerting.Rule {
+	rule := alerting.Rule{
+		Kind:        r.Kind,
+		Hysteresis:  r.Hysteresis,
+		MinDuration: time.Duration(r.MinDurationSec) * time.Second,
+		NoData:      time.Duration(r.NoDataSec) * time.Second,
+	}
+	if r.ThresholdLow != nil {
+		rule.Low = *r.ThresholdLow
+	}
+	if r.ThresholdHigh != nil {
+		rule.High = *r.ThresholdHigh
+	}
+	return rule
+}
+
+func alertingState(r domain.AlertRule) alerting.State {
+	st := alerting.State{Status: r.State}
+	if r.PendingSince != nil {
+		st.PendingSince = *r.PendingSince
+	}
+	return st
+}
+
+// Применение нового состояния к правилу
+func setAlertState(r *domain.AlertRule, st alerting.State, at time.Time) {
+	var pendingSince *time.Time
+	if st.Status != alerting.StatusOK {
+		since := st.PendingSince
+		pendingSince = &since
+	}
+
+	if r.State != st.Status {
+		r.StateChangedAt = at
+	}
+	r.State = st.Status
+	r.PendingSince = pendingSince
+}
+
+func alertTitle(r domain.AlertRule) string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return fmt.Sprintf("Sensor %d", r.SensorID)
+}
+
+func alertMessage(r domain.AlertRule, kind, value string) string {
+	title := alertTitle(r)
+
+	if kind == domain.AlertEventResolved {
+		if r.Kind == domain.AlertNoData {
+			return fmt.Sprintf("%s: data received again", title)
+		}
+		return fmt.Sprintf("%s: %s is back to normal", title, value)
+	}
+
+	switch r.Kind {
+	case domain.AlertAbove:
+		return fmt.Sprintf("%s: %s is above %g", title, value, *r.ThresholdHigh)
+	case domain.AlertBelow:
+		return fmt.Sprintf("%s: %s is below %g", title, value, *r.ThresholdLow)
+	case domain.AlertOutside:
+		return fmt.Sprintf("%s: %s is outside %g..%g", title, value, *r.ThresholdLow, *r.ThresholdHigh)
+	default:
+		return fmt.Sprintf("%s: no data for %s", title, time.Duration(r.NoDataSec)*time.Second)
+	}
+}
+
+func newAlertEvent(r domain.AlertRule, tr alerting.Transition, value *string, at time.Time) domain.AlertEvent {
+	kind := domain.AlertEventFiring
+	if tr == alerting.Resolved {
+		kind = domain.AlertEventResolved
+	}
+
+	v := ""
+	if value != nil {
+		v = *value
+	}
+
+	return domain.AlertEvent{
+		RuleID:    r.ID,
+		AccountID: r.AccountID,
+		SensorID:  r.SensorID,
+		Kind:      kind,
+		Value:     value,
+		Message:   alertMessage(r, kind, v),
+		CreatedAt: at,
+	}
+}
+
+// Evaluate прогоняет сохранённые показания через правила их сенсоров и записывает
+// изменившиеся состояния и события. Показания старше уже обработанных правилом пропускаются,
+// пороговые правила не проверяют нечисловые значения.
+func (s *AlertService) Evaluate(list []domain.SensorDataSet) {
+
+	var ids []int
+	for _, x := range list {
+		if !containsInt(ids, x.Sensor_id) {
+			ids = append(ids, x.Sensor_id)
+		}
+	}
+
+	rules, err := s.getRules(ids)
+	if err != nil {
+		s.log.Errorf("alert rules: %s", err.Error())
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return list[order[a]].CreatedAt.Before(list[order[b]].CreatedAt) })
+
+	var changed []domain.AlertRule
+	var events []domain.AlertEvent
+
+	for _, r := range rules {
+		rule := alertingRule(r)
+		touched := false
+
+		for _, i := range order {
+			x := list[i]
+			if x.Sensor_id != r.SensorID {
+				continue
+			}
+			if r.LastReadingAt != nil && !x.CreatedAt.After(*r.LastReadingAt) {
+				continue
+			}
+
+			v, ok := parseValue(x.Value)
+			if !ok && r.Kind != domain.AlertNoData {
+				continue
+			}
+
+			st, tr := alerting.Evaluate(rule, alertingState(r), v, x.CreatedAt)
+			setAlertState(&r, st, x.CreatedAt)
+			at := x.CreatedAt
+			r.LastReadingAt = &at
+			touched = true
+
+			if tr != alerting.None {
+				value := x.Value
+				events = append(events, newAlertEvent(r, tr, &value, x.CreatedAt))
+			}
+		}
+
+		if touched {
+			changed = append(changed, r)
+		}
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := s.save(changed, events); err != nil {
+		s.log.Errorf("alert rules: %s", err.Error())
+	}
+}
+
+// RunNoData - плановая задача: срабатывание правил "нет данных" по сенсорам, от которых давно нет показаний
+func (s *AlertService) RunNoData(ctx context.Context) {
+
+	rules, err := s.repo.GetNoData_NotFiring()
+	if err != nil {
+		s.log.Errorf("alert no-data job: %s", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+
+	var changed []domain.AlertRule
+	var events []domain.AlertEvent
+	for _, r := range rules {
+		if ctx.Err() != nil {
+			return
+		}
+		if r.LastReadingAt == nil {
+			continue
+		}
+
+		st, tr := alerting.EvaluateNoData(alertingRule(r), alertingState(r), *r.LastReadingAt, now)
+		if tr == alerting.None {
+			continue
+		}
+
+		setAlertState(&r, st, now)
+		r.LastReadingAt = nil
+		changed = append(changed, r)
+		events = append(events, newAlertEvent(r, tr, nil, now))
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := s.save(changed, events); err != nil {
+		s.log.Errorf("alert no-data job: %s", err.Error())
+	}
+}
+
+// Сохранение состояний и событий, затем уведомления о событиях
+func (s *AlertService) save(rules []domain.AlertRule, events []domain.AlertEvent) error {
+
+	if err := s.repo.SaveStates(rules, events); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		event := domain.NotifyAlertFiring
+		if e.Kind == domain.AlertEventResolved {
+			event = domain.NotifyAlertResolved
+		} else {
+			s.publishFired(e)
+		}
+		s.notifyEvent(event, e)
+	}
+
+	return nil
+}
+
+func (s *AlertService) publishFired(e domain.AlertEvent) {
+	data := domain.WebhookAlert{
+		RuleID:   e.RuleID,
+		SensorID: e.SensorID,
+		Value:    e.Value,
+		Message:  e.Message,
+	}
+	if e.AlertID != nil {
+		data.AlertID = *e.AlertID
+	}
+	s.webhooks.Publish(e.AccountID, domain.WebhookAlertFired, data)
+}
+
+func (s *AlertService) notifyEvent(event string, e domain.AlertEvent) {
+	if s.notify == nil {
+		return
+	}
+
+	n := domain.Notification{
+		AccountID: e.AccountID,
+		Event:     event,
+		SourceID:  e.ID,
+		SensorID:  e.SensorID,
+		RuleID:    e.RuleID,
+		Message:   e.Message,
+		Time:      e.CreatedAt,
+	}
+	if e.AlertID != nil {
+		n.AlertID = *e.AlertID
+	}
+	if e.Value != nil {
+		n.Value = *e.Value
+	}
+	s.notify.Notify(n)
+}
+
+func (s *AlertService) GetAlerts(userId int, filter domain.AlertsFilter) ([]domain.Alert, error) {
+	if !filter.To.After(filter.From) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetAlerts_OfUser(userId, filter)
+}
+
+func (s *AlertService) GetAlert(userId, id int) (*domain.Alert, error) {
+	return s.repo.GetAlert_OfUser(userId, id)
+}
+
+// Acknowledge - пользователь занимается оповещением, напоминания прекращаются
+func (s *AlertService) Acknowledge(userId, id int) error {
+	return s.act(userId, id, domain.AlertEventAcknowledged, func(in alerting.Instance, now time.Time) (alerting.Instance, error) {
+		return alerting.Acknowledge(in)
+	})
+}
+
+// Snooze откладывает напоминания; по истечении отсрочки оповещение снова активно
+func (s *AlertService) Snooze(userId, id int, input domain.SnoozeAlert) error {
+	return s.act(userId, id, domain.AlertEventSnoozed, func(in alerting.Instance, now time.Time) (alerting.Instance, error) {
+		until, err := input.Time(now)
+		if err != nil {
+			return in, err
+		}
+		return alerting.Snooze(in, until, now)
+	})
+}
+
+// Resolve закрывает оповещение вручную
+func (s *AlertService) Resolve(userId, id int) error {
+	return s.act(userId, id, domain.AlertEventResolved, func(in alerting.Instance, now time.Time) (alerting.Instance, error) {
+		return alerting.Resolve(in)
+	})
+}
+
+// Действие пользователя над оповещением: новое состояние и событие с автором действия
+func (s *AlertService) act(userId, id int, kind string, action func(alerting.Instance, time.Time) (alerting.Instance, error)) error {
+
+	a, err := s.repo.GetAlert_OfUser(userId, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, a.SensorID, domain.PermissionManage); err != nil {
+		return domain.ErrForbidden
+	}
+
+	now := time.Now().UTC()
+	in, err := action(alertInstance(*a), now)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrAlertAction, err.Error())
+	}
+	setAlertInstance(a, in)
+
+	title := alertTitle(domain.AlertRule{Title: a.RuleTitle, SensorID: a.SensorID})
+	var message string
+	switch kind {
+	case domain.AlertEventAcknowledged:
+		a.AcknowledgedAt, a.AcknowledgedBy = &now, &userId
+		message = fmt.Sprintf("%s: acknowledged", title)
+	case domain.AlertEventSnoozed:
+		message = fmt.Sprintf("%s: snoozed until %s", title, a.SnoozedUntil.Format(time.RFC3339))
+	case domain.AlertEventResolved:
+		a.ResolvedAt, a.ResolvedBy = &now, &userId
+		message = fmt.Sprintf("%s: resolved manually", title)
+	}
+
+	e := newAlertActionEvent(*a, kind, message, now)
+	e.UserID = &userId
+	if err := s.repo.UpdateAlert(*a, &e); err != nil {
+		return err
+	}
+
+	if kind == domain.AlertEventResolved {
+		s.notifyEvent(domain.NotifyAlertResolved, e)
+	}
+	return nil
+}
+
+func newAlertActionEvent(a domain.Alert, kind, message string, at time.Time) domain.AlertEvent {
+	id := a.ID
+	return domain.AlertEvent{
+		AlertID:   &id,
+		RuleID:    a.RuleID,
+		AccountID: a.AccountID,
+		SensorID:  a.SensorID,
+		Kind:      kind,
+		Value:     a.Value,
+		Message:   message,
+		CreatedAt: at,
+	}
+}
+
+func alertInstance(a domain.Alert) alerting.Instance {
+	in := alerting.Instance{Status: a.Status, LastNotified: a.StartedAt}
+	if a.SnoozedUntil != nil {
+		in.SnoozedUntil = *a.SnoozedUntil
+	}
+	if a.LastNotifiedAt != nil {
+		in.LastNotified = *a.LastNotifiedAt
+	}
+	return in
+}
+
+func setAlertInstance(a *domain.Alert, in alerting.Instance) {
+	a.Status = in.Status
+	a.SnoozedUntil = nil
+	if !in.SnoozedUntil.IsZero() {
+		until := in.SnoozedUntil
+		a.SnoozedUntil = &until
+	}
+	last := in.LastNotified
+	a.LastNotifiedAt = &last
+}
+
+// RunReminders - плановая задача: напоминания о неподтверждённых оповещениях
+// и возврат отложенных оповещений в активные по истечении отсрочки
+func (s *AlertService) RunReminders(ctx context.Context) {
+
+	list, err := s.repo.GetAlerts_Due()
+	if err != nil {
+		s.log.Errorf("alert reminders job: %s", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+
+	for _, a := range list {
+		if ctx.Err() != nil {
+			return
+		}
+
+		in, ok := alerting.Remind(alertInstance(a), time.Duration(a.RenotifySec)*time.Second, now)
+		if !ok {
+			continue
+		}
+
+		var e *domain.AlertEvent
+		if a.Status == domain.AlertSnoozed {
+			title := alertTitle(domain.AlertRule{Title: a.RuleTitle, SensorID: a.SensorID})
+			unsnoozed := newAlertActionEvent(a, domain.AlertEventUnsnoozed, fmt.Sprintf("%s: snooze expired", title), now)
+			e = &unsnoozed
+		}
+
+		setAlertInstance(&a, in)
+		a.NotifyCount++
+		if err := s.repo.UpdateAlert(a, e); err != nil {
+			s.log.Errorf("alert reminders job: alert %d: %s", a.ID, err.Error())
+			continue
+		}
+
+		if s.notify != nil {
+			n := domain.Notification{
+				AccountID: a.AccountID,
+				Event:     domain.NotifyAlertReminder,
+				SourceID:  a.ID,
+				SensorID:  a.SensorID,
+				RuleID:    a.RuleID,
+				AlertID:   a.ID,
+				Message:   a.Message,
+				Since:     a.StartedAt,
+				Time:      now,
+			}
+			if a.Value != nil {
+				n.Value = *a.Value
+			}
+			s.notify.Notify(n)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/anomaly"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис детекторов аномалий: подозрительные показания помечаются при приёме, но не отбрасываются
+
+type AnomalyService struct {
+	repo  IStoreAnomaly
+	cache domain.Cache
+	log   *logrus.Logger
+}
+
+func NewAnomalyService(log *logrus.Logger, cache domain.Cache, repo IStoreAnomaly) *AnomalyService {
+	return &AnomalyService{log: log, cache: cache, repo: repo}
+}
+
+// Длина поля flag_reason в БД
+const maxFlagReasonLen = 512
+
+func detectorsCacheKey(sensorId int) string {
+	return fmt.Sprintf("detectors-%d", sensorId)
+}
+
+func (s *AnomalyService) GetDetectors(userId, sensorId int) (domain.SensorDetectors, error) {
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionRead); err != nil {
+		return domain.SensorDetectors{}, err
+	}
+
+	list, err := s.repo.GetDetectors_OfSensors([]int{sensorId})
+	if err != nil {
+		return domain.SensorDetectors{}, err
+	}
+	if len(list) == 0 {
+		return domain.SensorDetectors{SensorID: sensorId}, nil
+	}
+
+	return list[0], nil
+}
+
+func (s *AnomalyService) SetDetectors(userId, sensorId int, input domain.SetSensorDetectors) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	err := s.repo.SetDetectors(domain.SensorDetectors{
+		SensorID:        sensorId,
+		MaxRate:         input.MaxRate,
+		ZScoreWindow:    input.ZScoreWindow,
+		ZScoreThreshold: input.ZScoreThreshold,
+		FlatlineMinutes: input.FlatlineMinutes,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.resetCache(sensorId)
+	return nil
+}
+
+func (s *AnomalyService) DeleteDetectors(userId, sensorId int) error {
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteDetectors(sensorId); err != nil {
+		return err
+	}
+
+	s.resetCache(sensorId)
+	return nil
+}
+
+func (s *AnomalyService) GetFlags(userId, sensorId int, from, to time.Time) ([]domain.SensorFlag, error) {
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetFlags_OfSensor(sensorId, from, to)
+}
+
+func (s *AnomalyService) resetCache(sensorId int) {
+	if s.cache != nil {
+		s.cache.Delete(detectorsCacheKey(sensorId))
+	}
+}
+
+// Настройки детекторов сенсоров; сенсоры без детекторов тоже кешируются (нулевыми настройками)
+func (s *AnomalyService) getConfigs(sensorIds []int) (map[int]anomaly.Config, error) {
+
+	configs := make(map[int]anomaly.Config, len(sensorIds))
+
+	var missing []int
+	for _, id := range sensorIds {
+		if s.cache != nil {
+			if cfg, err := s.cache.Get(detectorsCacheKey(id)); err == nil {
+				configs[id] = cfg.(anomaly.Config)
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return configs, nil
+	}
+
+	list, err := s.repo.GetDetectors_OfSensors(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range list {
+		configs[d.SensorID] = anomaly.Config{
+			MaxRate:    d.MaxRate,
+			ZWindow:    d.ZScoreWindow,
+			ZThreshold: d.ZScoreThreshold,
+			Flatline:   time.Duration(d.FlatlineMinutes) * time.Minute,
+		}
+	}
+	for _, id := range missing {
+		if s.cache != nil {
+			s.cache.Set(detectorsCacheKey(id), configs[id])
+		}
+	}
+
+	return configs, nil
+}
+
+// Включены ли у сенсора детекторы
+func (s *AnomalyService) enabled(sensorId int) (bool, error) {
+	configs, err := s.getConfigs([]int{sensorId})
+	if err != nil {
+		return false, err
+	}
+	return configs[sensorId].Enabled(), nil
+}
+
+// Mark прогоняет пришедшие показания через детекторы их сенсоров и заполняет Flag и Flag_reason.
+// Нечисловые показания не проверяются.
+func (s *AnomalyService) Mark(list []domain.SensorDataSet) {
+
+	var ids []int
+	for _, x := range list {
+		if !containsInt(ids, x.Sensor_id) {
+			ids = append(ids, x.Sensor_id)
+		}
+	}
+
+	configs, err := s.getConfigs(ids)
+	if err != nil {
+		s.log.Errorf("anomaly detectors: %s", err.Error())
+		return
+	}
+
+	var enabled []int
+	limit := 2
+	for _, id := range ids {
+		if cfg := configs[id]; cfg.Enabled() {
+			enabled = append(enabled, id)
+			if cfg.ZWindow > limit {
+				limit = cfg.ZWindow
+			}
+		}
+	}
+	if len(enabled) == 0 {
+		return
+	}
+
+	recent, err := s.repo.GetRecent_OfSensors(enabled, limit)
+	if err != nil {
+		s.log.Errorf("anomaly detectors: %s", err.Error())
+		return
+	}
+
+	history := make(map[int][]anomaly.Sample, len(enabled))
+	lastValue := make(map[int]string, len(enabled))
+	for _, x := range recent {
+		if v, ok := parseValue(x.Value); ok {
+			history[x.Sensor_id] = append(history[x.Sensor_id], anomaly.Sample{Time: x.CreatedAt, Value: v})
+			lastValue[x.Sensor_id] = x.Value
+		}
+	}
+
+	// Показания одного сенсора внутри пачки проверяем по порядку времени
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return list[order[a]].CreatedAt.Before(list[order[b]].CreatedAt) })
+
+	for _, i := range order {
+		x := &list[i]
+
+		cfg := configs[x.Sensor_id]
+		if !cfg.Enabled() {
+			continue
+		}
+
+		v, ok := parseValue(x.Value)
+		if !ok {
+			continue
+		}
+		cur := anomaly.Sample{Time: x.CreatedAt, Value: v}
+		h := history[x.Sensor_id]
+
+		// Серия одинаковых значений может быть длиннее прочитанной истории - начало серии берём из БД
+		var since time.Time
+		if cfg.Flatline > 0 && len(h) > 0 && h[len(h)-1].Value == v && anomaly.RunStart(h).Equal(h[0].Time) {
+			if last, ok := lastValue[x.Sensor_id]; ok {
+				if t, err := s.repo.GetRunStart(x.Sensor_id, last); err == nil {
+					since = t
+				}
+			}
+		}
+
+		if found := anomaly.Check(cfg, h, cur, since); len(found) > 0 {
+			flag, reason := anomaly.Join(found)
+			if len(reason) > maxFlagReasonLen {
+				reason = reason[:maxFlagReasonLen]
+			}
+			x.Flag, x.Flag_reason = &flag, &reason
+		}
+
+		history[x.Sensor_id] = append(h, cur)
+	}
+}
+
+func parseValue(value string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return v, err == nil
+}
@@ -2,20 +2,34 @@ package service
 
 import (
 	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
 )
 
+// Длина токена хаба (aquahubs.h_token varchar(32))
+const aquahubTokenLen = 28
+
 // Сервис для работы со списками
 
 type AquahubListService struct {
-	repo IStoreAquahubs
+	repo        IStoreAquahubs
+	calibration *CalibrationService
+	virtual     *VirtualSensorService
+	anomaly     *AnomalyService
+	alert       *AlertService
+	automation  *AutomationService
+	webhooks    *WebhookService
+	deviceTypes *DeviceTypeService
 }
 
 //-------------------------------------------------------------------------
 
 // Также в нашем сервисе и понадобится репозиторий
 // Добавим его в качестве поля нашей структуры и будем передавать в конструкторе.
-func NewAquahubListService(repo IStoreAquahubs) *AquahubListService {
-	return &AquahubListService{repo: repo}
+func NewAquahubListService(repo IStoreAquahubs, calibration *CalibrationService, virtual *VirtualSensorService,
+	anomaly *AnomalyService, alert *AlertService, automation *AutomationService, webhooks *WebhookService,
+	deviceTypes *DeviceTypeService) *AquahubListService {
+	return &AquahubListService{repo: repo, calibration: calibration, virtual: virtual, anomaly: anomaly, alert: alert,
+		automation: automation, webhooks: webhooks, deviceTypes: deviceTypes}
 }
 
 /*
@@ -49,33 +63,190 @@ func (s *ChecklistListService) Update(userId, listId int, input domain.UpdateLis
 
 // Метод GetAll, который будет принимать id пользователя
 // и возвращать слайс списка вместе с ошибкой.
-func (s *AquahubListService) GetAllAquahubOfUser(userId int) ([]domain.AquahubList, error) {
+func (s *AquahubListService) GetAllAquahubOfUser(userId int, filter domain.GroupFilter, includeArchived bool) ([]domain.AquahubList, error) {
 	// В сервисе мы будем вызывать аналогичный метод репозитория, поскольку дополнительной бизнес логики тут нет.
-	return s.repo.GetAquahubs_OfUser(userId)
+	return s.repo.GetAquahubs_OfUser(userId, filter, includeArchived)
+}
+
+func (s *AquahubListService) GetDevicesOfAquahub(userId, aquahubId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Device, error) {
+	return s.repo.GetDevices_OfAquahub(userId, aquahubId, filter, includeArchived)
+}
+
+func (s *AquahubListService) GetSensorsOfDevice(userId, deviceId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Sensor, error) {
+	return s.repo.GetSensors_OfDevice(userId, deviceId, filter, includeArchived)
+}
+
+func (s *AquahubListService) GetAquahub(userId, id int) (*domain.AquahubList, error) {
+	return s.repo.GetAquahub_OfUser(userId, id)
+}
+
+// Создание аквахаба в аккаунте пользователя; токен хаба возвращается для прошивки
+func (s *AquahubListService) CreateAquahub(userId int, input domain.CreateAquahub) (domain.AquahubCreated, error) {
+	if err := input.Validate(); err != nil {
+		return domain.AquahubCreated{}, err
+	}
+
+	hToken := randomstring.RandomBase64String(aquahubTokenLen)
+	id, err := s.repo.CreateAquahub(userId, input, hToken)
+	if err != nil {
+		return domain.AquahubCreated{}, err
+	}
+
+	return domain.AquahubCreated{ID: id, HToken: hToken}, nil
 }
 
-func (s *AquahubListService) GetDevicesOfAquahub(aquahubId int) ([]domain.AquahubList, error) {
-	return s.repo.GetDevices_OfAquahub(aquahubId)
+func (s *AquahubListService) UpdateAquahub(userId, id int, input domain.UpdateAquahub) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.UpdateAquahub(userId, id, input)
 }
 
-func (s *AquahubListService) GetSensorsOfDevice(deviceId int) ([]domain.AquahubList, error) {
-	return s.repo.GetSensors_OfDevice(deviceId)
+// Удаление переносит аквахаб в архив, окончательно его удаляет плановая задача
+func (s *AquahubListService) DeleteAquahub(userId, id int) error {
+	return s.repo.ArchiveAquahub(userId, id)
+}
+
+func (s *AquahubListService) RestoreAquahub(userId, id int) error {
+	return s.repo.RestoreAquahub(userId, id)
+}
+
+func (s *AquahubListService) GetDevice(userId, id int) (*domain.Device, error) {
+	return s.repo.GetDevice_OfUser(userId, id)
+}
+
+func (s *AquahubListService) CreateDevice(userId int, input domain.CreateDevice) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateDevice(userId, input)
+}
+
+func (s *AquahubListService) UpdateDevice(userId, id int, input domain.UpdateDevice) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.UpdateDevice(userId, id, input)
+}
+
+// Удаление переносит устройство в архив, окончательно его удаляет плановая задача
+func (s *AquahubListService) DeleteDevice(userId, id int) error {
+	return s.repo.ArchiveDevice(userId, id)
+}
+
+func (s *AquahubListService) RestoreDevice(userId, id int) error {
+	return s.repo.RestoreDevice(userId, id)
+}
+
+func (s *AquahubListService) GetSensor(userId, id int) (*domain.Sensor, error) {
+	return s.repo.GetSensor_OfUser(userId, id)
+}
+
+func (s *AquahubListService) CreateSensor(userId int, input domain.CreateSensor) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.CreateSensor(userId, input)
+}
+
+func (s *AquahubListService) UpdateSensor(userId, id int, input domain.UpdateSensor) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.UpdateSensor(userId, id, input)
+}
+
+// Удаление переносит сенсор в архив, окончательно его удаляет плановая задача
+func (s *AquahubListService) DeleteSensor(userId, id int) error {
+	return s.repo.ArchiveSensor(userId, id)
+}
+
+func (s *AquahubListService) RestoreSensor(userId, id int) error {
+	return s.repo.RestoreSensor(userId, id)
 }
 
 func (s *AquahubListService) GetDataSetOfSensor(sensorId int) ([]domain.SensorDataSet, error) {
 	return s.repo.GetDataSet_OfSensor(sensorId)
 }
 
+// Приём показаний: пришедшие значения калибруются, к ним добавляются вычисленные значения
+// виртуальных сенсоров, подозрительные показания помечаются детекторами, и всё сохраняется одной вставкой.
+// Сохранённые показания проверяются правилами оповещения и правилами автоматизации
+// и отправляются подписчикам события reading.received.
 func (s *AquahubListService) AppendDataOfSensor(list []domain.SensorDataSet) error {
-	return s.repo.AppendData_OfSensor(list)
+	if s.calibration != nil {
+		s.calibration.Apply(list)
+	}
+	if s.virtual != nil {
+		list = append(list, s.virtual.Derive(list)...)
+	}
+	if s.anomaly != nil {
+		s.anomaly.Mark(list)
+	}
+
+	if err := s.repo.AppendData_OfSensor(list); err != nil {
+		return err
+	}
+
+	if s.alert != nil {
+		s.alert.Evaluate(list)
+	}
+	if s.automation != nil {
+		s.automation.Evaluate(list)
+	}
+	s.webhooks.PublishReadings(list)
+
+	return nil
 }
 
+// Новое устройство хаба - событие device.added.
+// Устройство известной модели (value) без типа описывается по каталогу типов устройств.
 func (s *AquahubListService) DeviceCreateOrUpdate(aquahub_id int, device_local_id int, value string) error {
-	return s.repo.Device_CreateOrUpdate(aquahub_id, device_local_id, value)
+	id, created, err := s.repo.Device_CreateOrUpdate(aquahub_id, device_local_id, value)
+	if err != nil {
+		return err
+	}
+
+	if s.deviceTypes != nil {
+		if title, err := s.deviceTypes.Describe(aquahub_id, id, value); err != nil {
+			return err
+		} else if title != "" {
+			value = title
+		}
+	}
+
+	if created {
+		s.webhooks.PublishHub(aquahub_id, domain.WebhookDeviceAdded, domain.WebhookDevice{
+			AquahubID: aquahub_id,
+			DeviceID:  id,
+			LocalID:   device_local_id,
+			Title:     value,
+		})
+	}
+	return nil
 }
 
+// Новый сенсор хаба - событие sensor.added; сенсор устройства с типом описывается по раскладке типа
 func (s *AquahubListService) SensorCreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) error {
-	return s.repo.Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id, value)
+	id, created, err := s.repo.Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id, value)
+	if err != nil {
+		return err
+	}
+
+	if created {
+		if s.deviceTypes != nil {
+			if err := s.deviceTypes.DescribeSensor(id); err != nil {
+				return err
+			}
+		}
+		s.webhooks.PublishHub(aquahub_id, domain.WebhookSensorAdded, domain.WebhookSensor{
+			AquahubID:     aquahub_id,
+			SensorID:      id,
+			DeviceLocalID: device_local_id,
+			LocalID:       sensor_local_id,
+		})
+	}
+	return nil
 }
 
 func (s *AquahubListService) GetNameOfDeviceSensor(sensor_id int) (domain.NameOfDeviceSensor, error) {
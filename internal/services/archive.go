@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Окончательное удаление архивных хабов, устройств, сенсоров и чек-листов
+
+type ArchiveService struct {
+	repo IStoreArchive
+	log  *logrus.Logger
+}
+
+func NewArchiveService(log *logrus.Logger, repo IStoreArchive) *ArchiveService {
+	return &ArchiveService{repo: repo, log: log}
+}
+
+// Плановая задача: удаление записей, пролежавших в архиве дольше domain.ArchivePurgeAfter
+func (s *ArchiveService) RunPurge(ctx context.Context) {
+
+	purged, err := s.repo.Purge(time.Now().Add(-domain.ArchivePurgeAfter))
+	if err != nil {
+		s.log.Errorf("archive job: %s", err.Error())
+	}
+
+	if purged.Total() > 0 {
+		s.log.Infof("archive job: purged %d aquahubs, %d devices, %d sensors, %d checklists, %d checklist items",
+			purged.Aquahubs, purged.Devices, purged.Sensors, purged.Checklists, purged.ChecklistItems)
+	}
+}
@@ -3,25 +3,34 @@ package service
 import (
 	"crypto/sha1"
 	"fmt"
+	"strings"
+	"time"
 
 	cachememory "github.com/o-sokol-o/cache-memory"
 	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/jwt_processing"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const salt = "hjqrhjqw124617ajfhajs" // Случайные символы для генерации хеша пароля
 
 type AuthService struct {
-	repo  IStoreAuthorization
-	cache cachememory.Cache
+	repo   IStoreAuthorization
+	cache  cachememory.Cache
+	log    *logrus.Logger
+	notify *NotificationService
 }
 
-func NewAuthService(cache cachememory.Cache, repo IStoreAuthorization) *AuthService {
+func NewAuthService(log *logrus.Logger, cache cachememory.Cache, repo IStoreAuthorization, notify *NotificationService) *AuthService {
 	return &AuthService{
-		cache: cache,
-		repo:  repo}
+		log:    log,
+		cache:  cache,
+		notify: notify,
+		repo:   repo}
 }
 
 func (s *AuthService) GetUser_LoginPassword(email, password string) (domain.User, error) {
@@ -54,6 +63,108 @@ func (s *AuthService) Authenticate(email, password string) (*domain.User, error)
 	return user, nil
 }
 
+// --------------------------------- Сброс пароля -----------------------------------
+
+// Длина хеша одноразовой части токена сброса в users.password_reset (varchar(36))
+const passwordResetHashLen = 36
+
+// ForgotPassword ставит в очередь письмо с токеном сброса пароля. Ошибки только логируются:
+// ответ не должен выдавать, есть ли пользователь с этим адресом.
+func (s *AuthService) ForgotPassword(email string) {
+
+	user, err := s.repo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil || user.ArchivedAt != nil && user.ArchivedAt.Valid {
+		return
+	}
+
+	nonce := randomstring.RandomBase64String(domain.PasswordResetNonceLen)
+	expiresAt := time.Now().UTC().Add(domain.PasswordResetTTL)
+
+	token, err := jwt_processing.GeneratePasswordResetToken(user.ID, nonce, expiresAt)
+	if err != nil {
+		s.log.Errorf("password reset: user %d: %s", user.ID, err.Error())
+		return
+	}
+
+	if err := s.repo.SetPasswordReset(user.ID, hashToken(nonce)[:passwordResetHashLen]); err != nil {
+		s.log.Errorf("password reset: user %d: %s", user.ID, err.Error())
+		return
+	}
+
+	// Письмо только на адрес пользователя: токен не уходит в другие каналы уведомлений
+	if err := s.notify.NotifyEmail(user.Email, domain.Notification{
+		Event: domain.NotifyPasswordReset,
+		Token: token,
+		Time:  expiresAt,
+	}); err != nil {
+		s.log.Errorf("password reset: user %d: %s", user.ID, err.Error())
+		return
+	}
+
+	s.log.Infof("password reset: user %d requested a reset token", user.ID)
+}
+
+// ResetPassword задаёт новый пароль по токену сброса; все выданные ранее токены доступа пользователя
+// перестают действовать
+func (s *AuthService) ResetPassword(input domain.ResetPassword) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	userId, nonce, err := jwt_processing.ParsePasswordResetToken(input.Token)
+	if err != nil {
+		return domain.ErrInvalidResetToken
+	}
+
+	passwordHash, passwordSalt, err := hashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	changedAt, err := s.repo.ResetPassword(userId, hashToken(nonce)[:passwordResetHashLen], passwordHash, passwordSalt)
+	if err != nil {
+		return err
+	}
+	s.cache.Set(sessionsCacheKey(userId), changedAt)
+
+	// Токены обновления прежних сеансов тоже перестают действовать
+	if err := s.repo.RevokeSessions_OfUser(userId); err != nil {
+		return err
+	}
+
+	s.log.Infof("password reset: user %d changed the password", userId)
+	return nil
+}
+
+func sessionsCacheKey(userId int) string {
+	return fmt.Sprintf("sessions-%d", userId)
+}
+
+// CheckSession - токен доступа не отозван и выдан после последней смены пароля пользователя
+func (s *AuthService) CheckSession(access jwt_processing.Access) error {
+
+	if err := s.checkRevoked(access.Id); err != nil {
+		return err
+	}
+
+	var changedAt time.Time
+	if v, err := s.cache.Get(sessionsCacheKey(access.UserId)); err == nil {
+		changedAt = v.(time.Time)
+	} else {
+		if changedAt, err = s.repo.GetPasswordChangedAt(access.UserId); err != nil {
+			return err
+		}
+		s.cache.Set(sessionsCacheKey(access.UserId), changedAt)
+	}
+
+	// Время выдачи токена - с точностью до секунды, поэтому токены, выданные в ту же секунду, что и смена пароля,
+	// тоже недействительны: по ним не отличить выданные до смены
+	if !changedAt.IsZero() && !access.IssuedAt.After(changedAt.Truncate(time.Second)) {
+		return domain.ErrSessionExpired
+	}
+	return nil
+}
+
 // --------------------------------- Old -----------------------------------
 
 // Генерация хеша пароля
@@ -76,13 +187,10 @@ func (s *AuthService) CreateUser(user domain.User) (int, error) {
 	}
 
 	// Генерация хеша пароля
-	user.PasswordSalt = uuid.NewRandom().String()
-	saltedPassword := user.PasswordHash + user.PasswordSalt
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(saltedPassword), bcrypt.DefaultCost)
+	user.PasswordHash, user.PasswordSalt, err = hashPassword(user.PasswordHash)
 	if err != nil {
-		return 0, errors.Wrap(err, "generating password hash")
+		return 0, err
 	}
-	user.PasswordHash = string(passwordHash)
 
 	// Создание пользователя с аккаунтом
 	id, err := s.repo.CreateUser(user)
@@ -93,6 +201,17 @@ func (s *AuthService) CreateUser(user domain.User) (int, error) {
 	return id, nil
 }
 
+// Хеш пароля bcrypt с солью пользователя; возвращает хеш и соль
+func hashPassword(password string) (string, string, error) {
+	passwordSalt := uuid.NewRandom().String()
+	saltedPassword := password + passwordSalt
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(saltedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", errors.Wrap(err, "generating password hash")
+	}
+	return string(passwordHash), passwordSalt, nil
+}
+
 // // Запросить токен пользователя
 // func (s *AuthService) GenerateToken(username, password string) (string, error) {
 
@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/automation"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис правил автоматизации: условия по сенсорам проверяются при приёме показаний,
+// расписания и условия с длительностью - плановой задачей раз в минуту.
+// Действия правила: команда устройству (через очередь команд хаба), уведомление участникам аккаунта,
+// пункт в чек-листе. Каждый запуск записывается в журнал; запуски сверх паузы и лимита в час
+// пропускаются и записываются со статусом skipped.
+
+type AutomationService struct {
+	repo     IStoreAutomation
+	cache    domain.Cache
+	log      *logrus.Logger
+	notify   *NotificationService
+	commands *CommandService
+}
+
+func NewAutomationService(log *logrus.Logger, cache domain.Cache, repo IStoreAutomation,
+	notify *NotificationService, commands *CommandService) *AutomationService {
+	return &AutomationService{log: log, cache: cache, repo: repo, notify: notify, commands: commands}
+}
+
+// В кеше по сенсору хранится признак наличия включённых правил с условием по нему
+func automationsCacheKey(sensorId int) string {
+	return fmt.Sprintf("automations-%d", sensorId)
+}
+
+func (s *AutomationService) validate(userId int, a domain.Automation) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	for _, c := range a.Conditions {
+		if err := automationCondition(c).Validate(); err != nil {
+			return err
+		}
+	}
+	if a.Schedule != nil {
+		if _, err := automation.ParseClock(a.Schedule.At); err != nil {
+			return fmt.Errorf("schedule %s", err.Error())
+		}
+	}
+
+	if err := s.repo.CheckAccount_OfUser(userId, a.AccountID, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	sensors, devices, checklists := a.References()
+	return s.repo.CheckReferences(a.AccountID, sensors, devices, checklists)
+}
+
+func (s *AutomationService) Create(userId int, input domain.SetAutomation) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	a := input.Automation()
+	if err := s.validate(userId, a); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Create(a)
+	if err != nil {
+		return 0, err
+	}
+
+	s.resetCache(a)
+	return id, nil
+}
+
+func (s *AutomationService) GetAll(userId int) ([]domain.Automation, error) {
+	return s.repo.GetAll_OfUser(userId)
+}
+
+func (s *AutomationService) GetById(userId, id int) (*domain.Automation, error) {
+	return s.repo.GetById(userId, id)
+}
+
+// Update заменяет правило целиком; состояние условий начинается заново
+func (s *AutomationService) Update(userId, id int, input domain.SetAutomation) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	old, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, old.AccountID, domain.PermissionManage); err != nil {
+		return domain.ErrForbidden
+	}
+
+	a := input.Automation()
+	a.ID = id
+	if err := s.validate(userId, a); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(a); err != nil {
+		return err
+	}
+
+	s.resetCache(*old)
+	s.resetCache(a)
+	return nil
+}
+
+func (s *AutomationService) Delete(userId, id int) error {
+	a, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userId, id); err != nil {
+		return err
+	}
+
+	s.resetCache(*a)
+	return nil
+}
+
+func (s *AutomationService) GetRuns(userId, id int, from, to time.Time) ([]domain.AutomationRun, error) {
+	if !to.After(from) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetRuns_OfUser(userId, id, from, to)
+}
+
+func (s *AutomationService) resetCache(a domain.Automation) {
+	if s.cache == nil {
+		return
+	}
+	for _, c := range a.Conditions {
+		s.cache.Delete(automationsCacheKey(c.SensorID))
+	}
+}
+
+// Включённые правила с условиями по сенсорам; сенсоры без правил запоминаются в кеше
+func (s *AutomationService) getRules(sensorIds []int) ([]domain.Automation, error) {
+
+	var ids []int
+	for _, id := range sensorIds {
+		if s.cache != nil {
+			if has, err := s.cache.Get(automationsCacheKey(id)); err == nil && !has.(bool) {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rules, err := s.repo.GetEnabled_OfSensors(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		for _, id := range ids {
+			has := false
+			for _, r := range rules {
+				for _, c := range r.Conditions {
+					if c.SensorID == id {
+						has = true
+					}
+				}
+			}
+			s.cache.Set(automationsCacheKey(id), has)
+		}
+	}
+
+	return rules, nil
+}
+
+func automationCondition(c domain.AutomationCondition) automation.Condition {
+	return automation.Condition{Op: c.Op, Value: c.Value, For: time.Duration(c.ForSec) * time.Second}
+}
+
+func automationConditions(a domain.Automation) []automation.Condition {
+	conds := make([]automation.Condition, len(a.Conditions))
+	for i, c := range a.Conditions {
+		conds[i] = automationCondition(c)
+	}
+	return conds
+}
+
+// Состояния условий по порядку; сохранённое состояние другой длины (правило изменено) не используется
+func automationStates(a domain.Automation) []automation.ConditionState {
+	states := make([]automation.ConditionState, len(a.Conditions))
+	if len(a.State.Conditions) != len(a.Conditions) {
+		return states
+	}
+	for i, st := range a.State.Conditions {
+		states[i] = automation.ConditionState{Known: st.Known, Value: st.Value, At: st.At}
+		if st.Since != nil {
+			states[i].Since = *st.Since
+		}
+	}
+	return states
+}
+
+func setAutomationStates(a *domain.Automation, states []automation.ConditionState) {
+	list := make([]domain.AutomationConditionState, len(states))
+	for i, st := range states {
+		list[i] = domain.AutomationConditionState{Known: st.Known, Value: st.Value, At: st.At}
+		if !st.Since.IsZero() {
+			since := st.Since
+			list[i].Since = &since
+		}
+	}
+	a.State.Conditions = list
+}
+
+// Расписание во временной зоне аккаунта; неизвестная зона - UTC
+func automationSchedule(a domain.Automation) automation.Schedule {
+	minute, _ := automation.ParseClock(a.Schedule.At)
+
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	sch := automation.Schedule{Minute: minute, Location: loc}
+	for _, d := range a.Schedule.Weekdays {
+		sch.Weekdays = append(sch.Weekdays, time.Weekday(d))
+	}
+	return sch
+}
+
+func automationLimits(a domain.Automation) automation.Limits {
+	return automation.Limits{
+		Cooldown:   time.Duration(a.CooldownSec) * time.Second,
+		MaxPerHour: a.MaxRunsPerHour,
+	}
+}
+
+// Описание условий для журнала: "sensor 12 > 28 for 600s; sensor 14 < 7.9"
+func automationMessage(a domain.Automation, trigger string) string {
+	var parts []string
+	if trigger == domain.AutomationTriggerSchedule && a.Schedule != nil {
+		parts = append(parts, fmt.Sprintf("schedule %s", a.Schedule.At))
+	}
+	for _, c := range a.Conditions {
+		p := fmt.Sprintf("sensor %d %s %g", c.SensorID, c.Op, c.Value)
+		if c.ForSec > 0 {
+			p += fmt.Sprintf(" for %ds", c.ForSec)
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Evaluate учитывает сохранённые показания в состояниях условий правил их сенсоров.
+// Правило без расписания запускается, когда все условия начали выполняться;
+// условия с длительностью, которые выполнятся позже, запускает плановая задача.
+func (s *AutomationService) Evaluate(list []domain.SensorDataSet) {
+
+	var ids []int
+	for _, x := range list {
+		if !containsInt(ids, x.Sensor_id) {
+			ids = append(ids, x.Sensor_id)
+		}
+	}
+
+	rules, err := s.getRules(ids)
+	if err != nil {
+		s.log.Errorf("automations: %s", err.Error())
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return list[order[a]].CreatedAt.Before(list[order[b]].CreatedAt) })
+
+	now := time.Now().UTC()
+
+	for _, r := range rules {
+		conds := automationConditions(r)
+		states := automationStates(r)
+		touched := false
+
+		for _, i := range order {
+			x := list[i]
+			if x.Account_id != r.AccountID {
+				continue
+			}
+			v, ok := parseValue(x.Value)
+			if !ok {
+				continue
+			}
+			for ci, c := range r.Conditions {
+				if c.SensorID == x.Sensor_id {
+					states[ci] = automation.Observe(conds[ci], states[ci], v, x.CreatedAt)
+					touched = true
+				}
+			}
+		}
+
+		if !touched {
+			continue
+		}
+
+		holds := automation.AllHold(conds, states, now)
+		if r.Schedule == nil && automation.Edge(r.State.Active, holds) {
+			s.run(r, domain.AutomationTriggerSensor, now)
+		}
+
+		setAutomationStates(&r, states)
+		r.State.Active = holds
+		if err := s.repo.SaveState(r); err != nil {
+			s.log.Errorf("automations: %s", err.Error())
+		}
+	}
+}
+
+// RunScheduled - плановая задача (раз в минуту): запуск правил по расписанию
+// и правил, условия которых выполнились по истечении длительности
+func (s *AutomationService) RunScheduled(ctx context.Context) {
+
+	rules, err := s.repo.GetEnabled_Timed()
+	if err != nil {
+		s.log.Errorf("automations job: %s", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+
+	for _, r := range rules {
+		if ctx.Err() != nil {
+			return
+		}
+
+		holds := automation.AllHold(automationConditions(r), automationStates(r), now)
+
+		if r.Schedule != nil {
+			last := now.Add(-time.Minute)
+			if r.CheckedAt != nil && r.CheckedAt.After(last.Add(-time.Hour)) {
+				last = *r.CheckedAt
+			}
+			if automationSchedule(r).Due(last, now) && (len(r.Conditions) == 0 || holds) {
+				s.run(r, domain.AutomationTriggerSchedule, now)
+			}
+			checked := now
+			r.CheckedAt = &checked
+		} else if automation.Edge(r.State.Active, holds) {
+			s.run(r, domain.AutomationTriggerSensor, now)
+		} else if r.State.Active == holds {
+			continue
+		}
+
+		r.State.Active = holds
+		if err := s.repo.SaveState(r); err != nil {
+			s.log.Errorf("automations job: automation %d: %s", r.ID, err.Error())
+		}
+	}
+}
+
+// Запуск правила: проверка паузы и лимита, выполнение действий (в режиме dry_run - только описание), запись в журнал
+func (s *AutomationService) run(a domain.Automation, trigger string, now time.Time) {
+
+	run := domain.AutomationRun{
+		AutomationID: a.ID,
+		AccountID:    a.AccountID,
+		Trigger:      trigger,
+		Message:      automationMessage(a, trigger),
+		CreatedAt:    now,
+	}
+
+	if err := s.allow(a, now); err != nil {
+		run.Status = domain.AutomationRunSkipped
+		run.Message = fmt.Sprintf("%s: %s", run.Message, err.Error())
+	} else if a.DryRun {
+		run.Status = domain.AutomationRunDryRun
+		run.Actions = describeActions(a)
+	} else {
+		run.Status = domain.AutomationRunOK
+		run.Actions = s.execute(a, now)
+		for _, x := range run.Actions {
+			if x.Error != "" {
+				run.Status = domain.AutomationRunFailed
+			}
+		}
+	}
+
+	if _, err := s.repo.CreateRun(run); err != nil {
+		s.log.Errorf("automations: automation %d: %s", a.ID, err.Error())
+	}
+}
+
+// Защита от зацикливания по журналу запусков
+func (s *AutomationService) allow(a domain.Automation, now time.Time) error {
+	since := time.Hour
+	if cooldown := time.Duration(a.CooldownSec) * time.Second; cooldown > since {
+		since = cooldown
+	}
+
+	runs, err := s.repo.GetRunTimes(a.ID, now.Add(-since))
+	if err != nil {
+		return err
+	}
+
+	return automation.Allow(automationLimits(a), runs, now)
+}
+
+func describeAction(x domain.AutomationAction) string {
+	switch x.Type {
+	case domain.AutomationActionCommand:
+		return fmt.Sprintf("command %s=%s to device %d", x.Command, x.Value, x.DeviceID)
+	case domain.AutomationActionNotify:
+		return fmt.Sprintf("notify: %s", x.Message)
+	default:
+		return fmt.Sprintf("checklist item %q in checklist %d", x.Title, x.ChecklistID)
+	}
+}
+
+func describeActions(a domain.Automation) domain.AutomationActionResults {
+	list := make(domain.AutomationActionResults, len(a.Actions))
+	for i, x := range a.Actions {
+		list[i] = domain.AutomationActionResult{Type: x.Type, Result: describeAction(x)}
+	}
+	return list
+}
+
+// Выполнение действий по порядку; ошибка действия не останавливает остальные
+func (s *AutomationService) execute(a domain.Automation, now time.Time) domain.AutomationActionResults {
+
+	list := make(domain.AutomationActionResults, len(a.Actions))
+	for i, x := range a.Actions {
+		res := domain.AutomationActionResult{Type: x.Type}
+
+		switch x.Type {
+		case domain.AutomationActionCommand:
+			if id, err := s.commands.Queue(a.AccountID, &a.ID, x.DeviceID, x.Command, x.Value); err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Result = fmt.Sprintf("command %d queued", id)
+			}
+
+		case domain.AutomationActionNotify:
+			message := x.Message
+			if message == "" {
+				message = a.Title
+			}
+			if s.notify != nil {
+				s.notify.Notify(domain.Notification{
+					AccountID: a.AccountID,
+					Event:     domain.NotifyAutomation,
+					SourceID:  a.ID,
+					Message:   message,
+					Time:      now,
+				})
+			}
+			res.Result = "notification queued"
+
+		case domain.AutomationActionChecklist:
+			if id, err := s.repo.CreateChecklistItem(a.AccountID, x.ChecklistID, x.Title, x.Description); err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Result = fmt.Sprintf("checklist item %d created", id)
+			}
+		}
+
+		list[i] = res
+	}
+
+	return list
+}
+
+// Test проверяет правило сейчас и показывает, что было бы сделано; действия не выполняются.
+// Условия без состояния оцениваются по последним показаниям сенсоров. Проверка записывается в журнал.
+func (s *AutomationService) Test(userId, id int) (domain.AutomationTestResult, error) {
+
+	var res domain.AutomationTestResult
+
+	a, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return res, err
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, a.AccountID, domain.PermissionManage); err != nil {
+		return res, domain.ErrForbidden
+	}
+
+	conds := automationConditions(*a)
+	states := automationStates(*a)
+
+	var unknown []int
+	for i, c := range a.Conditions {
+		if !states[i].Known {
+			unknown = append(unknown, c.SensorID)
+		}
+	}
+	if len(unknown) > 0 {
+		latest, err := s.repo.GetLatest_OfSensors(a.AccountID, unknown)
+		if err != nil {
+			return res, err
+		}
+		for _, x := range latest {
+			v, ok := parseValue(x.Value)
+			if !ok {
+				continue
+			}
+			for i, c := range a.Conditions {
+				if c.SensorID == x.Sensor_id && !states[i].Known {
+					states[i] = automation.Observe(conds[i], states[i], v, x.CreatedAt)
+				}
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+
+	res.Conditions = make([]domain.AutomationConditionResult, len(a.Conditions))
+	for i, c := range a.Conditions {
+		cr := domain.AutomationConditionResult{SensorID: c.SensorID, Holds: automation.Holds(conds[i], states[i], now)}
+		if states[i].Known {
+			v, at := states[i].Value, states[i].At
+			cr.Value, cr.At = &v, &at
+		}
+		res.Conditions[i] = cr
+	}
+
+	res.WouldRun = len(conds) == 0 || automation.AllHold(conds, states, now)
+	if a.Schedule != nil {
+		next := automationSchedule(*a).Next(now)
+		res.NextRunAt = &next
+	}
+	if err := s.allow(*a, now); err != nil {
+		res.Blocked = err.Error()
+	}
+	res.Actions = describeActions(*a)
+
+	run := domain.AutomationRun{
+		AutomationID: a.ID,
+		AccountID:    a.AccountID,
+		Trigger:      domain.AutomationTriggerTest,
+		Status:       domain.AutomationRunDryRun,
+		Message:      automationMessage(*a, domain.AutomationTriggerTest),
+		Actions:      res.Actions,
+		CreatedAt:    now,
+	}
+	if !res.WouldRun {
+		run.Status = domain.AutomationRunSkipped
+		run.Message = fmt.Sprintf("%s: conditions do not hold", run.Message)
+	}
+	if _, err := s.repo.CreateRun(run); err != nil {
+		s.log.Errorf("automations: automation %d: %s", a.ID, err.Error())
+	}
+
+	return res, nil
+}
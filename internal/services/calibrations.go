@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис калибровок сенсоров
+
+type CalibrationService struct {
+	repo    IStoreCalibration
+	cache   domain.Cache
+	log     *logrus.Logger
+	virtual *VirtualSensorService
+	anomaly *AnomalyService
+}
+
+func NewCalibrationService(log *logrus.Logger, cache domain.Cache, repo IStoreCalibration,
+	virtual *VirtualSensorService, anomaly *AnomalyService) *CalibrationService {
+	return &CalibrationService{log: log, cache: cache, repo: repo, virtual: virtual, anomaly: anomaly}
+}
+
+func calibrationsCacheKey(sensorId int) string {
+	return fmt.Sprintf("calibrations-%d", sensorId)
+}
+
+func (s *CalibrationService) Create(userId, sensorId int, input domain.CreateSensorCalibration) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionManage); err != nil {
+		return 0, err
+	}
+
+	c := domain.SensorCalibration{
+		SensorID:  sensorId,
+		Scale:     1,
+		Points:    input.Points,
+		ValidFrom: time.Now().UTC(),
+	}
+	if input.Offset != nil {
+		c.Offset = *input.Offset
+	}
+	if input.Scale != nil {
+		c.Scale = *input.Scale
+	}
+	if input.ValidFrom != nil {
+		c.ValidFrom = input.ValidFrom.UTC()
+	}
+	if input.Description != nil {
+		c.Description = *input.Description
+	}
+
+	id, err := s.repo.Create(c)
+	if err != nil {
+		return 0, err
+	}
+
+	s.resetCache(sensorId)
+	return id, nil
+}
+
+func (s *CalibrationService) GetAll(userId, sensorId int) ([]domain.SensorCalibration, error) {
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAll_OfSensors([]int{sensorId})
+}
+
+func (s *CalibrationService) Delete(userId, sensorId, id int) error {
+	if _, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(sensorId, id); err != nil {
+		return err
+	}
+
+	s.resetCache(sensorId)
+	return nil
+}
+
+// Размер страницы показаний при пересчёте
+const recomputeBatchSize = 1000
+
+// Recompute пересчитывает сохранённые показания сенсора начиная с from
+// по исходным значениям и текущей истории калибровок. Возвращает число изменённых показаний
+// и зависящие от них ряды, которые не пересчитываются (см. domain.RecomputeResult).
+// Пересчёт идемпотентен: после ошибки его можно запустить повторно с того же from.
+func (s *CalibrationService) Recompute(userId, sensorId int, from time.Time) (domain.RecomputeResult, error) {
+	accountId, err := s.repo.GetSensorAccount_OfUser(userId, sensorId, domain.PermissionManage)
+	if err != nil {
+		return domain.RecomputeResult{}, err
+	}
+
+	updated, err := s.recompute(sensorId, from)
+	if err != nil || updated == 0 {
+		return domain.RecomputeResult{Updated: updated}, err
+	}
+
+	res := domain.RecomputeResult{Updated: updated}
+	if s.virtual != nil {
+		if res.StaleVirtualSensors, err = s.virtual.dependents(accountId, sensorId); err != nil {
+			return res, err
+		}
+	}
+	if s.anomaly != nil {
+		if res.StaleFlags, err = s.anomaly.enabled(sensorId); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+func (s *CalibrationService) recompute(sensorId int, from time.Time) (int, error) {
+
+	history, err := s.repo.GetAll_OfSensors([]int{sensorId})
+	if err != nil {
+		return 0, err
+	}
+
+	// Показания читаются и перезаписываются страницами: память и время каждого запроса ограничены
+	// размером страницы, а не длиной истории сенсора
+	updated := 0
+	afterTime, afterId := from, 0
+	for {
+		list, err := s.repo.GetRawData_OfSensor(sensorId, from, afterTime, afterId, recomputeBatchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(list) == 0 {
+			return updated, nil
+		}
+		last := list[len(list)-1]
+		afterTime, afterId = last.CreatedAt, last.ID
+
+		var changed []domain.SensorDataSet
+		for _, x := range list {
+			if x.Raw_value == nil {
+				continue
+			}
+
+			value := *x.Raw_value
+			if c, ok := domain.CalibrationAt(history, x.CreatedAt); ok {
+				if v, err := calibrate(c, value); err == nil {
+					value = v
+				}
+			}
+
+			if value != x.Value {
+				x.Value = value
+				changed = append(changed, x)
+			}
+		}
+
+		if len(changed) > 0 {
+			if err := s.repo.UpdateValues(changed); err != nil {
+				return updated, err
+			}
+			updated += len(changed)
+		}
+
+		if len(list) < recomputeBatchSize {
+			return updated, nil
+		}
+	}
+}
+
+func (s *CalibrationService) resetCache(sensorId int) {
+	if s.cache != nil {
+		s.cache.Delete(calibrationsCacheKey(sensorId))
+	}
+}
+
+// История калибровок сенсоров; сенсоры без калибровок тоже кешируются (пустым списком)
+func (s *CalibrationService) getHistory(sensorIds []int) (map[int][]domain.SensorCalibration, error) {
+
+	history := make(map[int][]domain.SensorCalibration, len(sensorIds))
+
+	var missing []int
+	for _, id := range sensorIds {
+		if s.cache != nil {
+			if list, err := s.cache.Get(calibrationsCacheKey(id)); err == nil {
+				history[id] = list.([]domain.SensorCalibration)
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return history, nil
+	}
+
+	list, err := s.repo.GetAll_OfSensors(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[int][]domain.SensorCalibration, len(missing))
+	for _, c := range list {
+		loaded[c.SensorID] = append(loaded[c.SensorID], c)
+	}
+	for _, id := range missing {
+		history[id] = loaded[id]
+		if s.cache != nil {
+			s.cache.Set(calibrationsCacheKey(id), loaded[id])
+		}
+	}
+
+	return history, nil
+}
+
+// Apply калибрует пришедшие показания перед сохранением.
+// Исходное значение сохраняется в Raw_value, калиброванное - в Value.
+func (s *CalibrationService) Apply(list []domain.SensorDataSet) {
+
+	ids := make([]int, 0, len(list))
+	for _, x := range list {
+		if !containsInt(ids, x.Sensor_id) {
+			ids = append(ids, x.Sensor_id)
+		}
+	}
+
+	history, err := s.getHistory(ids)
+	if err != nil {
+		s.log.Errorf("calibrations: %s", err.Error())
+		history = nil
+	}
+
+	for i := range list {
+		raw := list[i].Value
+		list[i].Raw_value = &raw
+
+		c, ok := domain.CalibrationAt(history[list[i].Sensor_id], list[i].CreatedAt)
+		if !ok {
+			continue
+		}
+
+		if v, err := calibrate(c, raw); err == nil {
+			list[i].Value = v
+		} else {
+			s.log.Infof("calibration of sensor %d: %s", list[i].Sensor_id, err.Error())
+		}
+	}
+}
+
+// Значения хранятся строками; нечисловые показания не калибруются
+func calibrate(c domain.SensorCalibration, raw string) (string, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return "", errors.New("value is not a number")
+	}
+
+	return strconv.FormatFloat(c.Apply(v), 'g', 10, 64), nil
+}
@@ -31,9 +31,9 @@ func (s *ChecklistService) Create(userId int, list domain.CreateChecklist) (int,
 
 // Метод GetAll, который будет принимать id пользователя
 // и возвращать слайс списка вместе с ошибкой.
-func (s *ChecklistService) GetAllChecklistOfUser(userId int) ([]domain.Checklist, error) {
+func (s *ChecklistService) GetAllChecklistOfUser(userId int, includeArchived bool) ([]domain.Checklist, error) {
 	// В сервисе мы будем вызывать аналогичный метод репозитория, поскольку дополнительной бизнес логики тут нет.
-	return s.repo.GetAll_ChecklistOfUser(userId)
+	return s.repo.GetAll_ChecklistOfUser(userId, includeArchived)
 }
 
 func (s *ChecklistService) GetById(userId, listId int) (*domain.Checklist, error) {
@@ -48,6 +48,11 @@ func (s *ChecklistService) Update(userId int, input domain.UpdateChecklist) erro
 	return s.repo.Update(userId, input)
 }
 
+// Удаление переносит список в архив, окончательно его удаляет плановая задача
 func (s *ChecklistService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+	return s.repo.Archive(userId, listId)
+}
+
+func (s *ChecklistService) Restore(userId, listId int) error {
+	return s.repo.Restore(userId, listId)
 }
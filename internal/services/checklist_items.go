@@ -7,10 +7,11 @@ import (
 type ChecklistItemService struct {
 	repo     IStoreChecklistItem
 	listRepo IStoreChecklist
+	webhooks *WebhookService
 }
 
-func NewChecklistItemService(repo IStoreChecklistItem, listRepo IStoreChecklist) *ChecklistItemService {
-	return &ChecklistItemService{repo: repo, listRepo: listRepo}
+func NewChecklistItemService(repo IStoreChecklistItem, listRepo IStoreChecklist, webhooks *WebhookService) *ChecklistItemService {
+	return &ChecklistItemService{repo: repo, listRepo: listRepo, webhooks: webhooks}
 }
 
 func (s *ChecklistItemService) Create(userId, listId int, item domain.ChecklistItem) (int, error) {
@@ -20,21 +21,43 @@ func (s *ChecklistItemService) Create(userId, listId int, item domain.ChecklistI
 		return 0, err
 	}
 
-	return s.repo.Create(listId, item)
+	// the item is added only to list of account managed by user
+	return s.repo.Create(userId, listId, item)
 }
 
-func (s *ChecklistItemService) GetAll(userId, listId int) ([]domain.ChecklistItem, error) {
-	return s.repo.GetAll(userId, listId)
+func (s *ChecklistItemService) GetAll(userId, listId int, includeArchived bool) ([]domain.ChecklistItem, error) {
+	return s.repo.GetAll(userId, listId, includeArchived)
 }
 
 func (s *ChecklistItemService) GetById(userId, listId, itemId int) (domain.ChecklistItem, error) {
 	return s.repo.GetById(userId, listId, itemId)
 }
 
+// Выполнение последнего невыполненного пункта завершает чек-лист - событие checklist.completed
 func (s *ChecklistItemService) Update(userId, listId, itemId int, input domain.UpdateChecklistItem) error {
-	return s.repo.Update(userId, listId, itemId, input)
+	completing := false
+	if input.Done != nil && *input.Done {
+		item, err := s.repo.GetById(userId, listId, itemId)
+		if err != nil {
+			return err
+		}
+		completing = !item.Done
+	}
+
+	if err := s.repo.Update(userId, listId, itemId, input); err != nil {
+		return err
+	}
+
+	if completing {
+		s.webhooks.PublishChecklistCompleted(listId)
+	}
+	return nil
 }
 
 func (s *ChecklistItemService) Delete(userId, listId, itemId int) error {
-	return s.repo.Delete(userId, listId, itemId)
+	return s.repo.Archive(userId, listId, itemId)
+}
+
+func (s *ChecklistItemService) Restore(userId, listId, itemId int) error {
+	return s.repo.Restore(userId, listId, itemId)
 }
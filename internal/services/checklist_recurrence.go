@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/rrule"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис повторяющихся чек-листов: чек-лист с правилом повторения становится шаблоном,
+// плановая задача в начале каждого периода создаёт экземпляр с неотмеченными пунктами шаблона.
+// Если задача не работала несколько периодов, создаётся только экземпляр текущего периода.
+
+type ChecklistRecurrenceService struct {
+	repo IStoreChecklistRecurrence
+	log  *logrus.Logger
+}
+
+func NewChecklistRecurrenceService(log *logrus.Logger, repo IStoreChecklistRecurrence) *ChecklistRecurrenceService {
+	return &ChecklistRecurrenceService{log: log, repo: repo}
+}
+
+// Временная зона аккаунта; неизвестная зона - UTC
+func accountLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (s *ChecklistRecurrenceService) Get(userId, checklistId int) (*domain.ChecklistRecurrence, error) {
+	rec, err := s.repo.GetById(userId, checklistId, domain.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if rec.RRule == "" {
+		return nil, errors.New("checklist is not recurring")
+	}
+	return rec, nil
+}
+
+// Set делает чек-лист шаблоном; первый экземпляр создаётся в первое повторение не раньше начала
+func (s *ChecklistRecurrenceService) Set(userId, checklistId int, input domain.SetChecklistRecurrence) (*domain.ChecklistRecurrence, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	rule, err := rrule.Parse(input.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidRecurrence, err.Error())
+	}
+
+	rec, err := s.repo.GetById(userId, checklistId, domain.PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now().Truncate(time.Minute)
+	if input.Start != nil {
+		start = *input.Start
+	}
+	start = start.In(accountLocation(rec.Timezone))
+
+	next := rule.Next(start, start.Add(-time.Second))
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: rule has no occurrences", domain.ErrInvalidRecurrence)
+	}
+
+	rec.RRule = strings.TrimPrefix(strings.TrimSpace(input.RRule), "RRULE:")
+	rec.Start = &start
+	rec.NextRunAt = &next
+
+	if err := s.repo.Set(*rec); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// Delete останавливает повторение; созданные экземпляры остаются
+func (s *ChecklistRecurrenceService) Delete(userId, checklistId int) error {
+	rec, err := s.repo.GetById(userId, checklistId, domain.PermissionManage)
+	if err != nil {
+		return err
+	}
+
+	rec.RRule, rec.Start, rec.NextRunAt = "", nil, nil
+	return s.repo.Set(*rec)
+}
+
+func (s *ChecklistRecurrenceService) GetInstances(userId, checklistId int, from, to time.Time) ([]domain.ChecklistInstance, error) {
+	if !to.After(from) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetInstances_OfUser(userId, checklistId, from, to)
+}
+
+// RunDue - плановая задача: экземпляры шаблонов, период которых начался
+func (s *ChecklistRecurrenceService) RunDue(ctx context.Context) {
+
+	now := time.Now().UTC()
+
+	list, err := s.repo.GetDue(now)
+	if err != nil {
+		s.log.Errorf("checklist recurrence job: %s", err.Error())
+		return
+	}
+
+	for _, rec := range list {
+		if ctx.Err() != nil {
+			return
+		}
+		if rec.Start == nil || rec.NextRunAt == nil {
+			continue
+		}
+
+		rule, err := rrule.Parse(rec.RRule)
+		if err != nil {
+			s.log.Errorf("checklist recurrence job: checklist %d: %s", rec.ChecklistID, err.Error())
+			continue
+		}
+
+		start := rec.Start.In(accountLocation(rec.Timezone))
+		from, to := rule.Current(start, *rec.NextRunAt, now)
+
+		var end *time.Time
+		if !to.IsZero() {
+			end = &to
+		}
+
+		if _, err := s.repo.CreateInstance(rec, from, end); err != nil {
+			s.log.Errorf("checklist recurrence job: checklist %d: %s", rec.ChecklistID, err.Error())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис команд устройствам: команды ставятся в очередь хаба пользователем или правилом автоматизации,
+// хаб забирает их запросом с токенами хаба и подтверждает выполнение.
+
+type CommandService struct {
+	repo IStoreCommand
+	log  *logrus.Logger
+	auth *AuthService
+}
+
+func NewCommandService(log *logrus.Logger, repo IStoreCommand, auth *AuthService) *CommandService {
+	return &CommandService{log: log, repo: repo, auth: auth}
+}
+
+// Сколько команд хаб получает за один запрос
+const hubCommandsBatch = 20
+
+func (s *CommandService) Create(userId int, input domain.CreateDeviceCommand) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	accountId, err := s.repo.GetDeviceAccount_OfUser(userId, input.DeviceID, domain.PermissionManage)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Queue(accountId, nil, input.DeviceID, input.Command, input.Value)
+}
+
+// Queue ставит команду устройству аккаунта в очередь; automationId - правило, создавшее команду
+func (s *CommandService) Queue(accountId int, automationId *int, deviceId int, command, value string) (int, error) {
+	if err := domain.ValidateCommand(command, value); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(domain.DeviceCommand{
+		AccountID:    accountId,
+		DeviceID:     deviceId,
+		AutomationID: automationId,
+		Command:      command,
+		Value:        value,
+		ExpiresAt:    time.Now().UTC().Add(domain.CommandTTL),
+	})
+}
+
+// QueueHub ставит в очередь команду хабу целиком (без устройства)
+func (s *CommandService) QueueHub(accountId, aquahubId int, command, value string) (int, error) {
+	if err := domain.ValidateCommand(command, value); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create_OfAquahub(domain.DeviceCommand{
+		AccountID: accountId,
+		AquahubID: aquahubId,
+		Command:   command,
+		Value:     value,
+		ExpiresAt: time.Now().UTC().Add(domain.CommandTTL),
+	})
+}
+
+func (s *CommandService) GetAll(userId int, filter domain.DeviceCommandsFilter) ([]domain.DeviceCommand, error) {
+	if !filter.To.After(filter.From) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetAll_OfUser(userId, filter)
+}
+
+func (s *CommandService) aquahub(h_token, u_token string) (int, error) {
+	id, err := s.auth.GetAquahubIdfromTokens(h_token, u_token)
+	if err != nil || id < 1 {
+		return 0, domain.ErrInvalidHubToken
+	}
+	return id, nil
+}
+
+// Poll - команды для хаба. Команда выдаётся повторно, пока хаб её не подтвердит или она не истечёт.
+func (s *CommandService) Poll(h_token, u_token string) ([]domain.HubCommand, error) {
+	aquahubId, err := s.aquahub(h_token, u_token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Claim_OfAquahub(aquahubId, hubCommandsBatch)
+}
+
+func (s *CommandService) Ack(h_token, u_token string, id int) error {
+	aquahubId, err := s.aquahub(h_token, u_token)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Ack(aquahubId, id)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/coverage"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис покрытия данными: пропуски в показаниях и процент покрытия периода
+
+type CoverageService struct {
+	repo IStoreCoverage
+	log  *logrus.Logger
+}
+
+func NewCoverageService(log *logrus.Logger, repo IStoreCoverage) *CoverageService {
+	return &CoverageService{log: log, repo: repo}
+}
+
+// Сколько последних показаний брать для оценки интервала, если в периоде их меньше двух
+const learnIntervalReadings = 50
+
+func checkCoveragePeriod(from, to time.Time) error {
+	if !to.After(from) {
+		return errors.New("period end must be after period start")
+	}
+	if to.Sub(from) > domain.MaxCoveragePeriod {
+		return errors.New("period must not exceed 31 days")
+	}
+	return nil
+}
+
+func (s *CoverageService) SetReportInterval(userId, sensorId int, input domain.SetReportInterval) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.SetReportInterval(userId, sensorId, input.ReportIntervalSec)
+}
+
+// Report рассчитывает покрытие за период [from, to) по сенсорам уровня level и сводит его
+func (s *CoverageService) Report(userId int, level string, id int, filter domain.GroupFilter, from, to time.Time) (domain.CoverageReport, error) {
+
+	report := domain.CoverageReport{Level: level, ID: id, From: from, To: to, Sensors: []domain.SensorCoverage{}}
+
+	if !domain.ValidCoverageLevel(level) {
+		return report, domain.ErrInvalidCoverageLevel
+	}
+	if err := checkCoveragePeriod(from, to); err != nil {
+		return report, err
+	}
+
+	sensors, err := s.repo.GetSensors_OfUser(userId, level, id, filter)
+	if err != nil {
+		return report, err
+	}
+	if len(sensors) == 0 {
+		return report, errors.New("no sensors found")
+	}
+
+	var missing time.Duration
+	for _, sensor := range sensors {
+		c, err := s.computeSensor(sensor, from, to)
+		if err != nil {
+			return report, err
+		}
+		report.Sensors = append(report.Sensors, c)
+		missing += time.Duration(c.MissingSeconds) * time.Second
+	}
+
+	expected := to.Sub(from) * time.Duration(len(sensors))
+	report.MissingSeconds = int(missing.Seconds())
+	report.Coverage = percent(1 - float64(missing)/float64(expected))
+
+	return report, nil
+}
+
+// Daily сводит рассчитанное плановой задачей суточное покрытие на уровень level
+func (s *CoverageService) Daily(userId int, level string, id int, filter domain.GroupFilter, from, to time.Time) ([]domain.CoverageDay, error) {
+
+	if !domain.ValidCoverageLevel(level) {
+		return nil, domain.ErrInvalidCoverageLevel
+	}
+	if !to.After(from) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	sensors, err := s.repo.GetSensors_OfUser(userId, level, id, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(sensors))
+	for i, sensor := range sensors {
+		ids[i] = sensor.ID
+	}
+
+	list, err := s.repo.GetDaily_OfSensors(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []domain.CoverageDay{}
+	for _, x := range list {
+		day := x.Day.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Day != day {
+			days = append(days, domain.CoverageDay{Day: day})
+		}
+		d := &days[len(days)-1]
+		d.Sensors++
+		d.MissingSeconds += x.MissingSeconds
+	}
+	for i := range days {
+		expected := float64(days[i].Sensors * 24 * 3600)
+		days[i].Coverage = percent(1 - float64(days[i].MissingSeconds)/expected)
+	}
+
+	return days, nil
+}
+
+// RunDaily - плановая задача: рассчитывает покрытие за прошедшие сутки (UTC)
+// для сенсоров, по которым оно ещё не рассчитано.
+func (s *CoverageService) RunDaily(ctx context.Context) {
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.Add(-24 * time.Hour)
+
+	done, err := s.repo.GetDailyComputed(from)
+	if err != nil {
+		s.log.Errorf("coverage job: %s", err.Error())
+		return
+	}
+
+	sensors, err := s.repo.GetSensors_Active()
+	if err != nil {
+		s.log.Errorf("coverage job: %s", err.Error())
+		return
+	}
+
+	var computed int
+	for _, sensor := range sensors {
+		if ctx.Err() != nil {
+			return
+		}
+		if containsInt(done, sensor.ID) {
+			continue
+		}
+
+		c, err := s.computeSensor(sensor, from, to)
+		if err != nil {
+			s.log.Errorf("coverage job: sensor %d: %s", sensor.ID, err.Error())
+			continue
+		}
+
+		err = s.repo.SaveDaily(domain.SensorCoverageDaily{
+			SensorID:       sensor.ID,
+			Day:            from,
+			IntervalSec:    c.IntervalSeconds,
+			Readings:       c.Readings,
+			Coverage:       c.Coverage,
+			MissingSeconds: c.MissingSeconds,
+			Gaps:           c.Gaps,
+		})
+		if err != nil {
+			continue
+		}
+		computed++
+	}
+
+	if computed > 0 {
+		s.log.Infof("coverage job: computed %d sensors for %s", computed, from.Format("2006-01-02"))
+	}
+}
+
+func (s *CoverageService) computeSensor(sensor domain.CoverageSensor, from, to time.Time) (domain.SensorCoverage, error) {
+
+	c := domain.SensorCoverage{
+		SensorID:  sensor.ID,
+		DeviceID:  sensor.DeviceID,
+		AquahubID: sensor.AquahubID,
+		Title:     sensor.Title,
+		Gaps:      domain.CoverageGaps{},
+	}
+
+	times, err := s.repo.GetTimes_OfSensor(sensor.ID, from, to)
+	if err != nil {
+		return c, err
+	}
+
+	var interval time.Duration
+	if sensor.ReportIntervalSec != nil {
+		interval = time.Duration(*sensor.ReportIntervalSec) * time.Second
+		c.IntervalSource = domain.IntervalSource_Configured
+	} else {
+		c.IntervalSource = domain.IntervalSource_Learned
+		interval = coverage.LearnInterval(times)
+		if interval == 0 {
+			last, err := s.repo.GetLastTimes_OfSensor(sensor.ID, to, learnIntervalReadings)
+			if err != nil {
+				return c, err
+			}
+			interval = coverage.LearnInterval(last)
+		}
+	}
+
+	res := coverage.Compute(times, from, to, interval)
+
+	c.IntervalSeconds = int(interval.Seconds())
+	c.Readings = res.Readings
+	c.Coverage = percent(res.Coverage())
+	c.MissingSeconds = int(res.Missing.Seconds())
+	for _, g := range res.Gaps {
+		c.Gaps = append(c.Gaps, domain.CoverageGap{From: g.From, To: g.To, Seconds: int(g.Duration().Seconds())})
+	}
+
+	return c, nil
+}
+
+// Доля в проценты с точностью до сотых
+func percent(share float64) float64 {
+	return math.Round(share*10000) / 100
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Каталог типов устройств (см. domain/device_type.go)
+
+type DeviceTypeService struct {
+	repo     IStoreDeviceType
+	alert    *AlertService
+	webhooks *WebhookService
+	log      *logrus.Logger
+}
+
+func NewDeviceTypeService(log *logrus.Logger, repo IStoreDeviceType, alert *AlertService, webhooks *WebhookService) *DeviceTypeService {
+	return &DeviceTypeService{log: log, repo: repo, alert: alert, webhooks: webhooks}
+}
+
+func (s *DeviceTypeService) GetAll(userId, accountId int) ([]domain.DeviceType, error) {
+	return s.repo.GetAll_OfUser(userId, accountId)
+}
+
+func (s *DeviceTypeService) GetById(userId, id int) (*domain.DeviceType, error) {
+	return s.repo.GetById(userId, id)
+}
+
+func (s *DeviceTypeService) Create(userId int, input domain.CreateDeviceType) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(userId, input)
+}
+
+// Шаблоны аккаунта меняются, общий каталог - только миграциями
+func (s *DeviceTypeService) Update(userId, id int, input domain.DeviceTypeInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	t, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+	if t.AccountID == nil {
+		return domain.ErrDeviceTypeReadOnly
+	}
+
+	return s.repo.Update(userId, id, input)
+}
+
+func (s *DeviceTypeService) Delete(userId, id int) error {
+	t, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+	if t.AccountID == nil {
+		return domain.ErrDeviceTypeReadOnly
+	}
+
+	return s.repo.Delete(userId, id)
+}
+
+// SetDeviceType - описание устройства пользователя по типу из общего каталога или шаблонов аккаунта устройства
+func (s *DeviceTypeService) SetDeviceType(userId, deviceId int, input domain.SetDeviceType) error {
+	accountId, err := s.repo.GetDeviceAccount_OfUser(userId, deviceId, domain.PermissionManage)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.repo.GetById(userId, input.DeviceTypeID)
+	if err != nil {
+		return err
+	}
+	if t.AccountID != nil && *t.AccountID != accountId {
+		return errors.New("device type belongs to another account")
+	}
+
+	return s.apply(deviceId, *t, true)
+}
+
+// Describe - устройство хаба без типа, сообщившее о модели model, описывается по каталогу;
+// возвращает название типа или "", если модель неизвестна или у устройства уже есть тип
+func (s *DeviceTypeService) Describe(aquahubId, deviceId int, model string) (string, error) {
+	t, err := s.repo.GetByModel_OfAquahub(aquahubId, model)
+	if err != nil || t == nil {
+		return "", err
+	}
+
+	described, created, err := s.repo.Apply(deviceId, *t, false)
+	if err != nil || described == nil {
+		return "", err
+	}
+
+	s.log.Infof("device %d of aquahub %d: described by device type %d (%s), %d sensors created",
+		deviceId, aquahubId, t.ID, t.Model, len(created))
+	s.applied(described, created)
+	return t.Title, nil
+}
+
+// DescribeSensor - новый сенсор устройства с типом описывается по раскладке типа
+func (s *DeviceTypeService) DescribeSensor(sensorId int) error {
+	described, err := s.repo.DescribeSensor(sensorId)
+	if err != nil || !described {
+		return err
+	}
+
+	s.applied([]int{sensorId}, nil)
+	return nil
+}
+
+func (s *DeviceTypeService) apply(deviceId int, t domain.DeviceType, force bool) error {
+	described, created, err := s.repo.Apply(deviceId, t, force)
+	if err != nil {
+		return err
+	}
+
+	s.applied(described, created)
+	return nil
+}
+
+// У описанных сенсоров могли появиться правила оповещения по умолчанию - кеш правил сбрасывается;
+// о созданных сенсорах уходит событие sensor.added
+func (s *DeviceTypeService) applied(described []int, created []domain.WebhookSensor) {
+	if s.alert != nil {
+		for _, id := range described {
+			s.alert.resetCache(id)
+		}
+	}
+	for _, x := range created {
+		s.webhooks.PublishHub(x.AquahubID, domain.WebhookSensorAdded, x)
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис сводок: плановая задача собирает для подписанных участников аккаунтов сводку за прошедшие
+// сутки или неделю по часовому поясу пользователя, сохраняет её в архив и отправляет уведомлением.
+
+type DigestService struct {
+	repo   IStoreDigest
+	log    *logrus.Logger
+	notify *NotificationService
+}
+
+func NewDigestService(log *logrus.Logger, repo IStoreDigest, notify *NotificationService) *DigestService {
+	return &DigestService{log: log, repo: repo, notify: notify}
+}
+
+// Час отправки по умолчанию
+const defaultDigestHour = 8
+
+// Подписка пользователя; без подписки - выключенные сводки с часом по умолчанию
+func (s *DigestService) GetSubscription(userId, accountId int) (*domain.DigestSubscription, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	sub, err := s.repo.GetSubscription(userId, accountId)
+	if err != nil || sub != nil {
+		return sub, err
+	}
+
+	tz, err := s.repo.GetTimezone_OfUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.DigestSubscription{UserID: userId, AccountID: accountId, Hour: defaultDigestHour, Timezone: tz}, nil
+}
+
+func (s *DigestService) SetSubscription(userId, accountId int, input domain.SetDigestSubscription) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return err
+	}
+
+	sub := domain.DigestSubscription{
+		UserID:    userId,
+		AccountID: accountId,
+		Daily:     *input.Daily,
+		Weekly:    *input.Weekly,
+		Hour:      defaultDigestHour,
+	}
+	if input.Hour != nil {
+		sub.Hour = *input.Hour
+	}
+
+	return s.repo.SetSubscription(sub)
+}
+
+func (s *DigestService) DeleteSubscription(userId, accountId int) error {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(userId, accountId)
+}
+
+func (s *DigestService) GetReports(userId int, filter domain.DigestReportsFilter) ([]domain.DigestReport, error) {
+	if filter.Kind != "" && filter.Kind != domain.DigestDaily && filter.Kind != domain.DigestWeekly {
+		return nil, domain.ErrUnknownDigestKind
+	}
+	if !filter.To.After(filter.From) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetReports_OfUser(userId, filter)
+}
+
+func (s *DigestService) GetReport(userId, id int) (*domain.DigestReport, error) {
+	return s.repo.GetReport_OfUser(userId, id)
+}
+
+// Последний завершившийся период сводки в часовом поясе loc и наступило ли время его отправки:
+// сутки до сегодняшней полуночи или неделя до понедельника текущей недели; отправка - в hour часов
+func digestPeriod(kind string, hour int, now time.Time, loc *time.Location) (from, to time.Time, due bool) {
+	local := now.In(loc)
+	to = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	if kind == domain.DigestWeekly {
+		to = to.AddDate(0, 0, -((int(to.Weekday()) + 6) % 7))
+		from = to.AddDate(0, 0, -7)
+	} else {
+		from = to.AddDate(0, 0, -1)
+	}
+
+	send := time.Date(to.Year(), to.Month(), to.Day(), hour, 0, 0, 0, loc)
+	return from, to, !now.Before(send)
+}
+
+// RunDue - плановая задача: сводки, время отправки которых наступило. Пропущенные при простое
+// периоды не догоняются - отправляется только последний.
+func (s *DigestService) RunDue(ctx context.Context) {
+
+	subs, err := s.repo.GetEnabled()
+	if err != nil {
+		s.log.Errorf("digest job: %s", err.Error())
+		return
+	}
+
+	// Участники аккаунта в одном часовом поясе получают одинаковые данные
+	built := make(map[string]domain.DigestData)
+	now := time.Now()
+
+	for _, sub := range subs {
+		for _, kind := range []string{domain.DigestDaily, domain.DigestWeekly} {
+			if ctx.Err() != nil {
+				return
+			}
+
+			last := sub.LastDailyStart
+			if kind == domain.DigestWeekly {
+				if !sub.Weekly {
+					continue
+				}
+				last = sub.LastWeeklyStart
+			} else if !sub.Daily {
+				continue
+			}
+
+			loc := accountLocation(sub.Timezone)
+			from, to, due := digestPeriod(kind, sub.Hour, now, loc)
+			if !due || last != nil && !last.Before(from) {
+				continue
+			}
+
+			key := fmt.Sprintf("%d-%d-%d", sub.AccountID, from.Unix(), to.Unix())
+			data, ok := built[key]
+			if !ok {
+				if data, err = s.build(sub.AccountID, from, to); err != nil {
+					s.log.Errorf("digest job: account %d: %s", sub.AccountID, err.Error())
+					continue
+				}
+				built[key] = data
+			}
+
+			s.send(sub, kind, from, to, loc, data)
+		}
+	}
+}
+
+// Данные сводки аккаунта за период
+func (s *DigestService) build(accountId int, from, to time.Time) (domain.DigestData, error) {
+	var data domain.DigestData
+
+	hubs, err := s.repo.GetHubs_OfAccount(accountId)
+	if err != nil {
+		return data, err
+	}
+	stats, err := s.repo.GetSensorStats(accountId, from, to)
+	if err != nil {
+		return data, err
+	}
+	offline, err := s.repo.GetOfflinePeriods(accountId, from, to, domain.HubOfflineAfter)
+	if err != nil {
+		return data, err
+	}
+	if data.Alerts, err = s.repo.GetAlerts(accountId, from, to); err != nil {
+		return data, err
+	}
+	if data.OverdueItems, err = s.repo.CountOverdueItems(accountId, to); err != nil {
+		return data, err
+	}
+
+	for _, hub := range hubs {
+		hub.Sensors = []domain.DigestSensor{}
+		hub.Offline = []domain.DigestPeriod{}
+		for _, x := range stats {
+			if x.AquahubID == hub.AquahubID {
+				hub.Sensors = append(hub.Sensors, x)
+			}
+		}
+		for _, p := range offline {
+			if p.AquahubID == hub.AquahubID {
+				hub.Offline = append(hub.Offline, p)
+			}
+		}
+		data.Hubs = append(data.Hubs, hub)
+	}
+	if data.Alerts == nil {
+		data.Alerts = []domain.DigestAlert{}
+	}
+
+	return data, nil
+}
+
+// Архив и уведомление; сводка за период сохраняется один раз, даже если задача запущена параллельно
+func (s *DigestService) send(sub domain.DigestSubscription, kind string, from, to time.Time, loc *time.Location, data domain.DigestData) {
+
+	id, err := s.repo.CreateReport(domain.DigestReport{
+		UserID:      sub.UserID,
+		AccountID:   sub.AccountID,
+		Kind:        kind,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		Timezone:    loc.String(),
+		Report:      data,
+	})
+	if err != nil {
+		s.log.Errorf("digest job: user %d: %s", sub.UserID, err.Error())
+		return
+	}
+
+	if id != 0 && s.notify != nil {
+		title := digestTitle(kind, from, to)
+		s.notify.NotifyUser(sub.UserID, domain.Notification{
+			AccountID: sub.AccountID,
+			Event:     domain.NotifyDigest,
+			SourceID:  id,
+			Message:   title,
+			Report:    digestText(title, data, loc),
+			Time:      to,
+		})
+	}
+
+	if err := s.repo.SetLastStart(sub.UserID, sub.AccountID, kind, from); err != nil {
+		s.log.Errorf("digest job: user %d: %s", sub.UserID, err.Error())
+	}
+}
+
+func digestTitle(kind string, from, to time.Time) string {
+	if kind == domain.DigestWeekly {
+		return fmt.Sprintf("Weekly digest %s - %s", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Daily digest %s", from.Format("2006-01-02"))
+}
+
+// Текст сводки для уведомления; время - в часовом поясе пользователя
+func digestText(title string, data domain.DigestData, loc *time.Location) string {
+	const layout = "Jan 2 15:04"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n", title, loc.String())
+
+	for _, hub := range data.Hubs {
+		// Время хаба из другого часового пояса - по месту хаба
+		hubLoc := loc
+		if hub.Timezone != "" && hub.Timezone != loc.String() {
+			hubLoc = accountLocation(hub.Timezone)
+		}
+
+		fmt.Fprintf(&b, "\n%s", hub.Title)
+		if hub.Place != "" {
+			fmt.Fprintf(&b, ", %s", hub.Place)
+		}
+		if hubLoc != loc {
+			fmt.Fprintf(&b, " (%s)", hubLoc.String())
+		}
+		b.WriteString("\n")
+		if len(hub.Sensors) == 0 {
+			b.WriteString("  no readings\n")
+		}
+		for _, x := range hub.Sensors {
+			fmt.Fprintf(&b, "  %s / %s: min %.2f, avg %.2f, max %.2f (%d readings)\n",
+				x.Device, x.Sensor, x.Min, x.Avg, x.Max, x.Count)
+		}
+		for _, p := range hub.Offline {
+			fmt.Fprintf(&b, "  offline %s - %s\n", p.From.In(hubLoc).Format(layout), p.To.In(hubLoc).Format(layout))
+		}
+	}
+
+	fmt.Fprintf(&b, "\nAlerts: %d\n", len(data.Alerts))
+	for _, a := range data.Alerts {
+		fmt.Fprintf(&b, "  %s %s (%s)\n", a.StartedAt.In(loc).Format(layout), a.Message, a.Status)
+	}
+
+	fmt.Fprintf(&b, "\nOverdue checklist items: %d\n", data.OverdueItems)
+	return b.String()
+}
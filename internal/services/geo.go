@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис местоположения хабов и аккаунтов по справочнику geonames (см. domain/geo.go)
+
+type GeoService struct {
+	repo IStoreGeo
+	log  *logrus.Logger
+}
+
+func NewGeoService(log *logrus.Logger, repo IStoreGeo) *GeoService {
+	return &GeoService{log: log, repo: repo}
+}
+
+func (s *GeoService) GetCountries() ([]domain.Country, error) {
+	return s.repo.GetCountries()
+}
+
+func (s *GeoService) GetTimezones(country string) ([]string, error) {
+	return s.repo.GetTimezones(strings.ToUpper(country))
+}
+
+// FindPlaces - автодополнение места по началу индекса или названия
+func (s *GeoService) FindPlaces(country, q string, limit int) ([]domain.GeoPlace, error) {
+	q = strings.TrimSpace(q)
+	if len(q) < domain.GeoQueryMinLen {
+		return []domain.GeoPlace{}, nil
+	}
+	if limit <= 0 {
+		limit = domain.GeoPlacesLimit
+	}
+	if limit > domain.GeoPlacesLimitMax {
+		limit = domain.GeoPlacesLimitMax
+	}
+
+	return s.repo.FindPlaces(strings.ToUpper(country), q, limit)
+}
+
+// Местоположение по индексу страны; current - текущий часовой пояс, сохраняется, если он есть в стране
+func (s *GeoService) resolve(input domain.SetLocation, current string) (domain.Location, error) {
+	if err := input.Validate(); err != nil {
+		return domain.Location{}, err
+	}
+
+	place, err := s.repo.GetPlace(input.Country, input.PostalCode, input.PlaceName)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	timezones, err := s.repo.GetTimezones(input.Country)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	tz, err := domain.LocationTimezone(input.Timezone, timezones, current)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	return domain.Location{
+		Country:    place.CountryCode,
+		PostalCode: place.PostalCode,
+		PlaceName:  place.PlaceName,
+		StateName:  place.StateName,
+		Latitude:   place.Latitude,
+		Longitude:  place.Longitude,
+		Timezone:   tz,
+	}, nil
+}
+
+func (s *GeoService) GetAquahubLocation(userId, aquahubId int) (*domain.Location, error) {
+	return s.repo.GetAquahubLocation_OfUser(userId, aquahubId, domain.PermissionRead)
+}
+
+// SetAquahubLocation - местоположение и часовой пояс хаба; без часового пояса в запросе
+// сохраняется часовой пояс хаба (или аккаунта), если он есть в стране
+func (s *GeoService) SetAquahubLocation(userId, aquahubId int, input domain.SetLocation) (*domain.Location, error) {
+	current, err := s.repo.GetAquahubLocation_OfUser(userId, aquahubId, domain.PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.resolve(input, current.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetAquahubLocation(aquahubId, loc); err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+func (s *GeoService) DeleteAquahubLocation(userId, aquahubId int) error {
+	if _, err := s.repo.GetAquahubLocation_OfUser(userId, aquahubId, domain.PermissionManage); err != nil {
+		return err
+	}
+	return s.repo.DeleteAquahubLocation(aquahubId)
+}
+
+func (s *GeoService) GetAccountLocation(userId, accountId int) (*domain.Location, error) {
+	return s.repo.GetAccountLocation_OfUser(userId, accountId, domain.PermissionRead)
+}
+
+// SetAccountLocation - адрес аккаунта (страна, индекс, город, регион) и часовой пояс,
+// по которому строятся расписания и отчёты аккаунта
+func (s *GeoService) SetAccountLocation(userId, accountId int, input domain.SetLocation) (*domain.Location, error) {
+	current, err := s.repo.GetAccountLocation_OfUser(userId, accountId, domain.PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.resolve(input, current.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetAccountLocation(accountId, loc); err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
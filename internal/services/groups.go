@@ -0,0 +1,113 @@
+package service
+
+import (
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис групп хабов (площадка -> зона -> резервуар) и тегов хабов, устройств и сенсоров
+
+type GroupService struct {
+	repo IStoreGroup
+	log  *logrus.Logger
+}
+
+func NewGroupService(log *logrus.Logger, repo IStoreGroup) *GroupService {
+	return &GroupService{log: log, repo: repo}
+}
+
+// Проверка родителя группы вида kind: родитель из того же аккаунта и допустимого вида
+func (s *GroupService) checkParent(userId, accountId int, kind string, parentId *int) error {
+	if parentId == nil {
+		if !domain.ValidGroupParent(kind, "") {
+			return domain.ErrInvalidGroupTree
+		}
+		return nil
+	}
+
+	parent, err := s.repo.GetById(userId, *parentId)
+	if err != nil {
+		return err
+	}
+	if parent.AccountID != accountId || !domain.ValidGroupParent(kind, parent.Kind) {
+		return domain.ErrInvalidGroupTree
+	}
+	return nil
+}
+
+func (s *GroupService) Create(userId int, input domain.CreateHubGroup) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	if err := s.checkParent(userId, input.AccountID, input.Kind, input.ParentID); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(userId, input)
+}
+
+func (s *GroupService) GetAll(userId, accountId int, kind string) ([]domain.HubGroup, error) {
+	return s.repo.GetAll_OfUser(userId, accountId, kind)
+}
+
+func (s *GroupService) GetById(userId, id int) (*domain.HubGroup, error) {
+	return s.repo.GetById(userId, id)
+}
+
+func (s *GroupService) Update(userId, id int, input domain.UpdateHubGroup) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if input.ParentID != nil {
+		group, err := s.repo.GetById(userId, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkParent(userId, group.AccountID, group.Kind, input.ParentID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Update(userId, id, input)
+}
+
+func (s *GroupService) Delete(userId, id int) error {
+	return s.repo.Delete(userId, id)
+}
+
+func (s *GroupService) SetAquahubGroup(userId, aquahubId int, input domain.SetAquahubGroup) error {
+	return s.repo.SetAquahubGroup(userId, aquahubId, input.GroupID)
+}
+
+//-------------------------------------------------------------------------
+
+func (s *GroupService) GetTags(userId int, entity string, id int) ([]string, error) {
+	if _, err := s.repo.GetEntityAccount_OfUser(userId, entity, id, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTags(entity, id)
+}
+
+// SetTags заменяет теги хаба, устройства или сенсора
+func (s *GroupService) SetTags(userId int, entity string, id int, input domain.SetTags) ([]string, error) {
+	if err := input.Normalize(); err != nil {
+		return nil, err
+	}
+
+	accountId, err := s.repo.GetEntityAccount_OfUser(userId, entity, id, domain.PermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTags(accountId, entity, id, input.Tags); err != nil {
+		return nil, err
+	}
+
+	return input.Tags, nil
+}
+
+func (s *GroupService) GetAllTags(userId, accountId int) ([]domain.TagCount, error) {
+	return s.repo.GetTags_OfUser(userId, accountId)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/lineproto"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис приёма показаний в формате InfluxDB line protocol.
+// Точки сопоставляются сенсорам по правилам аккаунта и сохраняются тем же сервисом, что и /v1/sensor.
+
+type InfluxService struct {
+	repo    IStoreInflux
+	cache   domain.Cache
+	log     *logrus.Logger
+	auth    *AuthService
+	aquahub *AquahubListService
+}
+
+func NewInfluxService(log *logrus.Logger, cache domain.Cache, repo IStoreInflux, auth *AuthService, aquahub *AquahubListService) *InfluxService {
+	return &InfluxService{log: log, cache: cache, repo: repo, auth: auth, aquahub: aquahub}
+}
+
+// Длина поля value в sensors_dataset
+const maxValueLen = 32
+
+func influxMappingsCacheKey(accountId int) string {
+	return fmt.Sprintf("influx-mappings-%d", accountId)
+}
+
+func (s *InfluxService) Create(userId, accountId int, input domain.CreateInfluxMapping) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return 0, err
+	}
+
+	// Сенсор должен принадлежать тому же аккаунту
+	sensorAccountId, err := s.repo.GetSensorAccount_OfUser(userId, input.SensorID, domain.PermissionManage)
+	if err != nil {
+		return 0, err
+	}
+	if sensorAccountId != accountId {
+		return 0, errors.New("sensor belongs to another account")
+	}
+
+	id, err := s.repo.Create(domain.InfluxMapping{
+		AccountID:   accountId,
+		Measurement: input.Measurement,
+		Tags:        input.Tags,
+		Field:       input.Field,
+		SensorID:    input.SensorID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.resetCache(accountId)
+	return id, nil
+}
+
+func (s *InfluxService) GetAll(userId, accountId int) ([]domain.InfluxMapping, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAll_OfAccount(accountId)
+}
+
+func (s *InfluxService) Delete(userId, accountId, id int) error {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(accountId, id); err != nil {
+		return err
+	}
+
+	s.resetCache(accountId)
+	return nil
+}
+
+func (s *InfluxService) resetCache(accountId int) {
+	if s.cache != nil {
+		s.cache.Delete(influxMappingsCacheKey(accountId))
+	}
+}
+
+func (s *InfluxService) getMappings(accountId int) ([]domain.InfluxMapping, error) {
+
+	if s.cache != nil {
+		if list, err := s.cache.Get(influxMappingsCacheKey(accountId)); err == nil {
+			return list.([]domain.InfluxMapping), nil
+		}
+	}
+
+	list, err := s.repo.GetAll_OfAccount(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		s.cache.Set(influxMappingsCacheKey(accountId), list)
+	}
+
+	return list, nil
+}
+
+// Write принимает пакет line protocol от хаба, определённого токенами.
+// Записываются только поля, для которых есть правило с сенсором этого хаба;
+// ошибки разбора отдельных строк не мешают записи остальных.
+func (s *InfluxService) Write(h_token, u_token, data string, precision time.Duration) (domain.InfluxWriteResult, error) {
+
+	var res domain.InfluxWriteResult
+
+	hubSensors, err := s.auth.GetUserHWfromTokens(h_token, u_token)
+	if err != nil || len(hubSensors) == 0 {
+		return res, domain.ErrInvalidHubToken
+	}
+	accountId := hubSensors[0].Account_id
+
+	mappings, err := s.getMappings(accountId)
+	if err != nil {
+		return res, err
+	}
+
+	now := time.Now().UTC()
+	points, lineErrs := lineproto.Parse(data, precision, now)
+	for _, e := range lineErrs {
+		res.Errors = append(res.Errors, e.Error())
+	}
+
+	var list []domain.SensorDataSet
+	for _, p := range points {
+
+		fields := make([]string, 0, len(p.Fields))
+		for name := range p.Fields {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+
+		for _, name := range fields {
+			x, ok := s.mapField(mappings, hubSensors, p, name)
+			if !ok {
+				res.Unmapped++
+				continue
+			}
+
+			x.Value = p.Fields[name].String()
+			if len(x.Value) > maxValueLen {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s.%s: value is longer than %d characters", p.Measurement, name, maxValueLen))
+				continue
+			}
+			x.CreatedAt = p.Time
+
+			list = append(list, x)
+		}
+	}
+
+	if len(list) == 0 {
+		return res, nil
+	}
+
+	if err := s.aquahub.AppendDataOfSensor(list); err != nil {
+		s.log.Errorf("influx write: %s", err.Error())
+		return res, errors.New("error storing points")
+	}
+	res.Accepted = len(list)
+
+	return res, nil
+}
+
+// Сенсор хаба для поля точки по первому подходящему правилу
+func (s *InfluxService) mapField(mappings []domain.InfluxMapping, hubSensors []domain.SensorDataSet, p lineproto.Point, field string) (domain.SensorDataSet, bool) {
+	for _, m := range mappings {
+		if !m.Match(p.Measurement, p.Tags, field) {
+			continue
+		}
+		for _, hs := range hubSensors {
+			if hs.Sensor_id == m.SensorID {
+				return domain.SensorDataSet{
+					Account_id:      hs.Account_id,
+					Aquahub_id:      hs.Aquahub_id,
+					Device_id:       hs.Device_id,
+					Sensor_id:       hs.Sensor_id,
+					Local_device_id: hs.Local_device_id,
+					Local_sensor_id: hs.Local_sensor_id,
+				}, true
+			}
+		}
+	}
+	return domain.SensorDataSet{}, false
+}
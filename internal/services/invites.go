@@ -0,0 +1,183 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/jwt_processing"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис приглашений в аккаунт (см. domain/invite.go)
+
+type InviteService struct {
+	repo   IStoreInvite
+	log    *logrus.Logger
+	notify *NotificationService
+	auth   *AuthService
+}
+
+func NewInviteService(log *logrus.Logger, repo IStoreInvite, notify *NotificationService, auth *AuthService) *InviteService {
+	return &InviteService{log: log, repo: repo, notify: notify, auth: auth}
+}
+
+// Create - менеджер приглашает адрес email в аккаунт; письмо с токеном ставится в очередь уведомлений
+func (s *InviteService) Create(userId, accountId int, input domain.CreateAccountInvite) (domain.AccountInviteSent, error) {
+	if err := input.Validate(); err != nil {
+		return domain.AccountInviteSent{}, err
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return domain.AccountInviteSent{}, domain.ErrForbidden
+	}
+
+	nonce := randomstring.RandomBase64String(domain.InviteNonceLen)
+	expiresAt := time.Now().UTC().Add(domain.InviteTTL)
+
+	id, err := s.repo.Create(userId, accountId, input, hashToken(nonce), expiresAt)
+	if err != nil {
+		return domain.AccountInviteSent{}, err
+	}
+	s.log.Infof("invite %d: user %d invited %s to account %d", id, userId, input.Email, accountId)
+
+	// Приглашение без письма остаётся ожидающим: менеджер может отправить его повторно
+	if err := s.send(id, accountId, input.Email, nonce, expiresAt); err != nil {
+		return domain.AccountInviteSent{}, err
+	}
+
+	return domain.AccountInviteSent{ID: id, ExpiresAt: expiresAt}, nil
+}
+
+func (s *InviteService) GetAll(userId, accountId int, status string) ([]domain.AccountInvite, error) {
+	switch status {
+	case "", domain.InvitePending, domain.InviteAccepted, domain.InviteRevoked, domain.InviteExpired:
+	default:
+		return nil, domain.ErrInviteStatus
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return nil, domain.ErrForbidden
+	}
+
+	return s.repo.GetAll(accountId, status)
+}
+
+// Resend - новое письмо с новым токеном и сроком действия; прежний токен перестаёт действовать
+func (s *InviteService) Resend(userId, accountId, inviteId int) (domain.AccountInviteSent, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return domain.AccountInviteSent{}, domain.ErrForbidden
+	}
+
+	nonce := randomstring.RandomBase64String(domain.InviteNonceLen)
+	expiresAt := time.Now().UTC().Add(domain.InviteTTL)
+
+	invite, err := s.repo.Resend(accountId, inviteId, hashToken(nonce), expiresAt)
+	if err != nil {
+		return domain.AccountInviteSent{}, err
+	}
+
+	if err := s.send(invite.ID, accountId, invite.Email, nonce, expiresAt); err != nil {
+		return domain.AccountInviteSent{}, err
+	}
+
+	s.log.Infof("invite %d: user %d resent invite to %s", inviteId, userId, invite.Email)
+	return domain.AccountInviteSent{ID: invite.ID, ExpiresAt: expiresAt}, nil
+}
+
+func (s *InviteService) Revoke(userId, accountId, inviteId int) error {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return domain.ErrForbidden
+	}
+	if err := s.repo.Revoke(userId, accountId, inviteId); err != nil {
+		return err
+	}
+
+	s.log.Infof("invite %d: user %d revoked invite to account %d", inviteId, userId, accountId)
+	return nil
+}
+
+// Accept - получатель принимает приглашение токеном из письма. Новый пользователь регистрируется
+// с паролем по правилам AuthService.CreateUser, существующий подтверждает свой пароль.
+// Возвращает ID пользователя и аккаунта.
+func (s *InviteService) Accept(input domain.AcceptAccountInvite) (int, int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, 0, err
+	}
+
+	inviteId, nonce, err := jwt_processing.ParseInviteToken(input.Token)
+	if err != nil {
+		return 0, 0, domain.ErrInvalidInvite
+	}
+	tokenHash := hashToken(nonce)
+
+	invite, err := s.repo.GetPending(inviteId, tokenHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	user, err := s.repo.GetUser_ByEmail(invite.Email)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var userId int
+	var newUser *domain.User
+	if user != nil {
+		if _, err := s.auth.Authenticate(user.Email, input.Password); err != nil {
+			return 0, 0, domain.ErrInvitePassword
+		}
+		userId = user.ID
+	} else {
+		newUser = &domain.User{
+			FirstName:    strings.TrimSpace(input.FirstName),
+			LastName:     strings.TrimSpace(input.LastName),
+			Email:        invite.Email,
+			PasswordHash: input.Password,
+		}
+		if err := newUser.Validate(); err != nil {
+			return 0, 0, domain.ErrInviteUserInfo
+		}
+		if newUser.PasswordHash, newUser.PasswordSalt, err = hashPassword(input.Password); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	userId, accountId, err := s.repo.Accept(inviteId, tokenHash, userId, newUser)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s.log.Infof("invite %d: user %d joined account %d", inviteId, userId, accountId)
+	return userId, accountId, nil
+}
+
+// Письмо с подписанным токеном приглашения
+func (s *InviteService) send(inviteId, accountId int, email, nonce string, expiresAt time.Time) error {
+
+	token, err := jwt_processing.GenerateInviteToken(inviteId, nonce, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	name, err := s.repo.GetAccountName(accountId)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = fmt.Sprintf("#%d", accountId)
+	}
+
+	if err := s.notify.NotifyEmail(email, domain.Notification{
+		AccountID: accountId,
+		Event:     domain.NotifyInvite,
+		SourceID:  inviteId,
+		Message:   fmt.Sprintf("You are invited to join the account %s", name),
+		Token:     token,
+		Time:      expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	return s.repo.MarkSent(inviteId)
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/o-sokol-o/hub/internal/domain"
 )
 
@@ -31,22 +33,38 @@ type IStoreAuthorization interface {
 
 	GetUserHW_fromTokens(h_token, u_token string) ([]domain.SensorDataSet, error) // user_id, aquahub_id, []{device_id, sensor_id}
 	GetAquahubId_fromTokens(h_token, u_token string) (int, error)
+
+	SetPasswordReset(userId int, reset string) error
+	ResetPassword(userId int, reset, passwordHash, passwordSalt string) (time.Time, error)
+	GetPasswordChangedAt(userId int) (time.Time, error)
+
+	CreateRefreshToken(t domain.RefreshToken) error
+	GetRefreshToken(tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshToken(oldId int, next domain.RefreshToken) error
+	RevokeRefreshFamily(userId int, family string) error
+	RevokeSessions_OfUser(userId int) error
+	GetFamily_ByAccess(userId int, jti string) (string, error)
+	RevokeToken(jti string, userId int, expiresAt time.Time) error
+	IsTokenRevoked(jti string) (bool, error)
+	PurgeSessions() (domain.SessionsPurged, error)
 }
 
 type IStoreChecklist interface {
 	Create(userId int, list domain.CreateChecklist) (int, error)
 	// GetAll_Checklist() ([]domain.Checklist, error)
-	GetAll_ChecklistOfUser(userId int) ([]domain.Checklist, error)
+	GetAll_ChecklistOfUser(userId int, includeArchived bool) ([]domain.Checklist, error)
 	GetById(userId, listId int) (*domain.Checklist, error)
-	Delete(userId, listId int) error
+	Archive(userId, listId int) error
+	Restore(userId, listId int) error
 	Update(userId int, input domain.UpdateChecklist) error
 }
 
 type IStoreChecklistItem interface {
-	Create(listId int, item domain.ChecklistItem) (int, error)
-	GetAll(userId, listId int) ([]domain.ChecklistItem, error)
+	Create(userId, listId int, item domain.ChecklistItem) (int, error)
+	GetAll(userId, listId int, includeArchived bool) ([]domain.ChecklistItem, error)
 	GetById(userId, listId, itemId int) (domain.ChecklistItem, error)
-	Delete(userId, listId, itemId int) error
+	Archive(userId, listId, itemId int) error
+	Restore(userId, listId, itemId int) error
 	Update(userId, listId, itemId int, input domain.UpdateChecklistItem) error
 }
 
@@ -54,14 +72,313 @@ type IStoreAquahubs interface {
 
 	//-----------------------------------
 
-	GetAquahubs_OfUser(userId int) ([]domain.AquahubList, error)
-	GetDevices_OfAquahub(aquahubId int) ([]domain.AquahubList, error)
-	GetSensors_OfDevice(deviceId int) ([]domain.AquahubList, error)
+	GetAquahubs_OfUser(userId int, filter domain.GroupFilter, includeArchived bool) ([]domain.AquahubList, error)
+	GetDevices_OfAquahub(userId, aquahubId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Device, error)
+	GetSensors_OfDevice(userId, deviceId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Sensor, error)
 	GetDataSet_OfSensor(sensorId int) ([]domain.SensorDataSet, error)
 
 	AppendData_OfSensor(list []domain.SensorDataSet) error
-	Device_CreateOrUpdate(aquahub_id, device_local_id int, value string) error
-	Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) error
+	Device_CreateOrUpdate(aquahub_id, device_local_id int, value string) (int, bool, error)
+	Sensor_CreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) (int, bool, error)
 
 	GetName_DeviceSensor(sensor_id int) (domain.NameOfDeviceSensor, error)
+
+	GetAquahub_OfUser(userId, id int) (*domain.AquahubList, error)
+	CreateAquahub(userId int, input domain.CreateAquahub, hToken string) (int, error)
+	UpdateAquahub(userId, id int, input domain.UpdateAquahub) error
+	ArchiveAquahub(userId, id int) error
+	RestoreAquahub(userId, id int) error
+
+	GetDevice_OfUser(userId, id int) (*domain.Device, error)
+	CreateDevice(userId int, input domain.CreateDevice) (int, error)
+	UpdateDevice(userId, id int, input domain.UpdateDevice) error
+	ArchiveDevice(userId, id int) error
+	RestoreDevice(userId, id int) error
+
+	GetSensor_OfUser(userId, id int) (*domain.Sensor, error)
+	CreateSensor(userId int, input domain.CreateSensor) (int, error)
+	UpdateSensor(userId, id int, input domain.UpdateSensor) error
+	ArchiveSensor(userId, id int) error
+	RestoreSensor(userId, id int) error
+}
+
+type IStoreVirtualSensor interface {
+	GetDevice_OfUser(userId, deviceId int) (domain.VirtualSensor, error)
+	GetSensorIds_OfAccount(accountId int, sensorIds []int) ([]int, error)
+
+	Create(deviceId int, input domain.CreateVirtualSensor) (int, error)
+	GetAll_OfUser(userId int) ([]domain.VirtualSensor, error)
+	GetAll_OfAccount(accountId int) ([]domain.VirtualSensor, error)
+	GetById(userId, id int) (*domain.VirtualSensor, error)
+	Update(userId int, input domain.UpdateVirtualSensor) error
+	Delete(userId, id int) error
+
+	GetLatestValues(accountId int, sensorIds []int) (map[int]string, error)
+}
+
+type IStoreCalibration interface {
+	GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error)
+
+	Create(c domain.SensorCalibration) (int, error)
+	GetAll_OfSensors(sensorIds []int) ([]domain.SensorCalibration, error)
+	Delete(sensorId, id int) error
+
+	GetRawData_OfSensor(sensorId int, from, afterTime time.Time, afterId, limit int) ([]domain.SensorDataSet, error)
+	UpdateValues(list []domain.SensorDataSet) error
+}
+
+type IStoreAnomaly interface {
+	GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error)
+
+	SetDetectors(d domain.SensorDetectors) error
+	DeleteDetectors(sensorId int) error
+	GetDetectors_OfSensors(sensorIds []int) ([]domain.SensorDetectors, error)
+
+	GetRecent_OfSensors(sensorIds []int, limit int) ([]domain.SensorDataSet, error)
+	GetRunStart(sensorId int, value string) (time.Time, error)
+	GetFlags_OfSensor(sensorId int, from, to time.Time) ([]domain.SensorFlag, error)
+}
+
+type IStoreCoverage interface {
+	GetSensors_OfUser(userId int, level string, id int, filter domain.GroupFilter) ([]domain.CoverageSensor, error)
+	GetSensors_Active() ([]domain.CoverageSensor, error)
+	SetReportInterval(userId, sensorId int, seconds *int) error
+
+	GetTimes_OfSensor(sensorId int, from, to time.Time) ([]time.Time, error)
+	GetLastTimes_OfSensor(sensorId int, before time.Time, limit int) ([]time.Time, error)
+
+	SaveDaily(c domain.SensorCoverageDaily) error
+	GetDailyComputed(day time.Time) ([]int, error)
+	GetDaily_OfSensors(sensorIds []int, from, to time.Time) ([]domain.SensorCoverageDaily, error)
+}
+
+type IStoreMetrics interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+
+	CreateToken(t domain.MetricsToken, tokenHash string) (int, error)
+	GetTokens_OfAccount(accountId int) ([]domain.MetricsToken, error)
+	RevokeToken(accountId, id int) error
+	GetAccount_ByToken(tokenHash string) (int, error)
+
+	GetLatestValues_OfAccount(accountId int) ([]domain.SensorMetric, error)
+	SetSensorUnit(userId, sensorId int, unit string) error
+}
+
+type IStoreInflux interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+	GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error)
+
+	Create(m domain.InfluxMapping) (int, error)
+	GetAll_OfAccount(accountId int) ([]domain.InfluxMapping, error)
+	Delete(accountId, id int) error
+}
+
+type IStoreAlert interface {
+	GetSensorAccount_OfUser(userId, sensorId int, perm domain.Permission) (int, error)
+
+	Create(rule domain.AlertRule) (int, error)
+	GetAll_OfUser(userId, sensorId int, filter domain.GroupFilter) ([]domain.AlertRule, error)
+	GetById(userId, id int) (*domain.AlertRule, error)
+	Update(rule domain.AlertRule) error
+	Delete(userId, id int) error
+
+	GetEnabled_OfSensors(sensorIds []int) ([]domain.AlertRule, error)
+	GetNoData_NotFiring() ([]domain.AlertRule, error)
+	SaveStates(rules []domain.AlertRule, events []domain.AlertEvent) error
+	GetEvents_OfUser(userId int, filter domain.AlertEventsFilter) ([]domain.AlertEvent, error)
+	GetAlerts_OfUser(userId int, filter domain.AlertsFilter) ([]domain.Alert, error)
+	GetAlert_OfUser(userId, id int) (*domain.Alert, error)
+	GetAlerts_Due() ([]domain.Alert, error)
+	UpdateAlert(alert domain.Alert, event *domain.AlertEvent) error
+}
+
+type IStoreNotification interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+
+	GetPreferences_OfUsers(userIds []int) ([]domain.NotificationPreference, error)
+	SetPreference(p domain.NotificationPreference) error
+	DeletePreference(userId int, channel string) error
+
+	GetTemplates_OfAccount(accountId int) ([]domain.NotificationTemplate, error)
+	SetTemplate(t domain.NotificationTemplate) error
+	DeleteTemplate(accountId int, event string) error
+
+	GetRecipients_OfAccount(accountId int) ([]domain.NotificationRecipient, error)
+	GetRecipient(userId int) (domain.NotificationRecipient, error)
+
+	CreateDeliveries(list []domain.NotificationDelivery) error
+	ClaimDue(limit int, lease time.Duration) ([]domain.NotificationDelivery, error)
+	UpdateDelivery(d domain.NotificationDelivery) error
+	GetDeliveries_OfUser(userId int, status string, from, to time.Time) ([]domain.NotificationDelivery, error)
+}
+
+type IStoreAutomation interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+	CheckReferences(accountId int, sensorIds, deviceIds, checklistIds []int) error
+
+	Create(a domain.Automation) (int, error)
+	GetAll_OfUser(userId int) ([]domain.Automation, error)
+	GetById(userId, id int) (*domain.Automation, error)
+	Update(a domain.Automation) error
+	Delete(userId, id int) error
+
+	GetEnabled_OfSensors(sensorIds []int) ([]domain.Automation, error)
+	GetEnabled_Timed() ([]domain.Automation, error)
+	SaveState(a domain.Automation) error
+	GetRunTimes(id int, since time.Time) ([]time.Time, error)
+	CreateRun(run domain.AutomationRun) (int, error)
+	GetRuns_OfUser(userId, id int, from, to time.Time) ([]domain.AutomationRun, error)
+
+	GetLatest_OfSensors(accountId int, sensorIds []int) ([]domain.SensorDataSet, error)
+	CreateChecklistItem(accountId, checklistId int, title, description string) (int, error)
+}
+
+type IStoreCommand interface {
+	GetDeviceAccount_OfUser(userId, deviceId int, perm domain.Permission) (int, error)
+
+	Create(c domain.DeviceCommand) (int, error)
+	Create_OfAquahub(c domain.DeviceCommand) (int, error)
+	Claim_OfAquahub(aquahubId, limit int) ([]domain.HubCommand, error)
+	Ack(aquahubId, id int) error
+	GetAll_OfUser(userId int, filter domain.DeviceCommandsFilter) ([]domain.DeviceCommand, error)
+}
+
+type IStoreGroup interface {
+	Create(userId int, input domain.CreateHubGroup) (int, error)
+	GetAll_OfUser(userId, accountId int, kind string) ([]domain.HubGroup, error)
+	GetById(userId, id int) (*domain.HubGroup, error)
+	Update(userId, id int, input domain.UpdateHubGroup) error
+	Delete(userId, id int) error
+	SetAquahubGroup(userId, aquahubId, groupId int) error
+
+	GetEntityAccount_OfUser(userId int, entity string, id int, perm domain.Permission) (int, error)
+	GetTags(entity string, id int) ([]string, error)
+	SetTags(accountId int, entity string, id int, tags []string) error
+	GetTags_OfUser(userId, accountId int) ([]domain.TagCount, error)
+}
+
+type IStoreTransfer interface {
+	GetAquahubAccount_OfUser(userId, aquahubId int, perm domain.Permission) (int, error)
+
+	Create(userId, accountId, aquahubId int, mode, code string, expiresAt time.Time) (int, error)
+	GetPending_OfAquahub(aquahubId int) (*domain.HubTransfer, error)
+	Cancel(userId, aquahubId int) error
+	GetAll_OfUser(userId int, filter domain.HubTransfersFilter) ([]domain.HubTransfer, error)
+
+	Accept(userId, accountId int, code, hToken, archivedToken string) (*domain.HubTransfer, error)
+}
+
+type IStoreGeo interface {
+	GetCountries() ([]domain.Country, error)
+	GetTimezones(country string) ([]string, error)
+	FindPlaces(country, q string, limit int) ([]domain.GeoPlace, error)
+	GetPlace(country, postalCode, placeName string) (*domain.GeoPlace, error)
+
+	GetAquahubLocation_OfUser(userId, aquahubId int, perm domain.Permission) (*domain.Location, error)
+	SetAquahubLocation(aquahubId int, loc domain.Location) error
+	DeleteAquahubLocation(aquahubId int) error
+
+	GetAccountLocation_OfUser(userId, accountId int, perm domain.Permission) (*domain.Location, error)
+	SetAccountLocation(accountId int, loc domain.Location) error
+}
+
+type IStorePairing interface {
+	GetHub_ByToken(hToken string) (*domain.PairedHub, error)
+	GetAquahubAccount_OfUser(userId, aquahubId int, perm domain.Permission) (int, error)
+
+	SetCode(hToken, code string, expiresAt time.Time) (domain.PairingCode, error)
+	Claim(userId int, input domain.ClaimAquahub) (int, error)
+	Unclaim(aquahubId int, hToken string) error
+	RequestReset(aquahubId int) error
+}
+
+type IStoreChecklistRecurrence interface {
+	GetById(userId, checklistId int, perm domain.Permission) (*domain.ChecklistRecurrence, error)
+	Set(rec domain.ChecklistRecurrence) error
+
+	GetDue(now time.Time) ([]domain.ChecklistRecurrence, error)
+	CreateInstance(rec domain.ChecklistRecurrence, from time.Time, to *time.Time) (int, error)
+	GetInstances_OfUser(userId, templateId int, from, to time.Time) ([]domain.ChecklistInstance, error)
+}
+
+type IStoreWebhook interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+
+	Create(w domain.Webhook) (int, error)
+	GetAll_OfAccount(accountId int) ([]domain.Webhook, error)
+	GetById(accountId, id int) (*domain.Webhook, error)
+	Update(w domain.Webhook) error
+	Delete(accountId, id int) error
+
+	GetAquahubAccount(aquahubId int) (int, error)
+	GetCompletedChecklist(checklistId int) (*domain.WebhookChecklist, error)
+	SetHubsOffline(after time.Duration) ([]domain.WebhookHub, error)
+
+	CreateDeliveries(list []domain.WebhookDelivery) error
+	ClaimDue(limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(d domain.WebhookDelivery) error
+	GetDeliveries(accountId, webhookId int, status string, from, to time.Time) ([]domain.WebhookDelivery, error)
+	Redeliver(accountId, webhookId, deliveryId int) (int, error)
+}
+
+type IStoreDigest interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+	GetTimezone_OfUser(userId int) (string, error)
+
+	GetSubscription(userId, accountId int) (*domain.DigestSubscription, error)
+	SetSubscription(sub domain.DigestSubscription) error
+	DeleteSubscription(userId, accountId int) error
+
+	GetEnabled() ([]domain.DigestSubscription, error)
+	SetLastStart(userId, accountId int, kind string, start time.Time) error
+
+	GetHubs_OfAccount(accountId int) ([]domain.DigestHub, error)
+	GetSensorStats(accountId int, from, to time.Time) ([]domain.DigestSensor, error)
+	GetOfflinePeriods(accountId int, from, to time.Time, gap time.Duration) ([]domain.DigestPeriod, error)
+	GetAlerts(accountId int, from, to time.Time) ([]domain.DigestAlert, error)
+	CountOverdueItems(accountId int, at time.Time) (int, error)
+
+	CreateReport(rep domain.DigestReport) (int, error)
+	GetReports_OfUser(userId int, filter domain.DigestReportsFilter) ([]domain.DigestReport, error)
+	GetReport_OfUser(userId, id int) (*domain.DigestReport, error)
+}
+
+type IStoreDeviceType interface {
+	GetAll_OfUser(userId, accountId int) ([]domain.DeviceType, error)
+	GetById(userId, id int) (*domain.DeviceType, error)
+	GetByModel_OfAquahub(aquahubId int, model string) (*domain.DeviceType, error)
+	Create(userId int, input domain.CreateDeviceType) (int, error)
+	Update(userId, id int, input domain.DeviceTypeInput) error
+	Delete(userId, id int) error
+
+	GetDeviceAccount_OfUser(userId, deviceId int, perm domain.Permission) (int, error)
+	Apply(deviceId int, t domain.DeviceType, force bool) ([]int, []domain.WebhookSensor, error)
+	DescribeSensor(sensorId int) (bool, error)
+}
+
+type IStoreArchive interface {
+	Purge(before time.Time) (domain.ArchivePurged, error)
+}
+
+type IStoreAccess interface {
+	GetRoles_OfUser(userId, accountId int) (domain.UserAccountRoles, error)
+	HasPermission(userId int, perm domain.Permission) (bool, error)
+	GetMembers(accountId int) ([]domain.AccountMember, error)
+	UpdateMember(accountId, userId int, input domain.UpdateAccountMember) error
+	RemoveMember(accountId, userId int) error
+}
+
+type IStoreInvite interface {
+	CheckAccount_OfUser(userId, accountId int, perm domain.Permission) error
+	GetAccountName(accountId int) (string, error)
+
+	Create(userId, accountId int, input domain.CreateAccountInvite, tokenHash string, expiresAt time.Time) (int, error)
+	MarkSent(inviteId int) error
+	GetAll(accountId int, status string) ([]domain.AccountInvite, error)
+	Resend(accountId, inviteId int, tokenHash string, expiresAt time.Time) (*domain.AccountInvite, error)
+	Revoke(userId, accountId, inviteId int) error
+
+	GetPending(inviteId int, tokenHash string) (*domain.AccountInvite, error)
+	GetUser_ByEmail(email string) (*domain.User, error)
+	Accept(inviteId int, tokenHash string, userId int, newUser *domain.User) (int, int, error)
 }
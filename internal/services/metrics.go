@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/promtext"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис экспорта последних показаний сенсоров в формате Prometheus
+
+type MetricsService struct {
+	repo IStoreMetrics
+	log  *logrus.Logger
+}
+
+func NewMetricsService(log *logrus.Logger, repo IStoreMetrics) *MetricsService {
+	return &MetricsService{log: log, repo: repo}
+}
+
+const (
+	metricsTokenPrefix = "mt_"
+	metricsTokenLength = 40
+
+	metricSensorValue    = "aquahub_sensor_value"
+	metricSensorLastSeen = "aquahub_sensor_last_seen_timestamp_seconds"
+)
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *MetricsService) CreateToken(userId, accountId int, input domain.CreateMetricsToken) (domain.MetricsTokenCreated, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return domain.MetricsTokenCreated{}, err
+	}
+
+	token := metricsTokenPrefix + randomstring.RandomBase16String(metricsTokenLength)
+
+	id, err := s.repo.CreateToken(domain.MetricsToken{
+		AccountID: accountId,
+		Name:      input.Name,
+		Prefix:    token[:len(metricsTokenPrefix)+4],
+		CreatedBy: userId,
+	}, hashToken(token))
+	if err != nil {
+		return domain.MetricsTokenCreated{}, err
+	}
+
+	return domain.MetricsTokenCreated{ID: id, Token: token}, nil
+}
+
+func (s *MetricsService) GetTokens(userId, accountId int) ([]domain.MetricsToken, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTokens_OfAccount(accountId)
+}
+
+func (s *MetricsService) RevokeToken(userId, accountId, id int) error {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeToken(accountId, id)
+}
+
+// Authenticate возвращает ID аккаунта, к которому относится токен сбора метрик
+func (s *MetricsService) Authenticate(token string) (int, error) {
+	if len(token) != len(metricsTokenPrefix)+metricsTokenLength {
+		return 0, errors.New("invalid metrics token")
+	}
+
+	return s.repo.GetAccount_ByToken(hashToken(token))
+}
+
+func (s *MetricsService) SetSensorUnit(userId, sensorId int, input domain.SetSensorUnit) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.SetSensorUnit(userId, sensorId, input.Unit)
+}
+
+// Write пишет последние показания сенсоров аккаунта в формате Prometheus.
+// Нечисловые показания пропускаются. Метки времени не указываются: Prometheus отбрасывает
+// слишком старые сэмплы, а возраст показания виден по метрике last_seen.
+func (s *MetricsService) Write(accountId int, w io.Writer) error {
+
+	list, err := s.repo.GetLatestValues_OfAccount(accountId)
+	if err != nil {
+		return err
+	}
+
+	p := promtext.NewWriter(w)
+
+	labels := make([][]promtext.Label, len(list))
+	values := make([]float64, len(list))
+	valid := make([]bool, len(list))
+	for i, x := range list {
+		v, ok := parseValue(x.Value)
+		if !ok {
+			continue
+		}
+		values[i], valid[i] = v, true
+		labels[i] = []promtext.Label{
+			{Name: "account_id", Value: strconv.Itoa(accountId)},
+			{Name: "hub_id", Value: strconv.Itoa(x.AquahubID)},
+			{Name: "hub", Value: x.AquahubTitle},
+			{Name: "device_id", Value: strconv.Itoa(x.DeviceID)},
+			{Name: "device", Value: x.DeviceTitle},
+			{Name: "sensor_id", Value: strconv.Itoa(x.SensorID)},
+			{Name: "sensor", Value: x.SensorTitle},
+			{Name: "unit", Value: x.Unit},
+		}
+	}
+
+	p.Header(metricSensorValue, "Latest value reported by the sensor.", "gauge")
+	for i := range list {
+		if valid[i] {
+			p.Sample(metricSensorValue, labels[i], values[i], time.Time{})
+		}
+	}
+
+	p.Header(metricSensorLastSeen, "Unix time of the latest sensor reading.", "gauge")
+	for i, x := range list {
+		if valid[i] {
+			p.Sample(metricSensorLastSeen, labels[i], float64(x.CreatedAt.Unix()), time.Time{})
+		}
+	}
+
+	return p.Flush()
+}
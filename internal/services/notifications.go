@@ -0,0 +1,521 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/netguard"
+	"github.com/o-sokol-o/hub/pkg/notify"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис уведомлений: события аккаунта превращаются в записи очереди доставки для каждого
+// участника и каждого его включённого канала, плановая задача отправляет их с повторами.
+
+type NotificationService struct {
+	repo IStoreNotification
+	log  *logrus.Logger
+
+	mu       sync.RWMutex
+	channels map[string]notify.Channel
+	cfg      domain.CfgNotify
+}
+
+func NewNotificationService(log *logrus.Logger, repo IStoreNotification) *NotificationService {
+	return &NotificationService{
+		log:      log,
+		repo:     repo,
+		channels: map[string]notify.Channel{domain.ChannelWebhook: &notify.Webhook{Client: netguard.NewClient(30 * time.Second)}},
+		cfg:      domain.CfgNotify{MaxAttempts: 6, RetryDelay: time.Minute, MaxRetryDelay: time.Hour, Timeout: 30 * time.Second},
+	}
+}
+
+// Сколько доставок отправляет один запуск задачи
+const deliveryBatch = 100
+
+// Встроенные шаблоны; аккаунт может заменить их своими
+var defaultNotificationTemplates = map[string]notify.Template{
+	domain.NotifyAlertFiring: {
+		Subject: "Alert: {{.Message}}",
+		Body:    "{{.Message}}\n\nSensor: {{.SensorID}}\nValue: {{.Value}}\nTime: {{.Time}}\n",
+	},
+	domain.NotifyAlertResolved: {
+		Subject: "Resolved: {{.Message}}",
+		Body:    "{{.Message}}\n\nSensor: {{.SensorID}}\nValue: {{.Value}}\nTime: {{.Time}}\n",
+	},
+	domain.NotifyAlertReminder: {
+		Subject: "Still firing: {{.Message}}",
+		Body:    "{{.Message}}\n\nFiring since: {{.Since}}\nSensor: {{.SensorID}}\nValue: {{.Value}}\n\nAcknowledge the alert to stop reminders.\n",
+	},
+	domain.NotifyAutomation: {
+		Subject: "Automation: {{.Message}}",
+		Body:    "{{.Message}}\n\nTime: {{.Time}}\n",
+	},
+	domain.NotifyDigest: {
+		Subject: "{{.Message}}",
+		Body:    "Hello {{.FirstName}},\n\n{{.Report}}",
+	},
+	domain.NotifyInvite: {
+		Subject: "Invitation: {{.Message}}",
+		Body:    "Hello,\n\n{{.Message}}.\n\nInvite token:\n{{.Token}}\n\nThe invite is valid until {{.Time}}.\n",
+	},
+	domain.NotifyPasswordReset: {
+		Subject: "Password reset",
+		Body:    "Hello {{.FirstName}},\n\nA password reset was requested for your account. Reset token:\n{{.Token}}\n\nThe token is valid until {{.Time}}. If you did not request the reset, ignore this email.\n",
+	},
+	domain.NotifyTest: {
+		Subject: "Test notification",
+		Body:    "Hello {{.FirstName}}, notifications are working.\n\nTime: {{.Time}}\n",
+	},
+}
+
+// Данные шаблона
+type notificationView struct {
+	Event     string
+	Message   string
+	Report    string
+	Token     string
+	Value     string
+	SensorID  int
+	RuleID    int
+	AlertID   int
+	Since     string
+	Time      string
+	FirstName string
+}
+
+// Configure подключает каналы по конфигурации приложения; sender - адрес отправителя писем
+func (s *NotificationService) Configure(cfg domain.CfgNotify, sender string) {
+
+	def := s.config()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = def.RetryDelay
+	}
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		cfg.MaxRetryDelay = cfg.RetryDelay
+	}
+
+	channels := map[string]notify.Channel{}
+	if cfg.SMTPHost != "" {
+		channels[domain.ChannelEmail] = &notify.SMTP{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPass,
+			From:     sender,
+			Timeout:  cfg.Timeout,
+		}
+	}
+	channels[domain.ChannelWebhook] = &notify.Webhook{Client: netguard.NewClient(cfg.Timeout)}
+	if cfg.OutboxDir != "" {
+		channels[domain.ChannelFile] = &notify.Outbox{Dir: cfg.OutboxDir}
+	}
+
+	s.mu.Lock()
+	s.channels = channels
+	s.cfg = cfg
+	s.mu.Unlock()
+}
+
+func (s *NotificationService) channel(name string) (notify.Channel, domain.CfgNotify) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.channels[name], s.cfg
+}
+
+func (s *NotificationService) config() domain.CfgNotify {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// Настройки каналов пользователя; email без настройки - включён на адрес пользователя
+func (s *NotificationService) GetPreferences(userId int) ([]domain.NotificationPreference, error) {
+	list, err := s.repo.GetPreferences_OfUsers([]int{userId})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range list {
+		if p.Channel == domain.ChannelEmail {
+			return list, nil
+		}
+	}
+	return append([]domain.NotificationPreference{{UserID: userId, Channel: domain.ChannelEmail, Enabled: true}}, list...), nil
+}
+
+func (s *NotificationService) SetPreference(userId int, channel string, input domain.SetNotificationPreference) error {
+	if err := input.Validate(channel); err != nil {
+		return err
+	}
+
+	return s.repo.SetPreference(domain.NotificationPreference{
+		UserID:  userId,
+		Channel: channel,
+		Target:  input.Target,
+		Enabled: *input.Enabled,
+	})
+}
+
+func (s *NotificationService) DeletePreference(userId int, channel string) error {
+	if channel != domain.ChannelEmail && channel != domain.ChannelWebhook && channel != domain.ChannelFile {
+		return domain.ErrUnknownChannel
+	}
+
+	return s.repo.DeletePreference(userId, channel)
+}
+
+// Шаблоны аккаунта по всем событиям: свои или встроенные
+func (s *NotificationService) GetTemplates(userId, accountId int) ([]domain.NotificationTemplate, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	custom, err := s.repo.GetTemplates_OfAccount(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]domain.NotificationTemplate, 0, len(domain.NotifyEvents))
+	for _, event := range domain.NotifyEvents {
+		t := domain.NotificationTemplate{
+			AccountID: accountId,
+			Event:     event,
+			Subject:   defaultNotificationTemplates[event].Subject,
+			Body:      defaultNotificationTemplates[event].Body,
+		}
+		for _, c := range custom {
+			if c.Event == event {
+				t = c
+				t.Custom = true
+			}
+		}
+		list = append(list, t)
+	}
+
+	return list, nil
+}
+
+func (s *NotificationService) SetTemplate(userId, accountId int, event string, input domain.SetNotificationTemplate) error {
+	if !domain.IsNotifyEvent(event) {
+		return domain.ErrUnknownNotifyEvent
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if err := (notify.Template{Subject: input.Subject, Body: input.Body}).Validate(); err != nil {
+		return errors.New("invalid template: " + err.Error())
+	}
+
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	return s.repo.SetTemplate(domain.NotificationTemplate{
+		AccountID: accountId,
+		Event:     event,
+		Subject:   input.Subject,
+		Body:      input.Body,
+	})
+}
+
+func (s *NotificationService) DeleteTemplate(userId, accountId int, event string) error {
+	if !domain.IsNotifyEvent(event) {
+		return domain.ErrUnknownNotifyEvent
+	}
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTemplate(accountId, event)
+}
+
+func (s *NotificationService) GetDeliveries(userId int, status string, from, to time.Time) ([]domain.NotificationDelivery, error) {
+	if !to.After(from) {
+		return nil, errors.New("period end must be after period start")
+	}
+
+	return s.repo.GetDeliveries_OfUser(userId, status, from, to)
+}
+
+// SendTest ставит в очередь тестовое уведомление пользователю по всем его включённым каналам
+func (s *NotificationService) SendTest(userId int) (int, error) {
+	rcpt, err := s.repo.GetRecipient(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	list, err := s.deliveries([]domain.NotificationRecipient{rcpt}, domain.Notification{
+		Event: domain.NotifyTest,
+		Time:  time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, errors.New("no notification channels enabled")
+	}
+
+	return len(list), s.repo.CreateDeliveries(list)
+}
+
+// Notify ставит уведомление о событии аккаунта в очередь для всех участников аккаунта.
+// Ошибки только логируются: уведомления не должны мешать приёму данных.
+func (s *NotificationService) Notify(n domain.Notification) {
+
+	rcpts, err := s.repo.GetRecipients_OfAccount(n.AccountID)
+	if err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+		return
+	}
+
+	list, err := s.deliveries(rcpts, n)
+	if err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	if err := s.repo.CreateDeliveries(list); err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+	}
+}
+
+// NotifyUser ставит уведомление в очередь одному пользователю (сводки); шаблон - аккаунта n.AccountID
+func (s *NotificationService) NotifyUser(userId int, n domain.Notification) {
+
+	rcpt, err := s.repo.GetRecipient(userId)
+	if err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+		return
+	}
+
+	list, err := s.deliveries([]domain.NotificationRecipient{rcpt}, n)
+	if err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	if err := s.repo.CreateDeliveries(list); err != nil {
+		s.log.Errorf("notify %s: %s", n.Event, err.Error())
+	}
+}
+
+// NotifyEmail ставит письмо в очередь на адрес, у которого может не быть пользователя (приглашения).
+// Без настроенного SMTP письмо уходит в файловый канал, если он включён.
+func (s *NotificationService) NotifyEmail(email string, n domain.Notification) error {
+
+	rcpt := domain.NotificationRecipient{Email: email}
+
+	list, err := s.deliveries([]domain.NotificationRecipient{rcpt}, n)
+	if err != nil {
+		return err
+	}
+
+	channel := domain.ChannelEmail
+	if ch, _ := s.channel(domain.ChannelEmail); ch == nil {
+		if ch, _ := s.channel(domain.ChannelFile); ch != nil {
+			channel = domain.ChannelFile
+		}
+	}
+
+	// Настройки каналов есть только у пользователей: письмо уходит одной доставкой на адрес
+	var mail []domain.NotificationDelivery
+	for _, d := range list {
+		if d.Channel == domain.ChannelEmail {
+			d.Channel = channel
+			mail = append(mail, d)
+		}
+	}
+	if len(mail) == 0 {
+		return errors.New("no notification channels enabled")
+	}
+
+	return s.repo.CreateDeliveries(mail)
+}
+
+// Записи очереди доставки: получатель x включённый канал, текст по шаблону аккаунта
+func (s *NotificationService) deliveries(rcpts []domain.NotificationRecipient, n domain.Notification) ([]domain.NotificationDelivery, error) {
+	if len(rcpts) == 0 {
+		return nil, nil
+	}
+
+	tpl := defaultNotificationTemplates[n.Event]
+	if n.AccountID != 0 {
+		custom, err := s.repo.GetTemplates_OfAccount(n.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range custom {
+			if c.Event == n.Event {
+				tpl = notify.Template{Subject: c.Subject, Body: c.Body}
+			}
+		}
+	}
+
+	userIds := make([]int, len(rcpts))
+	for i, r := range rcpts {
+		userIds[i] = r.UserID
+	}
+	prefs, err := s.repo.GetPreferences_OfUsers(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []domain.NotificationDelivery
+	for _, rcpt := range rcpts {
+
+		subject, body, err := tpl.Render(newNotificationView(n, rcpt))
+		if err != nil {
+			// Шаблон аккаунта проверен при сохранении, но может упасть на данных
+			s.log.Errorf("notify %s: account %d template: %s", n.Event, n.AccountID, err.Error())
+			subject, body, _ = defaultNotificationTemplates[n.Event].Render(newNotificationView(n, rcpt))
+		}
+
+		for channel, target := range channelTargets(rcpt, prefs) {
+			list = append(list, domain.NotificationDelivery{
+				UserID:    rcpt.UserID,
+				AccountID: n.AccountID,
+				Channel:   channel,
+				Target:    target,
+				Event:     n.Event,
+				SourceID:  n.SourceID,
+				Subject:   subject,
+				Body:      body,
+				Status:    domain.DeliveryPending,
+			})
+		}
+	}
+
+	return list, nil
+}
+
+// Включённые каналы получателя (канал => адрес)
+func channelTargets(rcpt domain.NotificationRecipient, prefs []domain.NotificationPreference) map[string]string {
+	targets := map[string]string{domain.ChannelEmail: rcpt.Email}
+
+	for _, p := range prefs {
+		if p.UserID != rcpt.UserID {
+			continue
+		}
+		if !p.Enabled {
+			delete(targets, p.Channel)
+			continue
+		}
+		switch {
+		case p.Target != "":
+			targets[p.Channel] = p.Target
+		case p.Channel != domain.ChannelWebhook:
+			targets[p.Channel] = rcpt.Email
+		}
+	}
+
+	return targets
+}
+
+func newNotificationView(n domain.Notification, rcpt domain.NotificationRecipient) notificationView {
+	loc, err := time.LoadLocation(rcpt.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	const layout = "2006-01-02 15:04:05 MST"
+
+	view := notificationView{
+		Event:     n.Event,
+		Message:   n.Message,
+		Report:    n.Report,
+		Token:     n.Token,
+		Value:     n.Value,
+		SensorID:  n.SensorID,
+		RuleID:    n.RuleID,
+		AlertID:   n.AlertID,
+		Time:      n.Time.In(loc).Format(layout),
+		FirstName: rcpt.FirstName,
+	}
+	if !n.Since.IsZero() {
+		view.Since = n.Since.In(loc).Format(layout)
+	}
+	return view
+}
+
+// RunDeliveries - плановая задача: отправка уведомлений из очереди. Неудачная отправка повторяется
+// с растущей задержкой до MaxAttempts попыток; окончательные ошибки (неверный адрес) не повторяются.
+func (s *NotificationService) RunDeliveries(ctx context.Context) {
+
+	lease := s.config().Timeout*2 + time.Minute
+
+	list, err := s.repo.ClaimDue(deliveryBatch, lease)
+	if err != nil {
+		s.log.Errorf("notification job: %s", err.Error())
+		return
+	}
+
+	for _, d := range list {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.deliver(ctx, &d)
+
+		if err := s.repo.UpdateDelivery(d); err != nil {
+			s.log.Errorf("notification job: delivery %d: %s", d.ID, err.Error())
+		}
+	}
+}
+
+// Длина поля last_error в БД
+const maxDeliveryErrorLen = 512
+
+func (s *NotificationService) deliver(ctx context.Context, d *domain.NotificationDelivery) {
+
+	ch, cfg := s.channel(d.Channel)
+	d.Attempts++
+
+	var err error
+	if ch == nil {
+		err = notify.Permanent(errors.New("channel is not configured"))
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		err = ch.Send(sendCtx, notify.Message{To: d.Target, Event: d.Event, Subject: d.Subject, Body: d.Body})
+		cancel()
+	}
+
+	now := time.Now().UTC()
+	if err == nil {
+		d.Status = domain.DeliverySent
+		d.SentAt = &now
+		d.NextAttemptAt = nil
+		d.LastError = ""
+		return
+	}
+
+	d.LastError = err.Error()
+	if len(d.LastError) > maxDeliveryErrorLen {
+		d.LastError = d.LastError[:maxDeliveryErrorLen]
+	}
+
+	if notify.IsPermanent(err) || d.Attempts >= cfg.MaxAttempts {
+		d.Status = domain.DeliveryFailed
+		d.NextAttemptAt = nil
+		s.log.Errorf("notification %d to %s failed: %s", d.ID, d.Channel, err.Error())
+		return
+	}
+
+	next := now.Add(notify.Backoff(d.Attempts, cfg.RetryDelay, cfg.MaxRetryDelay))
+	d.NextAttemptAt = &next
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис привязки хабов к аккаунтам по короткому коду (см. domain/pairing.go)
+
+type HubPairingService struct {
+	repo    IStorePairing
+	command *CommandService
+	log     *logrus.Logger
+}
+
+func NewHubPairingService(log *logrus.Logger, repo IStorePairing, command *CommandService) *HubPairingService {
+	return &HubPairingService{log: log, repo: repo, command: command}
+}
+
+// Сколько раз выдаётся новый код при совпадении с кодом другого хаба
+const pairingCodeAttempts = 3
+
+// Announce - запрос хаба с заводским h_token: привязанный хаб получает u_token аккаунта,
+// непривязанный - код привязки, хаб аквахаба в архиве - только статус archived.
+// Хаб, запросивший код после сброса к заводским настройкам, отвязывается.
+// Хаб, переданный другому аккаунту, с прежним токеном получает только код привязки:
+// новый h_token выдаётся получателю передачи (см. domain/transfer.go).
+func (s *HubPairingService) Announce(hToken string) (domain.HubPairing, error) {
+	if len(hToken) < 12 || len(hToken) > 32 {
+		return domain.HubPairing{}, domain.ErrInvalidHubToken
+	}
+
+	hub, err := s.repo.GetHub_ByToken(hToken)
+	if err != nil {
+		return domain.HubPairing{}, err
+	}
+
+	if hub != nil && hub.ArchivedAt != nil {
+		return domain.HubPairing{Status: domain.PairingArchived}, nil
+	}
+
+	if hub != nil {
+		if hub.ResetRequestedAt == nil {
+			return domain.HubPairing{Status: domain.PairingClaimed, UToken: hub.UToken}, nil
+		}
+
+		if err := s.repo.Unclaim(hub.ID, randomstring.RandomBase64String(aquahubTokenLen)); err != nil {
+			return domain.HubPairing{}, err
+		}
+		s.log.Infof("pairing: aquahub %d of account %d unclaimed after factory reset", hub.ID, hub.AccountID)
+	}
+
+	for i := 0; ; i++ {
+		code, err := s.repo.SetCode(hToken, randomstring.RandomCode(domain.PairingCodeLen), time.Now().UTC().Add(domain.PairingCodeTTL))
+		if err == nil {
+			return domain.HubPairing{Status: domain.PairingPending, Code: code.Code, ExpiresAt: &code.ExpiresAt}, nil
+		}
+		if i+1 == pairingCodeAttempts {
+			return domain.HubPairing{}, err
+		}
+	}
+}
+
+// Claim привязывает хаб с кодом code к аккаунту пользователя
+func (s *HubPairingService) Claim(userId int, input domain.ClaimAquahub) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Claim(userId, input)
+}
+
+// Unclaim отвязывает хаб: аквахаб с данными архивируется, хаб снова получает код привязки
+func (s *HubPairingService) Unclaim(userId, aquahubId int) error {
+	if _, err := s.repo.GetAquahubAccount_OfUser(userId, aquahubId, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	return s.repo.Unclaim(aquahubId, randomstring.RandomBase64String(aquahubTokenLen))
+}
+
+// FactoryReset ставит хабу команду сброса; хаб отвязывается, когда после сброса запросит код привязки
+func (s *HubPairingService) FactoryReset(userId, aquahubId int) (int, error) {
+	accountId, err := s.repo.GetAquahubAccount_OfUser(userId, aquahubId, domain.PermissionManage)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.command.QueueHub(accountId, aquahubId, domain.CommandFactoryReset, "")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.RequestReset(aquahubId); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
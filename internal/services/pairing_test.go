@@ -0,0 +1,102 @@
+package service
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// Хранилище привязки: хаб с токеном hToken привязан к аккаунту, хабов с другими токенами нет,
+// коды привязки выдаются всегда
+type pairingStoreStub struct {
+	IStorePairing
+	hToken string
+	hub    domain.PairedHub
+}
+
+func (s pairingStoreStub) GetHub_ByToken(hToken string) (*domain.PairedHub, error) {
+	if hToken != s.hToken {
+		return nil, nil
+	}
+	hub := s.hub
+	return &hub, nil
+}
+
+func (pairingStoreStub) SetCode(hToken, code string, expiresAt time.Time) (domain.PairingCode, error) {
+	return domain.PairingCode{Code: code, ExpiresAt: expiresAt}, nil
+}
+
+func TestAnnounceTransferred(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	// После передачи у аквахаба новый токен, прежний токен не принадлежит ни одному хабу
+	oldHToken := "old-factory-token"
+	newHToken := "new-hub-token-of-buyer"
+	s := NewHubPairingService(log, pairingStoreStub{
+		hToken: newHToken,
+		hub:    domain.PairedHub{ID: 7, AccountID: 2, UToken: "buyer-u-token"},
+	}, nil)
+
+	t.Log("Given a hub transferred to another account.")
+	{
+		t.Log("\tWhen anyone announces with the old token.")
+		{
+			p, err := s.Announce(oldHToken)
+			if err != nil {
+				t.Fatalf("\t%s\tShould announce without error : %v", failed, err)
+			}
+			if p.Status != domain.PairingPending || p.UToken != "" || p.Code == "" {
+				t.Fatalf("\t%s\tShould get only a pairing code, got %+v.", failed, p)
+			}
+			t.Logf("\t%s\tShould get only a pairing code.", success)
+		}
+
+		t.Log("\tWhen the hub provisioned by the buyer announces with the new token.")
+		{
+			p, err := s.Announce(newHToken)
+			if err != nil {
+				t.Fatalf("\t%s\tShould announce without error : %v", failed, err)
+			}
+			if p.Status != domain.PairingClaimed || p.UToken != "buyer-u-token" {
+				t.Fatalf("\t%s\tShould get the u_token of the buyer, got %+v.", failed, p)
+			}
+			t.Logf("\t%s\tShould get the u_token of the buyer.", success)
+		}
+	}
+}
+
+func TestAnnounceArchived(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	hToken := "archived-hub-token"
+	archivedAt := time.Now().UTC()
+	s := NewHubPairingService(log, pairingStoreStub{
+		hToken: hToken,
+		hub:    domain.PairedHub{ID: 9, AccountID: 1, UToken: "owner-u-token", ArchivedAt: &archivedAt},
+	}, nil)
+
+	t.Log("Given an aquahub moved to the archive.")
+	{
+		t.Log("\tWhen its hub announces itself.")
+		{
+			p, err := s.Announce(hToken)
+			if err != nil {
+				t.Fatalf("\t%s\tShould announce without error : %v", failed, err)
+			}
+			if p.Status != domain.PairingArchived || p.Code != "" || p.UToken != "" {
+				t.Fatalf("\t%s\tShould get only the archived status, got %+v.", failed, p)
+			}
+			t.Logf("\t%s\tShould get only the archived status.", success)
+		}
+	}
+}
@@ -15,19 +15,95 @@ func NewServices(log *logrus.Logger, cache domain.Cache,
 	a IStoreAuthorization,
 	b IStoreChecklist,
 	c IStoreChecklistItem,
-	d IStoreAquahubs) (
+	d IStoreAquahubs,
+	e IStoreVirtualSensor,
+	f IStoreCalibration,
+	g IStoreAnomaly,
+	h IStoreCoverage,
+	i IStoreMetrics,
+	j IStoreInflux,
+	k IStoreAlert,
+	l IStoreNotification,
+	m IStoreAutomation,
+	n IStoreCommand,
+	o IStoreChecklistRecurrence,
+	p IStoreWebhook,
+	q IStoreDigest,
+	r IStorePairing,
+	s IStoreGroup,
+	t IStoreGeo,
+	u IStoreTransfer,
+	v IStoreDeviceType,
+	w IStoreArchive,
+	x IStoreAccess,
+	y IStoreInvite) (
 
 	*logrus.Logger, domain.Cache,
 
 	*AuthService,
 	*ChecklistService,
 	*ChecklistItemService,
-	*AquahubListService) {
+	*AquahubListService,
+	*VirtualSensorService,
+	*CalibrationService,
+	*AnomalyService,
+	*CoverageService,
+	*MetricsService,
+	*InfluxService,
+	*AlertService,
+	*NotificationService,
+	*AutomationService,
+	*CommandService,
+	*ChecklistRecurrenceService,
+	*WebhookService,
+	*DigestService,
+	*HubPairingService,
+	*GroupService,
+	*GeoService,
+	*TransferService,
+	*DeviceTypeService,
+	*ArchiveService,
+	*AccessService,
+	*InviteService) {
+
+	virtualSensor := NewVirtualSensorService(log, cache, e)
+	anomaly := NewAnomalyService(log, cache, g)
+	calibration := NewCalibrationService(log, cache, f, virtualSensor, anomaly)
+	notification := NewNotificationService(log, l)
+	webhook := NewWebhookService(log, cache, p)
+	alert := NewAlertService(log, cache, k, notification, webhook)
+
+	auth := NewAuthService(log, cache, a, notification)
+	command := NewCommandService(log, n, auth)
+	automation := NewAutomationService(log, cache, m, notification, command)
+	deviceType := NewDeviceTypeService(log, v, alert, webhook)
+	aquahubList := NewAquahubListService(d, calibration, virtualSensor, anomaly, alert, automation, webhook, deviceType)
 
 	return log, cache,
 
-		NewAuthService(cache, a),
+		auth,
 		NewChecklistService(b),
-		NewChecklistItemService(c, b),
-		NewAquahubListService(d)
+		NewChecklistItemService(c, b, webhook),
+		aquahubList,
+		virtualSensor,
+		calibration,
+		anomaly,
+		NewCoverageService(log, h),
+		NewMetricsService(log, i),
+		NewInfluxService(log, cache, j, auth, aquahubList),
+		alert,
+		notification,
+		automation,
+		command,
+		NewChecklistRecurrenceService(log, o),
+		webhook,
+		NewDigestService(log, q, notification),
+		NewHubPairingService(log, r, command),
+		NewGroupService(log, s),
+		NewGeoService(log, t),
+		NewTransferService(log, u, virtualSensor),
+		deviceType,
+		NewArchiveService(log, w),
+		NewAccessService(log, x),
+		NewInviteService(log, y, notification, auth)
 }
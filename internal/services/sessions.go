@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/jwt_processing"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/pborman/uuid"
+)
+
+// Сеансы пользователя: пара токенов доступа и обновления (см. domain/session.go)
+
+// IssueTokens начинает новый сеанс пользователя (вход, регистрация, принятие приглашения)
+func (s *AuthService) IssueTokens(userId int) (domain.AuthTokens, error) {
+
+	tokens, next, err := s.newTokens(userId, uuid.NewRandom().String())
+	if err != nil {
+		return tokens, err
+	}
+
+	if err := s.repo.CreateRefreshToken(next); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh обменивает токен обновления на новую пару токенов того же сеанса.
+// Повторное предъявление уже обменянного токена завершает весь сеанс.
+func (s *AuthService) Refresh(refreshToken string) (domain.AuthTokens, error) {
+
+	current, err := s.repo.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	switch {
+	case current.RevokedAt != nil:
+		return domain.AuthTokens{}, domain.ErrInvalidRefreshToken
+	case current.UsedAt != nil:
+		s.log.Warnf("session: user %d reused a refresh token, session %s is revoked", current.UserID, current.Family)
+		if err := s.repo.RevokeRefreshFamily(current.UserID, current.Family); err != nil {
+			return domain.AuthTokens{}, err
+		}
+		return domain.AuthTokens{}, domain.ErrInvalidRefreshToken
+	case !current.ExpiresAt.After(time.Now()):
+		return domain.AuthTokens{}, domain.ErrInvalidRefreshToken
+	}
+
+	tokens, next, err := s.newTokens(current.UserID, current.Family)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	if err := s.repo.RotateRefreshToken(current.ID, next); err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// Logout отзывает текущий токен доступа и токены обновления его сеанса; all - все сеансы пользователя
+func (s *AuthService) Logout(access jwt_processing.Access, all bool) error {
+
+	if all {
+		if err := s.repo.RevokeSessions_OfUser(access.UserId); err != nil {
+			return err
+		}
+	} else if access.Id != "" {
+		family, err := s.repo.GetFamily_ByAccess(access.UserId, access.Id)
+		if err != nil {
+			return err
+		}
+		if family != "" {
+			if err := s.repo.RevokeRefreshFamily(access.UserId, family); err != nil {
+				return err
+			}
+		}
+	}
+
+	if access.Id != "" {
+		if err := s.repo.RevokeToken(access.Id, access.UserId, access.ExpiresAt); err != nil {
+			return err
+		}
+		s.cache.Set(revokedCacheKey(access.Id), true)
+	}
+
+	s.log.Infof("session: user %d logged out (all: %t)", access.UserId, all)
+	return nil
+}
+
+// RunSessionPurge - плановая задача: удаляет истёкшие токены обновления и записи об отозванных токенах
+func (s *AuthService) RunSessionPurge(ctx context.Context) {
+
+	purged, err := s.repo.PurgeSessions()
+	if err != nil {
+		s.log.Errorf("session job: %s", err.Error())
+	}
+
+	if purged.RefreshTokens+purged.RevokedTokens > 0 {
+		s.log.Infof("session job: purged %d refresh tokens, %d revoked tokens", purged.RefreshTokens, purged.RevokedTokens)
+	}
+}
+
+// Новый токен доступа и следующий токен обновления сеанса family
+func (s *AuthService) newTokens(userId int, family string) (domain.AuthTokens, domain.RefreshToken, error) {
+
+	jti := uuid.NewRandom().String()
+	token, expiresAt, err := jwt_processing.GenerateToken(userId, jti)
+	if err != nil {
+		return domain.AuthTokens{}, domain.RefreshToken{}, err
+	}
+
+	refresh := randomstring.RandomBase64String(domain.RefreshTokenLen)
+	next := domain.RefreshToken{
+		UserID:          userId,
+		Family:          family,
+		TokenHash:       hashToken(refresh),
+		AccessJTI:       jti,
+		AccessExpiresAt: expiresAt,
+		ExpiresAt:       time.Now().UTC().Add(domain.RefreshTokenTTL),
+	}
+
+	return domain.AuthTokens{
+		Token:            "Bearer " + token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: next.ExpiresAt,
+	}, next, nil
+}
+
+func revokedCacheKey(jti string) string {
+	return fmt.Sprintf("revoked-%s", jti)
+}
+
+// Отозванный токен доступа; отзыв окончателен, поэтому в кеше хранятся только отозванные jti.
+// Токены без jti выданы до появления отзыва и действуют до окончания своего срока.
+func (s *AuthService) checkRevoked(jti string) error {
+	if jti == "" {
+		return nil
+	}
+	if _, err := s.cache.Get(revokedCacheKey(jti)); err == nil {
+		return domain.ErrTokenRevoked
+	}
+
+	revoked, err := s.repo.IsTokenRevoked(jti)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		s.cache.Set(revokedCacheKey(jti), true)
+		return domain.ErrTokenRevoked
+	}
+	return nil
+}
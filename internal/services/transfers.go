@@ -0,0 +1,94 @@
+package service
+
+import (
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис передачи хабов между аккаунтами (см. domain/transfer.go)
+
+type TransferService struct {
+	repo    IStoreTransfer
+	log     *logrus.Logger
+	virtual *VirtualSensorService
+}
+
+func NewTransferService(log *logrus.Logger, repo IStoreTransfer, virtual *VirtualSensorService) *TransferService {
+	return &TransferService{log: log, repo: repo, virtual: virtual}
+}
+
+// Create - владелец хаба создаёт передачу и получает код для получателя
+func (s *TransferService) Create(userId, aquahubId int, input domain.CreateHubTransfer) (domain.HubTransferCreated, error) {
+	if err := input.Validate(); err != nil {
+		return domain.HubTransferCreated{}, err
+	}
+
+	accountId, err := s.repo.GetAquahubAccount_OfUser(userId, aquahubId, domain.PermissionManage)
+	if err != nil {
+		return domain.HubTransferCreated{}, err
+	}
+
+	expiresAt := time.Now().UTC().Add(domain.TransferCodeTTL)
+	for i := 0; ; i++ {
+		code := randomstring.RandomCode(domain.TransferCodeLen)
+		id, err := s.repo.Create(userId, accountId, aquahubId, input.Mode, code, expiresAt)
+		if err == nil {
+			s.log.Infof("transfer %d: user %d offered aquahub %d of account %d (%s)", id, userId, aquahubId, accountId, input.Mode)
+			return domain.HubTransferCreated{ID: id, Code: code, ExpiresAt: expiresAt}, nil
+		}
+		if i+1 == pairingCodeAttempts {
+			return domain.HubTransferCreated{}, err
+		}
+	}
+}
+
+// Get - ожидающая передача хаба; nil, если её нет
+func (s *TransferService) Get(userId, aquahubId int) (*domain.HubTransfer, error) {
+	if _, err := s.repo.GetAquahubAccount_OfUser(userId, aquahubId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPending_OfAquahub(aquahubId)
+}
+
+func (s *TransferService) Cancel(userId, aquahubId int) error {
+	if _, err := s.repo.GetAquahubAccount_OfUser(userId, aquahubId, domain.PermissionManage); err != nil {
+		return err
+	}
+	if err := s.repo.Cancel(userId, aquahubId); err != nil {
+		return err
+	}
+
+	s.log.Infof("transfer: user %d cancelled transfer of aquahub %d", userId, aquahubId)
+	return nil
+}
+
+func (s *TransferService) GetAll(userId int, filter domain.HubTransfersFilter) ([]domain.HubTransfer, error) {
+	return s.repo.GetAll_OfUser(userId, filter)
+}
+
+// Accept - получатель принимает передачу кодом в свой аккаунт; токен хаба меняется,
+// новый токен возвращается только получателю
+func (s *TransferService) Accept(userId int, input domain.AcceptHubTransfer) (*domain.HubTransferAccepted, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	hToken := randomstring.RandomBase64String(aquahubTokenLen)
+	t, err := s.repo.Accept(userId, input.AccountID, input.Code, hToken, randomstring.RandomBase64String(aquahubTokenLen))
+	if err != nil {
+		return nil, err
+	}
+
+	// При переносе хаба виртуальные сенсоры обоих аккаунтов меняются
+	if s.virtual != nil {
+		s.virtual.resetCache(t.FromAccountID)
+		s.virtual.resetCache(input.AccountID)
+	}
+
+	s.log.Infof("transfer %d: user %d accepted aquahub %d of account %d into account %d as aquahub %d (%s)",
+		t.ID, userId, t.AquahubID, t.FromAccountID, input.AccountID, *t.NewAquahubID, t.Mode)
+	return &domain.HubTransferAccepted{HubTransfer: *t, HToken: hToken}, nil
+}
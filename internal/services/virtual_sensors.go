@@ -0,0 +1,348 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/formula"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис виртуальных (вычисляемых) сенсоров
+
+type VirtualSensorService struct {
+	repo  IStoreVirtualSensor
+	cache domain.Cache
+	log   *logrus.Logger
+}
+
+func NewVirtualSensorService(log *logrus.Logger, cache domain.Cache, repo IStoreVirtualSensor) *VirtualSensorService {
+	return &VirtualSensorService{log: log, cache: cache, repo: repo}
+}
+
+// Виртуальный сенсор с разобранной формулой
+type compiledVirtualSensor struct {
+	domain.VirtualSensor
+	expr *formula.Expr
+}
+
+func virtualSensorsCacheKey(accountId int) string {
+	return fmt.Sprintf("virtual-sensors-%d", accountId)
+}
+
+func (s *VirtualSensorService) Create(userId int, input domain.CreateVirtualSensor) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	// Устройство должно принадлежать аккаунту пользователя
+	device, err := s.repo.GetDevice_OfUser(userId, input.DeviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.checkFormula(device.AccountID, 0, input.Formula); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Create(input.DeviceID, input)
+	if err != nil {
+		return 0, err
+	}
+
+	s.resetCache(device.AccountID)
+	return id, nil
+}
+
+func (s *VirtualSensorService) GetAll(userId int) ([]domain.VirtualSensor, error) {
+	return s.repo.GetAll_OfUser(userId)
+}
+
+func (s *VirtualSensorService) GetById(userId, id int) (*domain.VirtualSensor, error) {
+	return s.repo.GetById(userId, id)
+}
+
+func (s *VirtualSensorService) Update(userId int, input domain.UpdateVirtualSensor) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	sensor, err := s.repo.GetById(userId, input.ID)
+	if err != nil {
+		return err
+	}
+
+	if input.Formula != nil {
+		if err := s.checkFormula(sensor.AccountID, sensor.ID, *input.Formula); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Update(userId, input); err != nil {
+		return err
+	}
+
+	s.resetCache(sensor.AccountID)
+	return nil
+}
+
+func (s *VirtualSensorService) Delete(userId, id int) error {
+	sensor, err := s.repo.GetById(userId, id)
+	if err != nil {
+		return err
+	}
+
+	// Нельзя удалить сенсор, на который ссылаются другие формулы
+	list, err := s.repo.GetAll_OfAccount(sensor.AccountID)
+	if err != nil {
+		return err
+	}
+	for _, vs := range list {
+		if vs.ID == id {
+			continue
+		}
+		if expr, err := formula.Parse(vs.Formula); err == nil && containsInt(expr.Refs(), id) {
+			return fmt.Errorf("%w: sensor s%d is used in the formula of sensor s%d", domain.ErrInvalidFormula, id, vs.ID)
+		}
+	}
+
+	if err := s.repo.Delete(userId, id); err != nil {
+		return err
+	}
+
+	s.resetCache(sensor.AccountID)
+	return nil
+}
+
+// Проверка формулы при создании и изменении сенсора:
+//   - синтаксис;
+//   - все сенсоры из формулы принадлежат тому же аккаунту;
+//   - формулы виртуальных сенсоров аккаунта не ссылаются друг на друга по кругу.
+//
+// sensorId = 0 для нового сенсора.
+func (s *VirtualSensorService) checkFormula(accountId, sensorId int, src string) error {
+
+	expr, err := formula.Parse(src)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidFormula, err.Error())
+	}
+
+	refs := expr.Refs()
+	if len(refs) == 0 {
+		return fmt.Errorf("%w: expression does not reference any sensor", domain.ErrInvalidFormula)
+	}
+
+	found, err := s.repo.GetSensorIds_OfAccount(accountId, refs)
+	if err != nil {
+		return err
+	}
+	if len(found) != len(refs) {
+		var unknown []string
+		for _, id := range refs {
+			if !containsInt(found, id) {
+				unknown = append(unknown, fmt.Sprintf("s%d", id))
+			}
+		}
+		return fmt.Errorf("%w: unknown sensors %s", domain.ErrInvalidFormula, strings.Join(unknown, ", "))
+	}
+
+	list, err := s.repo.GetAll_OfAccount(accountId)
+	if err != nil {
+		return err
+	}
+
+	graph := make(map[int][]int, len(list)+1)
+	for _, vs := range list {
+		if e, err := formula.Parse(vs.Formula); err == nil {
+			graph[vs.ID] = e.Refs()
+		}
+	}
+	graph[sensorId] = refs
+
+	if _, err = formula.TopoSort(graph); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidFormula, err.Error())
+	}
+
+	return nil
+}
+
+// Виртуальные сенсоры аккаунта (кроме архивных), формулы которых зависят от сенсора,
+// в том числе через другие виртуальные сенсоры
+func (s *VirtualSensorService) dependents(accountId, sensorId int) ([]int, error) {
+
+	compiled, err := s.getCompiled(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	// В порядке вычисления зависимости идут раньше зависимых сенсоров
+	affected := map[int]bool{sensorId: true}
+	var ids []int
+	for _, vs := range compiled {
+		for _, ref := range vs.expr.Refs() {
+			if affected[ref] {
+				affected[vs.ID] = true
+				ids = append(ids, vs.ID)
+				break
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func (s *VirtualSensorService) resetCache(accountId int) {
+	if s.cache != nil {
+		s.cache.Delete(virtualSensorsCacheKey(accountId))
+	}
+}
+
+// Виртуальные сенсоры аккаунта (кроме архивных) в порядке вычисления (зависимые - после своих зависимостей)
+func (s *VirtualSensorService) getCompiled(accountId int) ([]compiledVirtualSensor, error) {
+
+	if s.cache != nil {
+		if list, err := s.cache.Get(virtualSensorsCacheKey(accountId)); err == nil {
+			return list.([]compiledVirtualSensor), nil
+		}
+	}
+
+	list, err := s.repo.GetAll_OfAccount(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int]compiledVirtualSensor, len(list))
+	graph := make(map[int][]int, len(list))
+	for _, vs := range list {
+		if vs.ArchivedAt != nil {
+			continue
+		}
+		expr, err := formula.Parse(vs.Formula)
+		if err != nil {
+			s.log.Errorf("virtual sensor s%d: %s", vs.ID, err.Error())
+			continue
+		}
+		byId[vs.ID] = compiledVirtualSensor{VirtualSensor: vs, expr: expr}
+		graph[vs.ID] = expr.Refs()
+	}
+
+	order, err := formula.TopoSort(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]compiledVirtualSensor, 0, len(order))
+	for _, id := range order {
+		compiled = append(compiled, byId[id])
+	}
+
+	if s.cache != nil {
+		s.cache.Set(virtualSensorsCacheKey(accountId), compiled)
+	}
+
+	return compiled, nil
+}
+
+// Derive вычисляет показания виртуальных сенсоров по пришедшим показаниям.
+// Вычисляются только те сенсоры, формулы которых зависят от пришедших (или уже вычисленных) значений.
+// Недостающие значения берутся из последних сохранённых показаний.
+func (s *VirtualSensorService) Derive(list []domain.SensorDataSet) []domain.SensorDataSet {
+
+	var derived []domain.SensorDataSet
+
+	byAccount := make(map[int][]domain.SensorDataSet)
+	for _, x := range list {
+		byAccount[x.Account_id] = append(byAccount[x.Account_id], x)
+	}
+
+	for accountId, readings := range byAccount {
+
+		compiled, err := s.getCompiled(accountId)
+		if err != nil {
+			s.log.Errorf("virtual sensors of account %d: %s", accountId, err.Error())
+			continue
+		}
+		if len(compiled) == 0 {
+			continue
+		}
+
+		values := make(map[int]float64)
+		changed := make(map[int]bool)
+		var createdAt time.Time
+
+		for _, x := range readings {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(x.Value), 64); err == nil {
+				values[x.Sensor_id] = v
+				changed[x.Sensor_id] = true
+			}
+			if x.CreatedAt.After(createdAt) {
+				createdAt = x.CreatedAt
+			}
+		}
+
+		for _, vs := range compiled {
+
+			refs := vs.expr.Refs()
+
+			affected := false
+			var missing []int
+			for _, id := range refs {
+				if changed[id] {
+					affected = true
+				}
+				if _, ok := values[id]; !ok {
+					missing = append(missing, id)
+				}
+			}
+			if !affected {
+				continue
+			}
+
+			if len(missing) > 0 {
+				latest, err := s.repo.GetLatestValues(accountId, missing)
+				if err != nil {
+					continue
+				}
+				for id, val := range latest {
+					if v, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+						values[id] = v
+					}
+				}
+			}
+
+			v, err := vs.expr.Eval(values)
+			if err != nil {
+				s.log.Debugf("virtual sensor s%d: %s", vs.ID, err.Error())
+				continue
+			}
+
+			values[vs.ID] = v
+			changed[vs.ID] = true
+
+			derived = append(derived, domain.SensorDataSet{
+				Account_id:      vs.AccountID,
+				Aquahub_id:      vs.AquahubID,
+				Device_id:       vs.DeviceID,
+				Sensor_id:       vs.ID,
+				Local_device_id: -1,
+				Local_sensor_id: vs.LocalID,
+				Value:           strconv.FormatFloat(v, 'g', 10, 64),
+				CreatedAt:       createdAt,
+			})
+		}
+	}
+
+	return derived
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
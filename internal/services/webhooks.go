@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/netguard"
+	"github.com/o-sokol-o/hub/pkg/notify"
+	"github.com/o-sokol-o/hub/pkg/randomstring"
+	"github.com/o-sokol-o/hub/pkg/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// Сервис webhook_ов: события платформы превращаются в записи очереди доставки для каждой
+// подписки аккаунта на событие, плановая задача отправляет их подписанными с повторами (at-least-once).
+
+type WebhookService struct {
+	repo   IStoreWebhook
+	cache  domain.Cache
+	log    *logrus.Logger
+	client *http.Client
+}
+
+func NewWebhookService(log *logrus.Logger, cache domain.Cache, repo IStoreWebhook) *WebhookService {
+	return &WebhookService{log: log, cache: cache, repo: repo, client: netguard.NewClient(domain.WebhookTimeout)}
+}
+
+// Длина секрета подписки и ID события
+const (
+	webhookSecretLen  = 32
+	webhookEventIdLen = 32
+)
+
+// Подписки аккаунта в кеше: показания приходят часто, а подписки меняются редко
+func webhooksCacheKey(accountId int) string {
+	return fmt.Sprintf("webhooks-%d", accountId)
+}
+
+func (s *WebhookService) forget(accountId int) {
+	if s.cache != nil {
+		s.cache.Delete(webhooksCacheKey(accountId))
+	}
+}
+
+func (s *WebhookService) getSubscriptions(accountId int) ([]domain.Webhook, error) {
+	if s.cache != nil {
+		if list, err := s.cache.Get(webhooksCacheKey(accountId)); err == nil {
+			return list.([]domain.Webhook), nil
+		}
+	}
+
+	list, err := s.repo.GetAll_OfAccount(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		s.cache.Set(webhooksCacheKey(accountId), list)
+	}
+	return list, nil
+}
+
+func (s *WebhookService) Create(userId, accountId int, input domain.SetWebhook) (domain.WebhookCreated, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return domain.WebhookCreated{}, err
+	}
+
+	w := domain.Webhook{
+		AccountID:   accountId,
+		URL:         input.URL,
+		Secret:      randomstring.RandomBase64String(webhookSecretLen),
+		Events:      input.Events,
+		Description: input.Description,
+		Enabled:     input.Enabled == nil || *input.Enabled,
+	}
+
+	id, err := s.repo.Create(w)
+	if err != nil {
+		return domain.WebhookCreated{}, err
+	}
+	s.forget(accountId)
+
+	return domain.WebhookCreated{ID: id, Secret: w.Secret}, nil
+}
+
+func (s *WebhookService) GetAll(userId, accountId int) ([]domain.Webhook, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll_OfAccount(accountId)
+}
+
+func (s *WebhookService) GetById(userId, accountId, id int) (*domain.Webhook, error) {
+	return s.getById(userId, accountId, id, domain.PermissionRead)
+}
+
+// Подписка аккаунта, в котором у пользователя есть право perm
+func (s *WebhookService) getById(userId, accountId, id int, perm domain.Permission) (*domain.Webhook, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, perm); err != nil {
+		return nil, err
+	}
+	return s.repo.GetById(accountId, id)
+}
+
+// Обновление подписки; секрет не меняется
+func (s *WebhookService) Update(userId, accountId, id int, input domain.SetWebhook) error {
+	w, err := s.getById(userId, accountId, id, domain.PermissionManage)
+	if err != nil {
+		return err
+	}
+
+	w.URL = input.URL
+	w.Events = input.Events
+	w.Description = input.Description
+	if input.Enabled != nil {
+		w.Enabled = *input.Enabled
+	}
+
+	if err := s.repo.Update(*w); err != nil {
+		return err
+	}
+	s.forget(accountId)
+
+	return nil
+}
+
+func (s *WebhookService) Delete(userId, accountId, id int) error {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(accountId, id); err != nil {
+		return err
+	}
+	s.forget(accountId)
+
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(userId, accountId, id int, status string, from, to time.Time) ([]domain.WebhookDelivery, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveries(accountId, id, status, from, to)
+}
+
+// Повторная доставка события из журнала: тело и ID события прежние, подпись - новая
+func (s *WebhookService) Redeliver(userId, accountId, id, deliveryId int) (int, error) {
+	if err := s.repo.CheckAccount_OfUser(userId, accountId, domain.PermissionManage); err != nil {
+		return 0, err
+	}
+	return s.repo.Redeliver(accountId, id, deliveryId)
+}
+
+//-------------------------------------------------------------------------
+
+// Publish ставит событие аккаунта в очередь доставки всем включённым подпискам на него.
+// Ошибки только записываются в лог: приём показаний и остальные операции от webhook_ов не зависят.
+func (s *WebhookService) Publish(accountId int, event string, data interface{}) {
+	if s == nil || accountId == 0 {
+		return
+	}
+
+	subs, err := s.getSubscriptions(accountId)
+	if err != nil {
+		s.log.Errorf("webhook %s: %s", event, err.Error())
+		return
+	}
+
+	var targets []domain.Webhook
+	for _, w := range subs {
+		if w.Enabled && w.Subscribed(event) {
+			targets = append(targets, w)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	e := domain.WebhookEvent{
+		ID:        randomstring.RandomBase16String(webhookEventIdLen),
+		Event:     event,
+		AccountID: accountId,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		s.log.Errorf("webhook %s: %s", event, err.Error())
+		return
+	}
+
+	list := make([]domain.WebhookDelivery, 0, len(targets))
+	for _, w := range targets {
+		list = append(list, domain.WebhookDelivery{
+			WebhookID: w.ID,
+			AccountID: accountId,
+			Event:     event,
+			EventID:   e.ID,
+			Payload:   payload,
+		})
+	}
+
+	if err := s.repo.CreateDeliveries(list); err != nil {
+		s.log.Errorf("webhook %s: %s", event, err.Error())
+	}
+}
+
+// PublishHub - событие хаба; аккаунт определяется по хабу
+func (s *WebhookService) PublishHub(aquahubId int, event string, data interface{}) {
+	if s == nil {
+		return
+	}
+
+	accountId, err := s.repo.GetAquahubAccount(aquahubId)
+	if err != nil {
+		s.log.Errorf("webhook %s: %s", event, err.Error())
+		return
+	}
+	s.Publish(accountId, event, data)
+}
+
+// Показания по аккаунтам - одно событие на аккаунт за один приём
+func (s *WebhookService) PublishReadings(list []domain.SensorDataSet) {
+	if s == nil {
+		return
+	}
+
+	byAccount := make(map[int][]domain.WebhookReading)
+	var order []int
+	for _, x := range list {
+		if _, ok := byAccount[x.Account_id]; !ok {
+			order = append(order, x.Account_id)
+		}
+		byAccount[x.Account_id] = append(byAccount[x.Account_id], domain.WebhookReading{
+			SensorID:  x.Sensor_id,
+			DeviceID:  x.Device_id,
+			AquahubID: x.Aquahub_id,
+			Value:     x.Value,
+			Flag:      x.Flag,
+			CreatedAt: x.CreatedAt,
+		})
+	}
+
+	for _, accountId := range order {
+		s.Publish(accountId, domain.WebhookReadingReceived, domain.WebhookReadings{Readings: byAccount[accountId]})
+	}
+}
+
+// Событие завершения чек-листа, если выполнены все его пункты
+func (s *WebhookService) PublishChecklistCompleted(checklistId int) {
+	if s == nil {
+		return
+	}
+
+	c, err := s.repo.GetCompletedChecklist(checklistId)
+	if err != nil {
+		s.log.Errorf("webhook %s: %s", domain.WebhookChecklistCompleted, err.Error())
+		return
+	}
+	if c != nil {
+		s.Publish(c.AccountID, domain.WebhookChecklistCompleted, c)
+	}
+}
+
+// RunHubsOffline - плановая задача: событие об отключении хаба без показаний дольше HubOfflineAfter.
+// Событие отправляется один раз, пока от хаба не придут новые показания.
+func (s *WebhookService) RunHubsOffline(ctx context.Context) {
+
+	list, err := s.repo.SetHubsOffline(domain.HubOfflineAfter)
+	if err != nil {
+		s.log.Errorf("webhook job: %s", err.Error())
+		return
+	}
+
+	for _, hub := range list {
+		if ctx.Err() != nil {
+			return
+		}
+		s.Publish(hub.AccountID, domain.WebhookHubOffline, hub)
+	}
+}
+
+//-------------------------------------------------------------------------
+
+// RunDeliveries - плановая задача: отправка событий из очереди. Неудачная отправка (сеть или ответ не 2xx)
+// повторяется с растущей задержкой до WebhookMaxAttempts попыток.
+func (s *WebhookService) RunDeliveries(ctx context.Context) {
+
+	lease := domain.WebhookTimeout*2 + time.Minute
+
+	list, err := s.repo.ClaimDue(deliveryBatch, lease)
+	if err != nil {
+		s.log.Errorf("webhook job: %s", err.Error())
+		return
+	}
+
+	for _, d := range list {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.deliver(ctx, &d)
+
+		if err := s.repo.UpdateDelivery(d); err != nil {
+			s.log.Errorf("webhook job: delivery %d: %s", d.ID, err.Error())
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, d *domain.WebhookDelivery) {
+
+	d.Attempts++
+
+	var err error
+	if !d.Enabled {
+		d.ResponseStatus = 0
+		err = notify.Permanent(errors.New("webhook is disabled"))
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, domain.WebhookTimeout)
+		d.ResponseStatus, err = webhook.Post(sendCtx, s.client, d.URL, d.Secret, d.Event, d.EventID, d.Payload)
+		cancel()
+	}
+
+	now := time.Now().UTC()
+	if err == nil {
+		d.Status = domain.DeliverySent
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		d.LastError = ""
+		return
+	}
+
+	d.LastError = err.Error()
+	if len(d.LastError) > maxDeliveryErrorLen {
+		d.LastError = d.LastError[:maxDeliveryErrorLen]
+	}
+
+	if notify.IsPermanent(err) || d.Attempts >= domain.WebhookMaxAttempts {
+		d.Status = domain.DeliveryFailed
+		d.NextAttemptAt = nil
+		s.log.Errorf("webhook delivery %d to %s failed: %s", d.ID, d.URL, err.Error())
+		return
+	}
+
+	next := now.Add(notify.Backoff(d.Attempts, domain.WebhookRetryDelay, domain.WebhookMaxRetryDelay))
+	d.NextAttemptAt = &next
+}
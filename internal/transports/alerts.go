@@ -0,0 +1,528 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type AlertRulesResponse struct {
+	Data []domain.AlertRule `json:"data"`
+}
+
+type AlertEventsResponse struct {
+	Data []domain.AlertEvent `json:"data"`
+}
+
+type AlertsResponse struct {
+	Data []domain.Alert `json:"data"`
+}
+
+// Необязательный числовой параметр запроса; отсутствующий параметр - 0
+func queryId(ctx *gin.Context, name string) (int, error) {
+	s := ctx.Query(name)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// @Summary     Create Alert Rule
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description create alert rule for sensor: above, below, outside range or no data for no_data_sec;
+// @Description hysteresis - margin to resolve fired alert, min_duration_sec - how long the threshold must be breached,
+// @Description renotify_sec - reminder interval while the alert is not acknowledged (0 - no reminders)
+// @ID          create-alert-rule
+// @Accept      json
+// @Produce     json
+// @Param       input body domain.CreateAlertRule true "Alert rule info"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-rules [post]
+func (h *Handler) createAlertRule(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateAlertRule
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceAlert.Create(userId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Alert Rules
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description get alert rules of the user accounts with their current state
+// @ID          get-all-alert-rules
+// @Accept      json
+// @Produce     json
+// @Param       sensor_id query int    false "Only rules of the sensor"
+// @Param       group_id  query int    false "Only rules of sensors of hubs in the site, zone or tank (with nested groups)"
+// @Param       tag       query string false "Only rules of sensors tagged directly or through their device or aquahub"
+// @Success     200     {object} AlertRulesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-rules [get]
+func (h *Handler) getAllAlertRules(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := queryId(ctx, "sensor_id")
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid sensor_id param")
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAlert.GetAll(userId, sensorId, filter)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AlertRulesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Alert Rule By Id
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description get alert rule by id
+// @ID          get-alert-rule-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Rule ID"
+// @Success     200     {object} domain.AlertRule
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-rules/{id} [get]
+func (h *Handler) getAlertRuleById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	rule, err := h.serviceAlert.GetById(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// @Summary     Update Alert Rule By Id
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description update thresholds, durations or enable/disable alert rule; kind and sensor can not be changed
+// @ID          update-alert-rule-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                    true "Rule ID"
+// @Param       input body domain.UpdateAlertRule true "Alert rule info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-rules/{id} [put]
+func (h *Handler) updateAlertRule(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input domain.UpdateAlertRule
+	if err = ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input.ID, err = strconv.Atoi(ctx.Param("id"))
+	if err != nil || input.ID == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAlert.Update(userId, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Alert Rule By Id
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description delete alert rule and its events
+// @ID          delete-alert-rule-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Rule ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-rules/{id} [delete]
+func (h *Handler) deleteAlertRule(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAlert.Delete(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Alert Events
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description get alert events of the user accounts (firing, resolved and user actions), newest first (default - last 24 hours)
+// @ID          get-alert-events
+// @Accept      json
+// @Produce     json
+// @Param       sensor_id  query int    false "Only events of the sensor"
+// @Param       rule_id    query int    false "Only events of the rule"
+// @Param       alert_id   query int    false "Only events of the alert"
+// @Param       aquahub_id query int    false "Only events of the aquahub sensors"
+// @Param       from       query string false "Period start, RFC3339"
+// @Param       to         query string false "Period end, RFC3339"
+// @Param       group_id   query int    false "Only sensors of hubs in the site, zone or tank (with nested groups)"
+// @Param       tag        query string false "Only sensors tagged directly or through their device or aquahub"
+// @Success     200     {object} AlertEventsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alert-events [get]
+func (h *Handler) getAlertEvents(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var filter domain.AlertEventsFilter
+
+	if filter.SensorID, err = queryId(ctx, "sensor_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid sensor_id param")
+		return
+	}
+	if filter.RuleID, err = queryId(ctx, "rule_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid rule_id param")
+		return
+	}
+	if filter.AlertID, err = queryId(ctx, "alert_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid alert_id param")
+		return
+	}
+	if filter.AquahubID, err = queryId(ctx, "aquahub_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid aquahub_id param")
+		return
+	}
+	if filter.From, filter.To, err = parsePeriod(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+	if filter.GroupFilter, err = parseGroupFilter(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAlert.GetEvents(userId, filter)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AlertEventsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Alerts
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description get alerts of the user accounts active in the period, newest first (default - last 24 hours);
+// @Description with aquahub_id - alert history of the aquahub
+// @ID          get-alerts
+// @Accept      json
+// @Produce     json
+// @Param       status     query string false "Alert status" Enums(firing, acknowledged, snoozed, resolved)
+// @Param       aquahub_id query int    false "Only alerts of the aquahub sensors"
+// @Param       sensor_id  query int    false "Only alerts of the sensor"
+// @Param       from       query string false "Period start, RFC3339"
+// @Param       to         query string false "Period end, RFC3339"
+// @Param       group_id   query int    false "Only sensors of hubs in the site, zone or tank (with nested groups)"
+// @Param       tag        query string false "Only sensors tagged directly or through their device or aquahub"
+// @Success     200     {object} AlertsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alerts [get]
+func (h *Handler) getAlerts(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filter := domain.AlertsFilter{Status: ctx.Query("status")}
+
+	switch filter.Status {
+	case "", domain.AlertFiring, domain.AlertAcknowledged, domain.AlertSnoozed, domain.AlertResolved:
+	default:
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid status param")
+		return
+	}
+	if filter.AquahubID, err = queryId(ctx, "aquahub_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid aquahub_id param")
+		return
+	}
+	if filter.SensorID, err = queryId(ctx, "sensor_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid sensor_id param")
+		return
+	}
+	if filter.From, filter.To, err = parsePeriod(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+	if filter.GroupFilter, err = parseGroupFilter(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAlert.GetAlerts(userId, filter)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AlertsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Alert By Id
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description get alert by id; its timeline - /api/alert-events?alert_id=
+// @ID          get-alert-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Alert ID"
+// @Success     200     {object} domain.Alert
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alerts/{id} [get]
+func (h *Handler) getAlertById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	alert, err := h.serviceAlert.GetAlert(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+// Недопустимое действие (оповещение уже закрыто, отсрочка в прошлом) - ошибка клиента
+func (h *Handler) alertActionErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrAlertAction) {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary     Acknowledge Alert
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description acknowledge alert: somebody is handling it, reminders stop until it is resolved
+// @ID          acknowledge-alert
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Alert ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alerts/{id}/ack [post]
+func (h *Handler) acknowledgeAlert(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAlert.Acknowledge(userId, id); err != nil {
+		h.alertActionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Snooze Alert
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description snooze alert until the time or for duration_sec (up to 7 days);
+// @Description when the snooze expires and the alert is still open, it fires again and a reminder is sent
+// @ID          snooze-alert
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                true "Alert ID"
+// @Param       input body domain.SnoozeAlert true "Snooze until or duration"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alerts/{id}/snooze [post]
+func (h *Handler) snoozeAlert(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SnoozeAlert
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	if err := h.serviceAlert.Snooze(userId, id, input); err != nil {
+		h.alertActionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Resolve Alert
+// @Security    ApiKeyAuth
+// @Tags        Alerts
+// @Description resolve alert manually; the rule is reset, so it fires again as a new alert if the condition persists
+// @ID          resolve-alert
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Alert ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/alerts/{id}/resolve [post]
+func (h *Handler) resolveAlert(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAlert.Resolve(userId, id); err != nil {
+		h.alertActionErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
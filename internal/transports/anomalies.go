@@ -0,0 +1,198 @@
+package handler_api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type SensorFlagsResponse struct {
+	Data []domain.SensorFlag `json:"data"`
+}
+
+// Период запроса из параметров from/to (RFC3339); по умолчанию - последние сутки
+func parsePeriod(ctx *gin.Context) (from, to time.Time, err error) {
+	to = time.Now().UTC()
+	if s := ctx.Query("to"); s != "" {
+		if to, err = time.Parse(time.RFC3339, s); err != nil {
+			return
+		}
+	}
+
+	from = to.Add(-24 * time.Hour)
+	if s := ctx.Query("from"); s != "" {
+		if from, err = time.Parse(time.RFC3339, s); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// @Summary     Get Sensor Detectors
+// @Security    ApiKeyAuth
+// @Tags        Anomaly Detection
+// @Description get anomaly detector settings of sensor (zero value - detector is disabled)
+// @ID          get-detectors
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} domain.SensorDetectors
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/detectors [get]
+func (h *Handler) getDetectors(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	detectors, err := h.serviceAnomaly.GetDetectors(userId, sensorId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, detectors)
+}
+
+// @Summary     Set Sensor Detectors
+// @Security    ApiKeyAuth
+// @Tags        Anomaly Detection
+// @Description set rate-of-change, rolling z-score and flatline detectors of sensor
+// @ID          set-detectors
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                       true "Sensor ID"
+// @Param       input body domain.SetSensorDetectors true "Detector settings"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/detectors [put]
+func (h *Handler) setDetectors(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetSensorDetectors
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAnomaly.SetDetectors(userId, sensorId, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Sensor Detectors
+// @Security    ApiKeyAuth
+// @Tags        Anomaly Detection
+// @Description disable all anomaly detectors of sensor; existing flags are kept
+// @ID          delete-detectors
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/detectors [delete]
+func (h *Handler) deleteDetectors(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAnomaly.DeleteDetectors(userId, sensorId); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Flagged Readings
+// @Security    ApiKeyAuth
+// @Tags        Anomaly Detection
+// @Description get readings of sensor marked by anomaly detectors in period [from, to)
+// @ID          get-flags
+// @Accept      json
+// @Produce     json
+// @Param       id   path  int    true  "Sensor ID"
+// @Param       from query string false "Period start, RFC3339 (default: 24 hours before to)"
+// @Param       to   query string false "Period end, RFC3339 (default: now)"
+// @Success     200     {object} SensorFlagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/flags [get]
+func (h *Handler) getFlags(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceAnomaly.GetFlags(userId, sensorId, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SensorFlagsResponse{
+		Data: list,
+	})
+}
@@ -0,0 +1,779 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type AquahubsResponse struct {
+	Data []domain.AquahubList `json:"data"`
+}
+
+type DevicesResponse struct {
+	Data []domain.Device `json:"data"`
+}
+
+type SensorsResponse struct {
+	Data []domain.Sensor `json:"data"`
+}
+
+// Занятый local_id - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) aquahubErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrLocalIdInUse) {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// Параметр include-archived: списки включают записи архива
+func parseIncludeArchived(ctx *gin.Context) (bool, error) {
+	s := ctx.Query("include-archived")
+	if s == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New("invalid include-archived param")
+	}
+	return include, nil
+}
+
+// @Summary     Create Aquahub
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description create aquahub in the user account, response contains the hub token for firmware
+// @ID          create-aquahub
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateAquahub true "Aquahub info"
+// @Success     200     {object}  domain.AquahubCreated
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs [post]
+func (h *Handler) createAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateAquahub
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hub, err := h.serviceAquahubList.CreateAquahub(userId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hub)
+}
+
+// @Summary     Get All Aquahubs
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description get all aquahubs of the user accounts, archived aquahubs only with include-archived
+// @ID          get-all-aquahubs
+// @Accept      json
+// @Produce     json
+// @Param       group_id query int    false "Only items of the group (site, zone or tank) and its nested groups"
+// @Param       tag      query string false "Only items with the tag (tag of hub or device applies to its devices and sensors)"
+// @Param       include-archived query bool false "Include archived items"
+// @Success     200     {object} AquahubsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs [get]
+func (h *Handler) getAllAquahubs(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeArchived, err := parseIncludeArchived(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAquahubList.GetAllAquahubOfUser(userId, filter, includeArchived)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AquahubsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Aquahub By Id
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description get aquahub by id
+// @ID          get-aquahub-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} domain.AquahubList
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id} [get]
+func (h *Handler) getAquahubById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	item, err := h.serviceAquahubList.GetAquahub(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// @Summary     Update Aquahub By Id
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description update title, description or status of aquahub
+// @ID          update-aquahub-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Param       input   body      domain.UpdateAquahub true "Aquahub info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id} [put]
+func (h *Handler) updateAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.UpdateAquahub
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAquahubList.UpdateAquahub(userId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Aquahub By Id
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description move aquahub to the archive: the hub stops accepting readings, its devices, sensors and readings are kept.
+// @Description Aquahub can be restored within 30 days, after that it is deleted with its devices, sensors and readings.
+// @ID          delete-aquahub-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id} [delete]
+func (h *Handler) deleteAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.DeleteAquahub(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Restore Aquahub By Id
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description restore aquahub from the archive
+// @ID          restore-aquahub-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/restore [post]
+func (h *Handler) restoreAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.RestoreAquahub(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Create Device
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description create device of aquahub, local_id must be unique within the hub
+// @ID          create-device
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateDevice true "Device info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices [post]
+func (h *Handler) createDevice(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateDevice
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceAquahubList.CreateDevice(userId, input)
+	if err != nil {
+		h.aquahubErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Devices
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description get all devices of aquahub, archived devices only with include-archived
+// @ID          get-all-devices-of-aquahub
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Param       group_id query int    false "Only items of the group (site, zone or tank) and its nested groups"
+// @Param       tag      query string false "Only items with the tag (tag of hub or device applies to its devices and sensors)"
+// @Param       include-archived query bool false "Include archived items"
+// @Success     200     {object} DevicesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/devices [get]
+func (h *Handler) getAllDevices(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeArchived, err := parseIncludeArchived(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAquahubList.GetDevicesOfAquahub(userId, id, filter, includeArchived)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DevicesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Device By Id
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description get device by id
+// @ID          get-device-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Success     200     {object} domain.Device
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id} [get]
+func (h *Handler) getDeviceById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	item, err := h.serviceAquahubList.GetDevice(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// @Summary     Update Device By Id
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description update title, description or status of device
+// @ID          update-device-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Param       input   body      domain.UpdateDevice true "Device info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id} [put]
+func (h *Handler) updateDevice(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.UpdateDevice
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAquahubList.UpdateDevice(userId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Device By Id
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description move device to the archive, its sensors and readings are kept.
+// @Description Device can be restored within 30 days, after that it is deleted with its sensors and readings.
+// @ID          delete-device-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id} [delete]
+func (h *Handler) deleteDevice(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.DeleteDevice(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Restore Device By Id
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description restore device from the archive
+// @ID          restore-device-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id}/restore [post]
+func (h *Handler) restoreDevice(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.RestoreDevice(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Create Sensor
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description create sensor of device, local_id must be unique within the device
+// @ID          create-sensor
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateSensor true "Sensor info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors [post]
+func (h *Handler) createSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateSensor
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceAquahubList.CreateSensor(userId, input)
+	if err != nil {
+		h.aquahubErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Sensors
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description get all sensors of device, archived sensors only with include-archived
+// @ID          get-all-sensors-of-device
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Param       group_id query int    false "Only items of the group (site, zone or tank) and its nested groups"
+// @Param       tag      query string false "Only items with the tag (tag of hub or device applies to its devices and sensors)"
+// @Param       include-archived query bool false "Include archived items"
+// @Success     200     {object} SensorsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id}/sensors [get]
+func (h *Handler) getAllSensors(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includeArchived, err := parseIncludeArchived(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceAquahubList.GetSensorsOfDevice(userId, id, filter, includeArchived)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SensorsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description get sensor by id
+// @ID          get-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} domain.Sensor
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id} [get]
+func (h *Handler) getSensorById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	item, err := h.serviceAquahubList.GetSensor(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// @Summary     Update Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description update title, description and for_engineer / for_analytics flags of sensor
+// @ID          update-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Param       input   body      domain.UpdateSensor true "Sensor info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id} [put]
+func (h *Handler) updateSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.UpdateSensor
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAquahubList.UpdateSensor(userId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description move sensor to the archive, its readings, alert rules and calibrations are kept.
+// @Description Sensor can be restored within 30 days, after that it is deleted with its readings.
+// @ID          delete-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id} [delete]
+func (h *Handler) deleteSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.DeleteSensor(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Restore Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Sensors
+// @Description restore sensor from the archive
+// @ID          restore-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/restore [post]
+func (h *Handler) restoreSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAquahubList.RestoreSensor(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
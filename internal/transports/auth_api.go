@@ -1,6 +1,7 @@
 package handler_api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/o-sokol-o/hub/internal/domain"
@@ -18,7 +19,7 @@ import (
 // @Accept      json
 // @Produce     json
 // @Param       input   body      domain.User true "account info"
-// @Success     200     {integer} integer     1
+// @Success     200     {object} domain.AuthTokens
 // @Failure     400,404 {object} statusResponse
 // @Failure     500     {object} statusResponse
 // @Failure     default {object} statusResponse
@@ -53,17 +54,15 @@ func (h *Handler) signUp(ctx *gin.Context) {
 		return
 	}
 
-	jwt_token, err := jwt_processing.GenerateToken(id)
+	tokens, err := h.serviceAuthentications.IssueTokens(id)
 	if err != nil {
 		h.log.Println("service failure: something went wrong: " + err.Error())
 		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong") // http.StatusInternalServerError = 500
 		return
 	}
 
-	// Если пользователь существует, то в ответе получаем токен.
-	ctx.JSON(http.StatusOK, map[string]interface{}{
-		"token": "Bearer " + jwt_token,
-	})
+	// Новый пользователь сразу получает токены сеанса.
+	ctx.JSON(http.StatusOK, tokens)
 }
 
 type signInInput struct {
@@ -75,12 +74,12 @@ type signInInput struct {
 
 // @Summary     SignIn
 // @Tags        Authentication
-// @Description login
+// @Description login: returns a short-lived access token and a refresh token
 // @ID          login
 // @Accept      json
 // @Produce     json
 // @Param       input   body     signInInput true "credentials"
-// @Success     200     {string} string      "token"
+// @Success     200     {object} domain.AuthTokens
 // @Failure     400,404 {object} statusResponse
 // @Failure     500     {object} statusResponse
 // @Failure     default {object} statusResponse
@@ -95,7 +94,6 @@ func (h *Handler) signIn(ctx *gin.Context) {
 		return
 	}
 
-	var jwt_token string
 	user, err := h.serviceAuthentications.Authenticate(input.Email, input.Password)
 	if err != nil {
 		// Возвращаем ошибку SQL из БД
@@ -104,15 +102,169 @@ func (h *Handler) signIn(ctx *gin.Context) {
 		return
 	}
 
-	jwt_token, err = jwt_processing.GenerateToken(user.ID)
+	tokens, err := h.serviceAuthentications.IssueTokens(user.ID)
 	if err != nil {
 		h.log.Println("service failure: something went wrong: " + err.Error())
 		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong") // http.StatusInternalServerError = 500
 		return
 	}
 
-	// Если пользователь существует, то в ответе получаем токен.
-	ctx.JSON(http.StatusOK, map[string]interface{}{
-		"token": "Bearer " + jwt_token,
-	})
+	// Если пользователь существует, то в ответе получаем токены сеанса.
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Обработчики сброса пароля
+
+// @Summary     Forgot Password
+// @Tags        Authentication
+// @Description request a password reset: a single-use token valid for one hour is sent to the email.
+// @Description The response is the same whether the email is registered or not.
+// @ID          forgot-password
+// @Accept      json
+// @Produce     json
+// @Param       input   body     domain.ForgotPassword true "email"
+// @Success     200     {object} statusResponse
+// @Failure     400     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /auth/forgot-password [post]
+func (h *Handler) forgotPassword(ctx *gin.Context) {
+
+	var input domain.ForgotPassword
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body") // http.StatusBadRequest = 400
+		return
+	}
+
+	h.serviceAuthentications.ForgotPassword(input.Email)
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Reset Password
+// @Tags        Authentication
+// @Description set a new password with the token from the password reset email; all sessions of the user end
+// @ID          reset-password
+// @Accept      json
+// @Produce     json
+// @Param       input   body     domain.ResetPassword true "reset token and new password"
+// @Success     200     {object} statusResponse
+// @Failure     400     {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /auth/reset-password [post]
+func (h *Handler) resetPassword(ctx *gin.Context) {
+
+	var input domain.ResetPassword
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body") // http.StatusBadRequest = 400
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAuthentications.ResetPassword(input); err != nil {
+		if errors.Is(err, domain.ErrInvalidResetToken) {
+			h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Println("service failure: something went wrong: " + err.Error())
+		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong") // http.StatusInternalServerError = 500
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// Обработчики сеанса
+
+// @Summary     Refresh Session
+// @Tags        Authentication
+// @Description exchange the refresh token for a new access token and refresh token. Each refresh token works once:
+// @Description presenting an already exchanged token ends the whole session.
+// @ID          refresh-session
+// @Accept      json
+// @Produce     json
+// @Param       input   body     domain.RefreshSession true "refresh token"
+// @Success     200     {object} domain.AuthTokens
+// @Failure     400,401 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /auth/refresh [post]
+func (h *Handler) refreshSession(ctx *gin.Context) {
+
+	var input domain.RefreshSession
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body") // http.StatusBadRequest = 400
+		return
+	}
+
+	tokens, err := h.serviceAuthentications.Refresh(input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			h.newErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+		h.log.Println("service failure: something went wrong: " + err.Error())
+		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong") // http.StatusInternalServerError = 500
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Summary     Logout
+// @Security    ApiKeyAuth
+// @Tags        Authentication
+// @Description revoke the access token and end the session; with all = true end every session of the user
+// @ID          logout
+// @Accept      json
+// @Produce     json
+// @Param       input   body     domain.Logout false "end all sessions"
+// @Success     200     {object} statusResponse
+// @Failure     400,401 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /auth/logout [post]
+func (h *Handler) logout(ctx *gin.Context) {
+
+	access, err := getAccessFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Тело запроса необязательно
+	var input domain.Logout
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.BindJSON(&input); err != nil {
+			h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body") // http.StatusBadRequest = 400
+			return
+		}
+	}
+
+	if err := h.serviceAuthentications.Logout(access, input.All); err != nil {
+		h.log.Println("service failure: something went wrong: " + err.Error())
+		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong") // http.StatusInternalServerError = 500
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// Открытые ключи подписи токенов для других сервисов
+
+// @Summary     JSON Web Key Set
+// @Tags        Authentication
+// @Description public keys (RS256, EdDSA) to verify tokens of the hub by the kid header. Includes keys that start
+// @Description signing later and previous keys within the rotation overlap. HS256 secrets are not published.
+// @ID          jwks
+// @Produce     json
+// @Success     200 {object} jwt_processing.JWKS
+// @Router      /.well-known/jwks.json [get]
+func (h *Handler) getJWKS(ctx *gin.Context) {
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwt_processing.PublicKeys())
 }
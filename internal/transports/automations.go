@@ -0,0 +1,296 @@
+package handler_api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type AutomationsResponse struct {
+	Data []domain.Automation `json:"data"`
+}
+
+type AutomationRunsResponse struct {
+	Data []domain.AutomationRun `json:"data"`
+}
+
+// @Summary     Create Automation
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description create automation rule of the account: conditions on sensors (op: >, >=, <, <=, ==, !=; for_sec - how long it must hold)
+// @Description and/or schedule ("HH:MM" in account timezone, weekdays 0 - Sunday .. 6) run actions:
+// @Description device_command, notify, checklist_item. Rule without schedule runs when all conditions start to hold.
+// @Description cooldown_sec and max_runs_per_hour protect from loops; dry_run - log runs without executing actions
+// @ID          create-automation
+// @Accept      json
+// @Produce     json
+// @Param       input body domain.SetAutomation true "Automation rule"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations [post]
+func (h *Handler) createAutomation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.SetAutomation
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceAutomation.Create(userId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Automations
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description get automation rules of the user accounts
+// @ID          get-all-automations
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} AutomationsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations [get]
+func (h *Handler) getAllAutomations(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	list, err := h.serviceAutomation.GetAll(userId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AutomationsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Automation By Id
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description get automation rule by id
+// @ID          get-automation-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Automation ID"
+// @Success     200     {object} domain.Automation
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations/{id} [get]
+func (h *Handler) getAutomationById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	a, err := h.serviceAutomation.GetById(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, a)
+}
+
+// @Summary     Update Automation By Id
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description replace automation rule; state of conditions starts over
+// @ID          update-automation-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                  true "Automation ID"
+// @Param       input body domain.SetAutomation true "Automation rule"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations/{id} [put]
+func (h *Handler) updateAutomation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetAutomation
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAutomation.Update(userId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Automation By Id
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description delete automation rule and its run log; queued commands stay in the queue
+// @ID          delete-automation-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Automation ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations/{id} [delete]
+func (h *Handler) deleteAutomation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceAutomation.Delete(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Automation Runs
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description get run log of automation rule: trigger, status (ok, dry_run, skipped, failed) and action results,
+// @Description newest first (default - last 24 hours)
+// @ID          get-automation-runs
+// @Accept      json
+// @Produce     json
+// @Param       id   path  int    true  "Automation ID"
+// @Param       from query string false "Period start, RFC3339"
+// @Param       to   query string false "Period end, RFC3339"
+// @Success     200     {object} AutomationRunsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations/{id}/runs [get]
+func (h *Handler) getAutomationRuns(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceAutomation.GetRuns(userId, id, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AutomationRunsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Test Automation
+// @Security    ApiKeyAuth
+// @Tags        Automations
+// @Description evaluate automation rule now and show what it would do without executing actions;
+// @Description conditions without state are checked against the latest sensor values. The test is written to the run log
+// @ID          test-automation
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Automation ID"
+// @Success     200     {object} domain.AutomationTestResult
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/automations/{id}/test [post]
+func (h *Handler) testAutomation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	res, err := h.serviceAutomation.Test(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
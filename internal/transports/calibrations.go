@@ -0,0 +1,189 @@
+package handler_api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type CalibrationsResponse struct {
+	Data []domain.SensorCalibration `json:"data"`
+}
+
+// @Summary     Create Sensor Calibration
+// @Security    ApiKeyAuth
+// @Tags        Calibrations
+// @Description add calibration record (offset/scale or multi-point table, not both) valid from the given time
+// @ID          create-calibration
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                            true "Sensor ID"
+// @Param       input body domain.CreateSensorCalibration true "Calibration info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/calibrations [post]
+func (h *Handler) createCalibration(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CreateSensorCalibration
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceCalibration.Create(userId, sensorId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get Sensor Calibrations
+// @Security    ApiKeyAuth
+// @Tags        Calibrations
+// @Description get calibration history of sensor ordered by valid_from
+// @ID          get-all-calibrations
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} CalibrationsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/calibrations [get]
+func (h *Handler) getAllCalibrations(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceCalibration.GetAll(userId, sensorId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CalibrationsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Delete Sensor Calibration
+// @Security    ApiKeyAuth
+// @Tags        Calibrations
+// @Description delete calibration record; stored readings are not changed until recompute
+// @ID          delete-calibration
+// @Accept      json
+// @Produce     json
+// @Param       id     path int true "Sensor ID"
+// @Param       cal_id path int true "Calibration ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/calibrations/{cal_id} [delete]
+func (h *Handler) deleteCalibration(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("cal_id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid cal_id param")
+		return
+	}
+
+	if err := h.serviceCalibration.Delete(userId, sensorId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Recompute Sensor Readings
+// @Security    ApiKeyAuth
+// @Tags        Calibrations
+// @Description recompute stored readings of sensor from the given time using raw values and calibration history.
+// @Description Readings of virtual sensors computed from the sensor and anomaly flags of the sensor are not recomputed:
+// @Description the response lists the virtual sensors depending on the sensor and whether the sensor has detectors.
+// @ID          recompute-calibration
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                         true "Sensor ID"
+// @Param       input body domain.RecomputeCalibration true "Recompute from"
+// @Success     200     {object} domain.RecomputeResult
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/calibrations/recompute [post]
+func (h *Handler) recomputeCalibration(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.RecomputeCalibration
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	result, err := h.serviceCalibration.Recompute(userId, sensorId, input.From)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
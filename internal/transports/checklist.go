@@ -61,10 +61,11 @@ func (h *Handler) createList(ctx *gin.Context) {
 // @Summary     Get All Checklists
 // @Security    ApiKeyAuth
 // @Tags        Checklists
-// @Description get all lists
+// @Description get all lists, archived lists only with include-archived
 // @ID          get-all-lists
 // @Accept      json
 // @Produce     json
+// @Param       include-archived query bool false "Include archived lists"
 // @Success     200     {object} ChecklistsResponse
 // @Failure     400,404 {object} statusResponse
 // @Failure     500     {object} statusResponse
@@ -78,7 +79,13 @@ func (h *Handler) getAllLists(ctx *gin.Context) {
 		return
 	}
 
-	lists, err := h.serviceChecklist.GetAllChecklistOfUser(userId)
+	includeArchived, err := parseIncludeArchived(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lists, err := h.serviceChecklist.GetAllChecklistOfUser(userId, includeArchived)
 	if err != nil {
 		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
 		return
@@ -177,7 +184,7 @@ func (h *Handler) updateListById(ctx *gin.Context) {
 // @Summary     Delete Checklist By Id
 // @Security    ApiKeyAuth
 // @Tags        Checklists
-// @Description get delete by id
+// @Description move list to the archive, the list can be restored within 30 days
 // @ID          get-delete-by-id
 // @Accept      json
 // @Produce     json
@@ -211,3 +218,40 @@ func (h *Handler) deleteListById(ctx *gin.Context) {
 		Status: "ok",
 	})
 }
+
+// @Summary     Restore Checklist By Id
+// @Security    ApiKeyAuth
+// @Tags        Checklists
+// @Description restore list from the archive
+// @ID          restore-list-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int     true  "Checklist ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/lists/{id}/restore [post]
+func (h *Handler) restoreListById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceChecklist.Restore(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -53,7 +53,13 @@ func (h *Handler) getAllItems(ctx *gin.Context) {
 		return
 	}
 
-	items, err := h.serviceChecklistItem.GetAll(userId, listId)
+	includeArchived, err := parseIncludeArchived(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := h.serviceChecklistItem.GetAll(userId, listId, includeArchived)
 	if err != nil {
 		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
 		return
@@ -161,3 +167,31 @@ func (h *Handler) deleteItem(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, statusResponse{"ok"})
 }
+
+func (h *Handler) restoreItem(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	listId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || listId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	itemId, err := strconv.Atoi(ctx.Param("item_id"))
+	if err != nil || itemId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	if err := h.serviceChecklistItem.Restore(userId, listId, itemId); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,193 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type ChecklistInstancesResponse struct {
+	Data []domain.ChecklistInstance `json:"data"`
+}
+
+// Неверное правило повторения - ошибка клиента
+func (h *Handler) recurrenceErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidRecurrence) {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary     Get Checklist Recurrence
+// @Security    ApiKeyAuth
+// @Tags        Checklists
+// @Description get recurrence rule of checklist template and start of its next period
+// @ID          get-checklist-recurrence
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Checklist ID"
+// @Success     200     {object} domain.ChecklistRecurrence
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/lists/{id}/recurrence [get]
+func (h *Handler) getChecklistRecurrence(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	rec, err := h.serviceRecurrence.Get(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rec)
+}
+
+// @Summary     Set Checklist Recurrence
+// @Security    ApiKeyAuth
+// @Tags        Checklists
+// @Description make checklist a template: at the start of every period a new checklist with unchecked items
+// @Description of the template is created. Rule is an RRULE subset: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL,
+// @Description BYDAY (weekly), BYMONTHDAY (monthly, -1 - last day), UNTIL. Time of day of start (default - now)
+// @Description sets the time instances are created in the account timezone
+// @ID          set-checklist-recurrence
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                           true "Checklist ID"
+// @Param       input body domain.SetChecklistRecurrence true "Recurrence"
+// @Success     200     {object} domain.ChecklistRecurrence
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/lists/{id}/recurrence [put]
+func (h *Handler) setChecklistRecurrence(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetChecklistRecurrence
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rec, err := h.serviceRecurrence.Set(userId, id, input)
+	if err != nil {
+		h.recurrenceErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rec)
+}
+
+// @Summary     Delete Checklist Recurrence
+// @Security    ApiKeyAuth
+// @Tags        Checklists
+// @Description stop recurrence of checklist template; created instances are kept
+// @ID          delete-checklist-recurrence
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Checklist ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/lists/{id}/recurrence [delete]
+func (h *Handler) deleteChecklistRecurrence(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceRecurrence.Delete(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Checklist Instances
+// @Security    ApiKeyAuth
+// @Tags        Checklists
+// @Description get checklists created from the template with completion of their items,
+// @Description by period start, newest first (default - periods started in the last 24 hours)
+// @ID          get-checklist-instances
+// @Accept      json
+// @Produce     json
+// @Param       id   path  int    true  "Checklist template ID"
+// @Param       from query string false "Period start, RFC3339"
+// @Param       to   query string false "Period end, RFC3339"
+// @Success     200     {object} ChecklistInstancesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/lists/{id}/instances [get]
+func (h *Handler) getChecklistInstances(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceRecurrence.GetInstances(userId, id, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ChecklistInstancesResponse{
+		Data: list,
+	})
+}
@@ -0,0 +1,190 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type DeviceCommandsResponse struct {
+	Data []domain.DeviceCommand `json:"data"`
+}
+
+// @Summary     Create Device Command
+// @Security    ApiKeyAuth
+// @Tags        Device Commands
+// @Description queue command to device of the user accounts; the hub receives it from /v1/commands
+// @Description until acknowledged or expired (1 hour)
+// @ID          create-device-command
+// @Accept      json
+// @Produce     json
+// @Param       input body domain.CreateDeviceCommand true "Command"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-commands [post]
+func (h *Handler) createDeviceCommand(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateDeviceCommand
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceCommand.Create(userId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get Device Commands
+// @Security    ApiKeyAuth
+// @Tags        Device Commands
+// @Description get commands to devices of the user accounts, newest first (default - last 24 hours)
+// @ID          get-device-commands
+// @Accept      json
+// @Produce     json
+// @Param       device_id     query int    false "Only commands of the device"
+// @Param       automation_id query int    false "Only commands of the automation rule"
+// @Param       status        query string false "Command status" Enums(pending, delivered, acked, expired)
+// @Param       from          query string false "Period start, RFC3339"
+// @Param       to            query string false "Period end, RFC3339"
+// @Success     200     {object} DeviceCommandsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-commands [get]
+func (h *Handler) getDeviceCommands(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var filter domain.DeviceCommandsFilter
+	if filter.DeviceID, err = queryId(ctx, "device_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid device_id param")
+		return
+	}
+	if filter.AutomationID, err = queryId(ctx, "automation_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid automation_id param")
+		return
+	}
+
+	filter.Status = ctx.Query("status")
+	switch filter.Status {
+	case "", domain.CommandPending, domain.CommandDelivered, domain.CommandAcked, domain.CommandExpired:
+	default:
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid status param")
+		return
+	}
+
+	if filter.From, filter.To, err = parsePeriod(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceCommand.GetAll(userId, filter)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeviceCommandsResponse{
+		Data: list,
+	})
+}
+
+// Неверные токены хаба - 401
+func (h *Handler) hubCommandErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidHubToken) {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary     Get Hub Commands
+// @Tags        AquaHub
+// @Description commands for devices of the hub; a command is delivered again until acknowledged or expired.
+// @Description Authorization by hub tokens "h_token:u_token" (Authorization: Token, Basic auth or u/p params)
+// @ID          hub-commands
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} domain.HubCommandsResponse
+// @Failure     400,401 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /v1/commands [get]
+func (h *Handler) hubCommands(ctx *gin.Context) {
+
+	h_token, u_token, ok := hubCredentials(ctx)
+	if !ok {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "missing hub token")
+		return
+	}
+
+	list, err := h.serviceCommand.Poll(h_token, u_token)
+	if err != nil {
+		h.hubCommandErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.HubCommandsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Acknowledge Hub Command
+// @Tags        AquaHub
+// @Description confirm that the command was executed by the device; authorization by hub tokens as in /v1/commands
+// @ID          hub-command-ack
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Command ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,401 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /v1/commands/{id}/ack [post]
+func (h *Handler) ackHubCommand(ctx *gin.Context) {
+
+	h_token, u_token, ok := hubCredentials(ctx)
+	if !ok {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "missing hub token")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceCommand.Ack(h_token, u_token, id); err != nil {
+		h.hubCommandErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,183 @@
+package handler_api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type CoverageDailyResponse struct {
+	Data []domain.CoverageDay `json:"data"`
+}
+
+// Уровень и ID отчёта из параметров level/id
+func parseCoverageScope(ctx *gin.Context) (level string, id int, ok bool) {
+	level = ctx.DefaultQuery("level", domain.CoverageLevel_Sensor)
+	if !domain.ValidCoverageLevel(level) {
+		return "", 0, false
+	}
+
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil || id == 0 {
+		return "", 0, false
+	}
+
+	return level, id, true
+}
+
+// @Summary     Set Sensor Report Interval
+// @Security    ApiKeyAuth
+// @Tags        Coverage
+// @Description set expected reporting interval of sensor; null - learn from data
+// @ID          set-report-interval
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                      true "Sensor ID"
+// @Param       input body domain.SetReportInterval true "Report interval"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/report-interval [put]
+func (h *Handler) setReportInterval(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetReportInterval
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceCoverage.SetReportInterval(userId, sensorId, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Get Data Coverage
+// @Security    ApiKeyAuth
+// @Tags        Coverage
+// @Description compute expected interval, missing intervals and coverage percentage in period [from, to)
+// @Description per sensor, rolled up to the sensor, device, aquahub or account
+// @ID          get-coverage
+// @Accept      json
+// @Produce     json
+// @Param       level query string true  "Report level" Enums(sensor, device, aquahub, account)
+// @Param       id    query int    true  "Sensor, device, aquahub or account ID"
+// @Param       from  query string false "Period start, RFC3339 (default: 24 hours before to)"
+// @Param       to    query string false "Period end, RFC3339 (default: now)"
+// @Param       group_id query int    false "Only sensors of hubs in the site, zone or tank (with nested groups)"
+// @Param       tag      query string false "Only sensors tagged directly or through their device or aquahub"
+// @Success     200     {object} domain.CoverageReport
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/coverage [get]
+func (h *Handler) getCoverage(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	level, id, ok := parseCoverageScope(ctx)
+	if !ok {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid level/id param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.serviceCoverage.Report(userId, level, id, filter, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// @Summary     Get Daily Data Coverage
+// @Security    ApiKeyAuth
+// @Tags        Coverage
+// @Description get daily coverage computed by the scheduled job, rolled up to the sensor, device, aquahub or account
+// @ID          get-daily-coverage
+// @Accept      json
+// @Produce     json
+// @Param       level query string true  "Report level" Enums(sensor, device, aquahub, account)
+// @Param       id    query int    true  "Sensor, device, aquahub or account ID"
+// @Param       from  query string false "Period start, RFC3339 (default: 24 hours before to)"
+// @Param       to    query string false "Period end, RFC3339 (default: now)"
+// @Param       group_id query int    false "Only sensors of hubs in the site, zone or tank (with nested groups)"
+// @Param       tag      query string false "Only sensors tagged directly or through their device or aquahub"
+// @Success     200     {object} CoverageDailyResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/coverage/daily [get]
+func (h *Handler) getDailyCoverage(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	level, id, ok := parseCoverageScope(ctx)
+	if !ok {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid level/id param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	filter, err := parseGroupFilter(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.serviceCoverage.Daily(userId, level, id, filter, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CoverageDailyResponse{
+		Data: list,
+	})
+}
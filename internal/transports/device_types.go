@@ -0,0 +1,267 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type DeviceTypesResponse struct {
+	Data []domain.DeviceType `json:"data"`
+}
+
+// Изменение общего каталога - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) deviceTypeErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrDeviceTypeReadOnly), errors.Is(err, domain.ErrUnknownSensorDataType):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary     Create Device Type
+// @Security    ApiKeyAuth
+// @Tags        DeviceTypes
+// @Description create device type template of the account: when a hub of the account reports the model in /v1/device/add,
+// @Description the device and its sensors are created with titles, units, data types and default alert rules.
+// @Description Template of the account overrides the common catalog type of the same model.
+// @ID          create-device-type
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateDeviceType true "Device type with sensor layout"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-types [post]
+func (h *Handler) createDeviceType(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateDeviceType
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceDeviceType.Create(userId, input)
+	if err != nil {
+		h.deviceTypeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Device Types
+// @Security    ApiKeyAuth
+// @Tags        DeviceTypes
+// @Description get device types of the common catalog (without account_id) and templates of the user accounts
+// @ID          get-all-device-types
+// @Accept      json
+// @Produce     json
+// @Param       account_id query int false "Only templates of the account"
+// @Success     200     {object} DeviceTypesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-types [get]
+func (h *Handler) getAllDeviceTypes(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := queryId(ctx, "account_id")
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid account_id param")
+		return
+	}
+
+	list, err := h.serviceDeviceType.GetAll(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeviceTypesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Device Type By Id
+// @Security    ApiKeyAuth
+// @Tags        DeviceTypes
+// @Description get device type with sensor layout by id
+// @ID          get-device-type-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device type ID"
+// @Success     200     {object} domain.DeviceType
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-types/{id} [get]
+func (h *Handler) getDeviceTypeById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	t, err := h.serviceDeviceType.GetById(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, t)
+}
+
+// @Summary     Update Device Type By Id
+// @Security    ApiKeyAuth
+// @Tags        DeviceTypes
+// @Description replace device type template of the account with its sensor layout; types of the common catalog are read-only.
+// @Description Devices already described by the type are not changed.
+// @ID          update-device-type-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                    true "Device type ID"
+// @Param       input body domain.DeviceTypeInput true "Device type with sensor layout"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-types/{id} [put]
+func (h *Handler) updateDeviceType(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.DeviceTypeInput
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceDeviceType.Update(userId, id, input); err != nil {
+		h.deviceTypeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Device Type By Id
+// @Security    ApiKeyAuth
+// @Tags        DeviceTypes
+// @Description delete device type template of the account; devices of the type keep their descriptions
+// @ID          delete-device-type-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device type ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/device-types/{id} [delete]
+func (h *Handler) deleteDeviceType(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceDeviceType.Delete(userId, id); err != nil {
+		h.deviceTypeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Set Device Type
+// @Security    ApiKeyAuth
+// @Tags        Devices
+// @Description describe the device by device type of the common catalog or template of the device account:
+// @Description device title, sensors of the layout (missing sensors are created) and default alert rules of sensors without rules
+// @ID          set-device-type
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                  true "Device ID"
+// @Param       input body domain.SetDeviceType true "Device type"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id}/type [put]
+func (h *Handler) setDeviceType(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetDeviceType
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	if err := h.serviceDeviceType.SetDeviceType(userId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,222 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type DigestReportsResponse struct {
+	Data []domain.DigestReport `json:"data"`
+}
+
+// @Summary     Get Digest Subscription
+// @Security    ApiKeyAuth
+// @Tags        Digests
+// @Description get digest subscription of the user for the account; reports are built in the user timezone
+// @ID          get-digest-subscription
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} domain.DigestSubscription
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/digest [get]
+func (h *Handler) getDigestSubscription(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	sub, err := h.serviceDigest.GetSubscription(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sub)
+}
+
+// @Summary     Set Digest Subscription
+// @Security    ApiKeyAuth
+// @Tags        Digests
+// @Description subscribe the user to daily (previous day) and weekly (previous Monday-Sunday) digests of the account:
+// @Description min/avg/max of key sensors per hub, offline periods, alerts and overdue checklist items.
+// @Description Digests are sent at hour (0-23, default 8) in the user timezone through notification channels
+// @ID          set-digest-subscription
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                          true "Account ID"
+// @Param       input body domain.SetDigestSubscription true "Subscription"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/digest [put]
+func (h *Handler) setDigestSubscription(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetDigestSubscription
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceDigest.SetSubscription(userId, accountId, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Digest Subscription
+// @Security    ApiKeyAuth
+// @Tags        Digests
+// @Description unsubscribe the user from digests of the account; archived reports are kept
+// @ID          delete-digest-subscription
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/digest [delete]
+func (h *Handler) deleteDigestSubscription(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceDigest.DeleteSubscription(userId, accountId); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Digest Reports
+// @Security    ApiKeyAuth
+// @Tags        Digests
+// @Description get archived digests of the user by period start, newest first (default - last 24 hours)
+// @ID          get-digest-reports
+// @Accept      json
+// @Produce     json
+// @Param       account_id query int    false "Account ID"
+// @Param       kind       query string false "Digest kind" Enums(daily, weekly)
+// @Param       from       query string false "Period start, RFC3339"
+// @Param       to         query string false "Period end, RFC3339"
+// @Success     200     {object} DigestReportsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/digests [get]
+func (h *Handler) getDigestReports(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var filter domain.DigestReportsFilter
+	if filter.AccountID, err = queryId(ctx, "account_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid account_id param")
+		return
+	}
+	filter.Kind = ctx.Query("kind")
+
+	if filter.From, filter.To, err = parsePeriod(ctx); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceDigest.GetReports(userId, filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownDigestKind) {
+			h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DigestReportsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Digest Report By Id
+// @Security    ApiKeyAuth
+// @Tags        Digests
+// @Description get archived digest of the user
+// @ID          get-digest-report-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Report ID"
+// @Success     200     {object} domain.DigestReport
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/digests/{id} [get]
+func (h *Handler) getDigestReportById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	rep, err := h.serviceDigest.GetReport(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rep)
+}
@@ -0,0 +1,336 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type CountriesResponse struct {
+	Data []domain.Country `json:"data"`
+}
+
+type TimezonesResponse struct {
+	Data []string `json:"data" example:"Europe/Kiev"`
+}
+
+type GeoPlacesResponse struct {
+	Data []domain.GeoPlace `json:"data"`
+}
+
+// Неизвестный индекс или часовой пояс - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) geoErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrLocationNotFound), errors.Is(err, domain.ErrInvalidTimezone):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary     Get Countries
+// @Security    ApiKeyAuth
+// @Tags        Geo
+// @Description get countries of the geonames directory with postal code format
+// @ID          get-countries
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} CountriesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/geo/countries [get]
+func (h *Handler) getCountries(ctx *gin.Context) {
+
+	list, err := h.serviceGeo.GetCountries()
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, CountriesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Country Timezones
+// @Security    ApiKeyAuth
+// @Tags        Geo
+// @Description get timezones of the country
+// @ID          get-country-timezones
+// @Accept      json
+// @Produce     json
+// @Param       code path string true "Country code, ISO 3166-1 alpha-2"
+// @Success     200     {object} TimezonesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/geo/countries/{code}/timezones [get]
+func (h *Handler) getCountryTimezones(ctx *gin.Context) {
+
+	code := ctx.Param("code")
+	if len(code) != 2 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid code param")
+		return
+	}
+
+	list, err := h.serviceGeo.GetTimezones(code)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, TimezonesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Find Places
+// @Security    ApiKeyAuth
+// @Tags        Geo
+// @Description autocomplete of places of the country by the beginning of postal code or place name (at least 2 characters)
+// @ID          find-places
+// @Accept      json
+// @Produce     json
+// @Param       country query string true  "Country code, ISO 3166-1 alpha-2"
+// @Param       q       query string true  "Beginning of postal code or place name"
+// @Param       limit   query int    false "Max places (default 10, max 50)"
+// @Success     200     {object} GeoPlacesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/geo/places [get]
+func (h *Handler) findPlaces(ctx *gin.Context) {
+
+	country := ctx.Query("country")
+	if len(country) != 2 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid country param")
+		return
+	}
+
+	limit, err := queryId(ctx, "limit")
+	if err != nil || limit < 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid limit param")
+		return
+	}
+
+	list, err := h.serviceGeo.FindPlaces(country, ctx.Query("q"), limit)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GeoPlacesResponse{
+		Data: list,
+	})
+}
+
+//-------------------------------------------------------------------------
+
+// @Summary     Get Aquahub Location
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description get location of aquahub; aquahub without location has the account timezone
+// @ID          get-aquahub-location
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} domain.Location
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/location [get]
+func (h *Handler) getAquahubLocation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	loc, err := h.serviceGeo.GetAquahubLocation(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loc)
+}
+
+// @Summary     Set Aquahub Location
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description set location of aquahub by country and postal code (place_name selects one of places of the postal code).
+// @Description Without timezone the hub keeps its timezone if it belongs to the country, otherwise gets the country timezone.
+// @Description Digests show hub readings and offline periods in the hub local time.
+// @ID          set-aquahub-location
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                true "Aquahub ID"
+// @Param       input body domain.SetLocation true "Country and postal code"
+// @Success     200     {object} domain.Location
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/location [put]
+func (h *Handler) setAquahubLocation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetLocation
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, err := h.serviceGeo.SetAquahubLocation(userId, id, input)
+	if err != nil {
+		h.geoErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loc)
+}
+
+// @Summary     Delete Aquahub Location
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description clear location of aquahub, the hub gets the account timezone
+// @ID          delete-aquahub-location
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/location [delete]
+func (h *Handler) deleteAquahubLocation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceGeo.DeleteAquahubLocation(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Get Account Location
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description get location and timezone of the account
+// @ID          get-account-location
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} domain.Location
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/location [get]
+func (h *Handler) getAccountLocation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	loc, err := h.serviceGeo.GetAccountLocation(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loc)
+}
+
+// @Summary     Set Account Location
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description set country, postal code, city and region of the account by geonames.
+// @Description The account timezone (schedules of checklists and automations, hubs without location) is kept
+// @Description if it belongs to the country, otherwise the country timezone is set.
+// @ID          set-account-location
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                true "Account ID"
+// @Param       input body domain.SetLocation true "Country and postal code"
+// @Success     200     {object} domain.Location
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/location [put]
+func (h *Handler) setAccountLocation(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetLocation
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loc, err := h.serviceGeo.SetAccountLocation(userId, accountId, input)
+	if err != nil {
+		h.geoErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loc)
+}
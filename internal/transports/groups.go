@@ -0,0 +1,504 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type HubGroupsResponse struct {
+	Data []domain.HubGroup `json:"data"`
+}
+
+type TagsResponse struct {
+	Data []string `json:"data" example:"reef"`
+}
+
+type TagCountsResponse struct {
+	Data []domain.TagCount `json:"data"`
+}
+
+// Фильтр по группе и тегу из параметров group_id и tag
+func parseGroupFilter(ctx *gin.Context) (domain.GroupFilter, error) {
+	var filter domain.GroupFilter
+	var err error
+
+	if filter.GroupID, err = queryId(ctx, "group_id"); err != nil {
+		return filter, errors.New("invalid group_id param")
+	}
+	filter.Tag = strings.ToLower(strings.TrimSpace(ctx.Query("tag")))
+	if len(filter.Tag) > domain.MaxTagLen {
+		return filter, errors.New("invalid tag param")
+	}
+
+	return filter, nil
+}
+
+// Ошибки структуры групп и тегов - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) groupErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidGroupTree), errors.Is(err, domain.ErrGroupNotEmpty),
+		errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrTooManyTags):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary     Create Group
+// @Security    ApiKeyAuth
+// @Tags        Groups
+// @Description create site, zone or tank: zone belongs to a site, tank - to a site or zone
+// @ID          create-group
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateHubGroup true "Group info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/groups [post]
+func (h *Handler) createGroup(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateHubGroup
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceGroup.Create(userId, input)
+	if err != nil {
+		h.groupErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Groups
+// @Security    ApiKeyAuth
+// @Tags        Groups
+// @Description get sites, zones and tanks of the user accounts
+// @ID          get-all-groups
+// @Accept      json
+// @Produce     json
+// @Param       account_id query int    false "Only groups of the account"
+// @Param       kind       query string false "Only groups of the kind" Enums(site, zone, tank)
+// @Success     200     {object} HubGroupsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/groups [get]
+func (h *Handler) getAllGroups(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := queryId(ctx, "account_id")
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid account_id param")
+		return
+	}
+
+	kind := ctx.Query("kind")
+	switch kind {
+	case "", domain.GroupSite, domain.GroupZone, domain.GroupTank:
+	default:
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid kind param")
+		return
+	}
+
+	list, err := h.serviceGroup.GetAll(userId, accountId, kind)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, HubGroupsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Group By Id
+// @Security    ApiKeyAuth
+// @Tags        Groups
+// @Description get site, zone or tank by id
+// @ID          get-group-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Group ID"
+// @Success     200     {object} domain.HubGroup
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/groups/{id} [get]
+func (h *Handler) getGroupById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	group, err := h.serviceGroup.GetById(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+// @Summary     Update Group By Id
+// @Security    ApiKeyAuth
+// @Tags        Groups
+// @Description update title or description of group, or move zone or tank to another group of the account
+// @ID          update-group-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Group ID"
+// @Param       input   body      domain.UpdateHubGroup true "Group info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/groups/{id} [put]
+func (h *Handler) updateGroup(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.UpdateHubGroup
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceGroup.Update(userId, id, input); err != nil {
+		h.groupErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Group By Id
+// @Security    ApiKeyAuth
+// @Tags        Groups
+// @Description delete group without nested groups; hubs of the group stay ungrouped
+// @ID          delete-group-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Group ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/groups/{id} [delete]
+func (h *Handler) deleteGroup(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceGroup.Delete(userId, id); err != nil {
+		h.groupErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Set Aquahub Group
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description put aquahub into site, zone or tank of its account; group_id = 0 - remove from group
+// @ID          set-aquahub-group
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Param       input   body      domain.SetAquahubGroup true "Group"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/group [put]
+func (h *Handler) setAquahubGroup(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetAquahubGroup
+	if err := ctx.BindJSON(&input); err != nil || input.GroupID < 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	if err := h.serviceGroup.SetAquahubGroup(userId, id, input); err != nil {
+		h.groupErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+//-------------------------------------------------------------------------
+
+// Обработчики тегов хаба, устройства или сенсора
+func (h *Handler) getTagsOf(entity string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		userId, err := getUserIdFromContext(ctx)
+		if err != nil {
+			h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || id == 0 {
+			h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+			return
+		}
+
+		list, err := h.serviceGroup.GetTags(userId, entity, id)
+		if err != nil {
+			h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+
+		ctx.JSON(http.StatusOK, TagsResponse{
+			Data: list,
+		})
+	}
+}
+
+func (h *Handler) setTagsOf(entity string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		userId, err := getUserIdFromContext(ctx)
+		if err != nil {
+			h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || id == 0 {
+			h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+			return
+		}
+
+		var input domain.SetTags
+		if err := ctx.BindJSON(&input); err != nil {
+			h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+			return
+		}
+
+		list, err := h.serviceGroup.SetTags(userId, entity, id, input)
+		if err != nil {
+			h.groupErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, TagsResponse{
+			Data: list,
+		})
+	}
+}
+
+// @Summary     Get Aquahub Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description get tags of aquahub
+// @ID          get-aquahub-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/tags [get]
+func (h *Handler) getAquahubTags(ctx *gin.Context) {
+	h.getTagsOf(domain.TagAquahub)(ctx)
+}
+
+// @Summary     Set Aquahub Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description replace tags of aquahub; tags are stored in lower case, the tag applies to all devices and sensors of the hub
+// @ID          set-aquahub-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Param       input   body      domain.SetTags true "Tags"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/tags [put]
+func (h *Handler) setAquahubTags(ctx *gin.Context) {
+	h.setTagsOf(domain.TagAquahub)(ctx)
+}
+
+// @Summary     Get Device Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description get tags of device
+// @ID          get-device-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id}/tags [get]
+func (h *Handler) getDeviceTags(ctx *gin.Context) {
+	h.getTagsOf(domain.TagDevice)(ctx)
+}
+
+// @Summary     Set Device Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description replace tags of device; the tag applies to all sensors of the device
+// @ID          set-device-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Device ID"
+// @Param       input   body      domain.SetTags true "Tags"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/devices/{id}/tags [put]
+func (h *Handler) setDeviceTags(ctx *gin.Context) {
+	h.setTagsOf(domain.TagDevice)(ctx)
+}
+
+// @Summary     Get Sensor Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description get tags of sensor
+// @ID          get-sensor-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/tags [get]
+func (h *Handler) getSensorTags(ctx *gin.Context) {
+	h.getTagsOf(domain.TagSensor)(ctx)
+}
+
+// @Summary     Set Sensor Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description replace tags of sensor
+// @ID          set-sensor-tags
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Param       input   body      domain.SetTags true "Tags"
+// @Success     200     {object} TagsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/tags [put]
+func (h *Handler) setSensorTags(ctx *gin.Context) {
+	h.setTagsOf(domain.TagSensor)(ctx)
+}
+
+// @Summary     Get All Tags
+// @Security    ApiKeyAuth
+// @Tags        Tags
+// @Description get tags used in the user accounts with the number of tagged hubs, devices and sensors
+// @ID          get-all-tags
+// @Accept      json
+// @Produce     json
+// @Param       account_id query int false "Only tags of the account"
+// @Success     200     {object} TagCountsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/tags [get]
+func (h *Handler) getAllTags(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := queryId(ctx, "account_id")
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid account_id param")
+		return
+	}
+
+	list, err := h.serviceGroup.GetAllTags(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, TagCountsResponse{
+		Data: list,
+	})
+}
@@ -17,6 +17,27 @@ type Handler struct {
 	serviceChecklist       IServiceChecklist
 	serviceChecklistItem   IServiceChecklistItem
 	serviceAquahubList     IServiceAquahubList
+	serviceVirtualSensor   IServiceVirtualSensor
+	serviceCalibration     IServiceCalibration
+	serviceAnomaly         IServiceAnomaly
+	serviceCoverage        IServiceCoverage
+	serviceMetrics         IServiceMetrics
+	serviceInflux          IServiceInflux
+	serviceAlert           IServiceAlert
+	serviceNotification    IServiceNotification
+	serviceAutomation      IServiceAutomation
+	serviceCommand         IServiceCommand
+	serviceRecurrence      IServiceChecklistRecurrence
+	serviceWebhook         IServiceWebhook
+	serviceDigest          IServiceDigest
+	servicePairing         IServicePairing
+	serviceGroup           IServiceGroup
+	serviceGeo             IServiceGeo
+	serviceTransfer        IServiceTransfer
+	serviceDeviceType      IServiceDeviceType
+	serviceArchive         IServiceArchive
+	serviceAccess          IServiceAccess
+	serviceInvite          IServiceInvite
 
 	Router *gin.Engine
 	cache  domain.Cache
@@ -26,7 +47,13 @@ type Handler struct {
 // Внедрение зависимостей:
 // Обработчики будут обращаться к Сервисам, поэтому в конструкторе ждём интерфейсы к Сервисам
 
-func NewHandler(log *logrus.Logger, cache domain.Cache, a IServiceAuthentications, b IServiceChecklist, c IServiceChecklistItem, d IServiceAquahubList) *Handler {
+func NewHandler(log *logrus.Logger, cache domain.Cache, a IServiceAuthentications, b IServiceChecklist, c IServiceChecklistItem, d IServiceAquahubList,
+	e IServiceVirtualSensor, f IServiceCalibration, g IServiceAnomaly, h IServiceCoverage,
+	i IServiceMetrics, j IServiceInflux, k IServiceAlert, l IServiceNotification,
+	m IServiceAutomation, n IServiceCommand, o IServiceChecklistRecurrence,
+	p IServiceWebhook, q IServiceDigest, r IServicePairing, s IServiceGroup, t IServiceGeo,
+	u IServiceTransfer, v IServiceDeviceType, w IServiceArchive, x IServiceAccess,
+	y IServiceInvite) *Handler {
 	return &Handler{
 		log:                    log,
 		cache:                  cache,
@@ -34,6 +61,27 @@ func NewHandler(log *logrus.Logger, cache domain.Cache, a IServiceAuthentication
 		serviceChecklist:       b,
 		serviceChecklistItem:   c,
 		serviceAquahubList:     d,
+		serviceVirtualSensor:   e,
+		serviceCalibration:     f,
+		serviceAnomaly:         g,
+		serviceCoverage:        h,
+		serviceMetrics:         i,
+		serviceInflux:          j,
+		serviceAlert:           k,
+		serviceNotification:    l,
+		serviceAutomation:      m,
+		serviceCommand:         n,
+		serviceRecurrence:      o,
+		serviceWebhook:         p,
+		serviceDigest:          q,
+		servicePairing:         r,
+		serviceGroup:           s,
+		serviceGeo:             t,
+		serviceTransfer:        u,
+		serviceDeviceType:      v,
+		serviceArchive:         w,
+		serviceAccess:          x,
+		serviceInvite:          y,
 	}
 }
 
@@ -47,35 +95,280 @@ func (h *Handler) InitRoutes() error {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Открытые ключи подписи токенов
+	router.GET("/.well-known/jwks.json", h.getJWKS)
+
 	// Методы авторизации
 	auth := router.Group("/auth") // группа маршрутов "/auth"
 	{
 		auth.POST("/sign-up", h.signUp) // маршрут (end-point) "/auth/sign-up"
 		auth.POST("/sign-in", h.signIn) // маршрут (end-point) "/auth/sign-in"
+		auth.POST("/forgot-password", h.forgotPassword)
+		auth.POST("/reset-password", h.resetPassword)
+		auth.POST("/refresh", h.refreshSession)
+		auth.POST("/logout", h.userIdentity_middleware, h.logout)
+		auth.POST("/invites/accept", h.acceptAccountInvite)
 	}
 
 	// Методы работы со списком и итемами
 	api := router.Group("/api", h.userIdentity_middleware) // Для группы маршрутов "/api" зададим middleware обработчик
 	{
+		// Изменения доступны менеджерам аккаунтов, пользователи с ролью user только просматривают данные
+		manage := h.permission_middleware(domain.PermissionManage)
+		manageAccount := h.accountPermission_middleware(domain.PermissionManage)
+
 		lists := api.Group("/lists") // группа маршрутов "/api/lists"
 		{
-			lists.POST("/", h.createList)          // end-point "/api/lists/"
-			lists.GET("/", h.getAllLists)          // end-point "/api/lists/"
-			lists.GET("/:id", h.getListById)       // end-point "/api/lists/:id", после ":" имя параметра, к которому можно получить доступ ( "id" )
-			lists.PUT("/:id", h.updateListById)    // end-point "/api/lists/:id", есть параметр "id"
-			lists.DELETE("/:id", h.deleteListById) // end-point "/api/lists/:id", есть параметр "id"
+			lists.POST("/", manage, h.createList)          // end-point "/api/lists/"
+			lists.GET("/", h.getAllLists)                  // end-point "/api/lists/"
+			lists.GET("/:id", h.getListById)               // end-point "/api/lists/:id", после ":" имя параметра, к которому можно получить доступ ( "id" )
+			lists.PUT("/:id", manage, h.updateListById)    // end-point "/api/lists/:id", есть параметр "id"
+			lists.DELETE("/:id", manage, h.deleteListById) // end-point "/api/lists/:id", есть параметр "id"
+			lists.POST("/:id/restore", manage, h.restoreListById)
 
 			items := lists.Group(":id/items") // группа маршрутов "/api/lists/:id/items"
 			{
-				items.POST("/", h.createItem) // end-point "/api/lists/:id/items/"
-				items.GET("/", h.getAllItems) // end-point "/api/lists/:id/items/"
+				items.POST("/", manage, h.createItem) // end-point "/api/lists/:id/items/"
+				items.GET("/", h.getAllItems)         // end-point "/api/lists/:id/items/"
 				items.GET("/:item_id", h.getItemById)
-				items.PUT("/:item_id", h.updateItem)
-				items.DELETE("/:item_id", h.deleteItem)
+				items.PUT("/:item_id", manage, h.updateItem)
+				items.DELETE("/:item_id", manage, h.deleteItem)
+				items.POST("/:item_id/restore", manage, h.restoreItem)
+			}
+
+			lists.GET("/:id/recurrence", h.getChecklistRecurrence)
+			lists.PUT("/:id/recurrence", manage, h.setChecklistRecurrence)
+			lists.DELETE("/:id/recurrence", manage, h.deleteChecklistRecurrence)
+			lists.GET("/:id/instances", h.getChecklistInstances)
+		}
+
+		virtual := api.Group("/virtual-sensors") // группа маршрутов "/api/virtual-sensors"
+		{
+			virtual.POST("/", manage, h.createVirtualSensor)
+			virtual.GET("/", h.getAllVirtualSensors)
+			virtual.GET("/:id", h.getVirtualSensorById)
+			virtual.PUT("/:id", manage, h.updateVirtualSensor)
+			virtual.DELETE("/:id", manage, h.deleteVirtualSensor)
+		}
+
+		aquahubs := api.Group("/aquahubs") // группа маршрутов "/api/aquahubs"
+		{
+			aquahubs.POST("/", manage, h.createAquahub)
+			aquahubs.GET("/", h.getAllAquahubs)
+			aquahubs.GET("/:id", h.getAquahubById)
+			aquahubs.PUT("/:id", manage, h.updateAquahub)
+			aquahubs.DELETE("/:id", manage, h.deleteAquahub)
+			aquahubs.POST("/:id/restore", manage, h.restoreAquahub)
+			aquahubs.GET("/:id/devices", h.getAllDevices)
+
+			aquahubs.POST("/claim", manage, h.claimAquahub)
+			aquahubs.POST("/:id/unclaim", manage, h.unclaimAquahub)
+			aquahubs.POST("/:id/factory-reset", manage, h.factoryResetAquahub)
+
+			aquahubs.PUT("/:id/group", manage, h.setAquahubGroup)
+			aquahubs.GET("/:id/tags", h.getAquahubTags)
+			aquahubs.PUT("/:id/tags", manage, h.setAquahubTags)
+
+			aquahubs.GET("/:id/location", h.getAquahubLocation)
+			aquahubs.PUT("/:id/location", manage, h.setAquahubLocation)
+			aquahubs.DELETE("/:id/location", manage, h.deleteAquahubLocation)
+
+			aquahubs.POST("/:id/transfer", manage, h.createHubTransfer)
+			aquahubs.GET("/:id/transfer", h.getHubTransfer)
+			aquahubs.DELETE("/:id/transfer", manage, h.cancelHubTransfer)
+		}
+
+		devices := api.Group("/devices") // группа маршрутов "/api/devices"
+		{
+			devices.POST("/", manage, h.createDevice)
+			devices.GET("/:id", h.getDeviceById)
+			devices.PUT("/:id", manage, h.updateDevice)
+			devices.DELETE("/:id", manage, h.deleteDevice)
+			devices.POST("/:id/restore", manage, h.restoreDevice)
+			devices.GET("/:id/sensors", h.getAllSensors)
+			devices.GET("/:id/tags", h.getDeviceTags)
+			devices.PUT("/:id/tags", manage, h.setDeviceTags)
+			devices.PUT("/:id/type", manage, h.setDeviceType)
+		}
+
+		deviceTypes := api.Group("/device-types") // группа маршрутов "/api/device-types"
+		{
+			deviceTypes.POST("/", manage, h.createDeviceType)
+			deviceTypes.GET("/", h.getAllDeviceTypes)
+			deviceTypes.GET("/:id", h.getDeviceTypeById)
+			deviceTypes.PUT("/:id", manage, h.updateDeviceType)
+			deviceTypes.DELETE("/:id", manage, h.deleteDeviceType)
+		}
+
+		groups := api.Group("/groups") // группа маршрутов "/api/groups"
+		{
+			groups.POST("/", manage, h.createGroup)
+			groups.GET("/", h.getAllGroups)
+			groups.GET("/:id", h.getGroupById)
+			groups.PUT("/:id", manage, h.updateGroup)
+			groups.DELETE("/:id", manage, h.deleteGroup)
+		}
+
+		api.GET("/tags", h.getAllTags)
+
+		transfers := api.Group("/transfers") // группа маршрутов "/api/transfers"
+		{
+			transfers.GET("/", h.getHubTransfers)
+			transfers.POST("/accept", manage, h.acceptHubTransfer)
+		}
+
+		geo := api.Group("/geo") // группа маршрутов "/api/geo"
+		{
+			geo.GET("/countries", h.getCountries)
+			geo.GET("/countries/:code/timezones", h.getCountryTimezones)
+			geo.GET("/places", h.findPlaces)
+		}
+
+		sensors := api.Group("/sensors") // группа маршрутов "/api/sensors"
+		{
+			sensors.POST("/", manage, h.createSensor)
+			sensors.GET("/:id", h.getSensorById)
+			sensors.PUT("/:id", manage, h.updateSensor)
+			sensors.DELETE("/:id", manage, h.deleteSensor)
+			sensors.POST("/:id/restore", manage, h.restoreSensor)
+			sensors.GET("/:id/tags", h.getSensorTags)
+			sensors.PUT("/:id/tags", manage, h.setSensorTags)
+
+			calibrations := sensors.Group(":id/calibrations") // группа маршрутов "/api/sensors/:id/calibrations"
+			{
+				calibrations.POST("/", manage, h.createCalibration)
+				calibrations.GET("/", h.getAllCalibrations)
+				calibrations.DELETE("/:cal_id", manage, h.deleteCalibration)
+				calibrations.POST("/recompute", manage, h.recomputeCalibration)
+			}
+
+			sensors.GET("/:id/detectors", h.getDetectors)
+			sensors.PUT("/:id/detectors", manage, h.setDetectors)
+			sensors.DELETE("/:id/detectors", manage, h.deleteDetectors)
+			sensors.GET("/:id/flags", h.getFlags)
+			sensors.PUT("/:id/report-interval", manage, h.setReportInterval)
+			sensors.PUT("/:id/unit", manage, h.setSensorUnit)
+		}
+
+		alertRules := api.Group("/alert-rules") // группа маршрутов "/api/alert-rules"
+		{
+			alertRules.POST("/", manage, h.createAlertRule)
+			alertRules.GET("/", h.getAllAlertRules)
+			alertRules.GET("/:id", h.getAlertRuleById)
+			alertRules.PUT("/:id", manage, h.updateAlertRule)
+			alertRules.DELETE("/:id", manage, h.deleteAlertRule)
+		}
+
+		api.GET("/alert-events", h.getAlertEvents)
+
+		alerts := api.Group("/alerts") // группа маршрутов "/api/alerts"
+		{
+			alerts.GET("/", h.getAlerts)
+			alerts.GET("/:id", h.getAlertById)
+			alerts.POST("/:id/ack", manage, h.acknowledgeAlert)
+			alerts.POST("/:id/snooze", manage, h.snoozeAlert)
+			alerts.POST("/:id/resolve", manage, h.resolveAlert)
+		}
+
+		notifications := api.Group("/notifications") // группа маршрутов "/api/notifications"
+		{
+			notifications.GET("/preferences", h.getNotificationPreferences)
+			notifications.PUT("/preferences/:channel", h.setNotificationPreference)
+			notifications.DELETE("/preferences/:channel", h.deleteNotificationPreference)
+			notifications.GET("/deliveries", h.getNotificationDeliveries)
+			notifications.POST("/test", h.sendTestNotification)
+		}
+
+		automations := api.Group("/automations") // группа маршрутов "/api/automations"
+		{
+			automations.POST("/", manage, h.createAutomation)
+			automations.GET("/", h.getAllAutomations)
+			automations.GET("/:id", h.getAutomationById)
+			automations.PUT("/:id", manage, h.updateAutomation)
+			automations.DELETE("/:id", manage, h.deleteAutomation)
+			automations.GET("/:id/runs", h.getAutomationRuns)
+			automations.POST("/:id/test", manage, h.testAutomation)
+		}
+
+		commands := api.Group("/device-commands") // группа маршрутов "/api/device-commands"
+		{
+			commands.POST("/", manage, h.createDeviceCommand)
+			commands.GET("/", h.getDeviceCommands)
+		}
+
+		coverage := api.Group("/coverage") // группа маршрутов "/api/coverage"
+		{
+			coverage.GET("/", h.getCoverage)
+			coverage.GET("/daily", h.getDailyCoverage)
+		}
+
+		digests := api.Group("/digests") // группа маршрутов "/api/digests"
+		{
+			digests.GET("/", h.getDigestReports)
+			digests.GET("/:id", h.getDigestReportById)
+		}
+
+		accounts := api.Group("/accounts") // группа маршрутов "/api/accounts"
+		{
+			tokens := accounts.Group(":id/metrics-tokens") // группа маршрутов "/api/accounts/:id/metrics-tokens"
+			{
+				tokens.POST("/", manageAccount, h.createMetricsToken)
+				tokens.GET("/", h.getMetricsTokens)
+				tokens.DELETE("/:token_id", manageAccount, h.revokeMetricsToken)
+			}
+
+			influx := accounts.Group(":id/influx-mappings") // группа маршрутов "/api/accounts/:id/influx-mappings"
+			{
+				influx.POST("/", manageAccount, h.createInfluxMapping)
+				influx.GET("/", h.getInfluxMappings)
+				influx.DELETE("/:mapping_id", manageAccount, h.deleteInfluxMapping)
+			}
+
+			templates := accounts.Group(":id/notification-templates") // группа маршрутов "/api/accounts/:id/notification-templates"
+			{
+				templates.GET("/", h.getNotificationTemplates)
+				templates.PUT("/:event", manageAccount, h.setNotificationTemplate)
+				templates.DELETE("/:event", manageAccount, h.deleteNotificationTemplate)
+			}
+
+			webhooks := accounts.Group(":id/webhooks") // группа маршрутов "/api/accounts/:id/webhooks"
+			{
+				webhooks.POST("/", manageAccount, h.createWebhook)
+				webhooks.GET("/", h.getWebhooks)
+				webhooks.GET("/:webhook_id", h.getWebhookById)
+				webhooks.PUT("/:webhook_id", manageAccount, h.updateWebhook)
+				webhooks.DELETE("/:webhook_id", manageAccount, h.deleteWebhook)
+				webhooks.GET("/:webhook_id/deliveries", h.getWebhookDeliveries)
+				webhooks.POST("/:webhook_id/deliveries/:delivery_id/redeliver", manageAccount, h.redeliverWebhook)
+			}
+
+			accounts.GET(":id/digest", h.getDigestSubscription)
+			accounts.PUT(":id/digest", h.setDigestSubscription)
+			accounts.DELETE(":id/digest", h.deleteDigestSubscription)
+
+			accounts.GET(":id/location", h.getAccountLocation)
+			accounts.PUT(":id/location", manageAccount, h.setAccountLocation)
+
+			members := accounts.Group(":id/members") // группа маршрутов "/api/accounts/:id/members"
+			{
+				members.GET("/", h.getAccountMembers)
+				members.PUT("/:user_id", manageAccount, h.updateAccountMember)
+				members.DELETE("/:user_id", h.removeAccountMember) // участник может выйти из аккаунта сам
+			}
+
+			invites := accounts.Group(":id/invites") // группа маршрутов "/api/accounts/:id/invites"
+			{
+				invites.POST("/", manageAccount, h.createAccountInvite)
+				invites.GET("/", manageAccount, h.getAccountInvites)
+				invites.POST("/:invite_id/resend", manageAccount, h.resendAccountInvite)
+				invites.DELETE("/:invite_id", manageAccount, h.revokeAccountInvite)
 			}
 		}
 	}
 
+	// Метрики аккаунта для Prometheus, авторизация - токеном сбора метрик
+	router.GET("/metrics", h.scrapeToken_middleware, h.getMetrics)
+
 	// Сгруппировать вместе маршруты, связанные с AquaHub API v1
 	apiRoutes := router.Group("/v1", h.checkApiKey_middleware)
 	{
@@ -88,6 +381,16 @@ func (h *Handler) InitRoutes() error {
 		apiRoutes.GET("/sensor/meta", h.api_SensorMeta)
 	}
 
+	// Приём показаний в формате InfluxDB line protocol (совместимо с Telegraf, outputs.influxdb)
+	router.POST("/v1/write", h.influxWrite)
+
+	// Очередь команд устройствам хаба, авторизация - токенами хаба
+	router.GET("/v1/commands", h.hubCommands)
+	router.POST("/v1/commands/:id/ack", h.ackHubCommand)
+
+	// Привязка хаба к аккаунту, авторизация - заводским токеном хаба
+	router.POST("/v1/pairing", h.hubPairing)
+
 	h.Router = router
 	return nil
 }
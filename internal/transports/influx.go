@@ -0,0 +1,258 @@
+package handler_api
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/lineproto"
+)
+
+// Максимальный размер пакета line protocol
+const maxInfluxBodySize = 10 << 20
+
+type InfluxMappingsResponse struct {
+	Data []domain.InfluxMapping `json:"data"`
+}
+
+// Ответ с ошибкой в формате InfluxDB - клиенты (Telegraf) читают поле "error"
+type influxErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) newInfluxErrorResponse(ctx *gin.Context, statusCode int, message string) {
+	h.log.Error(message)
+	ctx.AbortWithStatusJSON(statusCode, influxErrorResponse{message})
+}
+
+// Токены хаба "h_token:u_token" - как api_key в /v1/sensor.
+// Принимаются в хедере "Authorization: Token ..." (или Bearer), в Basic-авторизации
+// (логин - h_token, пароль - u_token) и в параметрах u и p.
+func hubCredentials(ctx *gin.Context) (h_token, u_token string, ok bool) {
+
+	header := ctx.GetHeader(authorizationHeader)
+	for _, scheme := range []string{"Token ", "Bearer "} {
+		if strings.HasPrefix(header, scheme) {
+			return strings.Cut(strings.TrimPrefix(header, scheme), ":")
+		}
+	}
+
+	if user, pass, found := ctx.Request.BasicAuth(); found {
+		return user, pass, user != "" && pass != ""
+	}
+
+	h_token, u_token = ctx.Query("u"), ctx.Query("p")
+	return h_token, u_token, h_token != "" && u_token != ""
+}
+
+func influxBody(ctx *gin.Context) (string, error) {
+
+	var r io.Reader = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxInfluxBodySize)
+
+	if ctx.GetHeader("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		r = io.LimitReader(gz, maxInfluxBodySize)
+	}
+
+	body, err := io.ReadAll(r)
+	return string(body), err
+}
+
+// @Summary     Write InfluxDB Line Protocol
+// @Tags        AquaHub
+// @Description accept points in InfluxDB line protocol and store fields mapped to sensors of the hub;
+// @Description authorization by hub tokens "h_token:u_token" (Authorization: Token, Basic auth or u/p params)
+// @ID          influx-write
+// @Accept      plain
+// @Produce     json
+// @Param       precision query string false "Timestamp precision" Enums(ns, us, ms, s)
+// @Param       db        query string false "Ignored, accepted for compatibility"
+// @Param       input     body  string true  "Line protocol"
+// @Success     204
+// @Failure     400,401 {object} influxErrorResponse
+// @Failure     500     {object} influxErrorResponse
+// @Failure     default {object} influxErrorResponse
+// @Router      /v1/write [post]
+func (h *Handler) influxWrite(ctx *gin.Context) {
+
+	h_token, u_token, ok := hubCredentials(ctx)
+	if !ok {
+		h.newInfluxErrorResponse(ctx, http.StatusUnauthorized, "missing hub token")
+		return
+	}
+
+	precision, err := lineproto.ParsePrecision(ctx.Query("precision"))
+	if err != nil {
+		h.newInfluxErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body, err := influxBody(ctx)
+	if err != nil {
+		h.newInfluxErrorResponse(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	res, err := h.serviceInflux.Write(h_token, u_token, body, precision)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidHubToken) {
+			h.newInfluxErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+		h.newInfluxErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if res.Unmapped > 0 {
+		h.log.Infof("influx write: accepted %d, unmapped %d fields", res.Accepted, res.Unmapped)
+	}
+
+	if len(res.Errors) > 0 {
+		h.newInfluxErrorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("partial write: accepted %d: %s", res.Accepted, strings.Join(res.Errors, "; ")))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary     Create Influx Mapping
+// @Security    ApiKeyAuth
+// @Tags        Influx Mappings
+// @Description map field of measurement with given tags to sensor of the account
+// @ID          create-influx-mapping
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                        true "Account ID"
+// @Param       input body domain.CreateInfluxMapping true "Mapping info"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/influx-mappings [post]
+func (h *Handler) createInfluxMapping(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CreateInfluxMapping
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceInflux.Create(userId, accountId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get Influx Mappings
+// @Security    ApiKeyAuth
+// @Tags        Influx Mappings
+// @Description get line protocol mappings of the account in the order they are applied
+// @ID          get-influx-mappings
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} InfluxMappingsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/influx-mappings [get]
+func (h *Handler) getInfluxMappings(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceInflux.GetAll(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, InfluxMappingsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Delete Influx Mapping
+// @Security    ApiKeyAuth
+// @Tags        Influx Mappings
+// @Description delete line protocol mapping of the account
+// @ID          delete-influx-mapping
+// @Accept      json
+// @Produce     json
+// @Param       id         path int true "Account ID"
+// @Param       mapping_id path int true "Mapping ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/influx-mappings/{mapping_id} [delete]
+func (h *Handler) deleteInfluxMapping(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("mapping_id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid mapping_id param")
+		return
+	}
+
+	if err := h.serviceInflux.Delete(userId, accountId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,255 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type AccountInvitesResponse struct {
+	Data []domain.AccountInvite `json:"data"`
+}
+
+// Нет прав - 403, неизвестное приглашение - 404, конфликт с участником или приглашением - 409,
+// неверный токен или пароль - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) inviteErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrInviteNotFound):
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvitePending), errors.Is(err, domain.ErrAlreadyMember):
+		h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidInvite), errors.Is(err, domain.ErrInvitePassword), errors.Is(err, domain.ErrInviteUserInfo),
+		errors.Is(err, domain.ErrInviteStatus), errors.Is(err, domain.ErrUnknownRole):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Параметры маршрута: ID аккаунта и ID приглашения
+func inviteParams(ctx *gin.Context) (int, int, error) {
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		return 0, 0, errors.New("invalid id param")
+	}
+
+	inviteId, err := strconv.Atoi(ctx.Param("invite_id"))
+	if err != nil || inviteId == 0 {
+		return 0, 0, errors.New("invalid invite_id param")
+	}
+
+	return accountId, inviteId, nil
+}
+
+// @Summary     Create Account Invite
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description invite a user into the account by email (manager only). The email with a signed invite token
+// @Description is queued to the notification outbox; the token is not returned. Roles default to user.
+// @ID          create-account-invite
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                        true "Account ID"
+// @Param       input body domain.CreateAccountInvite true "Email and roles"
+// @Success     200     {object} domain.AccountInviteSent
+// @Failure     400,403 {object} statusResponse
+// @Failure     409     {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/invites [post]
+func (h *Handler) createAccountInvite(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CreateAccountInvite
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sent, err := h.serviceInvite.Create(userId, accountId, input)
+	if err != nil {
+		h.inviteErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sent)
+}
+
+// @Summary     Get Account Invites
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description get invites of the account, newest first (manager only)
+// @ID          get-account-invites
+// @Accept      json
+// @Produce     json
+// @Param       id     path  int    true  "Account ID"
+// @Param       status query string false "Invite status" Enums(pending, accepted, revoked, expired)
+// @Success     200     {object} AccountInvitesResponse
+// @Failure     400,403 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/invites [get]
+func (h *Handler) getAccountInvites(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceInvite.GetAll(userId, accountId, ctx.Query("status"))
+	if err != nil {
+		h.inviteErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AccountInvitesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Resend Account Invite
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description send the pending invite again with a new token and expiration (manager only); the previous token stops working
+// @ID          resend-account-invite
+// @Accept      json
+// @Produce     json
+// @Param       id        path int true "Account ID"
+// @Param       invite_id path int true "Invite ID"
+// @Success     200     {object} domain.AccountInviteSent
+// @Failure     400,403 {object} statusResponse
+// @Failure     404     {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/invites/{invite_id}/resend [post]
+func (h *Handler) resendAccountInvite(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, inviteId, err := inviteParams(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sent, err := h.serviceInvite.Resend(userId, accountId, inviteId)
+	if err != nil {
+		h.inviteErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sent)
+}
+
+// @Summary     Revoke Account Invite
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description revoke the pending invite (manager only); the invited membership of an existing user is removed
+// @ID          revoke-account-invite
+// @Accept      json
+// @Produce     json
+// @Param       id        path int true "Account ID"
+// @Param       invite_id path int true "Invite ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,403 {object} statusResponse
+// @Failure     404     {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/invites/{invite_id} [delete]
+func (h *Handler) revokeAccountInvite(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, inviteId, err := inviteParams(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceInvite.Revoke(userId, accountId, inviteId); err != nil {
+		h.inviteErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Accept Account Invite
+// @Tags        Authentication
+// @Description accept the invite with the token from the email. A new user sets first name, last name and password;
+// @Description an existing user with the invited email confirms own password. Returns the tokens of a new session.
+// @ID          accept-account-invite
+// @Accept      json
+// @Produce     json
+// @Param       input body domain.AcceptAccountInvite true "Invite token and user info"
+// @Success     200     {object} domain.AccountInviteAccepted
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /auth/invites/accept [post]
+func (h *Handler) acceptAccountInvite(ctx *gin.Context) {
+
+	var input domain.AcceptAccountInvite
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userId, accountId, err := h.serviceInvite.Accept(input)
+	if err != nil {
+		h.inviteErrorResponse(ctx, err)
+		return
+	}
+
+	tokens, err := h.serviceAuthentications.IssueTokens(userId)
+	if err != nil {
+		h.log.Println("service failure: something went wrong: " + err.Error())
+		h.newErrorResponse(ctx, http.StatusInternalServerError, "service failure: something went wrong")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.AccountInviteAccepted{
+		UserID:     userId,
+		AccountID:  accountId,
+		AuthTokens: tokens,
+	})
+}
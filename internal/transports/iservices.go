@@ -1,7 +1,12 @@
 package handler_api
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/jwt_processing"
 )
 
 // Интерфейсы должны объявляться на том уровне абстракции (в том файле),
@@ -28,6 +33,15 @@ type IServiceAuthentications interface {
 
 	Authenticate(email, password string) (*domain.User, error)
 
+	ForgotPassword(email string)
+	ResetPassword(input domain.ResetPassword) error
+	CheckSession(access jwt_processing.Access) error
+
+	IssueTokens(userId int) (domain.AuthTokens, error)
+	Refresh(refreshToken string) (domain.AuthTokens, error)
+	Logout(access jwt_processing.Access, all bool) error
+	RunSessionPurge(ctx context.Context)
+
 	// --old--
 
 	// GetUser_LoginPassword(username, password string) (domain.User, error)
@@ -41,17 +55,19 @@ type IServiceAuthentications interface {
 type IServiceChecklist interface {
 	Create(userId int, list domain.CreateChecklist) (int, error)
 	// GetAllChecklist() ([]domain.Checklist, error)
-	GetAllChecklistOfUser(userId int) ([]domain.Checklist, error)
+	GetAllChecklistOfUser(userId int, includeArchived bool) ([]domain.Checklist, error)
 	GetById(userId, listId int) (*domain.Checklist, error)
 	Delete(userId, listId int) error
+	Restore(userId, listId int) error
 	Update(userId int, input domain.UpdateChecklist) error
 }
 
 type IServiceChecklistItem interface {
 	Create(userId, listId int, item domain.ChecklistItem) (int, error)
-	GetAll(userId, listId int) ([]domain.ChecklistItem, error)
+	GetAll(userId, listId int, includeArchived bool) ([]domain.ChecklistItem, error)
 	GetById(userId, listId, itemId int) (domain.ChecklistItem, error)
 	Delete(userId, listId, itemId int) error
+	Restore(userId, listId, itemId int) error
 	Update(userId, listId, itemId int, input domain.UpdateChecklistItem) error
 }
 
@@ -63,10 +79,240 @@ type IServiceAquahubList interface {
 	DeviceCreateOrUpdate(aquahub_id, device_local_id int, value string) error
 	SensorCreateOrUpdate(aquahub_id, device_local_id, sensor_local_id int, value string) error
 
-	// GetAllAquahubOfUser(userId int) ([]domain.AquahubList, error)
-	// GetDevicesOfAquahub(aquahubId int) ([]domain.AquahubList, error)
-	// GetSensorsOfDevice(deviceId int) ([]domain.AquahubList, error)
+	GetAllAquahubOfUser(userId int, filter domain.GroupFilter, includeArchived bool) ([]domain.AquahubList, error)
+	GetAquahub(userId, id int) (*domain.AquahubList, error)
+	CreateAquahub(userId int, input domain.CreateAquahub) (domain.AquahubCreated, error)
+	UpdateAquahub(userId, id int, input domain.UpdateAquahub) error
+	DeleteAquahub(userId, id int) error
+	RestoreAquahub(userId, id int) error
+
+	GetDevicesOfAquahub(userId, aquahubId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Device, error)
+	GetDevice(userId, id int) (*domain.Device, error)
+	CreateDevice(userId int, input domain.CreateDevice) (int, error)
+	UpdateDevice(userId, id int, input domain.UpdateDevice) error
+	DeleteDevice(userId, id int) error
+	RestoreDevice(userId, id int) error
+
+	GetSensorsOfDevice(userId, deviceId int, filter domain.GroupFilter, includeArchived bool) ([]domain.Sensor, error)
+	GetSensor(userId, id int) (*domain.Sensor, error)
+	CreateSensor(userId int, input domain.CreateSensor) (int, error)
+	UpdateSensor(userId, id int, input domain.UpdateSensor) error
+	DeleteSensor(userId, id int) error
+	RestoreSensor(userId, id int) error
 	// GetDataSetOfSensor(sensorId int) ([]domain.SensorDataSet, error)
 
 	// GetNameOfDeviceSensor(sensor_id int) (domain.NameOfDeviceSensor, error)
 }
+
+type IServiceVirtualSensor interface {
+	Create(userId int, input domain.CreateVirtualSensor) (int, error)
+	GetAll(userId int) ([]domain.VirtualSensor, error)
+	GetById(userId, id int) (*domain.VirtualSensor, error)
+	Update(userId int, input domain.UpdateVirtualSensor) error
+	Delete(userId, id int) error
+}
+
+type IServiceCalibration interface {
+	Create(userId, sensorId int, input domain.CreateSensorCalibration) (int, error)
+	GetAll(userId, sensorId int) ([]domain.SensorCalibration, error)
+	Delete(userId, sensorId, id int) error
+	Recompute(userId, sensorId int, from time.Time) (domain.RecomputeResult, error)
+}
+
+type IServiceAnomaly interface {
+	GetDetectors(userId, sensorId int) (domain.SensorDetectors, error)
+	SetDetectors(userId, sensorId int, input domain.SetSensorDetectors) error
+	DeleteDetectors(userId, sensorId int) error
+	GetFlags(userId, sensorId int, from, to time.Time) ([]domain.SensorFlag, error)
+}
+
+type IServiceCoverage interface {
+	SetReportInterval(userId, sensorId int, input domain.SetReportInterval) error
+	Report(userId int, level string, id int, filter domain.GroupFilter, from, to time.Time) (domain.CoverageReport, error)
+	Daily(userId int, level string, id int, filter domain.GroupFilter, from, to time.Time) ([]domain.CoverageDay, error)
+
+	RunDaily(ctx context.Context)
+}
+
+type IServiceMetrics interface {
+	CreateToken(userId, accountId int, input domain.CreateMetricsToken) (domain.MetricsTokenCreated, error)
+	GetTokens(userId, accountId int) ([]domain.MetricsToken, error)
+	RevokeToken(userId, accountId, id int) error
+	Authenticate(token string) (int, error)
+
+	SetSensorUnit(userId, sensorId int, input domain.SetSensorUnit) error
+	Write(accountId int, w io.Writer) error
+}
+
+type IServiceInflux interface {
+	Create(userId, accountId int, input domain.CreateInfluxMapping) (int, error)
+	GetAll(userId, accountId int) ([]domain.InfluxMapping, error)
+	Delete(userId, accountId, id int) error
+
+	Write(h_token, u_token, data string, precision time.Duration) (domain.InfluxWriteResult, error)
+}
+
+type IServiceAlert interface {
+	Create(userId int, input domain.CreateAlertRule) (int, error)
+	GetAll(userId, sensorId int, filter domain.GroupFilter) ([]domain.AlertRule, error)
+	GetById(userId, id int) (*domain.AlertRule, error)
+	Update(userId int, input domain.UpdateAlertRule) error
+	Delete(userId, id int) error
+	GetEvents(userId int, filter domain.AlertEventsFilter) ([]domain.AlertEvent, error)
+
+	GetAlerts(userId int, filter domain.AlertsFilter) ([]domain.Alert, error)
+	GetAlert(userId, id int) (*domain.Alert, error)
+	Acknowledge(userId, id int) error
+	Snooze(userId, id int, input domain.SnoozeAlert) error
+	Resolve(userId, id int) error
+
+	RunNoData(ctx context.Context)
+	RunReminders(ctx context.Context)
+}
+
+type IServiceNotification interface {
+	Configure(cfg domain.CfgNotify, sender string)
+
+	GetPreferences(userId int) ([]domain.NotificationPreference, error)
+	SetPreference(userId int, channel string, input domain.SetNotificationPreference) error
+	DeletePreference(userId int, channel string) error
+
+	GetTemplates(userId, accountId int) ([]domain.NotificationTemplate, error)
+	SetTemplate(userId, accountId int, event string, input domain.SetNotificationTemplate) error
+	DeleteTemplate(userId, accountId int, event string) error
+
+	GetDeliveries(userId int, status string, from, to time.Time) ([]domain.NotificationDelivery, error)
+	SendTest(userId int) (int, error)
+
+	RunDeliveries(ctx context.Context)
+}
+
+type IServiceAutomation interface {
+	Create(userId int, input domain.SetAutomation) (int, error)
+	GetAll(userId int) ([]domain.Automation, error)
+	GetById(userId, id int) (*domain.Automation, error)
+	Update(userId, id int, input domain.SetAutomation) error
+	Delete(userId, id int) error
+
+	GetRuns(userId, id int, from, to time.Time) ([]domain.AutomationRun, error)
+	Test(userId, id int) (domain.AutomationTestResult, error)
+
+	RunScheduled(ctx context.Context)
+}
+
+type IServiceCommand interface {
+	Create(userId int, input domain.CreateDeviceCommand) (int, error)
+	GetAll(userId int, filter domain.DeviceCommandsFilter) ([]domain.DeviceCommand, error)
+
+	Poll(h_token, u_token string) ([]domain.HubCommand, error)
+	Ack(h_token, u_token string, id int) error
+}
+
+type IServiceGroup interface {
+	Create(userId int, input domain.CreateHubGroup) (int, error)
+	GetAll(userId, accountId int, kind string) ([]domain.HubGroup, error)
+	GetById(userId, id int) (*domain.HubGroup, error)
+	Update(userId, id int, input domain.UpdateHubGroup) error
+	Delete(userId, id int) error
+	SetAquahubGroup(userId, aquahubId int, input domain.SetAquahubGroup) error
+
+	GetTags(userId int, entity string, id int) ([]string, error)
+	SetTags(userId int, entity string, id int, input domain.SetTags) ([]string, error)
+	GetAllTags(userId, accountId int) ([]domain.TagCount, error)
+}
+
+type IServiceTransfer interface {
+	Create(userId, aquahubId int, input domain.CreateHubTransfer) (domain.HubTransferCreated, error)
+	Get(userId, aquahubId int) (*domain.HubTransfer, error)
+	Cancel(userId, aquahubId int) error
+	GetAll(userId int, filter domain.HubTransfersFilter) ([]domain.HubTransfer, error)
+
+	Accept(userId int, input domain.AcceptHubTransfer) (*domain.HubTransferAccepted, error)
+}
+
+type IServiceGeo interface {
+	GetCountries() ([]domain.Country, error)
+	GetTimezones(country string) ([]string, error)
+	FindPlaces(country, q string, limit int) ([]domain.GeoPlace, error)
+
+	GetAquahubLocation(userId, aquahubId int) (*domain.Location, error)
+	SetAquahubLocation(userId, aquahubId int, input domain.SetLocation) (*domain.Location, error)
+	DeleteAquahubLocation(userId, aquahubId int) error
+
+	GetAccountLocation(userId, accountId int) (*domain.Location, error)
+	SetAccountLocation(userId, accountId int, input domain.SetLocation) (*domain.Location, error)
+}
+
+type IServicePairing interface {
+	Announce(hToken string) (domain.HubPairing, error)
+
+	Claim(userId int, input domain.ClaimAquahub) (int, error)
+	Unclaim(userId, aquahubId int) error
+	FactoryReset(userId, aquahubId int) (int, error)
+}
+
+type IServiceChecklistRecurrence interface {
+	Get(userId, checklistId int) (*domain.ChecklistRecurrence, error)
+	Set(userId, checklistId int, input domain.SetChecklistRecurrence) (*domain.ChecklistRecurrence, error)
+	Delete(userId, checklistId int) error
+	GetInstances(userId, checklistId int, from, to time.Time) ([]domain.ChecklistInstance, error)
+
+	RunDue(ctx context.Context)
+}
+
+type IServiceWebhook interface {
+	Create(userId, accountId int, input domain.SetWebhook) (domain.WebhookCreated, error)
+	GetAll(userId, accountId int) ([]domain.Webhook, error)
+	GetById(userId, accountId, id int) (*domain.Webhook, error)
+	Update(userId, accountId, id int, input domain.SetWebhook) error
+	Delete(userId, accountId, id int) error
+
+	GetDeliveries(userId, accountId, id int, status string, from, to time.Time) ([]domain.WebhookDelivery, error)
+	Redeliver(userId, accountId, id, deliveryId int) (int, error)
+
+	RunDeliveries(ctx context.Context)
+	RunHubsOffline(ctx context.Context)
+}
+
+type IServiceDigest interface {
+	GetSubscription(userId, accountId int) (*domain.DigestSubscription, error)
+	SetSubscription(userId, accountId int, input domain.SetDigestSubscription) error
+	DeleteSubscription(userId, accountId int) error
+
+	GetReports(userId int, filter domain.DigestReportsFilter) ([]domain.DigestReport, error)
+	GetReport(userId, id int) (*domain.DigestReport, error)
+
+	RunDue(ctx context.Context)
+}
+
+type IServiceDeviceType interface {
+	GetAll(userId, accountId int) ([]domain.DeviceType, error)
+	GetById(userId, id int) (*domain.DeviceType, error)
+	Create(userId int, input domain.CreateDeviceType) (int, error)
+	Update(userId, id int, input domain.DeviceTypeInput) error
+	Delete(userId, id int) error
+
+	SetDeviceType(userId, deviceId int, input domain.SetDeviceType) error
+}
+
+type IServiceArchive interface {
+	RunPurge(ctx context.Context)
+}
+
+type IServiceAccess interface {
+	Authorize(userId, accountId int, perm domain.Permission) error
+	AuthorizeAny(userId int, perm domain.Permission) error
+
+	GetMembers(userId, accountId int) ([]domain.AccountMember, error)
+	UpdateMember(userId, accountId, memberId int, input domain.UpdateAccountMember) error
+	RemoveMember(userId, accountId, memberId int) error
+}
+
+type IServiceInvite interface {
+	Create(userId, accountId int, input domain.CreateAccountInvite) (domain.AccountInviteSent, error)
+	GetAll(userId, accountId int, status string) ([]domain.AccountInvite, error)
+	Resend(userId, accountId, inviteId int) (domain.AccountInviteSent, error)
+	Revoke(userId, accountId, inviteId int) error
+
+	Accept(input domain.AcceptAccountInvite) (int, int, error)
+}
@@ -0,0 +1,45 @@
+package handler_api
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhashkevych/scheduler"
+)
+
+// Плановые задачи. Задачи идемпотентны: повторный запуск не пересчитывает уже сделанное.
+func (h *Handler) InitJobs(ctx context.Context, s *scheduler.Scheduler) {
+
+	// Покрытие данными за прошедшие сутки
+	s.Add(ctx, h.serviceCoverage.RunDaily, time.Hour)
+
+	// Правила оповещения "нет данных"
+	s.Add(ctx, h.serviceAlert.RunNoData, time.Minute)
+
+	// Напоминания о неподтверждённых и истёкших отложенных оповещениях
+	s.Add(ctx, h.serviceAlert.RunReminders, time.Minute)
+
+	// Правила автоматизации по расписанию и условия с длительностью
+	s.Add(ctx, h.serviceAutomation.RunScheduled, time.Minute)
+
+	// Экземпляры повторяющихся чек-листов
+	s.Add(ctx, h.serviceRecurrence.RunDue, time.Minute)
+
+	// Окончательное удаление записей, пролежавших в архиве дольше срока хранения
+	s.Add(ctx, h.serviceArchive.RunPurge, time.Hour)
+
+	// Удаление истёкших токенов обновления и записей об отозванных токенах
+	s.Add(ctx, h.serviceAuthentications.RunSessionPurge, time.Hour)
+
+	// Ежедневные и еженедельные сводки
+	s.Add(ctx, h.serviceDigest.RunDue, time.Minute)
+
+	// Отправка уведомлений из очереди
+	s.Add(ctx, h.serviceNotification.RunDeliveries, 15*time.Second)
+
+	// Отключившиеся хабы - событие hub.offline
+	s.Add(ctx, h.serviceWebhook.RunHubsOffline, time.Minute)
+
+	// Отправка событий webhook_ов из очереди
+	s.Add(ctx, h.serviceWebhook.RunDeliveries, 15*time.Second)
+}
@@ -0,0 +1,164 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type AccountMembersResponse struct {
+	Data []domain.AccountMember `json:"data"`
+}
+
+// Нет прав - 403, последний менеджер - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) memberErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrLastManager), errors.Is(err, domain.ErrUnknownRole):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Параметры маршрута: ID аккаунта и ID пользователя-участника
+func memberParams(ctx *gin.Context) (int, int, error) {
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		return 0, 0, errors.New("invalid id param")
+	}
+
+	memberId, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil || memberId == 0 {
+		return 0, 0, errors.New("invalid user_id param")
+	}
+
+	return accountId, memberId, nil
+}
+
+// @Summary     Get Account Members
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description get members of the account with their roles: manager manages hubs and members, user has read-only access
+// @ID          get-account-members
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} AccountMembersResponse
+// @Failure     400,403 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/members [get]
+func (h *Handler) getAccountMembers(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceAccess.GetMembers(userId, accountId)
+	if err != nil {
+		h.memberErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AccountMembersResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Update Account Member
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description set roles and status of the account member (manager only).
+// @Description The last active manager of the account can not lose the manager role or be disabled.
+// @ID          update-account-member
+// @Accept      json
+// @Produce     json
+// @Param       id      path int                        true "Account ID"
+// @Param       user_id path int                        true "User ID of the member"
+// @Param       input   body domain.UpdateAccountMember true "Roles and status"
+// @Success     200     {object} statusResponse
+// @Failure     400,403 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/members/{user_id} [put]
+func (h *Handler) updateAccountMember(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, memberId, err := memberParams(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input domain.UpdateAccountMember
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAccess.UpdateMember(userId, accountId, memberId, input); err != nil {
+		h.memberErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Remove Account Member
+// @Security    ApiKeyAuth
+// @Tags        Accounts
+// @Description remove member from the account (manager only); a member can leave the account by own user ID.
+// @Description The last active manager of the account can not be removed.
+// @ID          remove-account-member
+// @Accept      json
+// @Produce     json
+// @Param       id      path int true "Account ID"
+// @Param       user_id path int true "User ID of the member"
+// @Success     200     {object} statusResponse
+// @Failure     400,403 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/members/{user_id} [delete]
+func (h *Handler) removeAccountMember(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, memberId, err := memberParams(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceAccess.RemoveMember(userId, accountId, memberId); err != nil {
+		h.memberErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
@@ -0,0 +1,238 @@
+package handler_api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+	"github.com/o-sokol-o/hub/pkg/promtext"
+)
+
+const metricsAccountCtx = "metricsAccountId"
+
+type MetricsTokensResponse struct {
+	Data []domain.MetricsToken `json:"data"`
+}
+
+// Авторизация сбора метрик: токен сбора метрик аккаунта в хедере "Authorization: Bearer <token>".
+// JWT пользователя здесь не принимается.
+func (h *Handler) scrapeToken_middleware(c *gin.Context) {
+
+	header := c.GetHeader(authorizationHeader)
+	token := strings.TrimPrefix(header, "Bearer ")
+	if header == "" || token == header || token == "" {
+		h.newErrorResponse(c, http.StatusUnauthorized, "invalid auth header")
+		return
+	}
+
+	accountId, err := h.serviceMetrics.Authenticate(token)
+	if err != nil {
+		h.newErrorResponse(c, http.StatusUnauthorized, "invalid metrics token")
+		return
+	}
+
+	c.Set(metricsAccountCtx, accountId)
+}
+
+// @Summary     Get Account Metrics
+// @Security    ApiKeyAuth
+// @Tags        Metrics
+// @Description latest value of every sensor of the account in Prometheus text format;
+// @Description authorization: "Bearer <metrics token>" (user JWT is not accepted)
+// @ID          get-metrics
+// @Produce     plain
+// @Success     200     {string} string
+// @Failure     401     {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /metrics [get]
+func (h *Handler) getMetrics(ctx *gin.Context) {
+
+	accountId := ctx.GetInt(metricsAccountCtx)
+	if accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "invalid metrics token")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.serviceMetrics.Write(accountId, &buf); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Data(http.StatusOK, promtext.ContentType, buf.Bytes())
+}
+
+// @Summary     Create Metrics Token
+// @Security    ApiKeyAuth
+// @Tags        Metrics
+// @Description create scrape token of the account; the token is shown only once
+// @ID          create-metrics-token
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                       true "Account ID"
+// @Param       input body domain.CreateMetricsToken true "Token info"
+// @Success     200     {object} domain.MetricsTokenCreated
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/metrics-tokens [post]
+func (h *Handler) createMetricsToken(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CreateMetricsToken
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	token, err := h.serviceMetrics.CreateToken(userId, accountId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// @Summary     Get Metrics Tokens
+// @Security    ApiKeyAuth
+// @Tags        Metrics
+// @Description get scrape tokens of the account (without token values)
+// @ID          get-metrics-tokens
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} MetricsTokensResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/metrics-tokens [get]
+func (h *Handler) getMetricsTokens(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceMetrics.GetTokens(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, MetricsTokensResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Revoke Metrics Token
+// @Security    ApiKeyAuth
+// @Tags        Metrics
+// @Description revoke scrape token of the account
+// @ID          revoke-metrics-token
+// @Accept      json
+// @Produce     json
+// @Param       id       path int true "Account ID"
+// @Param       token_id path int true "Token ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/metrics-tokens/{token_id} [delete]
+func (h *Handler) revokeMetricsToken(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("token_id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid token_id param")
+		return
+	}
+
+	if err := h.serviceMetrics.RevokeToken(userId, accountId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Set Sensor Unit
+// @Security    ApiKeyAuth
+// @Tags        Metrics
+// @Description set unit of measurement of sensor (used as metric label)
+// @ID          set-sensor-unit
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                  true "Sensor ID"
+// @Param       input body domain.SetSensorUnit true "Unit"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/sensors/{id}/unit [put]
+func (h *Handler) setSensorUnit(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sensorId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || sensorId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetSensorUnit
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceMetrics.SetSensorUnit(userId, sensorId, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
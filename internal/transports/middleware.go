@@ -6,15 +6,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
 	"github.com/o-sokol-o/hub/pkg/jwt_processing"
 )
 
 const (
 	authorizationHeader = "Authorization"
 	userCtx             = "userId"
+	accessCtx           = "access"
 )
 
 func (h *Handler) middleware_PrintHeader(ctx *gin.Context) {
@@ -58,19 +61,30 @@ func (h *Handler) userIdentity_middleware(c *gin.Context) {
 		return
 	}
 
-	// Метод ParseToken принимает token в качестве аргумента
+	// Метод ParseAccessToken принимает token в качестве аргумента
 	// и возвращать id пользователя при успешном парcинге
-	userId, err := jwt_processing.ParseToken(headerParts[1])
+	access, err := jwt_processing.ParseAccessToken(headerParts[1])
 	if err != nil {
 		h.log.Println("invalid parse token: " + err.Error())
 		h.newErrorResponse(c, http.StatusUnauthorized, "invalid parse token")
 		return
 	}
 
+	// Отозванные токены и токены, выданные до смены пароля, не действуют
+	if err := h.serviceAuthentications.CheckSession(access); err != nil {
+		if errors.Is(err, domain.ErrSessionExpired) || errors.Is(err, domain.ErrTokenRevoked) {
+			h.newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	// Если операция ParseToken успешна - запишем значение id в контекст.
 	// Это мы делаем для того чтобы иметь доступ к id пользователям (которые делают запрос)
 	// в последующих обработчиках, которые вызываются после данной прослойки.
-	c.Set(userCtx, userId)
+	c.Set(userCtx, access.UserId)
+	c.Set(accessCtx, access)
 }
 
 // Функция, достающая ID пользователя из контекста, обрабатывает ошибки и выводит response.
@@ -87,3 +101,62 @@ func getUserIdFromContext(c *gin.Context) (int, error) {
 
 	return idInt, nil
 }
+
+// Данные токена доступа текущего запроса (для выхода)
+func getAccessFromContext(c *gin.Context) (jwt_processing.Access, error) {
+	access, ok := c.Get(accessCtx)
+	if !ok {
+		return jwt_processing.Access{}, errors.New("access token not found")
+	}
+
+	a, ok := access.(jwt_processing.Access)
+	if !ok || a.UserId == 0 {
+		return jwt_processing.Access{}, errors.New("access token is of invalid type")
+	}
+
+	return a, nil
+}
+
+// Прослойка прав доступа по ролям: без права perm хотя бы в одном из аккаунтов пользователя
+// запрос отклоняется со Status Code 403. Принадлежность хаба, устройства или сенсора аккаунту,
+// в котором у пользователя есть право, проверяют запросы к БД.
+func (h *Handler) permission_middleware(perm domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := getUserIdFromContext(c)
+		if err != nil {
+			h.newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		h.authorize(c, h.serviceAccess.AuthorizeAny(userId, perm))
+	}
+}
+
+// Прослойка прав доступа к аккаунту из параметра маршрута "id" (группа /api/accounts)
+func (h *Handler) accountPermission_middleware(perm domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := getUserIdFromContext(c)
+		if err != nil {
+			h.newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		accountId, err := strconv.Atoi(c.Param("id"))
+		if err != nil || accountId == 0 {
+			h.newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+			return
+		}
+
+		h.authorize(c, h.serviceAccess.Authorize(userId, accountId, perm))
+	}
+}
+
+func (h *Handler) authorize(c *gin.Context, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrForbidden):
+		h.newErrorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,342 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type NotificationPreferencesResponse struct {
+	Data []domain.NotificationPreference `json:"data"`
+}
+
+type NotificationTemplatesResponse struct {
+	Data []domain.NotificationTemplate `json:"data"`
+}
+
+type NotificationDeliveriesResponse struct {
+	Data []domain.NotificationDelivery `json:"data"`
+}
+
+// Подключение каналов уведомлений по конфигурации приложения
+func (h *Handler) InitNotifications(cfg domain.CfgApplication) {
+	h.serviceNotification.Configure(cfg.Notify, cfg.Project.EmailSender)
+}
+
+// Неизвестный канал или событие - ошибка клиента
+func (h *Handler) notificationErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrUnknownChannel) || errors.Is(err, domain.ErrUnknownNotifyEvent) {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary     Get Notification Preferences
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description get notification channels of the user; email without settings is enabled to the user address
+// @ID          get-notification-preferences
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} NotificationPreferencesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/notifications/preferences [get]
+func (h *Handler) getNotificationPreferences(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	list, err := h.serviceNotification.GetPreferences(userId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NotificationPreferencesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Set Notification Preference
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description enable or disable notification channel of the user and set its target:
+// @Description email address (default - user email), webhook url or recipient name for file outbox
+// @ID          set-notification-preference
+// @Accept      json
+// @Produce     json
+// @Param       channel path string                           true "Channel" Enums(email, webhook, file)
+// @Param       input   body domain.SetNotificationPreference true "Channel settings"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/notifications/preferences/{channel} [put]
+func (h *Handler) setNotificationPreference(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input domain.SetNotificationPreference
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+
+	channel := ctx.Param("channel")
+	if err := input.Validate(channel); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceNotification.SetPreference(userId, channel, input); err != nil {
+		h.notificationErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Notification Preference
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description reset notification channel of the user to defaults
+// @ID          delete-notification-preference
+// @Accept      json
+// @Produce     json
+// @Param       channel path string true "Channel" Enums(email, webhook, file)
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/notifications/preferences/{channel} [delete]
+func (h *Handler) deleteNotificationPreference(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.serviceNotification.DeletePreference(userId, ctx.Param("channel")); err != nil {
+		h.notificationErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Notification Deliveries
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description get delivery log of notifications sent to the user, newest first (default - last 24 hours)
+// @ID          get-notification-deliveries
+// @Accept      json
+// @Produce     json
+// @Param       status query string false "Delivery status" Enums(pending, sent, failed)
+// @Param       from   query string false "Period start, RFC3339"
+// @Param       to     query string false "Period end, RFC3339"
+// @Success     200     {object} NotificationDeliveriesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/notifications/deliveries [get]
+func (h *Handler) getNotificationDeliveries(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := ctx.Query("status")
+	if status != "" && status != domain.DeliveryPending && status != domain.DeliverySent && status != domain.DeliveryFailed {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid status param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceNotification.GetDeliveries(userId, status, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NotificationDeliveriesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Send Test Notification
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description queue test notification to all enabled channels of the user
+// @ID          send-test-notification
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} domain.NotificationTestResult
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/notifications/test [post]
+func (h *Handler) sendTestNotification(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	n, err := h.serviceNotification.SendTest(userId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.NotificationTestResult{
+		Queued: n,
+	})
+}
+
+// @Summary     Get Notification Templates
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description get notification templates of the account for every event (custom or built-in)
+// @ID          get-notification-templates
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} NotificationTemplatesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/notification-templates [get]
+func (h *Handler) getNotificationTemplates(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceNotification.GetTemplates(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NotificationTemplatesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Set Notification Template
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description set account template (Go text/template) for notification event;
+// @Description fields: .Event, .Message, .Value, .SensorID, .RuleID, .AlertID, .Since, .Time, .FirstName, .Report
+// @ID          set-notification-template
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                            true "Account ID"
+// @Param       event path string                         true "Event" Enums(alert.firing, alert.resolved, alert.reminder, automation, digest, account.invite, test)
+// @Param       input body domain.SetNotificationTemplate true "Template"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/notification-templates/{event} [put]
+func (h *Handler) setNotificationTemplate(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetNotificationTemplate
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceNotification.SetTemplate(userId, accountId, ctx.Param("event"), input); err != nil {
+		h.notificationErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Notification Template
+// @Security    ApiKeyAuth
+// @Tags        Notifications
+// @Description delete account template, built-in template is used again
+// @ID          delete-notification-template
+// @Accept      json
+// @Produce     json
+// @Param       id    path int    true "Account ID"
+// @Param       event path string true "Event" Enums(alert.firing, alert.resolved, alert.reminder, automation, digest, account.invite, test)
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/notification-templates/{event} [delete]
+func (h *Handler) deleteNotificationTemplate(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceNotification.DeleteTemplate(userId, accountId, ctx.Param("event")); err != nil {
+		h.notificationErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
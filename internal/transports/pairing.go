@@ -0,0 +1,186 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+// Ошибки кода привязки и проверки ввода - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) pairingErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidHubToken):
+		h.newErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, domain.ErrInvalidPairingCode), errors.Is(err, domain.ErrHubAlreadyClaimed):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Заводской токен хаба: "Authorization: Token h_token" или параметр h
+func hubFactoryToken(ctx *gin.Context) string {
+
+	header := ctx.GetHeader(authorizationHeader)
+	for _, scheme := range []string{"Token ", "Bearer "} {
+		if strings.HasPrefix(header, scheme) {
+			h_token, _, _ := strings.Cut(strings.TrimPrefix(header, scheme), ":")
+			return h_token
+		}
+	}
+
+	return ctx.Query("h")
+}
+
+// @Summary     Hub Pairing
+// @Tags        AquaHub
+// @Description request of the hub with factory h_token (Authorization: Token h_token or h param).
+// @Description Unclaimed hub gets a short pairing code to show to the user, claimed hub gets u_token of the account.
+// @Description The hub repeats the request until status is "claimed".
+// @Description Hub of an archived aquahub gets status "archived" without a code until the aquahub is restored.
+// @Description After transfer to another account the previous token gets only a pairing code.
+// @ID          hub-pairing
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} domain.HubPairing
+// @Failure     400,401 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /v1/pairing [post]
+func (h *Handler) hubPairing(ctx *gin.Context) {
+
+	h_token := hubFactoryToken(ctx)
+	if h_token == "" {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "missing hub token")
+		return
+	}
+
+	pairing, err := h.servicePairing.Announce(h_token)
+	if err != nil {
+		h.pairingErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pairing)
+}
+
+// @Summary     Claim Aquahub
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description bind the hub showing the pairing code to the account
+// @ID          claim-aquahub
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.ClaimAquahub true "Pairing code and aquahub info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/claim [post]
+func (h *Handler) claimAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.ClaimAquahub
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.servicePairing.Claim(userId, input)
+	if err != nil {
+		h.pairingErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Unclaim Aquahub
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description detach the hub from the account: the aquahub with its data is archived,
+// @Description its hub token is replaced, and the hub can be claimed again with a new pairing code
+// @ID          unclaim-aquahub
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/unclaim [post]
+func (h *Handler) unclaimAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.servicePairing.Unclaim(userId, id); err != nil {
+		h.pairingErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Factory Reset Aquahub
+// @Security    ApiKeyAuth
+// @Tags        Aquahubs
+// @Description queue the factory_reset command to the hub; after the reset the hub requests
+// @Description a new pairing code and is unclaimed (the aquahub with its data is archived)
+// @ID          factory-reset-aquahub
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/factory-reset [post]
+func (h *Handler) factoryResetAquahub(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	commandId, err := h.servicePairing.FactoryReset(userId, id)
+	if err != nil {
+		h.pairingErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: commandId,
+	})
+}
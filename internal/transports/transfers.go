@@ -0,0 +1,236 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type HubTransfersResponse struct {
+	Data []domain.HubTransfer `json:"data"`
+}
+
+// Ошибки кода передачи и режима - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) transferErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTransferCode), errors.Is(err, domain.ErrTransferSameAccount),
+		errors.Is(err, domain.ErrUnknownTransferMode):
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	default:
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary     Create Aquahub Transfer
+// @Security    ApiKeyAuth
+// @Tags        Transfers
+// @Description offer the aquahub to another account: the recipient accepts the transfer with the returned code within 7 days.
+// @Description mode "move" - the hub moves with devices, sensors and readings (alert rules, tags and group of the account are removed),
+// @Description "keep" - readings stay with the archived hub in the account, the recipient gets the hub with the same devices and sensors.
+// @Description A new transfer replaces the pending one.
+// @ID          create-hub-transfer
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                      true "Aquahub ID"
+// @Param       input body domain.CreateHubTransfer true "Transfer mode"
+// @Success     200     {object} domain.HubTransferCreated
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/transfer [post]
+func (h *Handler) createHubTransfer(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CreateHubTransfer
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.serviceTransfer.Create(userId, id, input)
+	if err != nil {
+		h.transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, created)
+}
+
+// @Summary     Get Aquahub Transfer
+// @Security    ApiKeyAuth
+// @Tags        Transfers
+// @Description get pending transfer of the aquahub
+// @ID          get-hub-transfer
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} domain.HubTransfer
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/transfer [get]
+func (h *Handler) getHubTransfer(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	transfer, err := h.serviceTransfer.Get(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+	if transfer == nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, "transfer not found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// @Summary     Cancel Aquahub Transfer
+// @Security    ApiKeyAuth
+// @Tags        Transfers
+// @Description cancel pending transfer of the aquahub
+// @ID          cancel-hub-transfer
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Aquahub ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/aquahubs/{id}/transfer [delete]
+func (h *Handler) cancelHubTransfer(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceTransfer.Cancel(userId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Get Aquahub Transfers
+// @Security    ApiKeyAuth
+// @Tags        Transfers
+// @Description get transfer log of aquahubs from and to the user accounts, newest first
+// @ID          get-hub-transfers
+// @Accept      json
+// @Produce     json
+// @Param       account_id query int false "Only transfers from or to the account"
+// @Param       aquahub_id query int false "Only transfers of the aquahub"
+// @Success     200     {object} HubTransfersResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/transfers [get]
+func (h *Handler) getHubTransfers(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var filter domain.HubTransfersFilter
+
+	if filter.AccountID, err = queryId(ctx, "account_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid account_id param")
+		return
+	}
+	if filter.AquahubID, err = queryId(ctx, "aquahub_id"); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid aquahub_id param")
+		return
+	}
+
+	list, err := h.serviceTransfer.GetAll(userId, filter)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, HubTransfersResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Accept Aquahub Transfer
+// @Security    ApiKeyAuth
+// @Tags        Transfers
+// @Description accept the aquahub transfer with the code into the account. The hub token is replaced:
+// @Description the new h_token is returned only in this response, write it to the hub to provision it.
+// @Description The previous token gets only a pairing code.
+// @ID          accept-hub-transfer
+// @Accept      json
+// @Produce     json
+// @Param       input body domain.AcceptHubTransfer true "Transfer code and recipient account"
+// @Success     200     {object} domain.HubTransferAccepted
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/transfers/accept [post]
+func (h *Handler) acceptHubTransfer(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.AcceptHubTransfer
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transfer, err := h.serviceTransfer.Accept(userId, input)
+	if err != nil {
+		h.transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
@@ -0,0 +1,215 @@
+package handler_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type VirtualSensorsResponse struct {
+	Data []domain.VirtualSensor `json:"data"`
+}
+
+// Ошибки формулы - ошибка клиента, остальные - ошибка сервера
+func (h *Handler) virtualSensorErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidFormula) {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
+
+// @Summary     Create Virtual Sensor
+// @Security    ApiKeyAuth
+// @Tags        Virtual Sensors
+// @Description create a sensor computed from a formula over other sensors of the account
+// @ID          create-virtual-sensor
+// @Accept      json
+// @Produce     json
+// @Param       input   body      domain.CreateVirtualSensor true "Virtual sensor info"
+// @Success     200     {object}  idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/virtual-sensors [post]
+func (h *Handler) createVirtualSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusUnauthorized, "user is unauthorized")
+		return
+	}
+
+	var input domain.CreateVirtualSensor
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.serviceVirtualSensor.Create(userId, input)
+	if err != nil {
+		h.virtualSensorErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: id,
+	})
+}
+
+// @Summary     Get All Virtual Sensors
+// @Security    ApiKeyAuth
+// @Tags        Virtual Sensors
+// @Description get all virtual sensors of the user accounts
+// @ID          get-all-virtual-sensors
+// @Accept      json
+// @Produce     json
+// @Success     200     {object} VirtualSensorsResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/virtual-sensors [get]
+func (h *Handler) getAllVirtualSensors(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	list, err := h.serviceVirtualSensor.GetAll(userId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, VirtualSensorsResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Virtual Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Virtual Sensors
+// @Description get virtual sensor by id
+// @ID          get-virtual-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} domain.VirtualSensor
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/virtual-sensors/{id} [get]
+func (h *Handler) getVirtualSensorById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	sensor, err := h.serviceVirtualSensor.GetById(userId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sensor)
+}
+
+// @Summary     Update Virtual Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Virtual Sensors
+// @Description update title, description or formula of virtual sensor
+// @ID          update-virtual-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id    path int                        true "Sensor ID"
+// @Param       input body domain.UpdateVirtualSensor true "Virtual sensor info"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/virtual-sensors/{id} [put]
+func (h *Handler) updateVirtualSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input domain.UpdateVirtualSensor
+	if err = ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input.ID, err = strconv.Atoi(ctx.Param("id"))
+	if err != nil || input.ID == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceVirtualSensor.Update(userId, input); err != nil {
+		h.virtualSensorErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Virtual Sensor By Id
+// @Security    ApiKeyAuth
+// @Tags        Virtual Sensors
+// @Description delete virtual sensor and its computed readings
+// @ID          delete-virtual-sensor-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Sensor ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/virtual-sensors/{id} [delete]
+func (h *Handler) deleteVirtualSensor(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.serviceVirtualSensor.Delete(userId, id); err != nil {
+		h.virtualSensorErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,357 @@
+package handler_api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/o-sokol-o/hub/internal/domain"
+)
+
+type WebhooksResponse struct {
+	Data []domain.Webhook `json:"data"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Data []domain.WebhookDelivery `json:"data"`
+}
+
+// @Summary     Create Webhook
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description subscribe url to account events: reading.received, device.added, sensor.added, hub.offline,
+// @Description alert.fired, checklist.completed. Requests are POST JSON {id, event, account_id, created_at, data}
+// @Description signed in X-Hub-Signature "t=<unix>,v1=<hex HMAC-SHA256 of "<unix>.<body>" with secret>";
+// @Description the secret is returned only once. Delivery is at-least-once: deduplicate by id (X-Hub-Delivery)
+// @ID          create-webhook
+// @Accept      json
+// @Produce     json
+// @Param       id    path int               true "Account ID"
+// @Param       input body domain.SetWebhook true "Webhook"
+// @Success     200     {object} domain.WebhookCreated
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks [post]
+func (h *Handler) createWebhook(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.SetWebhook
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.serviceWebhook.Create(userId, accountId, input)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, created)
+}
+
+// @Summary     Get Webhooks
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description get webhooks of the account
+// @ID          get-webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Account ID"
+// @Success     200     {object} WebhooksResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks [get]
+func (h *Handler) getWebhooks(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.serviceWebhook.GetAll(userId, accountId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhooksResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Get Webhook By Id
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description get webhook of the account
+// @ID          get-webhook-by-id
+// @Accept      json
+// @Produce     json
+// @Param       id         path int true "Account ID"
+// @Param       webhook_id path int true "Webhook ID"
+// @Success     200     {object} domain.Webhook
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks/{webhook_id} [get]
+func (h *Handler) getWebhookById(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid webhook_id param")
+		return
+	}
+
+	w, err := h.serviceWebhook.GetById(userId, accountId, id)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, w)
+}
+
+// @Summary     Update Webhook
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description update url, events, description and enabled flag of the webhook; the secret is kept
+// @ID          update-webhook
+// @Accept      json
+// @Produce     json
+// @Param       id         path int               true "Account ID"
+// @Param       webhook_id path int               true "Webhook ID"
+// @Param       input      body domain.SetWebhook true "Webhook"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks/{webhook_id} [put]
+func (h *Handler) updateWebhook(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid webhook_id param")
+		return
+	}
+
+	var input domain.SetWebhook
+	if err := ctx.BindJSON(&input); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "User send invalid input body")
+		return
+	}
+	if err := input.Validate(); err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.serviceWebhook.Update(userId, accountId, id, input); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary     Delete Webhook
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description delete webhook of the account with its delivery log
+// @ID          delete-webhook
+// @Accept      json
+// @Produce     json
+// @Param       id         path int true "Account ID"
+// @Param       webhook_id path int true "Webhook ID"
+// @Success     200     {object} statusResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks/{webhook_id} [delete]
+func (h *Handler) deleteWebhook(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid webhook_id param")
+		return
+	}
+
+	if err := h.serviceWebhook.Delete(userId, accountId, id); err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary     Get Webhook Deliveries
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description get delivery log of the webhook with payloads and response statuses, newest first (default - last 24 hours)
+// @ID          get-webhook-deliveries
+// @Accept      json
+// @Produce     json
+// @Param       id         path  int    true  "Account ID"
+// @Param       webhook_id path  int    true  "Webhook ID"
+// @Param       status     query string false "Delivery status" Enums(pending, sent, failed)
+// @Param       from       query string false "Period start, RFC3339"
+// @Param       to         query string false "Period end, RFC3339"
+// @Success     200     {object} WebhookDeliveriesResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks/{webhook_id}/deliveries [get]
+func (h *Handler) getWebhookDeliveries(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid webhook_id param")
+		return
+	}
+
+	status := ctx.Query("status")
+	if status != "" && status != domain.DeliveryPending && status != domain.DeliverySent && status != domain.DeliveryFailed {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid status param")
+		return
+	}
+
+	from, to, err := parsePeriod(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid from/to param")
+		return
+	}
+
+	list, err := h.serviceWebhook.GetDeliveries(userId, accountId, id, status, from, to)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhookDeliveriesResponse{
+		Data: list,
+	})
+}
+
+// @Summary     Redeliver Webhook Event
+// @Security    ApiKeyAuth
+// @Tags        Webhooks
+// @Description queue the event of the delivery again with the same payload and event id; returns id of the new delivery
+// @ID          redeliver-webhook
+// @Accept      json
+// @Produce     json
+// @Param       id          path int true "Account ID"
+// @Param       webhook_id  path int true "Webhook ID"
+// @Param       delivery_id path int true "Delivery ID"
+// @Success     200     {object} idResponse
+// @Failure     400,404 {object} statusResponse
+// @Failure     500     {object} statusResponse
+// @Failure     default {object} statusResponse
+// @Router      /api/accounts/{id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+func (h *Handler) redeliverWebhook(ctx *gin.Context) {
+
+	userId, err := getUserIdFromContext(ctx)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	accountId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || accountId == 0 {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	id, err := strconv.Atoi(ctx.Param("webhook_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid webhook_id param")
+		return
+	}
+
+	deliveryId, err := strconv.Atoi(ctx.Param("delivery_id"))
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusBadRequest, "invalid delivery_id param")
+		return
+	}
+
+	newId, err := h.serviceWebhook.Redeliver(userId, accountId, id, deliveryId)
+	if err != nil {
+		h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, idResponse{
+		ID: newId,
+	})
+}
@@ -0,0 +1,113 @@
+// Package alerting - вычисление состояния пороговых правил по показаниям сенсора.
+//
+// Правило переходит ok -> pending при первом выходе значения за порог и pending -> firing,
+// если значение остаётся за порогом не меньше MinDuration. Из firing в ok правило возвращается,
+// только когда значение вернётся за порог с запасом Hysteresis.
+// Правило "нет данных" срабатывает, если показаний не было дольше NoData.
+package alerting
+
+import (
+	"time"
+)
+
+const (
+	KindAbove   = "above"
+	KindBelow   = "below"
+	KindOutside = "outside"
+	KindNoData  = "no_data"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusPending = "pending"
+	StatusFiring  = "firing"
+)
+
+type Transition int
+
+const (
+	None Transition = iota
+	Fired
+	Resolved
+)
+
+type Rule struct {
+	Kind        string
+	Low         float64
+	High        float64
+	Hysteresis  float64
+	MinDuration time.Duration
+	NoData      time.Duration
+}
+
+type State struct {
+	Status       string
+	PendingSince time.Time
+}
+
+// breached - значение за порогом; при firing порог сдвигается на гистерезис
+func (r Rule) breached(value float64, firing bool) bool {
+	h := 0.0
+	if firing {
+		h = r.Hysteresis
+	}
+
+	switch r.Kind {
+	case KindAbove:
+		return value > r.High-h
+	case KindBelow:
+		return value < r.Low+h
+	case KindOutside:
+		return value < r.Low+h || value > r.High-h
+	}
+	return false
+}
+
+// Evaluate применяет показание value, полученное в момент at, к состоянию правила
+func Evaluate(r Rule, s State, value float64, at time.Time) (State, Transition) {
+
+	if r.Kind == KindNoData {
+		// Любое показание снимает срабатывание "нет данных"
+		if s.Status == StatusFiring {
+			return State{Status: StatusOK}, Resolved
+		}
+		return State{Status: StatusOK}, None
+	}
+
+	switch s.Status {
+	case StatusFiring:
+		if r.breached(value, true) {
+			return s, None
+		}
+		return State{Status: StatusOK}, Resolved
+
+	case StatusPending:
+		if !r.breached(value, false) {
+			return State{Status: StatusOK}, None
+		}
+		if at.Sub(s.PendingSince) >= r.MinDuration {
+			return State{Status: StatusFiring, PendingSince: s.PendingSince}, Fired
+		}
+		return s, None
+
+	default:
+		if !r.breached(value, false) {
+			return State{Status: StatusOK}, None
+		}
+		if r.MinDuration <= 0 {
+			return State{Status: StatusFiring, PendingSince: at}, Fired
+		}
+		return State{Status: StatusPending, PendingSince: at}, None
+	}
+}
+
+// EvaluateNoData проверяет правило "нет данных" в момент now по времени последнего показания
+func EvaluateNoData(r Rule, s State, lastReading, now time.Time) (State, Transition) {
+	if r.Kind != KindNoData || r.NoData <= 0 || s.Status == StatusFiring {
+		return s, None
+	}
+	if now.Sub(lastReading) < r.NoData {
+		return s, None
+	}
+	return State{Status: StatusFiring, PendingSince: lastReading}, Fired
+}
@@ -0,0 +1,101 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+type step struct {
+	minute int
+	value  float64
+	status string
+	tr     Transition
+}
+
+func run(t *testing.T, r Rule, steps []step) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := State{Status: StatusOK}
+
+	for _, st := range steps {
+		var tr Transition
+		s, tr = Evaluate(r, s, st.value, t0.Add(time.Duration(st.minute)*time.Minute))
+		if s.Status != st.status || tr != st.tr {
+			t.Fatalf("\t%s\tAt minute %d value %v should be %s/%d, got %s/%d.", failed, st.minute, st.value, st.status, st.tr, s.Status, tr)
+		}
+	}
+}
+
+// TestEvaluate validates threshold rules with hysteresis and minimum duration.
+func TestEvaluate(t *testing.T) {
+
+	t.Log("Given the need to alert when water leaves a safe range.")
+	{
+		t.Logf("\tWhen temperature rises above 28 with hysteresis 0.5.")
+		{
+			run(t, Rule{Kind: KindAbove, High: 28, Hysteresis: 0.5}, []step{
+				{0, 27.9, StatusOK, None},
+				{1, 28.1, StatusFiring, Fired},
+				{2, 27.8, StatusFiring, None}, // в пределах гистерезиса
+				{3, 27.4, StatusOK, Resolved},
+			})
+			t.Logf("\t%s\tShould fire once and resolve below 27.5.", success)
+		}
+
+		t.Logf("\tWhen pH stays outside 7.8..8.4 for at least 10 minutes.")
+		{
+			run(t, Rule{Kind: KindOutside, Low: 7.8, High: 8.4, MinDuration: 10 * time.Minute}, []step{
+				{0, 7.7, StatusPending, None},
+				{5, 8.1, StatusOK, None}, // короткий выброс не срабатывает
+				{6, 8.5, StatusPending, None},
+				{16, 8.6, StatusFiring, Fired},
+				{17, 8.3, StatusOK, Resolved},
+			})
+			t.Logf("\t%s\tShould ignore short spikes and fire after 10 minutes.", success)
+		}
+
+		t.Logf("\tWhen temperature drops below 22.")
+		{
+			run(t, Rule{Kind: KindBelow, Low: 22, Hysteresis: 1}, []step{
+				{0, 21.5, StatusFiring, Fired},
+				{1, 22.5, StatusFiring, None},
+				{2, 23, StatusOK, Resolved},
+			})
+			t.Logf("\t%s\tShould resolve at 23.", success)
+		}
+	}
+}
+
+// TestEvaluateNoData validates the no-data rule.
+func TestEvaluateNoData(t *testing.T) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	r := Rule{Kind: KindNoData, NoData: time.Hour}
+
+	t.Log("Given the need to alert when a hub stops sending data.")
+	{
+		t.Logf("\tWhen there was no reading for an hour.")
+		{
+			s, tr := EvaluateNoData(r, State{Status: StatusOK}, t0, t0.Add(30*time.Minute))
+			if tr != None {
+				t.Fatalf("\t%s\tShould not fire after 30 minutes.", failed)
+			}
+			s, tr = EvaluateNoData(r, s, t0, t0.Add(time.Hour))
+			if tr != Fired || s.Status != StatusFiring {
+				t.Fatalf("\t%s\tShould fire after an hour, got %s/%d.", failed, s.Status, tr)
+			}
+			if _, tr = EvaluateNoData(r, s, t0, t0.Add(2*time.Hour)); tr != None {
+				t.Fatalf("\t%s\tShould fire only once.", failed)
+			}
+			t.Logf("\t%s\tShould fire once after an hour.", success)
+
+			if s, tr = Evaluate(r, s, 25, t0.Add(2*time.Hour)); tr != Resolved || s.Status != StatusOK {
+				t.Fatalf("\t%s\tShould resolve on a new reading, got %s/%d.", failed, s.Status, tr)
+			}
+			t.Logf("\t%s\tShould resolve on a new reading.", success)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package alerting
+
+import (
+	"errors"
+	"time"
+)
+
+// Статусы экземпляра оповещения: срабатывание правила открывает экземпляр,
+// возврат значения в норму или ручное действие - закрывает.
+const (
+	AlertFiring       = "firing"
+	AlertAcknowledged = "acknowledged"
+	AlertSnoozed      = "snoozed"
+	AlertResolved     = "resolved"
+)
+
+var (
+	ErrResolved     = errors.New("alert is already resolved")
+	ErrSnoozeInPast = errors.New("snooze time must be in the future")
+)
+
+type Instance struct {
+	Status       string
+	SnoozedUntil time.Time
+	LastNotified time.Time
+}
+
+// Acknowledge - кто-то занимается проблемой: повторные уведомления прекращаются
+func Acknowledge(in Instance) (Instance, error) {
+	if in.Status == AlertResolved {
+		return in, ErrResolved
+	}
+	in.Status = AlertAcknowledged
+	in.SnoozedUntil = time.Time{}
+	return in, nil
+}
+
+// Snooze откладывает повторные уведомления до until
+func Snooze(in Instance, until, now time.Time) (Instance, error) {
+	if in.Status == AlertResolved {
+		return in, ErrResolved
+	}
+	if !until.After(now) {
+		return in, ErrSnoozeInPast
+	}
+	in.Status = AlertSnoozed
+	in.SnoozedUntil = until
+	return in, nil
+}
+
+func Resolve(in Instance) (Instance, error) {
+	if in.Status == AlertResolved {
+		return in, ErrResolved
+	}
+	in.Status = AlertResolved
+	in.SnoozedUntil = time.Time{}
+	return in, nil
+}
+
+// Remind проверяет, пора ли напомнить об оповещении в момент now.
+// Отложенное оповещение по истечении срока снова становится firing и напоминается сразу;
+// firing напоминается раз в every (0 - не напоминать); подтверждённое - никогда.
+func Remind(in Instance, every time.Duration, now time.Time) (Instance, bool) {
+	switch in.Status {
+	case AlertSnoozed:
+		if now.Before(in.SnoozedUntil) {
+			return in, false
+		}
+		in.Status = AlertFiring
+		in.SnoozedUntil = time.Time{}
+	case AlertFiring:
+		if every <= 0 || now.Sub(in.LastNotified) < every {
+			return in, false
+		}
+	default:
+		return in, false
+	}
+
+	in.LastNotified = now
+	return in, true
+}
@@ -0,0 +1,93 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLifecycle validates acknowledge, snooze and resolve actions.
+func TestLifecycle(t *testing.T) {
+	now := time.Date(2022, 9, 1, 2, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to manage a firing alert.")
+	{
+		t.Logf("\tWhen the alert is snoozed and then acknowledged.")
+		{
+			in, err := Snooze(Instance{Status: AlertFiring}, now.Add(time.Hour), now)
+			if err != nil || in.Status != AlertSnoozed {
+				t.Fatalf("\t%s\tShould snooze, got %s : %v.", failed, in.Status, err)
+			}
+			if in, err = Acknowledge(in); err != nil || in.Status != AlertAcknowledged || !in.SnoozedUntil.IsZero() {
+				t.Fatalf("\t%s\tShould acknowledge, got %+v : %v.", failed, in, err)
+			}
+			t.Logf("\t%s\tShould snooze and acknowledge.", success)
+		}
+
+		t.Logf("\tWhen the snooze time is in the past.")
+		{
+			if _, err := Snooze(Instance{Status: AlertFiring}, now, now); err != ErrSnoozeInPast {
+				t.Fatalf("\t%s\tShould refuse, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould refuse.", success)
+		}
+
+		t.Logf("\tWhen the alert is already resolved.")
+		{
+			in, _ := Resolve(Instance{Status: AlertFiring})
+			if _, err := Acknowledge(in); err != ErrResolved {
+				t.Fatalf("\t%s\tShould refuse acknowledge, got %v.", failed, err)
+			}
+			if _, err := Resolve(in); err != ErrResolved {
+				t.Fatalf("\t%s\tShould refuse resolve, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould refuse actions.", success)
+		}
+	}
+}
+
+// TestRemind validates re-notification of open alerts.
+func TestRemind(t *testing.T) {
+	now := time.Date(2022, 9, 1, 2, 0, 0, 0, time.UTC)
+	every := 30 * time.Minute
+
+	t.Log("Given the need to remind about open alerts.")
+	{
+		t.Logf("\tWhen the alert is firing.")
+		{
+			in := Instance{Status: AlertFiring, LastNotified: now.Add(-10 * time.Minute)}
+			if _, ok := Remind(in, every, now); ok {
+				t.Fatalf("\t%s\tShould not remind after 10 minutes.", failed)
+			}
+			in.LastNotified = now.Add(-30 * time.Minute)
+			in, ok := Remind(in, every, now)
+			if !ok || !in.LastNotified.Equal(now) {
+				t.Fatalf("\t%s\tShould remind after 30 minutes.", failed)
+			}
+			if _, ok := Remind(in, 0, now.Add(time.Hour)); ok {
+				t.Fatalf("\t%s\tShould not remind when reminders are off.", failed)
+			}
+			t.Logf("\t%s\tShould remind every 30 minutes.", success)
+		}
+
+		t.Logf("\tWhen the alert is acknowledged.")
+		{
+			if _, ok := Remind(Instance{Status: AlertAcknowledged}, every, now.Add(24*time.Hour)); ok {
+				t.Fatalf("\t%s\tShould never remind.", failed)
+			}
+			t.Logf("\t%s\tShould never remind.", success)
+		}
+
+		t.Logf("\tWhen the alert is snoozed.")
+		{
+			in := Instance{Status: AlertSnoozed, SnoozedUntil: now.Add(time.Hour), LastNotified: now.Add(-time.Hour)}
+			if _, ok := Remind(in, every, now); ok {
+				t.Fatalf("\t%s\tShould not remind before the snooze ends.", failed)
+			}
+			in, ok := Remind(in, 0, now.Add(time.Hour))
+			if !ok || in.Status != AlertFiring {
+				t.Fatalf("\t%s\tShould fire again when the snooze ends, got %s.", failed, in.Status)
+			}
+			t.Logf("\t%s\tShould fire again when the snooze ends.", success)
+		}
+	}
+}
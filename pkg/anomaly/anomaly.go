@@ -0,0 +1,197 @@
+// Package anomaly - детекторы подозрительных показаний сенсоров:
+// превышение скорости изменения, выброс по скользящему z-score и "залипание" значения (flatline).
+//
+// Детекторы не хранят состояние: история показаний передаётся при каждом вызове.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	FlagRate     = "rate"
+	FlagZScore   = "zscore"
+	FlagFlatline = "flatline"
+)
+
+// Показание сенсора
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Настройки детекторов сенсора; нулевое значение выключает детектор
+type Config struct {
+	// Максимальное изменение значения за минуту
+	MaxRate float64
+
+	// Число предыдущих показаний и порог для z-score
+	ZWindow    int
+	ZThreshold float64
+
+	// Длительность неизменного значения, после которой показание помечается
+	Flatline time.Duration
+}
+
+func (c Config) Enabled() bool {
+	return c.MaxRate > 0 || (c.ZWindow > 1 && c.ZThreshold > 0) || c.Flatline > 0
+}
+
+// Срабатывание детектора
+type Finding struct {
+	Flag   string
+	Reason string
+}
+
+// RateOfChange сравнивает скорость изменения между соседними показаниями с пределом (в минуту)
+func RateOfChange(prev, cur Sample, maxPerMinute float64) (Finding, bool) {
+	if maxPerMinute <= 0 {
+		return Finding{}, false
+	}
+
+	minutes := cur.Time.Sub(prev.Time).Minutes()
+	delta := math.Abs(cur.Value - prev.Value)
+
+	// Показания с одинаковым временем сравниваем как пришедшие с интервалом в минуту
+	if minutes < 1.0/60 {
+		minutes = 1
+	}
+
+	rate := delta / minutes
+	if rate <= maxPerMinute {
+		return Finding{}, false
+	}
+
+	return Finding{
+		Flag:   FlagRate,
+		Reason: fmt.Sprintf("changed by %g in %s (%.3g/min, limit %g/min)", delta, roundDuration(cur.Time.Sub(prev.Time)), rate, maxPerMinute),
+	}, true
+}
+
+// ZScore сравнивает значение со средним по окну предыдущих показаний.
+// При нулевом разбросе окна детектор не срабатывает - такой случай ловит Flatline.
+func ZScore(window []float64, value, threshold float64) (Finding, bool) {
+	if threshold <= 0 || len(window) < 2 {
+		return Finding{}, false
+	}
+
+	var sum float64
+	for _, v := range window {
+		sum += v
+	}
+	mean := sum / float64(len(window))
+
+	var sq float64
+	for _, v := range window {
+		sq += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(sq / float64(len(window)-1))
+	if std == 0 {
+		return Finding{}, false
+	}
+
+	z := (value - mean) / std
+	if math.Abs(z) <= threshold {
+		return Finding{}, false
+	}
+
+	return Finding{
+		Flag:   FlagZScore,
+		Reason: fmt.Sprintf("z-score %.2f over last %d readings (mean %.4g, threshold %g)", z, len(window), mean, threshold),
+	}, true
+}
+
+// Flatline срабатывает, если значение не менялось с момента since дольше limit
+func Flatline(since, at time.Time, limit time.Duration) (Finding, bool) {
+	if limit <= 0 || since.IsZero() {
+		return Finding{}, false
+	}
+
+	d := at.Sub(since)
+	if d < limit {
+		return Finding{}, false
+	}
+
+	return Finding{
+		Flag:   FlagFlatline,
+		Reason: fmt.Sprintf("value unchanged for %s (limit %s)", roundDuration(d), limit),
+	}, true
+}
+
+// Check прогоняет показание cur через все включённые детекторы.
+//
+// history - предыдущие показания сенсора по возрастанию времени;
+// since - начало серии одинаковых значений, к которой относится последнее показание истории
+// (если неизвестно - берётся самое раннее совпадающее показание истории).
+func Check(cfg Config, history []Sample, cur Sample, since time.Time) []Finding {
+
+	var found []Finding
+
+	if len(history) == 0 {
+		return found
+	}
+	last := history[len(history)-1]
+
+	if f, ok := RateOfChange(last, cur, cfg.MaxRate); ok {
+		found = append(found, f)
+	}
+
+	if cfg.ZWindow > 1 {
+		window := history
+		if len(window) > cfg.ZWindow {
+			window = window[len(window)-cfg.ZWindow:]
+		}
+		values := make([]float64, len(window))
+		for i, s := range window {
+			values[i] = s.Value
+		}
+		if f, ok := ZScore(values, cur.Value, cfg.ZThreshold); ok {
+			found = append(found, f)
+		}
+	}
+
+	if cfg.Flatline > 0 && cur.Value == last.Value {
+		if since.IsZero() || since.After(last.Time) {
+			since = RunStart(history)
+		}
+		if f, ok := Flatline(since, cur.Time, cfg.Flatline); ok {
+			found = append(found, f)
+		}
+	}
+
+	return found
+}
+
+// RunStart возвращает время первого показания серии одинаковых значений в конце истории
+func RunStart(history []Sample) time.Time {
+	if len(history) == 0 {
+		return time.Time{}
+	}
+
+	i := len(history) - 1
+	for i > 0 && history[i-1].Value == history[i].Value {
+		i--
+	}
+	return history[i].Time
+}
+
+// Join собирает срабатывания в флаг и причину для хранения в одной строке
+func Join(found []Finding) (flag, reason string) {
+	flags := make([]string, len(found))
+	reasons := make([]string, len(found))
+	for i, f := range found {
+		flags[i] = f.Flag
+		reasons[i] = f.Flag + ": " + f.Reason
+	}
+	return strings.Join(flags, ","), strings.Join(reasons, "; ")
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
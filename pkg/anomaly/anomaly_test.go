@@ -0,0 +1,88 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func series(start time.Time, step time.Duration, values ...float64) []Sample {
+	list := make([]Sample, len(values))
+	for i, v := range values {
+		list[i] = Sample{Time: start.Add(time.Duration(i) * step), Value: v}
+	}
+	return list
+}
+
+func flags(found []Finding) string {
+	flag, _ := Join(found)
+	return flag
+}
+
+// TestCheck validates the detectors against typical probe failures.
+func TestCheck(t *testing.T) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	normal := series(t0, time.Minute, 25.0, 25.1, 24.9, 25.0, 25.2, 24.8, 25.1, 25.0)
+	next := t0.Add(8 * time.Minute)
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		history []Sample
+		cur     Sample
+		since   time.Time
+		want    string
+	}{
+		{"normal reading", Config{MaxRate: 1, ZWindow: 8, ZThreshold: 3, Flatline: time.Hour}, normal, Sample{next, 25.1}, time.Time{}, ""},
+		{"spike 25 -> 80", Config{MaxRate: 1, ZWindow: 8, ZThreshold: 3}, normal, Sample{next, 80}, time.Time{}, "rate,zscore"},
+		{"slow drift within rate limit", Config{MaxRate: 1}, normal, Sample{next.Add(time.Hour), 30}, time.Time{}, ""},
+		{"outlier by z-score only", Config{ZWindow: 8, ZThreshold: 3}, normal, Sample{next, 26}, time.Time{}, "zscore"},
+		{"flatline from history", Config{Flatline: 5 * time.Minute}, series(t0, time.Minute, 24, 7, 7, 7, 7, 7, 7, 7), Sample{next, 7}, time.Time{}, "flatline"},
+		{"flatline from stored run start", Config{Flatline: 24 * time.Hour}, series(t0, time.Minute, 7, 7), Sample{next, 7}, t0.Add(-24 * time.Hour), "flatline"},
+		{"value changed ends flatline", Config{Flatline: time.Minute}, series(t0, time.Minute, 7, 7, 7), Sample{next, 7.1}, t0, ""},
+		{"no history", Config{MaxRate: 1, Flatline: time.Minute}, nil, Sample{next, 80}, time.Time{}, ""},
+	}
+
+	t.Log("Given the need to flag suspicious sensor readings.")
+	{
+		for _, tt := range tests {
+			t.Logf("\tWhen checking %s.", tt.name)
+			{
+				got := flags(Check(tt.cfg, tt.history, tt.cur, tt.since))
+				if got != tt.want {
+					t.Fatalf("\t%s\tShould flag %q, got %q.", failed, tt.want, got)
+				}
+				t.Logf("\t%s\tShould flag %q.", success, tt.want)
+			}
+		}
+	}
+}
+
+// TestRunStart validates detection of the start of the trailing run of equal values.
+func TestRunStart(t *testing.T) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Log("Given the need to know how long a value has been unchanged.")
+	{
+		t.Logf("\tWhen the history ends with a run of equal values.")
+		{
+			got := RunStart(series(t0, time.Minute, 1, 2, 3, 3, 3))
+			if want := t0.Add(2 * time.Minute); !got.Equal(want) {
+				t.Fatalf("\t%s\tShould start at %v, got %v.", failed, want, got)
+			}
+			t.Logf("\t%s\tShould start at the first equal value.", success)
+		}
+
+		t.Logf("\tWhen the history is empty.")
+		{
+			if got := RunStart(nil); !got.IsZero() {
+				t.Fatalf("\t%s\tShould be zero time, got %v.", failed, got)
+			}
+			t.Logf("\t%s\tShould be zero time.", success)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package automation - вычисление условий и расписаний правил автоматизации.
+//
+// Условие выполняется, когда последнее значение сенсора удовлетворяет сравнению
+// не меньше For подряд. Правило с условиями срабатывает по фронту: когда все условия
+// начали выполняться; пока они выполняются, повторного срабатывания нет.
+// Правило с расписанием срабатывает в заданное время суток (и, если есть условия, - только при их выполнении).
+// От зацикливания (команда меняет показания, показания снова запускают правило) защищают
+// пауза между запусками и ограничение числа запусков в час.
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Операции сравнения
+const (
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+)
+
+var ErrUnknownOp = errors.New("op must be one of: >, >=, <, <=, ==, !=")
+
+type Condition struct {
+	Op    string
+	Value float64
+	For   time.Duration
+}
+
+func (c Condition) Validate() error {
+	switch c.Op {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual, OpEqual, OpNotEqual:
+	default:
+		return ErrUnknownOp
+	}
+	if c.For < 0 {
+		return errors.New("condition duration must not be negative")
+	}
+	return nil
+}
+
+func (c Condition) Match(v float64) bool {
+	switch c.Op {
+	case OpGreater:
+		return v > c.Value
+	case OpGreaterEqual:
+		return v >= c.Value
+	case OpLess:
+		return v < c.Value
+	case OpLessEqual:
+		return v <= c.Value
+	case OpEqual:
+		return v == c.Value
+	case OpNotEqual:
+		return v != c.Value
+	}
+	return false
+}
+
+// Состояние условия: последнее значение и с какого момента сравнение выполняется (нулевое - не выполняется)
+type ConditionState struct {
+	Known bool      `json:"known"`
+	Value float64   `json:"value"`
+	At    time.Time `json:"at"`
+	Since time.Time `json:"since,omitempty"`
+}
+
+// Observe учитывает показание сенсора в момент at; показания старше последнего пропускаются
+func Observe(c Condition, st ConditionState, v float64, at time.Time) ConditionState {
+	if st.Known && at.Before(st.At) {
+		return st
+	}
+
+	if !c.Match(v) {
+		st.Since = time.Time{}
+	} else if st.Since.IsZero() {
+		st.Since = at
+	}
+
+	st.Known, st.Value, st.At = true, v, at
+	return st
+}
+
+// Holds - условие выполняется к моменту now
+func Holds(c Condition, st ConditionState, now time.Time) bool {
+	return st.Known && !st.Since.IsZero() && now.Sub(st.Since) >= c.For
+}
+
+// AllHold - выполняются все условия; states соответствуют conds по индексу
+func AllHold(conds []Condition, states []ConditionState, now time.Time) bool {
+	if len(conds) == 0 || len(states) != len(conds) {
+		return false
+	}
+	for i, c := range conds {
+		if !Holds(c, states[i], now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Edge - правило с условиями срабатывает, когда условия начали выполняться (active - выполнялись раньше)
+func Edge(active, holds bool) bool {
+	return holds && !active
+}
+
+// Расписание: время суток в минутах от полуночи и дни недели (пусто - каждый день) в зоне Location
+type Schedule struct {
+	Minute   int
+	Weekdays []time.Weekday
+	Location *time.Location
+}
+
+// ParseClock разбирает время суток "HH:MM"
+func ParseClock(s string) (int, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, errors.New("time must be HH:MM")
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, errors.New("time must be HH:MM")
+	}
+	return h*60 + m, nil
+}
+
+func (s Schedule) day(d time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range s.Weekdays {
+		if w == d {
+			return true
+		}
+	}
+	return false
+}
+
+// Next возвращает первый момент расписания после after
+func (s Schedule) Next(after time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t := after.In(loc)
+	for i := 0; i <= 7; i++ {
+		d := t.AddDate(0, 0, i)
+		at := time.Date(d.Year(), d.Month(), d.Day(), s.Minute/60, s.Minute%60, 0, 0, loc)
+		if at.After(after) && s.day(at.Weekday()) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+// Due - момент расписания наступил в интервале (last, now]
+func (s Schedule) Due(last, now time.Time) bool {
+	next := s.Next(last)
+	return !next.IsZero() && !next.After(now)
+}
+
+// Ограничения частоты запусков правила
+type Limits struct {
+	Cooldown   time.Duration
+	MaxPerHour int
+}
+
+var (
+	ErrCooldown  = errors.New("cooldown: rule ran too recently")
+	ErrRateLimit = errors.New("rate limit: too many runs in the last hour")
+)
+
+// Allow проверяет, можно ли запустить правило в now; runs - моменты предыдущих запусков
+func Allow(l Limits, runs []time.Time, now time.Time) error {
+	var last time.Time
+	count := 0
+	for _, r := range runs {
+		if r.After(last) {
+			last = r
+		}
+		if now.Sub(r) < time.Hour {
+			count++
+		}
+	}
+
+	if l.Cooldown > 0 && !last.IsZero() && now.Sub(last) < l.Cooldown {
+		return ErrCooldown
+	}
+	if l.MaxPerHour > 0 && count >= l.MaxPerHour {
+		return ErrRateLimit
+	}
+	return nil
+}
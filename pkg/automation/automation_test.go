@@ -0,0 +1,152 @@
+package automation
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestConditions validates conditions held for a duration.
+func TestConditions(t *testing.T) {
+	start := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	c := Condition{Op: OpGreater, Value: 28, For: 10 * time.Minute}
+
+	t.Log("Given the need to turn on the fan when the temperature is above 28 for 10 minutes.")
+	{
+		t.Logf("\tWhen the temperature stays above 28.")
+		{
+			var st ConditionState
+			st = Observe(c, st, 28.5, start)
+			st = Observe(c, st, 28.7, start.Add(5*time.Minute))
+			if Holds(c, st, start.Add(9*time.Minute)) {
+				t.Fatalf("\t%s\tShould not hold after 9 minutes.", failed)
+			}
+			if !Holds(c, st, start.Add(10*time.Minute)) {
+				t.Fatalf("\t%s\tShould hold after 10 minutes.", failed)
+			}
+			t.Logf("\t%s\tShould hold after 10 minutes.", success)
+		}
+
+		t.Logf("\tWhen the temperature drops in between.")
+		{
+			var st ConditionState
+			st = Observe(c, st, 28.5, start)
+			st = Observe(c, st, 27.9, start.Add(5*time.Minute))
+			st = Observe(c, st, 28.5, start.Add(6*time.Minute))
+			if Holds(c, st, start.Add(10*time.Minute)) || !st.Since.Equal(start.Add(6*time.Minute)) {
+				t.Fatalf("\t%s\tShould restart the duration, since %s.", failed, st.Since)
+			}
+			t.Logf("\t%s\tShould restart the duration.", success)
+		}
+
+		t.Logf("\tWhen a late reading arrives.")
+		{
+			var st ConditionState
+			st = Observe(c, st, 28.5, start.Add(time.Minute))
+			st = Observe(c, st, 20, start)
+			if st.Value != 28.5 {
+				t.Fatalf("\t%s\tShould ignore the late reading, got %g.", failed, st.Value)
+			}
+			t.Logf("\t%s\tShould ignore the late reading.", success)
+		}
+
+		t.Logf("\tWhen all conditions are combined.")
+		{
+			ph := Condition{Op: OpLess, Value: 8}
+			states := []ConditionState{
+				Observe(c, ConditionState{}, 29, start),
+				Observe(ph, ConditionState{}, 8.2, start),
+			}
+			if AllHold([]Condition{c, ph}, states, start.Add(time.Hour)) {
+				t.Fatalf("\t%s\tShould not hold while pH is 8.2.", failed)
+			}
+			states[1] = Observe(ph, states[1], 7.9, start.Add(time.Minute))
+			if !AllHold([]Condition{c, ph}, states, start.Add(time.Hour)) {
+				t.Fatalf("\t%s\tShould hold when both conditions hold.", failed)
+			}
+			if !Edge(false, true) || Edge(true, true) {
+				t.Fatalf("\t%s\tShould fire only on the rising edge.", failed)
+			}
+			t.Logf("\t%s\tShould fire once when all conditions hold.", success)
+		}
+	}
+}
+
+// TestSchedule validates time of day schedules.
+func TestSchedule(t *testing.T) {
+	kiev, err := time.LoadLocation("Europe/Kiev")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+
+	t.Log("Given the need to switch lights on at 08:00.")
+	{
+		minute, err := ParseClock("08:00")
+		if err != nil || minute != 480 {
+			t.Fatalf("\t%s\tShould parse the clock, got %d : %v.", failed, minute, err)
+		}
+		for _, bad := range []string{"8:00", "24:00", "08:60", "08-00"} {
+			if _, err := ParseClock(bad); err == nil {
+				t.Fatalf("\t%s\tShould reject %q.", failed, bad)
+			}
+		}
+		t.Logf("\t%s\tShould parse HH:MM.", success)
+
+		s := Schedule{Minute: minute, Location: kiev}
+
+		t.Logf("\tWhen the job runs every minute.")
+		{
+			// 08:00 в Киеве 1 сентября - 05:00 UTC
+			at := time.Date(2022, 9, 1, 5, 0, 0, 0, time.UTC)
+			if s.Due(at.Add(-2*time.Minute), at.Add(-time.Minute)) {
+				t.Fatalf("\t%s\tShould not be due at 07:59.", failed)
+			}
+			if !s.Due(at.Add(-time.Minute), at) {
+				t.Fatalf("\t%s\tShould be due at 08:00.", failed)
+			}
+			if s.Due(at, at.Add(time.Minute)) {
+				t.Fatalf("\t%s\tShould not be due again at 08:01.", failed)
+			}
+			t.Logf("\t%s\tShould be due once at 08:00 local time.", success)
+		}
+
+		t.Logf("\tWhen only weekends are allowed.")
+		{
+			s.Weekdays = []time.Weekday{time.Saturday, time.Sunday}
+			// 1 сентября 2022 - четверг, следующая суббота - 3 сентября
+			next := s.Next(time.Date(2022, 9, 1, 5, 0, 0, 0, time.UTC))
+			if want := time.Date(2022, 9, 3, 8, 0, 0, 0, kiev); !next.Equal(want) {
+				t.Fatalf("\t%s\tShould run on Saturday, got %s.", failed, next)
+			}
+			t.Logf("\t%s\tShould run on Saturday.", success)
+		}
+	}
+}
+
+// TestAllow validates loop protection limits.
+func TestAllow(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+	l := Limits{Cooldown: 5 * time.Minute, MaxPerHour: 3}
+
+	t.Log("Given the need to stop rules from running in a loop.")
+	{
+		if err := Allow(l, nil, now); err != nil {
+			t.Fatalf("\t%s\tShould allow the first run : %v", failed, err)
+		}
+		if err := Allow(l, []time.Time{now.Add(-time.Minute)}, now); err != ErrCooldown {
+			t.Fatalf("\t%s\tShould wait for the cooldown, got %v.", failed, err)
+		}
+		runs := []time.Time{now.Add(-50 * time.Minute), now.Add(-30 * time.Minute), now.Add(-10 * time.Minute)}
+		if err := Allow(l, runs, now); err != ErrRateLimit {
+			t.Fatalf("\t%s\tShould limit runs per hour, got %v.", failed, err)
+		}
+		if err := Allow(l, runs[1:], now); err != nil {
+			t.Fatalf("\t%s\tShould allow after old runs expire : %v", failed, err)
+		}
+		t.Logf("\t%s\tShould enforce cooldown and hourly limit.", success)
+	}
+}
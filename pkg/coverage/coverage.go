@@ -0,0 +1,121 @@
+// Package coverage - поиск пропусков в показаниях сенсора и расчёт покрытия периода данными.
+//
+// Показание "покрывает" интервал ожидаемой периодичности после себя. Пропуском считается промежуток
+// между соседними показаниями (или границей периода), превышающий ожидаемый интервал больше чем
+// в Tolerance раз; непокрытой считается часть промежутка после ожидаемого интервала.
+package coverage
+
+import (
+	"sort"
+	"time"
+)
+
+// Во сколько раз промежуток должен превысить ожидаемый интервал, чтобы считаться пропуском
+const Tolerance = 2.0
+
+// Пропуск в данных [From, To)
+type Gap struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+func (g Gap) Duration() time.Duration {
+	return g.To.Sub(g.From)
+}
+
+// Результат расчёта за период
+type Result struct {
+	From     time.Time
+	To       time.Time
+	Interval time.Duration
+	Readings int
+	Gaps     []Gap
+	Missing  time.Duration
+}
+
+// Доля периода, покрытая данными, от 0 до 1
+func (r Result) Coverage() float64 {
+	total := r.To.Sub(r.From)
+	if total <= 0 {
+		return 0
+	}
+	return 1 - float64(r.Missing)/float64(total)
+}
+
+// LearnInterval оценивает периодичность показаний как медиану промежутков между ними.
+// Возвращает 0, если показаний меньше двух.
+func LearnInterval(times []time.Time) time.Duration {
+	if len(times) < 2 {
+		return 0
+	}
+
+	deltas := make([]time.Duration, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > 0 {
+			deltas = append(deltas, d)
+		}
+	}
+	if len(deltas) == 0 {
+		return 0
+	}
+
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i] < deltas[j] })
+
+	n := len(deltas)
+	if n%2 == 1 {
+		return deltas[n/2]
+	}
+	return (deltas[n/2-1] + deltas[n/2]) / 2
+}
+
+// Compute находит пропуски в показаниях за период [from, to).
+// times - моменты показаний по возрастанию; показания вне периода игнорируются.
+// При interval <= 0 весь период без показаний считается пропуском, а при наличии показаний - покрытым.
+func Compute(times []time.Time, from, to time.Time, interval time.Duration) Result {
+
+	res := Result{From: from, To: to, Interval: interval}
+	if !to.After(from) {
+		return res
+	}
+
+	var inside []time.Time
+	for _, t := range times {
+		if !t.Before(from) && t.Before(to) {
+			inside = append(inside, t)
+		}
+	}
+	res.Readings = len(inside)
+
+	if len(inside) == 0 {
+		res.Gaps = []Gap{{From: from, To: to}}
+		res.Missing = to.Sub(from)
+		return res
+	}
+	if interval <= 0 {
+		return res
+	}
+
+	limit := time.Duration(float64(interval) * Tolerance)
+
+	// Начало периода: первое показание ожидалось не позже чем через interval
+	if d := inside[0].Sub(from); d > limit {
+		res.addGap(from, inside[0])
+	}
+
+	for i := 1; i < len(inside); i++ {
+		if d := inside[i].Sub(inside[i-1]); d > limit {
+			res.addGap(inside[i-1].Add(interval), inside[i])
+		}
+	}
+
+	if d := to.Sub(inside[len(inside)-1]); d > limit {
+		res.addGap(inside[len(inside)-1].Add(interval), to)
+	}
+
+	return res
+}
+
+func (r *Result) addGap(from, to time.Time) {
+	r.Gaps = append(r.Gaps, Gap{From: from, To: to})
+	r.Missing += to.Sub(from)
+}
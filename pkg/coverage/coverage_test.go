@@ -0,0 +1,87 @@
+package coverage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func every(start time.Time, step time.Duration, n int) []time.Time {
+	list := make([]time.Time, n)
+	for i := range list {
+		list[i] = start.Add(time.Duration(i) * step)
+	}
+	return list
+}
+
+// TestLearnInterval validates estimation of the reporting interval.
+func TestLearnInterval(t *testing.T) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// Регулярные показания раз в 5 минут с одним пропуском в час
+	times := append(every(t0, 5*time.Minute, 12), every(t0.Add(2*time.Hour), 5*time.Minute, 12)...)
+
+	t.Log("Given the need to learn how often a sensor reports.")
+	{
+		t.Logf("\tWhen readings are regular with a single gap.")
+		{
+			if got := LearnInterval(times); got != 5*time.Minute {
+				t.Fatalf("\t%s\tShould learn 5m, got %v.", failed, got)
+			}
+			t.Logf("\t%s\tShould learn 5m.", success)
+		}
+
+		t.Logf("\tWhen there is only one reading.")
+		{
+			if got := LearnInterval(times[:1]); got != 0 {
+				t.Fatalf("\t%s\tShould be 0, got %v.", failed, got)
+			}
+			t.Logf("\t%s\tShould be 0.", success)
+		}
+	}
+}
+
+// TestCompute validates gap detection and coverage.
+func TestCompute(t *testing.T) {
+	t0 := time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)
+	day := t0.Add(24 * time.Hour)
+
+	// Показания раз в минуту, хаб молчал с 10:00 до 13:00
+	times := append(every(t0, time.Minute, 600), every(t0.Add(13*time.Hour), time.Minute, 660)...)
+
+	tests := []struct {
+		name     string
+		times    []time.Time
+		interval time.Duration
+		gaps     int
+		coverage float64
+	}{
+		{"full day", every(t0, time.Minute, 1440), time.Minute, 0, 1},
+		{"silent for three hours", times, time.Minute, 1, 0.875},
+		{"no readings", nil, time.Minute, 1, 0},
+		{"stopped in the evening", every(t0, time.Minute, 1200), time.Minute, 1, 1 - 240/1440.0},
+		{"started late", every(t0.Add(6*time.Hour), time.Minute, 1080), time.Minute, 1, 0.75},
+	}
+
+	t.Log("Given the need to find gaps in sensor data.")
+	{
+		for _, tt := range tests {
+			t.Logf("\tWhen the sensor was %s.", tt.name)
+			{
+				res := Compute(tt.times, t0, day, tt.interval)
+				if len(res.Gaps) != tt.gaps {
+					t.Fatalf("\t%s\tShould find %d gaps, got %v.", failed, tt.gaps, res.Gaps)
+				}
+				if math.Abs(res.Coverage()-tt.coverage) > 1e-9 {
+					t.Fatalf("\t%s\tShould cover %.4f, got %.4f.", failed, tt.coverage, res.Coverage())
+				}
+				t.Logf("\t%s\tShould find %d gaps and cover %.4f.", success, tt.gaps, tt.coverage)
+			}
+		}
+	}
+}
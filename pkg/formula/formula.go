@@ -0,0 +1,571 @@
+// Package formula разбирает и вычисляет выражения виртуальных сенсоров.
+//
+// Выражение состоит из чисел, ссылок на сенсоры вида s12 (где 12 - ID сенсора в БД),
+// операторов + - * / ^, скобок и функций avg, min, max, abs, sqrt, pow, round.
+//
+//	(s12 + s13 + s14) / 3
+//	s21 * (1 + 0.02 * (s22 - 25))
+//	avg(s12, s13, s14)
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ограничения выражения: длина - как у столбца sensors.formula, глубина вложенности
+// (скобки, функции, унарный минус, степень) - чтобы рекурсивный спуск не исчерпал стек
+const (
+	MaxLen   = 1024
+	MaxDepth = 64
+)
+
+var (
+	ErrTooLong     = fmt.Errorf("formula: expression is longer than %d bytes", MaxLen)
+	ErrTooDeep     = fmt.Errorf("formula: expression is nested deeper than %d levels", MaxDepth)
+	ErrEmpty       = errors.New("formula: empty expression")
+	ErrMissingRef  = errors.New("formula: no value for sensor")
+	ErrNotFinite   = errors.New("formula: result is not a finite number")
+	ErrDivByZero   = errors.New("formula: division by zero")
+	ErrCycleDetect = errors.New("formula: cyclic reference between sensors")
+)
+
+// Expr разобранное выражение
+type Expr struct {
+	src  string
+	root node
+	refs []int
+}
+
+// Parse разбирает выражение и проверяет его синтаксис
+func Parse(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, ErrEmpty
+	}
+	if len(src) > MaxLen {
+		return nil, ErrTooLong
+	}
+
+	p := parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+
+	refs := make(map[int]bool)
+	collectRefs(root, refs)
+
+	e := &Expr{src: src, root: root}
+	for id := range refs {
+		e.refs = append(e.refs, id)
+	}
+	sort.Ints(e.refs)
+
+	return e, nil
+}
+
+// String возвращает исходный текст выражения
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Refs возвращает отсортированный список ID сенсоров, на которые ссылается выражение
+func (e *Expr) Refs() []int {
+	return append([]int(nil), e.refs...)
+}
+
+// Eval вычисляет выражение по значениям сенсоров (ID сенсора => значение)
+func (e *Expr) Eval(values map[int]float64) (float64, error) {
+	v, err := e.root.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, ErrNotFinite
+	}
+	return v, nil
+}
+
+//_____________________________________________________________________________________________________________________
+
+type node interface {
+	eval(values map[int]float64) (float64, error)
+}
+
+type numNode float64
+
+func (n numNode) eval(map[int]float64) (float64, error) { return float64(n), nil }
+
+type refNode int
+
+func (n refNode) eval(values map[int]float64) (float64, error) {
+	v, ok := values[int(n)]
+	if !ok {
+		return 0, fmt.Errorf("%w s%d", ErrMissingRef, int(n))
+	}
+	return v, nil
+}
+
+type negNode struct{ x node }
+
+func (n negNode) eval(values map[int]float64) (float64, error) {
+	v, err := n.x.eval(values)
+	return -v, err
+}
+
+type binNode struct {
+	op   byte
+	l, r node
+}
+
+func (n binNode) eval(values map[int]float64) (float64, error) {
+	l, err := n.l.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.r.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, ErrDivByZero
+		}
+		return l / r, nil
+	case '^':
+		return math.Pow(l, r), nil
+	}
+	return 0, fmt.Errorf("formula: unknown operator %q", n.op)
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+// Поддерживаемые функции и допустимое число аргументов (-1 = любое, но не меньше одного)
+var functions = map[string]int{
+	"avg":   -1,
+	"min":   -1,
+	"max":   -1,
+	"abs":   1,
+	"sqrt":  1,
+	"round": 1,
+	"pow":   2,
+}
+
+func (n callNode) eval(values map[int]float64) (float64, error) {
+	args := make([]float64, 0, len(n.args))
+	for _, a := range n.args {
+		v, err := a.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+
+	switch n.name {
+	case "avg":
+		sum := 0.0
+		for _, v := range args {
+			sum += v
+		}
+		return sum / float64(len(args)), nil
+	case "min":
+		m := args[0]
+		for _, v := range args[1:] {
+			m = math.Min(m, v)
+		}
+		return m, nil
+	case "max":
+		m := args[0]
+		for _, v := range args[1:] {
+			m = math.Max(m, v)
+		}
+		return m, nil
+	case "abs":
+		return math.Abs(args[0]), nil
+	case "sqrt":
+		return math.Sqrt(args[0]), nil
+	case "round":
+		return math.Round(args[0]), nil
+	case "pow":
+		return math.Pow(args[0], args[1]), nil
+	}
+	return 0, fmt.Errorf("formula: unknown function %q", n.name)
+}
+
+func collectRefs(n node, refs map[int]bool) {
+	switch t := n.(type) {
+	case refNode:
+		refs[int(t)] = true
+	case negNode:
+		collectRefs(t.x, refs)
+	case binNode:
+		collectRefs(t.l, refs)
+		collectRefs(t.r, refs)
+	case callNode:
+		for _, a := range t.args {
+			collectRefs(a, refs)
+		}
+	}
+}
+
+//_____________________________________________________________________________________________________________________
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+// Рекурсивный спуск:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/") unary }
+//	unary  = "-" unary | power
+//	power  = atom [ "^" unary ]
+//	atom   = number | ref | func "(" expr { "," expr } ")" | "(" expr ")"
+type parser struct {
+	src   string
+	pos   int
+	tok   token
+	depth int // текущая глубина вложенности parseUnary
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("formula: position %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) next() error {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// Экспонента: 1e-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+				p.pos++
+			}
+		}
+		p.tok = token{kind: tokNum, text: p.src[start:p.pos], pos: start}
+
+	case unicode.IsLetter(rune(c)) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: strings.ToLower(p.src[start:p.pos]), pos: start}
+
+	case strings.IndexByte("+-*/^(),", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+
+	default:
+		p.tok = token{pos: start}
+		return p.errorf("unexpected character %q", c)
+	}
+
+	return nil
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		if p.tok.kind == tokEOF {
+			return p.errorf("expected %q, got end of expression", op)
+		}
+		return p.errorf("expected %q, got %q", op, p.tok.text)
+	}
+	return p.next()
+}
+
+func (p *parser) parseExpr() (node, error) {
+	l, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("+") || p.isOp("-") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		l = binNode{op: op, l: l, r: r}
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("*") || p.isOp("/") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binNode{op: op, l: l, r: r}
+	}
+
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	// Каждый вложенный уровень (скобки, аргумент функции, унарный минус, показатель степени) проходит здесь
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return nil, ErrTooDeep
+	}
+
+	if p.isOp("-") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x}, nil
+	}
+
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isOp("^") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		// Степень правоассоциативна: 2^3^2 = 2^(3^2)
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binNode{op: '^', l: base, r: exp}, nil
+	}
+
+	return base, nil
+}
+
+func (p *parser) parseAtom() (node, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokNum:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", tok.text)
+		}
+		return numNode(v), p.next()
+
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		if p.isOp("(") {
+			return p.parseCall(tok)
+		}
+
+		// Ссылка на сенсор: s<ID>
+		if len(tok.text) > 1 && tok.text[0] == 's' {
+			if id, err := strconv.Atoi(tok.text[1:]); err == nil && id > 0 {
+				return refNode(id), nil
+			}
+		}
+		p.tok = tok
+		return nil, p.errorf("unknown identifier %q", tok.text)
+
+	case tokOp:
+		if tok.text == "(" {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+		return nil, p.errorf("unexpected %q", tok.text)
+	}
+
+	return nil, p.errorf("unexpected end of expression")
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	arity, ok := functions[name.text]
+	if !ok {
+		p.tok = name
+		return nil, p.errorf("unknown function %q", name.text)
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var args []node
+	for {
+		a, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+
+		if !p.isOp(",") {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if arity > 0 && len(args) != arity {
+		p.tok = name
+		return nil, p.errorf("function %s expects %d argument(s), got %d", name.text, arity, len(args))
+	}
+
+	return callNode{name: name.text, args: args}, nil
+}
+
+//_____________________________________________________________________________________________________________________
+
+// TopoSort упорядочивает вершины графа зависимостей (ID => ID, от которых он зависит)
+// так, что каждая вершина идёт после своих зависимостей.
+// Зависимости, отсутствующие среди ключей графа, считаются внешними (физическими сенсорами).
+// При наличии цикла возвращается ErrCycleDetect и вершины, образующие цикл.
+func TopoSort(graph map[int][]int) ([]int, error) {
+	const (
+		white = iota
+		grey
+		black
+	)
+
+	keys := make([]int, 0, len(graph))
+	for id := range graph {
+		keys = append(keys, id)
+	}
+	sort.Ints(keys)
+
+	color := make(map[int]int, len(graph))
+	order := make([]int, 0, len(graph))
+	var stack []int
+
+	var visit func(id int) error
+	visit = func(id int) error {
+		color[id] = grey
+		stack = append(stack, id)
+
+		for _, dep := range graph[id] {
+			if _, internal := graph[dep]; !internal {
+				continue
+			}
+			switch color[dep] {
+			case grey:
+				// Вырезаем цикл из стека обхода
+				cycle := []int{dep}
+				for i := len(stack) - 1; i >= 0 && stack[i] != dep; i-- {
+					cycle = append([]int{stack[i]}, cycle...)
+				}
+				cycle = append([]int{dep}, cycle...)
+				return fmt.Errorf("%w: %s", ErrCycleDetect, formatCycle(cycle))
+			case white:
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		color[id] = black
+		order = append(order, id)
+		return nil
+	}
+
+	for _, id := range keys {
+		if color[id] == white {
+			if err := visit(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return order, nil
+}
+
+func formatCycle(cycle []int) string {
+	parts := make([]string, 0, len(cycle))
+	for _, id := range cycle {
+		parts = append(parts, fmt.Sprintf("s%d", id))
+	}
+	return strings.Join(parts, " -> ")
+}
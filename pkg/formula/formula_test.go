@@ -0,0 +1,207 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestEval validates parsing and evaluation of valid expressions.
+func TestEval(t *testing.T) {
+	values := map[int]float64{12: 24, 13: 26, 14: 25, 21: 35}
+
+	tests := []struct {
+		src  string
+		want float64
+		refs []int
+	}{
+		{"(s12 + s13 + s14) / 3", 25, []int{12, 13, 14}},
+		{"avg(s12, s13, s14)", 25, []int{12, 13, 14}},
+		{"s21 * (1 + 0.02 * (s14 - 25))", 35, []int{14, 21}},
+		{"-s12 + 2 * 3", -18, []int{12}},
+		{"2 ^ 3 ^ 2", 512, nil},
+		{"max(s12, s13) - min(s12, s13)", 2, []int{12, 13}},
+		{"round(sqrt(abs(-16)) * 1.4)", 6, nil},
+		{"pow(s12, 0.5) * 1e1", math.Sqrt(24) * 10, []int{12}},
+		{"S12 + s12", 48, []int{12}},
+	}
+
+	t.Log("Given the need to evaluate virtual sensor formulas.")
+	{
+		for _, tt := range tests {
+			t.Logf("\tWhen evaluating %q.", tt.src)
+			{
+				e, err := Parse(tt.src)
+				if err != nil {
+					t.Fatalf("\t%s\tShould parse without error : %s.", failed, err)
+				}
+				if !reflect.DeepEqual(e.Refs(), tt.refs) && !(len(e.Refs()) == 0 && len(tt.refs) == 0) {
+					t.Fatalf("\t%s\tShould reference %v, got %v.", failed, tt.refs, e.Refs())
+				}
+
+				got, err := e.Eval(values)
+				if err != nil {
+					t.Fatalf("\t%s\tShould evaluate without error : %s.", failed, err)
+				}
+				if math.Abs(got-tt.want) > 1e-9 {
+					t.Fatalf("\t%s\tShould evaluate to %v, got %v.", failed, tt.want, got)
+				}
+				t.Logf("\t%s\tShould evaluate to %v.", success, tt.want)
+			}
+		}
+	}
+}
+
+// TestParseErrors validates that invalid expressions are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"s12 +",
+		"(s12 + s13",
+		"s12 s13",
+		"foo(s12)",
+		"temperature + 1",
+		"abs(s1, s2)",
+		"pow(s1)",
+		"s0 + 1",
+		"s12 # 2",
+	}
+
+	t.Log("Given the need to reject invalid formulas.")
+	{
+		for _, src := range tests {
+			t.Logf("\tWhen parsing %q.", src)
+			{
+				if _, err := Parse(src); err == nil {
+					t.Fatalf("\t%s\tShould return an error.", failed)
+				} else {
+					t.Logf("\t%s\tShould return an error : %s.", success, err)
+				}
+			}
+		}
+	}
+}
+
+// TestEvalErrors validates runtime errors of evaluation.
+func TestEvalErrors(t *testing.T) {
+	t.Log("Given the need to report evaluation errors.")
+	{
+		t.Log("\tWhen a referenced sensor has no value.")
+		{
+			e, _ := Parse("s1 + s2")
+			if _, err := e.Eval(map[int]float64{1: 1}); !errors.Is(err, ErrMissingRef) {
+				t.Fatalf("\t%s\tShould return ErrMissingRef, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrMissingRef.", success)
+		}
+
+		t.Log("\tWhen dividing by zero.")
+		{
+			e, _ := Parse("s1 / (s2 - s2)")
+			if _, err := e.Eval(map[int]float64{1: 1, 2: 5}); !errors.Is(err, ErrDivByZero) {
+				t.Fatalf("\t%s\tShould return ErrDivByZero, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrDivByZero.", success)
+		}
+
+		t.Log("\tWhen the result is not finite.")
+		{
+			e, _ := Parse("sqrt(s1)")
+			if _, err := e.Eval(map[int]float64{1: -1}); !errors.Is(err, ErrNotFinite) {
+				t.Fatalf("\t%s\tShould return ErrNotFinite, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrNotFinite.", success)
+		}
+	}
+}
+
+// TestTopoSort validates ordering of dependent formulas and cycle detection.
+func TestTopoSort(t *testing.T) {
+	t.Log("Given the need to order virtual sensors by their dependencies.")
+	{
+		t.Log("\tWhen the graph has no cycles.")
+		{
+			// 30 = f(20, 1), 20 = f(10, 2), 10 = f(1)
+			order, err := TopoSort(map[int][]int{30: {20, 1}, 20: {10, 2}, 10: {1}})
+			if err != nil {
+				t.Fatalf("\t%s\tShould order without error : %s.", failed, err)
+			}
+			if !reflect.DeepEqual(order, []int{10, 20, 30}) {
+				t.Fatalf("\t%s\tShould order as [10 20 30], got %v.", failed, order)
+			}
+			t.Logf("\t%s\tShould order as [10 20 30].", success)
+		}
+
+		t.Log("\tWhen the graph has a cycle.")
+		{
+			_, err := TopoSort(map[int][]int{10: {20}, 20: {30}, 30: {10, 1}})
+			if !errors.Is(err, ErrCycleDetect) {
+				t.Fatalf("\t%s\tShould return ErrCycleDetect, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrCycleDetect : %s.", success, err)
+		}
+
+		t.Log("\tWhen a formula references itself.")
+		{
+			if _, err := TopoSort(map[int][]int{10: {10}}); !errors.Is(err, ErrCycleDetect) {
+				t.Fatalf("\t%s\tShould return ErrCycleDetect, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrCycleDetect.", success)
+		}
+	}
+}
+
+// TestLimits validates that overly long and deeply nested expressions are rejected before the stack runs out.
+func TestLimits(t *testing.T) {
+	t.Log("Given the need to bound the size of formulas.")
+	{
+		t.Logf("\tWhen the expression is longer than %d bytes.", MaxLen)
+		{
+			src := "s1" + strings.Repeat(" + s1", MaxLen/5+1)
+			if _, err := Parse(src); !errors.Is(err, ErrTooLong) {
+				t.Fatalf("\t%s\tShould return ErrTooLong, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould return ErrTooLong.", success)
+		}
+
+		t.Logf("\tWhen the expression is nested %d levels deep.", MaxDepth)
+		{
+			src := strings.Repeat("(", MaxDepth-1) + "s1" + strings.Repeat(")", MaxDepth-1)
+			if _, err := Parse(src); err != nil {
+				t.Fatalf("\t%s\tShould parse without error : %s.", failed, err)
+			}
+			t.Logf("\t%s\tShould parse without error.", success)
+		}
+
+		t.Logf("\tWhen the expression is nested deeper than %d levels.", MaxDepth)
+		{
+			for _, src := range []string{
+				strings.Repeat("(", MaxDepth) + "s1" + strings.Repeat(")", MaxDepth),
+				strings.Repeat("abs(", MaxDepth) + "s1" + strings.Repeat(")", MaxDepth),
+				strings.Repeat("-", MaxDepth) + "s1",
+				"s1" + strings.Repeat("^2", MaxDepth),
+			} {
+				if _, err := Parse(src); !errors.Is(err, ErrTooDeep) {
+					t.Fatalf("\t%s\tShould return ErrTooDeep for %q, got %v.", failed, src, err)
+				}
+			}
+			t.Logf("\t%s\tShould return ErrTooDeep.", success)
+		}
+
+		t.Log("\tWhen the expression is millions of nested parentheses.")
+		{
+			src := strings.Repeat("(", 5000000) + "s1" + strings.Repeat(")", 5000000)
+			if _, err := Parse(src); err == nil {
+				t.Fatalf("\t%s\tShould return an error.", failed)
+			}
+			t.Logf("\t%s\tShould return an error without exhausting the stack.", success)
+		}
+	}
+}
@@ -7,10 +7,8 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
-const (
-	signingKey = "c3ert#5vqzpm34&s6hhie8ngt[is" // Случайные символы для
-	tokenTTL   = 12 * time.Hour
-)
+// Ключи подписи - см. keys.go
+const tokenTTL = 15 * time.Minute // сеанс продлевается токеном обновления
 
 // Структура со стандартным Claims и с добавленным полем id пользователя
 // Где будет сохранятся всё о токене
@@ -19,56 +17,144 @@ type tokenClaims struct {
 	UserId int `json:"user_id"`
 }
 
-// Запросить токен пользователя
-func GenerateToken(user_id int) (string, error) {
+// Запросить токен пользователя. jti - уникальный ID токена, по нему токен можно отозвать.
+// Возвращает токен и время окончания его действия.
+func GenerateToken(user_id int, jti string) (string, time.Time, error) {
+
+	now := time.Now()
+	expiresAt := now.Add(tokenTTL)
 
-	// Генерируем токен из Стандартной подписи и Claims
+	// Генерируем токен из Стандартной подписи и Claims и подписываем текущим ключом
 	// Claims - JSON объект с набором полей
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
+	signed, err := signToken(&tokenClaims{
 		jwt.StandardClaims{
-			// ExpiresAt = на 12 часов болше текущего времени
-			// т.е. токен перестанет быть валидным через 12 часов
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
+			Id: jti,
+			// ExpiresAt = на tokenTTL больше текущего времени
+			// т.е. токен перестанет быть валидным через tokenTTL
+			ExpiresAt: expiresAt.Unix(),
 			// Время генерации токена
-			IssuedAt: time.Now().Unix(),
+			IssuedAt: now.Unix(),
 		},
 		user_id,
 	})
 
-	// Подпишем и вернём токен с ключём подписи signingKey. Для расшифровки он же.
-	return token.SignedString([]byte(signingKey))
+	return signed, time.Unix(expiresAt.Unix(), 0), err
+}
+
+// Данные токена доступа
+type Access struct {
+	UserId    int
+	Id        string // jti
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 // Метод ParseToken принимает token в качестве аргумента
 // и возвращает id пользователя при успешном парcинге
 func ParseToken(accessToken string) (int, error) {
+	access, err := ParseAccessToken(accessToken)
+	if err != nil {
+		return 0, err
+	}
+	return access.UserId, nil
+}
+
+// ParseAccessToken проверяет токен доступа и возвращает id пользователя, jti и время выдачи и окончания токена
+func ParseAccessToken(accessToken string) (Access, error) {
 
 	// Вызываем функцию ParseWithClaims из библиотеки jwt, которая принимает:
 	//   - token,
 	//   - структуру Claims,
-	//   - функцию которая возвращает ключ подписи или ошибку
-	// В этой функции нам нужно проверить метод подписи токена.
-	// Если это не HMAC то мы возвращаем ошибку
-	// А если всё О'кей то ключ подписи
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-
-		return []byte(signingKey), nil
-	})
+	//   - функцию которая возвращает ключ проверки или ошибку
+	// keyFunc находит ключ по kid из заголовка и проверяет, что метод подписи - метод этого ключа
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, keyFunc)
 
 	if err != nil {
-		return 0, err
+		return Access{}, err
 	}
 
 	// Функция ParseWithClaims возвращает объект token в котором есть поле Claims типа интерфейс
 	// Приведём его к нашей структуре и проверим всё ли хорошо
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("token claims are not of type *tokenClaims")
+		return Access{}, errors.New("token claims are not of type *tokenClaims")
+	}
+
+	// Одноразовые токены (приглашения, сброс пароля) подписаны тем же ключом, но не дают доступа к API
+	if claims.Audience != "" || claims.UserId == 0 {
+		return Access{}, errors.New("token is not an access token")
 	}
 
 	// Возвращаем id пользователя при успешном парcинге token
-	return claims.UserId, nil
+	return Access{
+		UserId:    claims.UserId,
+		Id:        claims.Id,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+// --------------------------------- Одноразовые токены -----------------------------------
+
+// Назначение одноразовых токенов (поле aud): токен одного назначения не принимается для другого
+const (
+	inviteAudience        = "invite"
+	passwordResetAudience = "password-reset"
+)
+
+// Одноразовый токен: ID объекта и одноразовая часть, хеш которой хранится в БД
+type onceClaims struct {
+	jwt.StandardClaims
+	Id    int    `json:"id"`
+	Nonce string `json:"nonce"`
+}
+
+func generateOnceToken(audience string, id int, nonce string, expiresAt time.Time) (string, error) {
+
+	return signToken(&onceClaims{
+		jwt.StandardClaims{
+			Audience:  audience,
+			ExpiresAt: expiresAt.Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		id,
+		nonce,
+	})
+}
+
+// Проверка подписи, срока действия и назначения; возвращает ID объекта и одноразовую часть
+func parseOnceToken(audience, onceToken string) (int, string, error) {
+
+	token, err := jwt.ParseWithClaims(onceToken, &onceClaims{}, keyFunc)
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	claims, ok := token.Claims.(*onceClaims)
+	if !ok || !claims.VerifyAudience(audience, true) || claims.Id == 0 || claims.Nonce == "" {
+		return 0, "", errors.New("token is not a " + audience + " token")
+	}
+
+	return claims.Id, claims.Nonce, nil
+}
+
+// GenerateInviteToken - подписанный токен приглашения, действующий до expiresAt
+func GenerateInviteToken(inviteId int, nonce string, expiresAt time.Time) (string, error) {
+	return generateOnceToken(inviteAudience, inviteId, nonce, expiresAt)
+}
+
+// ParseInviteToken возвращает ID приглашения и одноразовую часть
+func ParseInviteToken(inviteToken string) (int, string, error) {
+	return parseOnceToken(inviteAudience, inviteToken)
+}
+
+// GeneratePasswordResetToken - подписанный токен сброса пароля пользователя, действующий до expiresAt
+func GeneratePasswordResetToken(userId int, nonce string, expiresAt time.Time) (string, error) {
+	return generateOnceToken(passwordResetAudience, userId, nonce, expiresAt)
+}
+
+// ParsePasswordResetToken возвращает ID пользователя и одноразовую часть
+func ParsePasswordResetToken(resetToken string) (int, string, error) {
+	return parseOnceToken(passwordResetAudience, resetToken)
 }
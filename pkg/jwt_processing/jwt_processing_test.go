@@ -0,0 +1,83 @@
+package jwt_processing
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestOnceTokens(t *testing.T) {
+
+	t.Log("Given the need to issue signed single-use tokens.")
+	{
+		t.Logf("\tWhen the invite token is valid.")
+		{
+			token, err := GenerateInviteToken(3, "nonce", time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+
+			id, nonce, err := ParseInviteToken(token)
+			if err != nil || id != 3 || nonce != "nonce" {
+				t.Fatalf("\t%s\tShould return invite 3 with its nonce, got %d %q %v.", failed, id, nonce, err)
+			}
+			t.Logf("\t%s\tShould return invite ID and nonce.", success)
+
+			if _, err := ParseToken(token); err == nil {
+				t.Fatalf("\t%s\tShould not accept the invite token as an access token.", failed)
+			}
+			t.Logf("\t%s\tShould not accept the invite token as an access token.", success)
+		}
+
+		t.Logf("\tWhen the invite token is expired.")
+		{
+			token, err := GenerateInviteToken(3, "nonce", time.Now().Add(-time.Minute))
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+			if _, _, err := ParseInviteToken(token); err == nil {
+				t.Fatalf("\t%s\tShould reject the expired token.", failed)
+			}
+			t.Logf("\t%s\tShould reject the expired token.", success)
+		}
+
+		t.Logf("\tWhen an access token is given.")
+		{
+			token, _, err := GenerateToken(1, "jti-1")
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+			if _, _, err := ParseInviteToken(token); err == nil {
+				t.Fatalf("\t%s\tShould not accept the access token as an invite.", failed)
+			}
+			t.Logf("\t%s\tShould not accept the access token as an invite.", success)
+
+			access, err := ParseAccessToken(token)
+			if err != nil || access.UserId != 1 || access.Id != "jti-1" {
+				t.Fatalf("\t%s\tShould return user 1 with jti-1, got %+v %v.", failed, access, err)
+			}
+			t.Logf("\t%s\tShould still parse as an access token with its jti.", success)
+		}
+
+		t.Logf("\tWhen a password reset token is given.")
+		{
+			token, err := GeneratePasswordResetToken(1, "nonce", time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+			if id, nonce, err := ParsePasswordResetToken(token); err != nil || id != 1 || nonce != "nonce" {
+				t.Fatalf("\t%s\tShould return user 1 with the nonce, got %d %q %v.", failed, id, nonce, err)
+			}
+			t.Logf("\t%s\tShould return user ID and nonce.", success)
+
+			if _, _, err := ParseInviteToken(token); err == nil {
+				t.Fatalf("\t%s\tShould not accept the reset token as an invite.", failed)
+			}
+			t.Logf("\t%s\tShould not accept the reset token as an invite.", success)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package jwt_processing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Ключи подписи токенов. Набор ключей задаётся в конфиге (секция jwt), каждый ключ - с kid,
+// алгоритмом (HS256, RS256, EdDSA) и временем начала подписи not_before.
+// Подписывает самый новый из начавших действовать ключей, его kid записывается в заголовок токена.
+// Прежний ключ после начала подписи следующим ещё rotation_overlap принимает выданные им токены.
+// Открытые ключи RS256 и EdDSA публикуются в /.well-known/jwks.json, в том числе будущие -
+// так другие сервисы узнают о новом ключе до того, как он начнёт подписывать.
+
+const (
+	// Перекрытие по умолчанию покрывает самый долгий токен - приглашение (7 суток)
+	defaultRotationOverlap = 7 * 24 * time.Hour
+
+	minSecretLen  = 32
+	minRSAKeyBits = 2048
+)
+
+// Ключ в конфиге: секрет HS256 задаётся в secret или файлом, ключи RS256 и EdDSA - файлом PEM.
+// Для проверки токенов прежних ключей достаточно открытого ключа (PUBLIC KEY).
+type KeyConfig struct {
+	ID        string `mapstructure:"kid"`
+	Algorithm string `mapstructure:"alg"` // HS256, RS256, EdDSA; по умолчанию - по типу ключа
+	Secret    string `mapstructure:"secret"`
+	File      string `mapstructure:"file"`
+	NotBefore string `mapstructure:"not_before"` // RFC 3339; пусто - действует сразу
+}
+
+type Config struct {
+	Keys            []KeyConfig   `mapstructure:"keys"`
+	RotationOverlap time.Duration `mapstructure:"rotation_overlap"`
+	// Без ключей - случайный ключ до перезапуска (NewEphemeralKeySet), только для разработки
+	Ephemeral bool `mapstructure:"ephemeral"`
+}
+
+type Key struct {
+	ID        string
+	NotBefore time.Time
+	method    jwt.SigningMethod
+	signKey   interface{} // nil - ключ только для проверки
+	verifyKey interface{}
+}
+
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+type KeySet struct {
+	keys    []*Key // от новых к старым
+	overlap time.Duration
+}
+
+// LoadKeys читает ключи из конфига и файлов
+func LoadKeys(cfg Config) (*KeySet, error) {
+
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("jwt: no signing keys configured")
+	}
+
+	ks := &KeySet{overlap: cfg.RotationOverlap}
+	if ks.overlap <= 0 {
+		ks.overlap = defaultRotationOverlap
+	}
+
+	ids := map[string]bool{}
+	for _, kc := range cfg.Keys {
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("jwt: duplicate key %s", key.ID)
+		}
+		ids[key.ID] = true
+		ks.keys = append(ks.keys, key)
+	}
+
+	sort.SliceStable(ks.keys, func(i, j int) bool {
+		return ks.keys[i].NotBefore.After(ks.keys[j].NotBefore)
+	})
+
+	now := time.Now()
+	for i, key := range ks.keys {
+		if i > 0 && key.NotBefore.Equal(ks.keys[i-1].NotBefore) {
+			return nil, fmt.Errorf("jwt: keys %s and %s have the same not_before", ks.keys[i-1].ID, key.ID)
+		}
+		// Действующий и будущие ключи подписывают токены
+		if key.signKey == nil {
+			return nil, fmt.Errorf("jwt: key %s has no private key to sign tokens", key.ID)
+		}
+		if !key.NotBefore.After(now) {
+			return ks, nil
+		}
+	}
+
+	return nil, errors.New("jwt: no key is active yet, check not_before")
+}
+
+// NewEphemeralKeySet - случайный ключ HS256 на время работы процесса: токены не переживают
+// перезапуск и не принимаются другими экземплярами. Только для разработки.
+func NewEphemeralKeySet() *KeySet {
+	secret := make([]byte, minSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+
+	return &KeySet{
+		keys: []*Key{{
+			ID:        "ephemeral-" + hex.EncodeToString(secret[:4]),
+			method:    jwt.SigningMethodHS256,
+			signKey:   secret,
+			verifyKey: secret,
+		}},
+		overlap: defaultRotationOverlap,
+	}
+}
+
+func loadKey(kc KeyConfig) (*Key, error) {
+
+	key := &Key{ID: strings.TrimSpace(kc.ID)}
+	if key.ID == "" {
+		return nil, errors.New("jwt: key kid is required")
+	}
+
+	if kc.NotBefore != "" {
+		notBefore, err := time.Parse(time.RFC3339, kc.NotBefore)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %s: not_before: %s", key.ID, err.Error())
+		}
+		key.NotBefore = notBefore
+	}
+
+	if (kc.Secret == "") == (kc.File == "") {
+		return nil, fmt.Errorf("jwt: key %s: exactly one of secret and file is required", key.ID)
+	}
+
+	secret := []byte(kc.Secret)
+	if kc.File != "" {
+		data, err := os.ReadFile(kc.File)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %s: %s", key.ID, err.Error())
+		}
+
+		if block, _ := pem.Decode(data); block != nil {
+			if err := key.setPEM(block); err != nil {
+				return nil, fmt.Errorf("jwt: key %s: %s", key.ID, err.Error())
+			}
+			secret = nil
+		} else {
+			secret = []byte(strings.TrimSpace(string(data)))
+		}
+	}
+
+	if secret != nil {
+		if len(secret) < minSecretLen {
+			return nil, fmt.Errorf("jwt: key %s: secret must be at least %d bytes", key.ID, minSecretLen)
+		}
+		key.method, key.signKey, key.verifyKey = jwt.SigningMethodHS256, secret, secret
+	}
+
+	if kc.Algorithm != "" && kc.Algorithm != key.Algorithm() {
+		return nil, fmt.Errorf("jwt: key %s: alg %s does not match the %s key", key.ID, kc.Algorithm, key.Algorithm())
+	}
+
+	return key, nil
+}
+
+// Закрытый ключ RSA (PKCS #1, PKCS #8) или Ed25519 (PKCS #8), либо открытый ключ для проверки
+func (k *Key) setPEM(block *pem.Block) error {
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.signKey, k.verifyKey = jwt.SigningMethodRS256, key, &key.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.verifyKey = jwt.SigningMethodRS256, key
+	case ed25519.PrivateKey:
+		k.method, k.signKey, k.verifyKey = signingMethodEdDSA, key, key.Public()
+	case ed25519.PublicKey:
+		k.method, k.verifyKey = signingMethodEdDSA, key
+	default:
+		return fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if pub, ok := k.verifyKey.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+	}
+	return nil
+}
+
+// Ключ подписи на момент now
+func (ks *KeySet) signing(now time.Time) (*Key, error) {
+	for _, key := range ks.keys {
+		if !key.NotBefore.After(now) {
+			return key, nil
+		}
+	}
+	return nil, errors.New("jwt: no active signing key")
+}
+
+// Ключ kid принимается, пока следующий за ним ключ подписывает не дольше периода перекрытия
+func (ks *KeySet) verifying(kid string, now time.Time) (*Key, bool) {
+	for i, key := range ks.keys {
+		if key.ID != kid {
+			continue
+		}
+		if i > 0 && !now.Before(ks.keys[i-1].NotBefore.Add(ks.overlap)) {
+			return nil, false
+		}
+		return key, true
+	}
+	return nil, false
+}
+
+// --------------------------------- Текущий набор ключей -----------------------------------
+
+var (
+	keysMu sync.RWMutex
+	keys   = NewEphemeralKeySet()
+)
+
+// SetKeys заменяет набор ключей (при запуске приложения)
+func SetKeys(ks *KeySet) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = ks
+}
+
+func currentKeys() *KeySet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys
+}
+
+// Подписать claims текущим ключом; kid ключа - в заголовке токена
+func signToken(claims jwt.Claims) (string, error) {
+
+	key, err := currentKeys().signing(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signKey)
+}
+
+// Ключ проверки по kid из заголовка; алгоритм токена должен совпадать с алгоритмом ключа
+func keyFunc(token *jwt.Token) (interface{}, error) {
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := currentKeys().verifying(kid, time.Now())
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.Algorithm() {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key.verifyKey, nil
+}
+
+// --------------------------------- JWKS -----------------------------------
+
+// Открытый ключ в формате JWK (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	Kid string `json:"kid" example:"hub-2026-10"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty" example:"AQAB"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys - открытые ключи RS256 и EdDSA, которыми сейчас можно проверить токены,
+// и будущие ключи. Секреты HS256 не публикуются.
+func PublicKeys() JWKS {
+
+	ks := currentKeys()
+	now := time.Now()
+
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if _, ok := ks.verifying(key.ID, now); !ok {
+			continue
+		}
+
+		jwk := JWK{Use: "sig", Alg: key.Algorithm(), Kid: key.ID}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
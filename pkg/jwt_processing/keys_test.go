@@ -0,0 +1,179 @@
+package jwt_processing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Записать ключ в файл PEM во временном каталоге
+func writeKey(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestKeySet(t *testing.T) {
+	defer SetKeys(NewEphemeralKeySet())
+
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDer, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edFile := writeKey(t, "ed.pem", "PRIVATE KEY", edDer)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaFile := writeKey(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate))
+	rsaPublicDer, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublicFile := writeKey(t, "rsa.pub", "PUBLIC KEY", rsaPublicDer)
+
+	now := time.Now().UTC()
+	hourAgo := now.Add(-time.Hour).Format(time.RFC3339)
+	twoHoursAgo := now.Add(-2 * time.Hour).Format(time.RFC3339)
+
+	t.Log("Given the need to sign tokens with configured and rotated keys.")
+	{
+		t.Logf("\tWhen an EdDSA key is configured.")
+		{
+			ks, err := LoadKeys(Config{Keys: []KeyConfig{{ID: "ed-1", File: edFile}}})
+			if err != nil {
+				t.Fatalf("\t%s\tShould load the key : %v", failed, err)
+			}
+			SetKeys(ks)
+
+			token, _, err := GenerateToken(1, "jti-1")
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+			if !strings.HasPrefix(token, "eyJhbGciOiJFZERTQSIsImtpZCI6ImVkLTEi") {
+				t.Fatalf("\t%s\tShould sign with EdDSA and kid ed-1, got %s.", failed, token)
+			}
+			if access, err := ParseAccessToken(token); err != nil || access.UserId != 1 {
+				t.Fatalf("\t%s\tShould parse the token, got %+v %v.", failed, access, err)
+			}
+			t.Logf("\t%s\tShould sign and verify with the EdDSA key.", success)
+
+			jwks := PublicKeys()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "ed-1" || jwks.Keys[0].Kty != "OKP" || jwks.Keys[0].X == "" {
+				t.Fatalf("\t%s\tShould publish the Ed25519 public key, got %+v.", failed, jwks)
+			}
+			t.Logf("\t%s\tShould publish the Ed25519 public key.", success)
+		}
+
+		t.Logf("\tWhen the RS256 key is rotated to an EdDSA key.")
+		{
+			old, err := LoadKeys(Config{Keys: []KeyConfig{{ID: "rsa-1", Algorithm: "RS256", File: rsaFile, NotBefore: twoHoursAgo}}})
+			if err != nil {
+				t.Fatalf("\t%s\tShould load the key : %v", failed, err)
+			}
+			SetKeys(old)
+			oldToken, err := GenerateInviteToken(3, "nonce", now.Add(time.Hour))
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+
+			rotated, err := LoadKeys(Config{
+				RotationOverlap: 2 * time.Hour,
+				Keys: []KeyConfig{
+					{ID: "rsa-1", File: rsaPublicFile, NotBefore: twoHoursAgo},
+					{ID: "ed-2", Algorithm: "EdDSA", File: edFile, NotBefore: hourAgo},
+				},
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tShould load the key set : %v", failed, err)
+			}
+			SetKeys(rotated)
+
+			if _, _, err := ParseInviteToken(oldToken); err != nil {
+				t.Fatalf("\t%s\tShould accept the token of the previous key within the overlap : %v", failed, err)
+			}
+			t.Logf("\t%s\tShould accept the token of the previous key within the overlap.", success)
+
+			token, _, err := GenerateToken(1, "jti-2")
+			if err != nil {
+				t.Fatalf("\t%s\tShould generate the token : %v", failed, err)
+			}
+			if !strings.HasPrefix(token, "eyJhbGciOiJFZERTQSIsImtpZCI6ImVkLTIi") {
+				t.Fatalf("\t%s\tShould sign with the new key ed-2, got %s.", failed, token)
+			}
+			t.Logf("\t%s\tShould sign with the new key.", success)
+
+			if jwks := PublicKeys(); len(jwks.Keys) != 2 || jwks.Keys[1].Kty != "RSA" || jwks.Keys[1].E != "AQAB" {
+				t.Fatalf("\t%s\tShould publish both keys, got %+v.", failed, jwks)
+			}
+			t.Logf("\t%s\tShould publish both keys.", success)
+
+			rotated.overlap = 30 * time.Minute
+			if _, _, err := ParseInviteToken(oldToken); err == nil {
+				t.Fatalf("\t%s\tShould reject the token of the previous key after the overlap.", failed)
+			}
+			if jwks := PublicKeys(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "ed-2" {
+				t.Fatalf("\t%s\tShould publish only the new key, got %+v.", failed, jwks)
+			}
+			t.Logf("\t%s\tShould retire the previous key after the overlap.", success)
+		}
+
+		t.Logf("\tWhen an HS256 secret is configured.")
+		{
+			ks, err := LoadKeys(Config{Keys: []KeyConfig{{ID: "hs-1", Secret: strings.Repeat("s", minSecretLen)}}})
+			if err != nil {
+				t.Fatalf("\t%s\tShould load the key : %v", failed, err)
+			}
+			SetKeys(ks)
+
+			if jwks := PublicKeys(); len(jwks.Keys) != 0 {
+				t.Fatalf("\t%s\tShould not publish the secret, got %+v.", failed, jwks)
+			}
+			t.Logf("\t%s\tShould not publish the secret.", success)
+
+			other, _ := LoadKeys(Config{Keys: []KeyConfig{{ID: "hs-2", Secret: strings.Repeat("s", minSecretLen)}}})
+			SetKeys(other)
+			token, _, _ := GenerateToken(1, "jti-3")
+			SetKeys(ks)
+			if _, err := ParseAccessToken(token); err == nil {
+				t.Fatalf("\t%s\tShould reject a token with an unknown kid.", failed)
+			}
+			t.Logf("\t%s\tShould reject a token with an unknown kid.", success)
+		}
+
+		t.Logf("\tWhen the key set is misconfigured.")
+		{
+			for name, cfg := range map[string]Config{
+				"no keys":            {},
+				"short secret":       {Keys: []KeyConfig{{ID: "hs", Secret: "short"}}},
+				"missing kid":        {Keys: []KeyConfig{{File: edFile}}},
+				"wrong alg":          {Keys: []KeyConfig{{ID: "ed", Algorithm: "RS256", File: edFile}}},
+				"public active key":  {Keys: []KeyConfig{{ID: "rsa", File: rsaPublicFile}}},
+				"only a future key":  {Keys: []KeyConfig{{ID: "ed", File: edFile, NotBefore: now.Add(time.Hour).Format(time.RFC3339)}}},
+				"duplicate kid":      {Keys: []KeyConfig{{ID: "ed", File: edFile}, {ID: "ed", File: rsaFile, NotBefore: hourAgo}}},
+				"secret and file":    {Keys: []KeyConfig{{ID: "ed", File: edFile, Secret: strings.Repeat("s", minSecretLen)}}},
+				"invalid not_before": {Keys: []KeyConfig{{ID: "ed", File: edFile, NotBefore: "yesterday"}}},
+			} {
+				if _, err := LoadKeys(cfg); err == nil {
+					t.Fatalf("\t%s\tShould reject the config with %s.", failed, name)
+				}
+			}
+			t.Logf("\t%s\tShould reject invalid configs.", success)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Package lineproto - разбор InfluxDB line protocol:
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+//
+// Поддерживаются экранирование ("\ ", "\,", "\=", "\"" и "\\"), типы полей float, integer (i),
+// unsigned (u), string и boolean, точность меток времени ns, us, ms и s.
+package lineproto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Тип значения поля
+type Kind int
+
+const (
+	Float Kind = iota
+	Integer
+	Unsigned
+	String
+	Boolean
+)
+
+type Field struct {
+	Kind  Kind
+	Float float64
+	Int   int64
+	Uint  uint64
+	Str   string
+	Bool  bool
+}
+
+// Number возвращает числовое значение поля; для boolean - 1 или 0
+func (f Field) Number() (float64, bool) {
+	switch f.Kind {
+	case Float:
+		return f.Float, true
+	case Integer:
+		return float64(f.Int), true
+	case Unsigned:
+		return float64(f.Uint), true
+	case Boolean:
+		if f.Bool {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// String возвращает значение поля в текстовом виде
+func (f Field) String() string {
+	switch f.Kind {
+	case Float:
+		return strconv.FormatFloat(f.Float, 'g', -1, 64)
+	case Integer:
+		return strconv.FormatInt(f.Int, 10)
+	case Unsigned:
+		return strconv.FormatUint(f.Uint, 10)
+	case Boolean:
+		if f.Bool {
+			return "1"
+		}
+		return "0"
+	}
+	return f.Str
+}
+
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]Field
+	Time        time.Time
+}
+
+// Ошибка разбора строки
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err.Error())
+}
+
+var (
+	ErrNoFields  = errors.New("missing fields")
+	ErrNoMeasure = errors.New("missing measurement")
+)
+
+// ParsePrecision переводит точность меток времени в длительность единицы
+func ParsePrecision(p string) (time.Duration, error) {
+	switch p {
+	case "", "ns", "n":
+		return time.Nanosecond, nil
+	case "us", "u":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("invalid precision %q", p)
+}
+
+// Parse разбирает пакет строк. Корректные точки возвращаются даже при ошибках в других строках.
+// Точкам без метки времени присваивается now.
+func Parse(data string, precision time.Duration, now time.Time) ([]Point, []LineError) {
+
+	var points []Point
+	var errs []LineError
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		p, err := ParseLine(line, precision, now)
+		if err != nil {
+			errs = append(errs, LineError{Line: i + 1, Err: err})
+			continue
+		}
+		points = append(points, p)
+	}
+
+	return points, errs
+}
+
+// ParseLine разбирает одну строку
+func ParseLine(line string, precision time.Duration, now time.Time) (Point, error) {
+
+	p := Point{Tags: map[string]string{}, Fields: map[string]Field{}, Time: now}
+
+	key, rest := splitUnescaped(line, ' ', false)
+	fieldSet, ts := splitUnescaped(rest, ' ', true)
+
+	// measurement и теги
+	parts := splitAll(key, ',', false)
+	p.Measurement = unescape(parts[0])
+	if p.Measurement == "" {
+		return p, ErrNoMeasure
+	}
+	for _, tag := range parts[1:] {
+		k, v := splitUnescaped(tag, '=', false)
+		if k == "" || v == "" {
+			return p, fmt.Errorf("invalid tag %q", tag)
+		}
+		p.Tags[unescape(k)] = unescape(v)
+	}
+
+	// поля
+	if fieldSet == "" {
+		return p, ErrNoFields
+	}
+	for _, field := range splitAll(fieldSet, ',', true) {
+		k, v := splitUnescaped(field, '=', true)
+		if k == "" || v == "" {
+			return p, fmt.Errorf("invalid field %q", field)
+		}
+		f, err := parseFieldValue(v)
+		if err != nil {
+			return p, fmt.Errorf("field %q: %s", unescape(k), err.Error())
+		}
+		p.Fields[unescape(k)] = f
+	}
+
+	// метка времени
+	if ts = strings.TrimSpace(ts); ts != "" {
+		n, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid timestamp %q", ts)
+		}
+		p.Time = time.Unix(0, n*int64(precision)).UTC()
+	}
+
+	return p, nil
+}
+
+func parseFieldValue(v string) (Field, error) {
+
+	if v[0] == '"' {
+		if len(v) < 2 || v[len(v)-1] != '"' {
+			return Field{}, errors.New("unterminated string")
+		}
+		s := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(v[1 : len(v)-1])
+		return Field{Kind: String, Str: s}, nil
+	}
+
+	switch v {
+	case "t", "T", "true", "True", "TRUE":
+		return Field{Kind: Boolean, Bool: true}, nil
+	case "f", "F", "false", "False", "FALSE":
+		return Field{Kind: Boolean, Bool: false}, nil
+	}
+
+	switch v[len(v)-1] {
+	case 'i':
+		n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return Field{}, errors.New("invalid integer")
+		}
+		return Field{Kind: Integer, Int: n}, nil
+	case 'u':
+		n, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return Field{}, errors.New("invalid unsigned integer")
+		}
+		return Field{Kind: Unsigned, Uint: n}, nil
+	}
+
+	x, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return Field{}, errors.New("invalid number")
+	}
+	return Field{Kind: Float, Float: x}, nil
+}
+
+// splitUnescaped делит строку по первому неэкранированному разделителю.
+// При quoted разделители внутри строк в кавычках пропускаются.
+func splitUnescaped(s string, sep byte, quoted bool) (string, string) {
+	i := indexUnescaped(s, sep, quoted)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
+
+func splitAll(s string, sep byte, quoted bool) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep, quoted)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
+
+func indexUnescaped(s string, sep byte, quoted bool) int {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quoted && s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			return i
+		}
+	}
+	return -1
+}
+
+var unescaper = strings.NewReplacer(`\ `, ` `, `\,`, `,`, `\=`, `=`, `\"`, `"`, `\\`, `\`)
+
+func unescape(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,101 @@
+package lineproto
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestParseLine validates parsing of single points.
+func TestParseLine(t *testing.T) {
+	now := time.Date(2022, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		line        string
+		precision   time.Duration
+		measurement string
+		tags        map[string]string
+		fields      map[string]string
+		time        time.Time
+	}{
+		{
+			"water,tank=reef\\ main,probe=1 temp=25.4,ph=8.12 1661990400000000000", time.Nanosecond,
+			"water", map[string]string{"tank": "reef main", "probe": "1"},
+			map[string]string{"temp": "25.4", "ph": "8.12"},
+			time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"cpu usage=42i,load=3u,ok=true,state=\"running, fine\" 1661990400", time.Second,
+			"cpu", map[string]string{},
+			map[string]string{"usage": "42", "load": "3", "ok": "1", "state": "running, fine"},
+			time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"my\\,measure,a\\=b=c\\,d value=-1.5e2", time.Nanosecond,
+			"my,measure", map[string]string{"a=b": "c,d"},
+			map[string]string{"value": "-150"},
+			now,
+		},
+	}
+
+	t.Log("Given the need to accept InfluxDB line protocol.")
+	{
+		for _, tt := range tests {
+			t.Logf("\tWhen parsing %q.", tt.line)
+			{
+				p, err := ParseLine(tt.line, tt.precision, now)
+				if err != nil {
+					t.Fatalf("\t%s\tShould parse without error : %s.", failed, err)
+				}
+				if p.Measurement != tt.measurement {
+					t.Fatalf("\t%s\tShould have measurement %q, got %q.", failed, tt.measurement, p.Measurement)
+				}
+				if !reflect.DeepEqual(p.Tags, tt.tags) {
+					t.Fatalf("\t%s\tShould have tags %v, got %v.", failed, tt.tags, p.Tags)
+				}
+				fields := map[string]string{}
+				for k, f := range p.Fields {
+					fields[k] = f.String()
+				}
+				if !reflect.DeepEqual(fields, tt.fields) {
+					t.Fatalf("\t%s\tShould have fields %v, got %v.", failed, tt.fields, fields)
+				}
+				if !p.Time.Equal(tt.time) {
+					t.Fatalf("\t%s\tShould have time %v, got %v.", failed, tt.time, p.Time)
+				}
+				t.Logf("\t%s\tShould parse measurement, tags, fields and time.", success)
+			}
+		}
+	}
+}
+
+// TestParse validates that valid lines are kept when other lines are broken.
+func TestParse(t *testing.T) {
+	data := "# comment\nwater temp=25\n\nwater\nwater temp=abc\nwater ph=8.1 123x\nwater ph=8.2\n"
+
+	t.Log("Given a batch with broken lines.")
+	{
+		t.Logf("\tWhen parsing the batch.")
+		{
+			points, errs := Parse(data, time.Nanosecond, time.Now())
+			if len(points) != 2 {
+				t.Fatalf("\t%s\tShould keep 2 valid points, got %d.", failed, len(points))
+			}
+			t.Logf("\t%s\tShould keep 2 valid points.", success)
+
+			var lines []int
+			for _, e := range errs {
+				lines = append(lines, e.Line)
+			}
+			if !reflect.DeepEqual(lines, []int{4, 5, 6}) {
+				t.Fatalf("\t%s\tShould report lines 4, 5, 6, got %v.", failed, lines)
+			}
+			t.Logf("\t%s\tShould report broken lines.", success)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package netguard - защита исходящих запросов на адреса пользователей (webhook_и) от SSRF:
+// запросы на адреса внутренней сети сервера (loopback, частные, link-local, unspecified) запрещены.
+//
+// Адрес проверяется дважды: при сохранении URL (CheckURL) и при каждом соединении (Control
+// в net.Dialer клиента NewClient) - так имя, которое после проверки стало указывать
+// на внутренний адрес (DNS rebinding), тоже не пропускается.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("netguard: address is not allowed")
+
+// Сколько ждать разрешения имени при проверке URL
+const resolveTimeout = 5 * time.Second
+
+// Forbidden - адрес внутренней сети сервера
+func Forbidden(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 { // 0.0.0.0/8 - "этот" хост
+		return true
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// CheckURL разрешает имя хоста URL и проверяет все его адреса
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+
+	if ip := net.ParseIP(host); ip != nil {
+		if Forbidden(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("netguard: cannot resolve %s", host)
+	}
+	for _, a := range addrs {
+		if Forbidden(a.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, a.IP)
+		}
+	}
+	return nil
+}
+
+// Control для net.Dialer: соединение с адресом внутренней сети отклоняется
+func Control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || Forbidden(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// NewClient - HTTP-клиент, который соединяется только с внешними адресами.
+// Прокси из окружения не используется: его адрес проверялся бы вместо адреса получателя.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: Control}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestForbidden validates rejection of the server internal addresses.
+func TestForbidden(t *testing.T) {
+	t.Log("Given the need to refuse requests to internal addresses.")
+	{
+		t.Logf("\tWhen the address is internal.")
+		{
+			for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+				"0.0.0.0", "0.1.2.3", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:10.0.0.1"} {
+				if !Forbidden(net.ParseIP(addr)) {
+					t.Fatalf("\t%s\tShould refuse %s.", failed, addr)
+				}
+				if err := CheckURL("http://" + net.JoinHostPort(addr, "8080") + "/hook"); !errors.Is(err, ErrForbiddenAddress) {
+					t.Fatalf("\t%s\tShould refuse the url with %s, got %v.", failed, addr, err)
+				}
+			}
+			t.Logf("\t%s\tShould refuse internal addresses.", success)
+		}
+
+		t.Logf("\tWhen the address is public.")
+		{
+			for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111"} {
+				if Forbidden(net.ParseIP(addr)) {
+					t.Fatalf("\t%s\tShould accept %s.", failed, addr)
+				}
+			}
+			if err := CheckURL("https://93.184.216.34/hook"); err != nil {
+				t.Fatalf("\t%s\tShould accept the url : %v", failed, err)
+			}
+			t.Logf("\t%s\tShould accept public addresses.", success)
+		}
+	}
+}
+
+// TestClient validates that the client does not connect to internal addresses.
+func TestClient(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	t.Log("Given a client for requests to user urls.")
+	{
+		t.Logf("\tWhen the url points to the loopback address.")
+		{
+			_, err := NewClient(time.Second).Post(srv.URL, "application/json", nil)
+			if !errors.Is(err, ErrForbiddenAddress) || called {
+				t.Fatalf("\t%s\tShould not connect, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould not connect.", success)
+		}
+	}
+}
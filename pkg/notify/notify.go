@@ -0,0 +1,107 @@
+// Package notify - каналы доставки уведомлений (email по SMTP, HTTP webhook, файловый outbox),
+// шаблоны сообщений и расчёт задержки повторных попыток.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"text/template"
+	"time"
+)
+
+// Имена каналов
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+)
+
+// Сообщение для одного получателя. To - адрес в терминах канала: email, URL webhook_а или имя получателя для outbox.
+type Message struct {
+	To      string
+	Event   string
+	Subject string
+	Body    string
+}
+
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, m Message) error
+}
+
+// PermanentError - ошибка, после которой повторять отправку бессмысленно (неверный адрес, отказ сервера)
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *PermanentError
+	return errors.As(err, &p)
+}
+
+// Backoff - задержка перед попыткой attempt (с 1): base, 2*base, 4*base ... но не больше max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// Template - шаблоны темы и текста сообщения (text/template)
+type Template struct {
+	Subject string
+	Body    string
+}
+
+// Validate проверяет синтаксис шаблонов
+func (t Template) Validate() error {
+	if _, err := template.New("subject").Parse(t.Subject); err != nil {
+		return err
+	}
+	_, err := template.New("body").Parse(t.Body)
+	return err
+}
+
+// Render заполняет шаблоны данными; переводы строк в теме заменяются пробелами
+func (t Template) Render(data interface{}) (subject, body string, err error) {
+	if subject, err = execute("subject", t.Subject, data); err != nil {
+		return "", "", err
+	}
+	if body, err = execute("body", t.Body, data); err != nil {
+		return "", "", err
+	}
+	return string(bytes.Join(bytes.Fields([]byte(subject)), []byte(" "))), body, nil
+}
+
+func execute(name, text string, data interface{}) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
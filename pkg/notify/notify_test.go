@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// smtpSink - минимальный SMTP-сервер: принимает одно письмо и отдаёт текст DATA в канал.
+// rcptCode - код ответа на RCPT TO.
+func smtpSink(t *testing.T, rcptCode string) (host string, port int, data chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to listen : %v", failed, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	data = make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 sink ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250-sink")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL"):
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "RCPT"):
+				reply(rcptCode + " rcpt")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				data <- b.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, data
+}
+
+// TestSMTP validates sending email to a local SMTP sink.
+func TestSMTP(t *testing.T) {
+
+	t.Log("Given the need to send alerts by email.")
+	{
+		t.Logf("\tWhen the server accepts the message.")
+		{
+			host, port, data := smtpSink(t, "250")
+			c := &SMTP{Host: host, Port: port, From: "alerts@example.com", Timeout: 5 * time.Second}
+
+			err := c.Send(context.Background(), Message{To: "owner@example.com", Subject: "Reef: 28.4 is above 28", Body: "Check the heater.\nТемпература"})
+			if err != nil {
+				t.Fatalf("\t%s\tShould send the message : %v", failed, err)
+			}
+			t.Logf("\t%s\tShould send the message.", success)
+
+			msg := <-data
+			for _, want := range []string{"From: alerts@example.com", "To: owner@example.com", "Subject: Reef: 28.4 is above 28", "Check the heater."} {
+				if !strings.Contains(msg, want) {
+					t.Fatalf("\t%s\tShould contain %q in:\n%s", failed, want, msg)
+				}
+			}
+			t.Logf("\t%s\tShould contain headers and body.", success)
+		}
+
+		t.Logf("\tWhen the server rejects the recipient.")
+		{
+			host, port, _ := smtpSink(t, "550")
+			c := &SMTP{Host: host, Port: port, From: "alerts@example.com", Timeout: 5 * time.Second}
+
+			err := c.Send(context.Background(), Message{To: "nobody@example.com", Subject: "s", Body: "b"})
+			if err == nil || !IsPermanent(err) {
+				t.Fatalf("\t%s\tShould fail permanently, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould fail permanently.", success)
+		}
+
+		t.Logf("\tWhen the recipient contains a line break.")
+		{
+			c := &SMTP{Host: "127.0.0.1", Port: 1, From: "alerts@example.com"}
+			if err := c.Send(context.Background(), Message{To: "a@example.com\r\nBcc: b@example.com"}); !IsPermanent(err) {
+				t.Fatalf("\t%s\tShould reject the recipient, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould reject the recipient.", success)
+		}
+	}
+}
+
+// TestWebhook validates posting notifications to an HTTP endpoint.
+func TestWebhook(t *testing.T) {
+
+	t.Log("Given the need to send alerts to a webhook.")
+	{
+		var got webhookPayload
+		status := http.StatusOK
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(status)
+		}))
+		defer srv.Close()
+
+		c := &Webhook{Client: srv.Client()}
+		m := Message{To: srv.URL, Event: "alert.firing", Subject: "Reef: 28.4 is above 28", Body: "Check the heater."}
+
+		t.Logf("\tWhen the endpoint answers 200.")
+		{
+			if err := c.Send(context.Background(), m); err != nil {
+				t.Fatalf("\t%s\tShould deliver : %v", failed, err)
+			}
+			if got.Event != m.Event || got.Subject != m.Subject || got.Body != m.Body {
+				t.Fatalf("\t%s\tShould post the message, got %+v.", failed, got)
+			}
+			t.Logf("\t%s\tShould post the message as JSON.", success)
+		}
+
+		t.Logf("\tWhen the endpoint answers 503.")
+		{
+			status = http.StatusServiceUnavailable
+			if err := c.Send(context.Background(), m); err == nil || IsPermanent(err) {
+				t.Fatalf("\t%s\tShould fail temporarily, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould fail temporarily.", success)
+		}
+
+		t.Logf("\tWhen the endpoint answers 404.")
+		{
+			status = http.StatusNotFound
+			if err := c.Send(context.Background(), m); !IsPermanent(err) {
+				t.Fatalf("\t%s\tShould fail permanently, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould fail permanently.", success)
+		}
+	}
+}
+
+// TestOutbox validates writing notifications to files.
+func TestOutbox(t *testing.T) {
+
+	t.Log("Given the need to see notifications during development.")
+	{
+		dir := t.TempDir()
+		c := &Outbox{Dir: dir}
+
+		t.Logf("\tWhen two messages are sent.")
+		{
+			for i := 0; i < 2; i++ {
+				if err := c.Send(context.Background(), Message{To: "dev", Subject: "Reef", Body: "Hello"}); err != nil {
+					t.Fatalf("\t%s\tShould write the message : %v", failed, err)
+				}
+			}
+
+			files, _ := os.ReadDir(dir)
+			if len(files) != 2 {
+				t.Fatalf("\t%s\tShould write 2 files, got %d.", failed, len(files))
+			}
+			b, _ := os.ReadFile(dir + "/" + files[0].Name())
+			if !strings.Contains(string(b), "Subject: Reef") || !strings.Contains(string(b), "Hello") {
+				t.Fatalf("\t%s\tShould write subject and body, got %q.", failed, b)
+			}
+			t.Logf("\t%s\tShould write a file per message.", success)
+		}
+	}
+}
+
+// TestBackoff validates delays between delivery attempts.
+func TestBackoff(t *testing.T) {
+
+	t.Log("Given the need to retry failed deliveries.")
+	{
+		want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+		for i, w := range want {
+			if got := Backoff(i+1, time.Minute, 10*time.Minute); got != w {
+				t.Fatalf("\t%s\tAttempt %d should wait %s, got %s.", failed, i+1, w, got)
+			}
+		}
+		t.Logf("\t%s\tShould double the delay up to the maximum.", success)
+
+		if !IsPermanent(Permanent(errors.New("x"))) || IsPermanent(errors.New("x")) {
+			t.Fatalf("\t%s\tShould recognise permanent errors.", failed)
+		}
+		t.Logf("\t%s\tShould recognise permanent errors.", success)
+	}
+}
+
+// TestTemplate validates rendering of message templates.
+func TestTemplate(t *testing.T) {
+
+	t.Log("Given the need to render notification templates.")
+	{
+		tpl := Template{Subject: "Alert:\n{{.Message}}", Body: "{{.Message}} at {{.Time}}{{.Missing}}"}
+		data := map[string]string{"Message": "Reef: 28.4 is above 28", "Time": "10:00"}
+
+		subject, body, err := tpl.Render(data)
+		if err != nil {
+			t.Fatalf("\t%s\tShould render : %v", failed, err)
+		}
+		if subject != "Alert: Reef: 28.4 is above 28" || body != "Reef: 28.4 is above 28 at 10:00" {
+			t.Fatalf("\t%s\tShould render subject in one line, got %q / %q.", failed, subject, body)
+		}
+		t.Logf("\t%s\tShould render subject in one line.", success)
+
+		if err := (Template{Subject: "{{.Message", Body: ""}).Validate(); err == nil {
+			t.Fatalf("\t%s\tShould reject invalid template.", failed)
+		}
+		t.Logf("\t%s\tShould reject invalid template.", success)
+	}
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outbox - канал для разработки: каждое сообщение пишется отдельным файлом в каталог Dir
+type Outbox struct {
+	Dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func (c *Outbox) Name() string { return ChannelFile }
+
+func (c *Outbox) Send(ctx context.Context, m Message) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.seq++
+	name := fmt.Sprintf("%s-%06d.txt", time.Now().UTC().Format("20060102T150405.000000"), c.seq)
+	c.mu.Unlock()
+
+	data := fmt.Sprintf("To: %s\nEvent: %s\nSubject: %s\n\n%s\n", m.To, m.Event, m.Subject, m.Body)
+
+	return os.WriteFile(filepath.Join(c.Dir, name), []byte(data), 0644)
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Webhook - POST JSON на URL получателя. Успех - любой ответ 2xx.
+type Webhook struct {
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Event   string    `json:"event"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func (c *Webhook) Name() string { return ChannelWebhook }
+
+func (c *Webhook) Send(ctx context.Context, m Message) error {
+	u, err := url.Parse(m.To)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Permanent(fmt.Errorf("webhook: invalid url %q", m.To))
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		Event:   m.Event,
+		Subject: m.Subject,
+		Body:    m.Body,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.To, bytes.NewReader(payload))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	// 4xx, кроме таймаута и ограничения частоты, повторять бессмысленно
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
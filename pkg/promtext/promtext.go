@@ -0,0 +1,98 @@
+// Package promtext - запись метрик в текстовом формате Prometheus (text exposition format 0.0.4).
+package promtext
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Content-Type ответа с метриками
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// SanitizeName приводит строку к допустимому имени метрики или метки
+func SanitizeName(s string) string {
+	s = invalidNameChars.ReplaceAllString(s, "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) write(s ...string) {
+	for _, x := range s {
+		if w.err != nil {
+			return
+		}
+		_, w.err = w.w.WriteString(x)
+	}
+}
+
+// Header пишет строки HELP и TYPE метрики; пишется один раз перед её сэмплами
+func (w *Writer) Header(name, help, typ string) {
+	w.write("# HELP ", name, " ", helpEscaper.Replace(help), "\n")
+	w.write("# TYPE ", name, " ", typ, "\n")
+}
+
+// Sample пишет одно значение метрики; при нулевом ts метка времени не указывается
+func (w *Writer) Sample(name string, labels []Label, value float64, ts time.Time) {
+	w.write(name)
+	if len(labels) > 0 {
+		w.write("{")
+		for i, l := range labels {
+			if i > 0 {
+				w.write(",")
+			}
+			w.write(l.Name, `="`, labelEscaper.Replace(l.Value), `"`)
+		}
+		w.write("}")
+	}
+	w.write(" ", formatFloat(value))
+	if !ts.IsZero() {
+		w.write(" ", strconv.FormatInt(ts.UnixMilli(), 10))
+	}
+	w.write("\n")
+}
+
+// Flush дописывает буфер и возвращает первую ошибку записи
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
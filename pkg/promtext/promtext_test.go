@@ -0,0 +1,67 @@
+package promtext
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestWriter validates the text exposition output.
+func TestWriter(t *testing.T) {
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.Header("aquahub_sensor_value", "Latest value\nof sensor", "gauge")
+	w.Sample("aquahub_sensor_value", []Label{{"hub", `Reef "main"`}, {"sensor", `pH\probe`}}, 8.1, time.Time{})
+	w.Sample("aquahub_sensor_value", nil, math.Inf(1), time.UnixMilli(1661990400123))
+
+	want := `# HELP aquahub_sensor_value Latest value\nof sensor
+# TYPE aquahub_sensor_value gauge
+aquahub_sensor_value{hub="Reef \"main\"",sensor="pH\\probe"} 8.1
+aquahub_sensor_value +Inf 1661990400123
+`
+
+	t.Log("Given the need to expose sensor values to Prometheus.")
+	{
+		t.Logf("\tWhen writing metrics with special characters in labels.")
+		{
+			if err := w.Flush(); err != nil {
+				t.Fatalf("\t%s\tShould flush without error : %s.", failed, err)
+			}
+			if buf.String() != want {
+				t.Fatalf("\t%s\tShould write\n%s\ngot\n%s", failed, want, buf.String())
+			}
+			t.Logf("\t%s\tShould escape labels and help text.", success)
+		}
+	}
+}
+
+// TestSanitizeName validates conversion of arbitrary strings to metric names.
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"temperature": "temperature",
+		"pH probe #2": "pH_probe__2",
+		"1wire":       "_1wire",
+		"":            "_",
+	}
+
+	t.Log("Given the need to build valid metric names.")
+	{
+		for in, want := range tests {
+			t.Logf("\tWhen sanitizing %q.", in)
+			{
+				if got := SanitizeName(in); got != want {
+					t.Fatalf("\t%s\tShould be %q, got %q.", failed, want, got)
+				}
+				t.Logf("\t%s\tShould be %q.", success, want)
+			}
+		}
+	}
+}
@@ -36,3 +36,16 @@ func RandomBase16String(l int) string {
 	str := hex.EncodeToString(buff)
 	return str[:l]
 }
+
+// Алфавит кодов без похожих символов (0/O, 1/I); 32 символа - без перекоса при делении по модулю
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RandomCode - код из l символов для ручного ввода (коды привязки, подтверждения)
+func RandomCode(l int) string {
+	buff := make([]byte, l)
+	rand.Read(buff)
+	for i, b := range buff {
+		buff[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(buff)
+}
@@ -0,0 +1,209 @@
+// Package rrule - повторения по подмножеству RRULE (RFC 5545).
+//
+// Поддерживаются части FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY (для WEEKLY, без номеров),
+// BYMONTHDAY (для MONTHLY, -1 - последний день месяца) и UNTIL. Повторения отсчитываются от start
+// (DTSTART): время суток и временная зона берутся из start; неделя начинается с понедельника.
+// Дни, которых нет в месяце (31 февраля), пропускаются.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// Ограничения интервала и окна поиска следующего повторения
+const (
+	MaxInterval = 366
+	searchDays  = 5*366 + 1
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      time.Time
+}
+
+// Parse разбирает правило вида "FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU"; префикс "RRULE:" допускается
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return r, errors.New("rrule: empty rule")
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return r, fmt.Errorf("rrule: invalid part %q", part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			switch v := strings.ToUpper(value); v {
+			case Daily, Weekly, Monthly:
+				r.Freq = v
+			default:
+				return r, fmt.Errorf("rrule: unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > MaxInterval {
+				return r, fmt.Errorf("rrule: INTERVAL must be 1..%d", MaxInterval)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					return r, fmt.Errorf("rrule: invalid BYDAY %q", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return r, fmt.Errorf("rrule: invalid BYMONTHDAY %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return r, err
+			}
+			r.Until = until
+		default:
+			return r, fmt.Errorf("rrule: unsupported part %q", name)
+		}
+	}
+
+	if r.Freq == "" {
+		return r, errors.New("rrule: FREQ is required")
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return r, errors.New("rrule: BYDAY is supported only with FREQ=WEEKLY")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly {
+		return r, errors.New("rrule: BYMONTHDAY is supported only with FREQ=MONTHLY")
+	}
+
+	return r, nil
+}
+
+// UNTIL - дата (включительно, до конца суток UTC) или момент UTC
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", s); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("rrule: invalid UNTIL %q", s)
+}
+
+// Номер дня от эпохи без учёта времени суток и перехода на летнее время
+func dayNumber(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// Понедельник недели дня n (1 января 1970 - четверг)
+func weekStart(n int) int {
+	return n - (n+3)%7
+}
+
+func lastDay(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Совпадает ли день d с правилом, отсчитанным от start
+func (r Rule) match(start, d time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		return (dayNumber(d)-dayNumber(start))%r.Interval == 0
+
+	case Weekly:
+		if (weekStart(dayNumber(d))-weekStart(dayNumber(start)))/7%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return d.Weekday() == start.Weekday()
+		}
+		for _, wd := range r.ByDay {
+			if d.Weekday() == wd {
+				return true
+			}
+		}
+		return false
+
+	case Monthly:
+		months := (d.Year()-start.Year())*12 + int(d.Month()-start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 {
+			return d.Day() == start.Day()
+		}
+		last := lastDay(d.Year(), d.Month())
+		for _, md := range r.ByMonthDay {
+			if md > 0 && d.Day() == md || md < 0 && d.Day() == last+md+1 {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// Next возвращает первое повторение строго после after (нулевое время - повторений больше нет)
+func (r Rule) Next(start, after time.Time) time.Time {
+	if r.Interval < 1 {
+		r.Interval = 1
+	}
+
+	loc := start.Location()
+	from := after.In(loc)
+	if from.Before(start) {
+		from = start
+	}
+
+	for i := 0; i < searchDays; i++ {
+		d := time.Date(from.Year(), from.Month(), from.Day()+i, start.Hour(), start.Minute(), start.Second(), 0, loc)
+		if !r.Until.IsZero() && d.After(r.Until) {
+			return time.Time{}
+		}
+		if d.Before(start) || !d.After(after) || !r.match(start, d) {
+			continue
+		}
+		return d
+	}
+	return time.Time{}
+}
+
+// Current возвращает последнее повторение не позже now, начиная с наступившего повторения due
+// (пропущенные периоды перескакиваются), и начало следующего периода (нулевое - повторений больше нет)
+func (r Rule) Current(start, due, now time.Time) (from, to time.Time) {
+	from = due
+	for next := r.Next(start, due); !next.IsZero() && !next.After(now); next = r.Next(start, next) {
+		from = next
+	}
+	return from, r.Next(start, from)
+}
@@ -0,0 +1,131 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestParse validates the supported RRULE subset.
+func TestParse(t *testing.T) {
+	t.Log("Given the need to parse recurrence rules.")
+	{
+		valid := []string{
+			"FREQ=DAILY",
+			"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU",
+			"FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20231231",
+			"FREQ=WEEKLY;UNTIL=20231231T235959Z",
+		}
+		for _, s := range valid {
+			t.Logf("\tWhen parsing %q.", s)
+			if _, err := Parse(s); err != nil {
+				t.Fatalf("\t%s\tShould parse : %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould parse.", success)
+		}
+
+		invalid := []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=YEARLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;BYDAY=MO",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=MONTHLY;BYMONTHDAY=32",
+			"FREQ=DAILY;COUNT=5",
+			"FREQ=DAILY;UNTIL=tomorrow",
+		}
+		for _, s := range invalid {
+			t.Logf("\tWhen parsing %q.", s)
+			if _, err := Parse(s); err == nil {
+				t.Fatalf("\t%s\tShould refuse.", failed)
+			}
+			t.Logf("\t%s\tShould refuse.", success)
+		}
+	}
+}
+
+// TestNext validates occurrences of the rules.
+func TestNext(t *testing.T) {
+	kiev, err := time.LoadLocation("Europe/Kiev")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+
+	// Суббота 10:00
+	start := time.Date(2022, 10, 1, 10, 0, 0, 0, kiev)
+
+	tests := []struct {
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{"FREQ=DAILY", start.Add(-time.Hour), start},
+		{"FREQ=DAILY", start, start.AddDate(0, 0, 1)},
+		{"FREQ=DAILY;INTERVAL=3", start.AddDate(0, 0, 1), start.AddDate(0, 0, 3)},
+		{"FREQ=WEEKLY", start, start.AddDate(0, 0, 7)},
+		{"FREQ=WEEKLY;BYDAY=MO,SA", start, start.AddDate(0, 0, 2)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", start, start.AddDate(0, 0, 9)},
+		{"FREQ=MONTHLY", start, time.Date(2022, 11, 1, 10, 0, 0, 0, kiev)},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", start, time.Date(2022, 10, 31, 10, 0, 0, 0, kiev)},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", time.Date(2022, 10, 31, 11, 0, 0, 0, kiev), time.Date(2022, 12, 31, 10, 0, 0, 0, kiev)},
+		{"FREQ=DAILY;UNTIL=20221002", start, start.AddDate(0, 0, 1)},
+		{"FREQ=DAILY;UNTIL=20221002", start.AddDate(0, 0, 1), time.Time{}},
+	}
+
+	t.Log("Given the need to find the next occurrence.")
+	{
+		for _, tt := range tests {
+			t.Logf("\tWhen rule is %q after %s.", tt.rule, tt.after)
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("\t%s\tShould parse : %v.", failed, err)
+			}
+			if got := r.Next(start, tt.after); !got.Equal(tt.want) {
+				t.Fatalf("\t%s\tShould be %s, got %s.", failed, tt.want, got)
+			}
+			t.Logf("\t%s\tShould be %s.", success, tt.want)
+		}
+
+		t.Logf("\tWhen daylight saving time ends.")
+		{
+			r, _ := Parse("FREQ=DAILY")
+			got := r.Next(start, time.Date(2022, 10, 29, 12, 0, 0, 0, kiev))
+			if got.Hour() != 10 || got.Day() != 30 {
+				t.Fatalf("\t%s\tShould keep 10:00 local time, got %s.", failed, got)
+			}
+			t.Logf("\t%s\tShould keep 10:00 local time.", success)
+		}
+	}
+}
+
+// TestCurrent validates catching up with missed periods.
+func TestCurrent(t *testing.T) {
+	start := time.Date(2022, 10, 3, 9, 0, 0, 0, time.UTC)
+	r, _ := Parse("FREQ=WEEKLY")
+
+	t.Log("Given the need to create the current period.")
+	{
+		t.Logf("\tWhen three periods were missed.")
+		{
+			from, to := r.Current(start, start, start.AddDate(0, 0, 22))
+			if !from.Equal(start.AddDate(0, 0, 21)) || !to.Equal(start.AddDate(0, 0, 28)) {
+				t.Fatalf("\t%s\tShould be the fourth week, got %s - %s.", failed, from, to)
+			}
+			t.Logf("\t%s\tShould be the fourth week.", success)
+		}
+
+		t.Logf("\tWhen the due occurrence has just come.")
+		{
+			from, to := r.Current(start, start, start)
+			if !from.Equal(start) || !to.Equal(start.AddDate(0, 0, 7)) {
+				t.Fatalf("\t%s\tShould be the first week, got %s - %s.", failed, from, to)
+			}
+			t.Logf("\t%s\tShould be the first week.", success)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package webhook - подпись исходящих webhook_ов и её проверка на стороне получателя.
+//
+// Подпись передаётся в хедере X-Hub-Signature в виде "t=<unix time>,v1=<hex HMAC-SHA256>",
+// где HMAC считается секретом подписки от строки "<unix time>.<тело запроса>".
+// Время в подписи позволяет получателю отклонять повторно отправленные злоумышленником запросы.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Хедеры запроса
+const (
+	HeaderSignature = "X-Hub-Signature"
+	HeaderEvent     = "X-Hub-Event"
+	HeaderDelivery  = "X-Hub-Delivery" // ID события: при повторной доставке не меняется
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrExpired          = errors.New("webhook: signature timestamp is out of tolerance")
+)
+
+func mac(secret string, ts int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(ts, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sign возвращает значение хедера подписи тела body в момент at
+func Sign(secret string, body []byte, at time.Time) string {
+	ts := at.Unix()
+	return "t=" + strconv.FormatInt(ts, 10) + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify проверяет подпись; tolerance - допустимое расхождение времени подписи с now (0 - не проверять)
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts int64
+	var sigs []string
+
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+		switch k {
+		case "t":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return ErrInvalidSignature
+			}
+			ts = n
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		d := now.Sub(time.Unix(ts, 0))
+		if d > tolerance || d < -tolerance {
+			return ErrExpired
+		}
+	}
+
+	want := mac(secret, ts, body)
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Post отправляет подписанное тело body на url и возвращает код ответа; ошибка - сеть или ответ не 2xx
+func Post(ctx context.Context, client *http.Client, url, secret, event, id string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, id)
+	req.Header.Set(HeaderSignature, Sign(secret, body, time.Now()))
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
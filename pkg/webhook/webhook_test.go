@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// TestSignVerify validates signing of webhook payloads.
+func TestSignVerify(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"alert.fired"}`)
+	header := Sign("secret", body, now)
+
+	t.Log("Given the need to verify signed webhook payloads.")
+	{
+		t.Logf("\tWhen the payload is not modified.")
+		{
+			if err := Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+				t.Fatalf("\t%s\tShould accept : %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould accept.", success)
+		}
+
+		t.Logf("\tWhen the payload or the secret differ.")
+		{
+			if err := Verify("secret", header, []byte(`{"event":"x"}`), now, 0); err != ErrInvalidSignature {
+				t.Fatalf("\t%s\tShould refuse modified body, got %v.", failed, err)
+			}
+			if err := Verify("other", header, body, now, 0); err != ErrInvalidSignature {
+				t.Fatalf("\t%s\tShould refuse other secret, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould refuse.", success)
+		}
+
+		t.Logf("\tWhen the signature is too old.")
+		{
+			if err := Verify("secret", header, body, now.Add(time.Hour), 5*time.Minute); err != ErrExpired {
+				t.Fatalf("\t%s\tShould refuse, got %v.", failed, err)
+			}
+			t.Logf("\t%s\tShould refuse.", success)
+		}
+
+		t.Logf("\tWhen the header is malformed.")
+		{
+			for _, h := range []string{"", "v1=abc", "t=x,v1=abc", "t=1"} {
+				if err := Verify("secret", h, body, now, 0); err != ErrInvalidSignature {
+					t.Fatalf("\t%s\tShould refuse %q, got %v.", failed, h, err)
+				}
+			}
+			t.Logf("\t%s\tShould refuse.", success)
+		}
+	}
+}
+
+// TestPost validates posting signed payloads to an HTTP endpoint.
+func TestPost(t *testing.T) {
+
+	t.Log("Given the need to deliver signed webhook payloads.")
+	{
+		body := []byte(`{"id":"e1","event":"hub.offline"}`)
+		status := http.StatusOK
+		var verr error
+		var event, id string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ := io.ReadAll(r.Body)
+			verr = Verify("secret", r.Header.Get(HeaderSignature), got, time.Now(), time.Minute)
+			event, id = r.Header.Get(HeaderEvent), r.Header.Get(HeaderDelivery)
+			w.WriteHeader(status)
+		}))
+		defer srv.Close()
+
+		t.Logf("\tWhen the endpoint answers 200.")
+		{
+			code, err := Post(context.Background(), srv.Client(), srv.URL, "secret", "hub.offline", "e1", body)
+			if err != nil || code != http.StatusOK {
+				t.Fatalf("\t%s\tShould deliver, got %d %v.", failed, code, err)
+			}
+			if verr != nil || event != "hub.offline" || id != "e1" {
+				t.Fatalf("\t%s\tShould send verifiable signature and headers, got %v %q %q.", failed, verr, event, id)
+			}
+			t.Logf("\t%s\tShould deliver signed payload.", success)
+		}
+
+		t.Logf("\tWhen the endpoint answers 500.")
+		{
+			status = http.StatusInternalServerError
+			code, err := Post(context.Background(), srv.Client(), srv.URL, "secret", "hub.offline", "e1", body)
+			if err == nil || code != http.StatusInternalServerError {
+				t.Fatalf("\t%s\tShould fail with status, got %d %v.", failed, code, err)
+			}
+			t.Logf("\t%s\tShould fail with status.", success)
+		}
+	}
+}
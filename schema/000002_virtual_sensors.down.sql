@@ -0,0 +1,3 @@
+DELETE FROM sensors_dataset WHERE sensor_id IN ( SELECT id FROM sensors WHERE formula IS NOT NULL );
+DELETE FROM sensors WHERE formula IS NOT NULL;
+ALTER TABLE sensors DROP COLUMN formula;
@@ -0,0 +1,2 @@
+-- Виртуальные (вычисляемые) сенсоры: formula IS NULL - физический сенсор
+ALTER TABLE sensors ADD COLUMN formula varchar(1024);
@@ -0,0 +1,3 @@
+UPDATE sensors_dataset SET "value" = raw_value WHERE raw_value IS NOT NULL;
+ALTER TABLE sensors_dataset DROP COLUMN raw_value;
+DROP TABLE IF EXISTS sensor_calibrations;
@@ -0,0 +1,18 @@
+-- Калибровки сенсоров. Калибровка действует с valid_from до начала следующей калибровки сенсора.
+CREATE TABLE sensor_calibrations ( 
+	id                   serial not null unique,
+	sensor_id            integer NOT NULL,
+	offset_value         double precision DEFAULT 0 NOT NULL,
+	scale                double precision DEFAULT 1 NOT NULL,
+	points               jsonb,
+	valid_from           timestamptz NOT NULL,
+	description          varchar(255) DEFAULT ''::character varying NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT sensor_calibrations_pkey PRIMARY KEY ( id ),
+	CONSTRAINT sensor_calibrations_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_sensor_calibrations_sensor ON sensor_calibrations ( sensor_id, valid_from );
+
+-- Исходное (некалиброванное) показание сенсора
+ALTER TABLE sensors_dataset ADD COLUMN raw_value varchar(32);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sensors_dataset_flagged;
+DROP INDEX IF EXISTS idx_sensors_dataset_sensor_time;
+ALTER TABLE sensors_dataset DROP COLUMN flag_reason;
+ALTER TABLE sensors_dataset DROP COLUMN flag;
+DROP TABLE IF EXISTS sensor_detectors;
@@ -0,0 +1,18 @@
+-- Детекторы аномалий сенсоров (0 - детектор выключен)
+CREATE TABLE sensor_detectors ( 
+	sensor_id            integer NOT NULL,
+	max_rate             double precision DEFAULT 0 NOT NULL,
+	zscore_window        integer DEFAULT 0 NOT NULL,
+	zscore_threshold     double precision DEFAULT 0 NOT NULL,
+	flatline_minutes     integer DEFAULT 0 NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT sensor_detectors_pkey PRIMARY KEY ( sensor_id ),
+	CONSTRAINT sensor_detectors_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+-- Пометки подозрительных показаний
+ALTER TABLE sensors_dataset ADD COLUMN flag varchar(64);
+ALTER TABLE sensors_dataset ADD COLUMN flag_reason varchar(512);
+
+CREATE INDEX idx_sensors_dataset_sensor_time ON sensors_dataset ( sensor_id, created_at );
+CREATE INDEX idx_sensors_dataset_flagged ON sensors_dataset ( sensor_id, created_at ) WHERE flag IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sensor_coverage_daily;
+ALTER TABLE sensors DROP COLUMN report_interval_sec;
@@ -0,0 +1,18 @@
+-- Ожидаемый интервал показаний сенсора (NULL - определяется по данным)
+ALTER TABLE sensors ADD COLUMN report_interval_sec integer;
+
+-- Суточное покрытие сенсоров данными, рассчитывается плановой задачей
+CREATE TABLE sensor_coverage_daily ( 
+	sensor_id            integer NOT NULL,
+	"day"                date NOT NULL,
+	interval_sec         integer DEFAULT 0 NOT NULL,
+	readings             integer DEFAULT 0 NOT NULL,
+	coverage             double precision DEFAULT 0 NOT NULL,
+	missing_sec          integer DEFAULT 0 NOT NULL,
+	gaps                 jsonb DEFAULT '[]'::jsonb NOT NULL,
+	computed_at          timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT sensor_coverage_daily_pkey PRIMARY KEY ( sensor_id, "day" ),
+	CONSTRAINT sensor_coverage_daily_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_sensor_coverage_daily_day ON sensor_coverage_daily ( "day" );
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS metrics_tokens;
+ALTER TABLE sensors DROP COLUMN unit;
@@ -0,0 +1,21 @@
+-- Единица измерения сенсора
+ALTER TABLE sensors ADD COLUMN unit varchar(32) DEFAULT ''::character varying NOT NULL;
+
+-- Токены для сбора метрик аккаунта (Prometheus scrape), хранится SHA-256 хеш токена
+CREATE TABLE metrics_tokens ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	name                 varchar(255) NOT NULL,
+	prefix               varchar(16) NOT NULL,
+	token_hash           char(64) NOT NULL,
+	created_by           integer,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	last_used_at         timestamptz,
+	revoked_at           timestamptz,
+	CONSTRAINT metrics_tokens_pkey PRIMARY KEY ( id ),
+	CONSTRAINT metrics_tokens_token_hash_key UNIQUE ( token_hash ),
+	CONSTRAINT metrics_tokens_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT metrics_tokens_created_by_fkey FOREIGN KEY ( created_by ) REFERENCES users( id ) ON DELETE SET NULL
+ );
+
+CREATE INDEX idx_metrics_tokens_account ON metrics_tokens ( account_id );
@@ -0,0 +1,15 @@
+-- Правила сопоставления точек InfluxDB line protocol сенсорам аккаунта
+CREATE TABLE influx_mappings ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	measurement          varchar(255) NOT NULL,
+	tags                 jsonb DEFAULT '{}'::jsonb NOT NULL,
+	field                varchar(255) NOT NULL,
+	sensor_id            integer NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT influx_mappings_pkey PRIMARY KEY ( id ),
+	CONSTRAINT influx_mappings_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT influx_mappings_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_influx_mappings_account ON influx_mappings ( account_id );
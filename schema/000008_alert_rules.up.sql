@@ -0,0 +1,45 @@
+-- Правила оповещения по показаниям сенсоров; текущее состояние правила хранится в той же строке
+CREATE TABLE alert_rules ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	sensor_id            integer NOT NULL,
+	title                varchar(255) DEFAULT '' NOT NULL,
+	kind                 varchar(16) NOT NULL,
+	threshold_low        double precision,
+	threshold_high       double precision,
+	hysteresis           double precision DEFAULT 0 NOT NULL,
+	min_duration_sec     integer DEFAULT 0 NOT NULL,
+	no_data_sec          integer DEFAULT 0 NOT NULL,
+	enabled              boolean DEFAULT true NOT NULL,
+	state                varchar(16) DEFAULT 'ok' NOT NULL,
+	pending_since        timestamptz,
+	last_reading_at      timestamptz,
+	state_changed_at     timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT alert_rules_pkey PRIMARY KEY ( id ),
+	CONSTRAINT alert_rules_kind_check CHECK ( kind IN ('above', 'below', 'outside', 'no_data') ),
+	CONSTRAINT alert_rules_state_check CHECK ( state IN ('ok', 'pending', 'firing') ),
+	CONSTRAINT alert_rules_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT alert_rules_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_alert_rules_sensor ON alert_rules ( sensor_id ) WHERE enabled;
+
+-- История срабатываний и снятий оповещений
+CREATE TABLE alert_events ( 
+	id                   serial not null unique,
+	rule_id              integer NOT NULL,
+	account_id           integer NOT NULL,
+	sensor_id            integer NOT NULL,
+	kind                 varchar(16) NOT NULL,
+	value                varchar(32),
+	message              varchar(512) DEFAULT '' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT alert_events_pkey PRIMARY KEY ( id ),
+	CONSTRAINT alert_events_rule_id_fkey FOREIGN KEY ( rule_id ) REFERENCES alert_rules( id ) ON DELETE CASCADE,
+	CONSTRAINT alert_events_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT alert_events_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_alert_events_account_time ON alert_events ( account_id, created_at );
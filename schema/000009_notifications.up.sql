@@ -0,0 +1,48 @@
+-- Настройки каналов уведомлений пользователя
+CREATE TABLE notification_preferences ( 
+	user_id              integer NOT NULL,
+	channel              varchar(16) NOT NULL,
+	target               varchar(512) DEFAULT '' NOT NULL,
+	enabled              boolean DEFAULT true NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT notification_preferences_pkey PRIMARY KEY ( user_id, channel ),
+	CONSTRAINT notification_preferences_channel_check CHECK ( channel IN ('email', 'webhook', 'file') ),
+	CONSTRAINT notification_preferences_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE
+ );
+
+-- Шаблоны уведомлений аккаунта (вместо встроенных)
+CREATE TABLE notification_templates ( 
+	account_id           integer NOT NULL,
+	event                varchar(64) NOT NULL,
+	subject              varchar(255) NOT NULL,
+	body                 text NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT notification_templates_pkey PRIMARY KEY ( account_id, event ),
+	CONSTRAINT notification_templates_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+-- Очередь и журнал доставки уведомлений
+CREATE TABLE notification_deliveries ( 
+	id                   serial not null unique,
+	user_id              integer NOT NULL,
+	account_id           integer,
+	channel              varchar(16) NOT NULL,
+	target               varchar(512) NOT NULL,
+	event                varchar(64) NOT NULL,
+	source_id            integer DEFAULT 0 NOT NULL,
+	subject              text NOT NULL,
+	body                 text NOT NULL,
+	status               varchar(16) DEFAULT 'pending' NOT NULL,
+	attempts             integer DEFAULT 0 NOT NULL,
+	next_attempt_at      timestamptz DEFAULT CURRENT_TIMESTAMP,
+	last_error           varchar(512) DEFAULT '' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	sent_at              timestamptz,
+	CONSTRAINT notification_deliveries_pkey PRIMARY KEY ( id ),
+	CONSTRAINT notification_deliveries_status_check CHECK ( status IN ('pending', 'sent', 'failed') ),
+	CONSTRAINT notification_deliveries_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE,
+	CONSTRAINT notification_deliveries_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries ( next_attempt_at ) WHERE status = 'pending';
+CREATE INDEX idx_notification_deliveries_user ON notification_deliveries ( user_id, created_at );
@@ -0,0 +1,5 @@
+ALTER TABLE alert_events DROP COLUMN IF EXISTS user_id;
+ALTER TABLE alert_events DROP COLUMN IF EXISTS alert_id;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS renotify_sec;
+
+DROP TABLE IF EXISTS alerts;
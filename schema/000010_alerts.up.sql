@@ -0,0 +1,41 @@
+-- Экземпляры оповещений: открываются срабатыванием правила, закрываются возвратом в норму или вручную.
+-- У правила одновременно открыт не более одного экземпляра.
+CREATE TABLE alerts ( 
+	id                   serial not null unique,
+	rule_id              integer NOT NULL,
+	account_id           integer NOT NULL,
+	sensor_id            integer NOT NULL,
+	status               varchar(16) DEFAULT 'firing' NOT NULL,
+	value                varchar(32),
+	message              varchar(512) DEFAULT '' NOT NULL,
+	started_at           timestamptz NOT NULL,
+	acknowledged_at      timestamptz,
+	acknowledged_by      integer,
+	snoozed_until        timestamptz,
+	resolved_at          timestamptz,
+	resolved_by          integer,
+	last_notified_at     timestamptz,
+	notify_count         integer DEFAULT 0 NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT alerts_pkey PRIMARY KEY ( id ),
+	CONSTRAINT alerts_status_check CHECK ( status IN ('firing', 'acknowledged', 'snoozed', 'resolved') ),
+	CONSTRAINT alerts_rule_id_fkey FOREIGN KEY ( rule_id ) REFERENCES alert_rules( id ) ON DELETE CASCADE,
+	CONSTRAINT alerts_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT alerts_sensor_id_fkey FOREIGN KEY ( sensor_id ) REFERENCES sensors( id ) ON DELETE CASCADE,
+	CONSTRAINT alerts_acknowledged_by_fkey FOREIGN KEY ( acknowledged_by ) REFERENCES users( id ) ON DELETE SET NULL,
+	CONSTRAINT alerts_resolved_by_fkey FOREIGN KEY ( resolved_by ) REFERENCES users( id ) ON DELETE SET NULL
+ );
+
+CREATE UNIQUE INDEX idx_alerts_open_rule ON alerts ( rule_id ) WHERE status <> 'resolved';
+CREATE INDEX idx_alerts_account_time ON alerts ( account_id, started_at );
+
+-- Интервал повторных уведомлений о неподтверждённом оповещении; 0 - не повторять
+ALTER TABLE alert_rules ADD COLUMN renotify_sec integer DEFAULT 0 NOT NULL;
+
+-- События относятся к экземпляру; действия пользователей записываются с его ID
+ALTER TABLE alert_events ADD COLUMN alert_id integer;
+ALTER TABLE alert_events ADD COLUMN user_id integer;
+ALTER TABLE alert_events ADD CONSTRAINT alert_events_alert_id_fkey FOREIGN KEY ( alert_id ) REFERENCES alerts( id ) ON DELETE CASCADE;
+ALTER TABLE alert_events ADD CONSTRAINT alert_events_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE SET NULL;
+
+CREATE INDEX idx_alert_events_alert ON alert_events ( alert_id );
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS device_commands;
+DROP TABLE IF EXISTS automation_runs;
+DROP TABLE IF EXISTS automations;
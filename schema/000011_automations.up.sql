@@ -0,0 +1,66 @@
+-- Правила автоматизации аккаунта: условия, расписание и действия хранятся в jsonb,
+-- state - состояния условий между показаниями
+CREATE TABLE automations ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	title                varchar(255) NOT NULL,
+	enabled              boolean DEFAULT true NOT NULL,
+	dry_run              boolean DEFAULT false NOT NULL,
+	conditions           jsonb DEFAULT '[]'::jsonb NOT NULL,
+	schedule             jsonb,
+	actions              jsonb NOT NULL,
+	cooldown_sec         integer DEFAULT 60 NOT NULL,
+	max_runs_per_hour    integer DEFAULT 10 NOT NULL,
+	state                jsonb DEFAULT '{}'::jsonb NOT NULL,
+	checked_at           timestamptz,
+	last_run_at          timestamptz,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT automations_pkey PRIMARY KEY ( id ),
+	CONSTRAINT automations_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_automations_account ON automations ( account_id );
+
+-- Журнал запусков правил
+CREATE TABLE automation_runs ( 
+	id                   serial not null unique,
+	automation_id        integer NOT NULL,
+	account_id           integer NOT NULL,
+	"trigger"            varchar(16) NOT NULL,
+	status               varchar(16) NOT NULL,
+	message              varchar(512) DEFAULT '' NOT NULL,
+	actions              jsonb DEFAULT '[]'::jsonb NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT automation_runs_pkey PRIMARY KEY ( id ),
+	CONSTRAINT automation_runs_status_check CHECK ( status IN ('ok', 'dry_run', 'skipped', 'failed') ),
+	CONSTRAINT automation_runs_automation_id_fkey FOREIGN KEY ( automation_id ) REFERENCES automations( id ) ON DELETE CASCADE,
+	CONSTRAINT automation_runs_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_automation_runs_automation_time ON automation_runs ( automation_id, created_at );
+
+-- Очередь команд устройствам; хаб забирает команды и подтверждает выполнение
+CREATE TABLE device_commands ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	aquahub_id           integer NOT NULL,
+	device_id            integer NOT NULL,
+	automation_id        integer,
+	command              varchar(64) NOT NULL,
+	value                varchar(255) DEFAULT '' NOT NULL,
+	status               varchar(16) DEFAULT 'pending' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	delivered_at         timestamptz,
+	acked_at             timestamptz,
+	CONSTRAINT device_commands_pkey PRIMARY KEY ( id ),
+	CONSTRAINT device_commands_status_check CHECK ( status IN ('pending', 'delivered', 'acked', 'expired') ),
+	CONSTRAINT device_commands_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT device_commands_aquahub_id_fkey FOREIGN KEY ( aquahub_id ) REFERENCES aquahubs( id ) ON DELETE CASCADE,
+	CONSTRAINT device_commands_device_id_fkey FOREIGN KEY ( device_id ) REFERENCES devices( id ) ON DELETE CASCADE,
+	CONSTRAINT device_commands_automation_id_fkey FOREIGN KEY ( automation_id ) REFERENCES automations( id ) ON DELETE SET NULL
+ );
+
+CREATE INDEX idx_device_commands_hub_open ON device_commands ( aquahub_id, id ) WHERE status IN ('pending', 'delivered');
+CREATE INDEX idx_device_commands_account_time ON device_commands ( account_id, created_at );
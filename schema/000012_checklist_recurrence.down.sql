@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_checklists_next_run;
+DROP INDEX IF EXISTS idx_checklists_template_period;
+
+ALTER TABLE checklists DROP CONSTRAINT IF EXISTS checklists_template_id_fkey;
+ALTER TABLE checklists DROP COLUMN IF EXISTS period_end;
+ALTER TABLE checklists DROP COLUMN IF EXISTS period_start;
+ALTER TABLE checklists DROP COLUMN IF EXISTS template_id;
+
+ALTER TABLE checklists DROP COLUMN IF EXISTS next_run_at;
+ALTER TABLE checklists DROP COLUMN IF EXISTS recurrence_start;
+ALTER TABLE checklists DROP COLUMN IF EXISTS recurrence;
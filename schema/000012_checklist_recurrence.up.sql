@@ -0,0 +1,16 @@
+-- Повторяющийся чек-лист - шаблон: по правилу recurrence (подмножество RRULE) от recurrence_start
+-- во временной зоне аккаунта создаются экземпляры с неотмеченными пунктами шаблона.
+-- next_run_at - начало следующего периода, для которого ещё нет экземпляра
+ALTER TABLE checklists ADD COLUMN recurrence varchar(255) DEFAULT ''::character varying NOT NULL;
+ALTER TABLE checklists ADD COLUMN recurrence_start timestamptz;
+ALTER TABLE checklists ADD COLUMN next_run_at timestamptz;
+
+-- Экземпляр ссылается на шаблон и свой период
+ALTER TABLE checklists ADD COLUMN template_id integer;
+ALTER TABLE checklists ADD COLUMN period_start timestamptz;
+ALTER TABLE checklists ADD COLUMN period_end timestamptz;
+ALTER TABLE checklists ADD CONSTRAINT checklists_template_id_fkey FOREIGN KEY ( template_id ) REFERENCES checklists( id ) ON DELETE SET NULL;
+
+-- Один экземпляр на период шаблона
+CREATE UNIQUE INDEX idx_checklists_template_period ON checklists ( template_id, period_start ) WHERE template_id IS NOT NULL;
+CREATE INDEX idx_checklists_next_run ON checklists ( next_run_at ) WHERE next_run_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sensors_dataset_aquahub_time;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS offline_at;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
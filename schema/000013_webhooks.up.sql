@@ -0,0 +1,45 @@
+-- Подписки аккаунта на события платформы; запросы подписываются секретом подписки
+CREATE TABLE webhooks ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	url                  varchar(512) NOT NULL,
+	secret               varchar(64) NOT NULL,
+	events               varchar(32)[] NOT NULL,
+	description          varchar(255) DEFAULT ''::character varying NOT NULL,
+	enabled              boolean DEFAULT true NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT webhooks_pkey PRIMARY KEY ( id ),
+	CONSTRAINT webhooks_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_webhooks_account ON webhooks ( account_id ) WHERE enabled;
+
+-- Очередь и журнал доставки: доставка повторяется, пока получатель не ответит 2xx.
+-- event_id одинаков у всех доставок события и у повторной доставки - получатель отбрасывает дубликаты по нему
+CREATE TABLE webhook_deliveries ( 
+	id                   serial not null unique,
+	webhook_id           integer NOT NULL,
+	account_id           integer NOT NULL,
+	event                varchar(32) NOT NULL,
+	event_id             varchar(32) NOT NULL,
+	payload              jsonb NOT NULL,
+	status               varchar(16) DEFAULT 'pending' NOT NULL,
+	attempts             integer DEFAULT 0 NOT NULL,
+	next_attempt_at      timestamptz DEFAULT CURRENT_TIMESTAMP,
+	response_status      integer DEFAULT 0 NOT NULL,
+	last_error           varchar(512) DEFAULT '' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	delivered_at         timestamptz,
+	CONSTRAINT webhook_deliveries_pkey PRIMARY KEY ( id ),
+	CONSTRAINT webhook_deliveries_status_check CHECK ( status IN ('pending', 'sent', 'failed') ),
+	CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY ( webhook_id ) REFERENCES webhooks( id ) ON DELETE CASCADE,
+	CONSTRAINT webhook_deliveries_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries ( next_attempt_at ) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_time ON webhook_deliveries ( webhook_id, created_at );
+
+-- Хаб без показаний дольше порога считается отключённым; событие отправляется один раз до новых показаний
+ALTER TABLE aquahubs ADD COLUMN offline_at timestamptz;
+CREATE INDEX idx_sensors_dataset_aquahub_time ON sensors_dataset ( aquahub_id, created_at );
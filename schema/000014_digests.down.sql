@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS digest_reports;
+DROP TABLE IF EXISTS digest_subscriptions;
@@ -0,0 +1,36 @@
+-- Подписка участника аккаунта на сводки; время отправки - час в часовом поясе пользователя (users.timezone).
+-- last_*_start - начало последнего отправленного периода
+CREATE TABLE digest_subscriptions ( 
+	user_id              integer NOT NULL,
+	account_id           integer NOT NULL,
+	daily                boolean DEFAULT true NOT NULL,
+	weekly               boolean DEFAULT false NOT NULL,
+	hour                 integer DEFAULT 8 NOT NULL,
+	last_daily_start     timestamptz,
+	last_weekly_start    timestamptz,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT digest_subscriptions_pkey PRIMARY KEY ( user_id, account_id ),
+	CONSTRAINT digest_subscriptions_hour_check CHECK ( hour BETWEEN 0 AND 23 ),
+	CONSTRAINT digest_subscriptions_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE,
+	CONSTRAINT digest_subscriptions_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+-- Архив сводок; один отчёт на пользователя, аккаунт и период
+CREATE TABLE digest_reports ( 
+	id                   serial not null unique,
+	user_id              integer NOT NULL,
+	account_id           integer NOT NULL,
+	kind                 varchar(16) NOT NULL,
+	period_start         timestamptz NOT NULL,
+	period_end           timestamptz NOT NULL,
+	timezone             varchar(128) NOT NULL,
+	report               jsonb NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT digest_reports_pkey PRIMARY KEY ( id ),
+	CONSTRAINT digest_reports_kind_check CHECK ( kind IN ('daily', 'weekly') ),
+	CONSTRAINT digest_reports_period_key UNIQUE ( user_id, account_id, kind, period_start ),
+	CONSTRAINT digest_reports_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE,
+	CONSTRAINT digest_reports_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_digest_reports_user_time ON digest_reports ( user_id, period_start );
@@ -0,0 +1,7 @@
+DELETE FROM device_commands WHERE device_id IS NULL;
+ALTER TABLE device_commands ALTER COLUMN device_id SET NOT NULL;
+
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS reset_requested_at;
+DROP INDEX IF EXISTS idx_aquahubs_h_token;
+
+DROP TABLE IF EXISTS hub_pairings;
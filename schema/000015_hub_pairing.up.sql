@@ -0,0 +1,19 @@
+-- Коды привязки хабов: хаб с заводским h_token получает короткий код,
+-- пользователь вводит код, и хаб привязывается к аккаунту. Запись удаляется при привязке.
+CREATE TABLE hub_pairings ( 
+	h_token              varchar(32) NOT NULL,
+	code                 varchar(8) NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	CONSTRAINT hub_pairings_pkey PRIMARY KEY ( h_token ),
+	CONSTRAINT hub_pairings_code_key UNIQUE ( code )
+ );
+
+-- Токен хаба однозначно определяет хаб
+CREATE UNIQUE INDEX idx_aquahubs_h_token ON aquahubs ( h_token );
+
+-- Запрошен сброс к заводским настройкам: хаб отвязывается, когда заново запросит код привязки
+ALTER TABLE aquahubs ADD COLUMN reset_requested_at timestamptz;
+
+-- Команды хабу целиком (без устройства), например factory_reset
+ALTER TABLE device_commands ALTER COLUMN device_id DROP NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS entity_tags;
+
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS group_id;
+
+DROP TABLE IF EXISTS hub_groups;
@@ -0,0 +1,33 @@
+-- Группы хабов аккаунта: площадка -> зона -> резервуар
+CREATE TABLE hub_groups ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	parent_id            integer,
+	kind                 varchar(8) NOT NULL,
+	title                varchar(255) NOT NULL,
+	description          varchar(255) DEFAULT '' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT hub_groups_pkey PRIMARY KEY ( id ),
+	CONSTRAINT hub_groups_kind_check CHECK ( kind IN ('site', 'zone', 'tank') ),
+	CONSTRAINT hub_groups_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT hub_groups_parent_id_fkey FOREIGN KEY ( parent_id ) REFERENCES hub_groups( id )
+ );
+
+CREATE INDEX idx_hub_groups_account ON hub_groups ( account_id );
+CREATE INDEX idx_hub_groups_parent ON hub_groups ( parent_id );
+
+ALTER TABLE aquahubs ADD COLUMN group_id integer;
+ALTER TABLE aquahubs ADD CONSTRAINT aquahubs_group_id_fkey FOREIGN KEY ( group_id ) REFERENCES hub_groups( id ) ON DELETE SET NULL;
+
+-- Теги хабов, устройств и сенсоров
+CREATE TABLE entity_tags ( 
+	account_id           integer NOT NULL,
+	entity               varchar(16) NOT NULL,
+	entity_id            integer NOT NULL,
+	tag                  varchar(64) NOT NULL,
+	CONSTRAINT entity_tags_pkey PRIMARY KEY ( entity, entity_id, tag ),
+	CONSTRAINT entity_tags_entity_check CHECK ( entity IN ('aquahub', 'device', 'sensor') ),
+	CONSTRAINT entity_tags_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_entity_tags_account_tag ON entity_tags ( account_id, tag );
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_geonames_country_place;
+DROP INDEX IF EXISTS idx_geonames_country_postal;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS longitude;
+ALTER TABLE accounts DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS timezone;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS longitude;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS latitude;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS state_name;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS place_name;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS postal_code;
+ALTER TABLE aquahubs DROP COLUMN IF EXISTS country;
@@ -0,0 +1,17 @@
+-- Местоположение хаба: страна и почтовый индекс, место из geonames, часовой пояс.
+-- Хаб без часового пояса живёт по часовому поясу аккаунта.
+ALTER TABLE aquahubs ADD COLUMN country char(2);
+ALTER TABLE aquahubs ADD COLUMN postal_code varchar(60);
+ALTER TABLE aquahubs ADD COLUMN place_name varchar(200);
+ALTER TABLE aquahubs ADD COLUMN state_name varchar(200);
+ALTER TABLE aquahubs ADD COLUMN latitude double precision;
+ALTER TABLE aquahubs ADD COLUMN longitude double precision;
+ALTER TABLE aquahubs ADD COLUMN timezone varchar(50);
+
+-- Координаты места аккаунта (страна, индекс, город и регион уже есть в accounts)
+ALTER TABLE accounts ADD COLUMN latitude double precision;
+ALTER TABLE accounts ADD COLUMN longitude double precision;
+
+-- Автодополнение по началу индекса или названия места в стране
+CREATE INDEX idx_geonames_country_postal ON geonames ( country_code, postal_code varchar_pattern_ops );
+CREATE INDEX idx_geonames_country_place ON geonames ( country_code, lower(place_name) varchar_pattern_ops );
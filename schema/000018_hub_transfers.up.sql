@@ -0,0 +1,37 @@
+-- Передача хаба другому аккаунту: владелец создаёт передачу и сообщает код получателю,
+-- получатель принимает её кодом. Записи не удаляются и служат журналом передач.
+CREATE TABLE hub_transfers ( 
+	id                   serial not null unique,
+	aquahub_id           integer NOT NULL,
+	from_account_id      integer NOT NULL,
+	to_account_id        integer,
+	new_aquahub_id       integer,
+	mode                 varchar(8) NOT NULL,
+	code                 varchar(8) NOT NULL,
+	status               varchar(16) DEFAULT 'pending' NOT NULL,
+	created_by           integer,
+	accepted_by          integer,
+	cancelled_by         integer,
+	old_h_token          varchar(32),
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	completed_at         timestamptz,
+	delivered_at         timestamptz,
+	CONSTRAINT hub_transfers_pkey PRIMARY KEY ( id ),
+	CONSTRAINT hub_transfers_mode_check CHECK ( mode IN ('move', 'keep') ),
+	CONSTRAINT hub_transfers_status_check CHECK ( status IN ('pending', 'completed', 'cancelled') ),
+	CONSTRAINT hub_transfers_aquahub_id_fkey FOREIGN KEY ( aquahub_id ) REFERENCES aquahubs( id ) ON DELETE CASCADE,
+	CONSTRAINT hub_transfers_new_aquahub_id_fkey FOREIGN KEY ( new_aquahub_id ) REFERENCES aquahubs( id ) ON DELETE SET NULL,
+	CONSTRAINT hub_transfers_from_account_id_fkey FOREIGN KEY ( from_account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT hub_transfers_to_account_id_fkey FOREIGN KEY ( to_account_id ) REFERENCES accounts( id ) ON DELETE SET NULL,
+	CONSTRAINT hub_transfers_created_by_fkey FOREIGN KEY ( created_by ) REFERENCES users( id ) ON DELETE SET NULL,
+	CONSTRAINT hub_transfers_accepted_by_fkey FOREIGN KEY ( accepted_by ) REFERENCES users( id ) ON DELETE SET NULL,
+	CONSTRAINT hub_transfers_cancelled_by_fkey FOREIGN KEY ( cancelled_by ) REFERENCES users( id ) ON DELETE SET NULL
+ );
+
+-- У хаба не больше одной ожидающей передачи, код ожидающей передачи уникален
+CREATE UNIQUE INDEX idx_hub_transfers_pending_hub ON hub_transfers ( aquahub_id ) WHERE status = 'pending';
+CREATE UNIQUE INDEX idx_hub_transfers_pending_code ON hub_transfers ( code ) WHERE status = 'pending';
+CREATE INDEX idx_hub_transfers_old_h_token ON hub_transfers ( old_h_token ) WHERE old_h_token IS NOT NULL;
+CREATE INDEX idx_hub_transfers_from ON hub_transfers ( from_account_id );
+CREATE INDEX idx_hub_transfers_to ON hub_transfers ( to_account_id );
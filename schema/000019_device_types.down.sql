@@ -0,0 +1,7 @@
+ALTER TABLE sensors DROP CONSTRAINT IF EXISTS sensors_data_type_check;
+ALTER TABLE sensors DROP COLUMN IF EXISTS data_type;
+
+ALTER TABLE devices DROP COLUMN IF EXISTS device_type_id;
+
+DROP TABLE IF EXISTS device_type_sensors;
+DROP TABLE IF EXISTS device_types;
@@ -0,0 +1,51 @@
+-- Каталог типов устройств: модель, о которой хаб сообщает в /v1/device/add, и раскладка её сенсоров.
+-- Типы без account_id - общий каталог, типы аккаунта - его шаблоны, они перекрывают общий каталог
+CREATE TABLE device_types ( 
+	id                   serial not null unique,
+	account_id           integer,
+	model                varchar(64) NOT NULL,
+	title                varchar(255) NOT NULL,
+	description          varchar(255) DEFAULT '' NOT NULL,
+	manufacturer         varchar(100) DEFAULT '' NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT device_types_pkey PRIMARY KEY ( id ),
+	CONSTRAINT device_types_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE
+ );
+
+CREATE UNIQUE INDEX uq_device_types_model ON device_types ( lower(model) ) WHERE account_id IS NULL;
+CREATE UNIQUE INDEX uq_device_types_account_model ON device_types ( account_id, lower(model) ) WHERE account_id IS NOT NULL;
+
+-- Сенсоры типа: описание, единицы, тип данных и диапазон для правила оповещения по умолчанию
+CREATE TABLE device_type_sensors ( 
+	device_type_id       integer NOT NULL,
+	local_id             integer NOT NULL,
+	title                varchar(255) NOT NULL,
+	description          varchar(255) DEFAULT '' NOT NULL,
+	unit                 varchar(32) DEFAULT '' NOT NULL,
+	data_type            varchar(16) DEFAULT 'float' NOT NULL,
+	report_interval_sec  integer,
+	for_analytics        boolean DEFAULT false NOT NULL,
+	alert_low            double precision,
+	alert_high           double precision,
+	CONSTRAINT device_type_sensors_pkey PRIMARY KEY ( device_type_id, local_id ),
+	CONSTRAINT device_type_sensors_data_type_check CHECK ( data_type IN ('float', 'int', 'bool', 'string') ),
+	CONSTRAINT device_type_sensors_device_type_id_fkey FOREIGN KEY ( device_type_id ) REFERENCES device_types( id ) ON DELETE CASCADE
+ );
+
+ALTER TABLE devices ADD COLUMN device_type_id integer;
+ALTER TABLE devices ADD CONSTRAINT devices_device_type_id_fkey FOREIGN KEY ( device_type_id ) REFERENCES device_types( id ) ON DELETE SET NULL;
+
+ALTER TABLE sensors ADD COLUMN data_type varchar(16) DEFAULT 'float' NOT NULL;
+ALTER TABLE sensors ADD CONSTRAINT sensors_data_type_check CHECK ( data_type IN ('float', 'int', 'bool', 'string') );
+
+INSERT INTO device_types( model, title, description, manufacturer ) VALUES ( 'Home_Weather', 'Home weather', 'Room temperature, humidity and pressure', 'AquaHub');
+INSERT INTO device_types( model, title, description, manufacturer ) VALUES ( 'PowerMeter', 'Power meter', 'Mains voltage, current, power and energy', 'AquaHub');
+
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 0, 'Temperature', 'Room temperature', '°C', 'float', 60, true, 15, 30 FROM device_types WHERE model = 'Home_Weather';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 1, 'Humidity', 'Relative humidity', '%', 'float', 60, true, 30, 80 FROM device_types WHERE model = 'Home_Weather';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 2, 'Pressure', 'Atmospheric pressure', 'hPa', 'float', 60, false, NULL, NULL FROM device_types WHERE model = 'Home_Weather';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 0, 'Voltage', 'Mains voltage', 'V', 'float', 60, true, 200, 250 FROM device_types WHERE model = 'PowerMeter';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 1, 'Current', 'Load current', 'A', 'float', 60, false, NULL, NULL FROM device_types WHERE model = 'PowerMeter';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 2, 'Power', 'Active power', 'W', 'float', 60, true, NULL, NULL FROM device_types WHERE model = 'PowerMeter';
+INSERT INTO device_type_sensors( device_type_id, local_id, title, description, unit, data_type, report_interval_sec, for_analytics, alert_low, alert_high ) SELECT id, 3, 'Energy', 'Consumed energy', 'kWh', 'float', 300, true, NULL, NULL FROM device_types WHERE model = 'PowerMeter';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_checklist_items_archived_at;
+DROP INDEX IF EXISTS idx_checklists_archived_at;
+DROP INDEX IF EXISTS idx_sensors_archived_at;
+DROP INDEX IF EXISTS idx_devices_archived_at;
+DROP INDEX IF EXISTS idx_aquahubs_archived_at;
+
+ALTER TABLE sensors DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,15 @@
+-- Архив: удаление хабов, устройств, сенсоров и чек-листов переносит их в архив (archived_at),
+-- из архива их можно восстановить до окончательного удаления плановой задачей
+ALTER TABLE devices ADD COLUMN archived_at timestamptz;
+ALTER TABLE sensors ADD COLUMN archived_at timestamptz;
+
+-- archived_at хабов и устройств следует их статусу
+UPDATE aquahubs SET archived_at = NULL WHERE COALESCE(status, 'active') = 'active';
+UPDATE aquahubs SET archived_at = COALESCE(updated_at, created_at) WHERE status = 'archived' AND archived_at IS NULL;
+UPDATE devices SET archived_at = CURRENT_TIMESTAMP WHERE status = 'archived';
+
+CREATE INDEX idx_aquahubs_archived_at ON aquahubs ( archived_at ) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_devices_archived_at ON devices ( archived_at ) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_sensors_archived_at ON sensors ( archived_at ) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_checklists_archived_at ON checklists ( archived_at ) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_checklist_items_archived_at ON checklist_items ( archived_at ) WHERE archived_at IS NOT NULL;
@@ -0,0 +1 @@
+-- Роль менеджера, назначенная участникам при миграции, не снимается: после неё роль могла быть изменена через API
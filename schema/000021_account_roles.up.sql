@@ -0,0 +1,6 @@
+-- Роли участников аккаунта проверяются при доступе: пользователь, зарегистрировавший аккаунт,
+-- получает роль менеджера (раньше при регистрации назначалась роль user)
+UPDATE users_accounts ua SET roles = array_append(ua.roles, 'manager'), updated_at = CURRENT_TIMESTAMP
+	FROM accounts a
+	WHERE a.id = ua.account_id AND a.signup_user_id = ua.user_id AND NOT 'manager' = ANY(ua.roles);
+
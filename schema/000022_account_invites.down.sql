@@ -0,0 +1,4 @@
+DELETE FROM notification_deliveries WHERE user_id IS NULL;
+ALTER TABLE notification_deliveries ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS account_invites;
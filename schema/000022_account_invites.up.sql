@@ -0,0 +1,31 @@
+-- Приглашения в аккаунт по email: менеджер приглашает, получатель принимает подписанный токен из письма.
+-- В БД хранится только хеш одноразовой части токена; при повторной отправке она меняется.
+CREATE TABLE account_invites ( 
+	id                   serial not null unique,
+	account_id           integer NOT NULL,
+	email                varchar(200) NOT NULL,
+	roles                _user_account_role_t DEFAULT '{user}'::user_account_role_t[] NOT NULL,
+	token_hash           varchar(64) NOT NULL,
+	status               varchar(16) DEFAULT 'pending' NOT NULL,
+	invited_by           integer,
+	accepted_by          integer,
+	revoked_by           integer,
+	sent_count           integer DEFAULT 0 NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	sent_at              timestamptz,
+	accepted_at          timestamptz,
+	revoked_at           timestamptz,
+	CONSTRAINT account_invites_pkey PRIMARY KEY ( id ),
+	CONSTRAINT account_invites_status_check CHECK ( status IN ('pending', 'accepted', 'revoked', 'expired') ),
+	CONSTRAINT account_invites_account_id_fkey FOREIGN KEY ( account_id ) REFERENCES accounts( id ) ON DELETE CASCADE,
+	CONSTRAINT account_invites_invited_by_fkey FOREIGN KEY ( invited_by ) REFERENCES users( id ) ON DELETE SET NULL,
+	CONSTRAINT account_invites_accepted_by_fkey FOREIGN KEY ( accepted_by ) REFERENCES users( id ) ON DELETE SET NULL,
+	CONSTRAINT account_invites_revoked_by_fkey FOREIGN KEY ( revoked_by ) REFERENCES users( id ) ON DELETE SET NULL
+ );
+
+-- На один адрес в аккаунте не больше одного ожидающего приглашения
+CREATE UNIQUE INDEX idx_account_invites_pending_email ON account_invites ( account_id, lower(email) ) WHERE status = 'pending';
+
+-- Письма с приглашениями уходят адресатам, у которых ещё нет пользователя
+ALTER TABLE notification_deliveries ALTER COLUMN user_id DROP NOT NULL;
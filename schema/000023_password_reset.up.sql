@@ -0,0 +1,3 @@
+-- Время последней смены пароля: токены доступа, выданные раньше, не принимаются.
+-- В users.password_reset хранится часть хеша одноразовой части токена сброса пароля.
+ALTER TABLE users ADD COLUMN password_changed_at timestamptz;
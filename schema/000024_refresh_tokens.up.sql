@@ -0,0 +1,33 @@
+-- Токены обновления: выдаются при входе вместе с коротким токеном доступа и меняются при каждом
+-- обновлении. Токены одного входа образуют семейство (family); повторное использование уже
+-- обменянного токена отзывает всё семейство. В БД хранится только хеш токена.
+CREATE TABLE refresh_tokens ( 
+	id                   serial not null unique,
+	user_id              integer NOT NULL,
+	family               varchar(36) NOT NULL,
+	token_hash           varchar(64) NOT NULL,
+	access_jti           varchar(36) NOT NULL,
+	access_expires_at    timestamptz NOT NULL,
+	created_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	used_at              timestamptz,
+	revoked_at           timestamptz,
+	CONSTRAINT refresh_tokens_pkey PRIMARY KEY ( id ),
+	CONSTRAINT refresh_tokens_token_hash_key UNIQUE ( token_hash ),
+	CONSTRAINT refresh_tokens_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_refresh_tokens_user_family ON refresh_tokens ( user_id, family );
+CREATE INDEX idx_refresh_tokens_access_jti ON refresh_tokens ( access_jti );
+
+-- Отозванные токены доступа (по jti) до окончания их срока действия
+CREATE TABLE revoked_tokens ( 
+	jti                  varchar(36) NOT NULL,
+	user_id              integer NOT NULL,
+	expires_at           timestamptz NOT NULL,
+	revoked_at           timestamptz DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	CONSTRAINT revoked_tokens_pkey PRIMARY KEY ( jti ),
+	CONSTRAINT revoked_tokens_user_id_fkey FOREIGN KEY ( user_id ) REFERENCES users( id ) ON DELETE CASCADE
+ );
+
+CREATE INDEX idx_revoked_tokens_expires ON revoked_tokens ( expires_at );
@@ -0,0 +1,3 @@
+ALTER TABLE hub_transfers ADD COLUMN delivered_at timestamptz;
+ALTER TABLE hub_transfers ADD COLUMN old_h_token varchar(32);
+CREATE INDEX idx_hub_transfers_old_h_token ON hub_transfers ( old_h_token ) WHERE old_h_token IS NOT NULL;
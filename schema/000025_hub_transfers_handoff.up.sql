@@ -0,0 +1,4 @@
+-- Новый токен переданного хаба выдаётся только получателю передачи, по прежнему токену хаба он не выдаётся
+DROP INDEX IF EXISTS idx_hub_transfers_old_h_token;
+ALTER TABLE hub_transfers DROP COLUMN IF EXISTS old_h_token;
+ALTER TABLE hub_transfers DROP COLUMN IF EXISTS delivered_at;